@@ -2,10 +2,13 @@ package blockscanner
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -14,6 +17,7 @@ import (
 	"github.com/mapprotocol/compass-tss/common"
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/internal/tracing"
 	"github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/metrics"
 	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
@@ -31,6 +35,9 @@ type Fetcher interface {
 	GetNetworkFee() (transactionSize, txSwapGasLimit, transactionFeeRate uint64)
 }
 
+// MaxRescanBlocks is the max number of blocks which can be rescanned at once
+const MaxRescanBlocks = 1000
+
 type Block struct {
 	Height int64
 	Txs    []string
@@ -192,7 +199,7 @@ func (b *BlockScanner) isChainPaused() bool {
 	//}
 	//
 	//return (haltHeight > 0 && thorHeight > haltHeight) || (solvencyHaltHeight > 0 && thorHeight > solvencyHaltHeight)
-	return false
+	return IsChainPausedLocally(b.cfg.ChainID)
 }
 
 // scanBlocks
@@ -235,6 +242,7 @@ func (b *BlockScanner) scanBlocks() {
 				time.Sleep(b.cfg.BlockHeightDiscoverBackoff)
 				continue
 			}
+			fetchStart := time.Now()
 			txIn, err := b.chainScanner.FetchTxs(currentBlock, latestHeight)
 			if err != nil {
 				// don't log an error if its because the block doesn't exist yet
@@ -280,6 +288,7 @@ func (b *BlockScanner) scanBlocks() {
 					return
 				case b.globalTxsQueue <- txIn:
 				}
+				b.traceBlockScan(txIn, currentBlock, latestHeight, fetchStart)
 			}
 			if err = b.scannerStorage.SetScanPos(b.previousBlock); err != nil {
 				b.logger.Error().Err(err).Msg("fail to save block scan pos")
@@ -290,6 +299,106 @@ func (b *BlockScanner) scanBlocks() {
 	}
 }
 
+// traceBlockScan records the scan of the block for the orders observed in it, from the
+// fetch of the block until its txs were handed to the observer
+func (b *BlockScanner) traceBlockScan(txIn types.TxIn, height, chainHeight int64, start time.Time) {
+	end := time.Now()
+	for _, item := range txIn.TxArray {
+		if item.OrderId == (ecommon.Hash{}) {
+			continue
+		}
+		tracing.Record(item.OrderId.Hex(), tracing.StageBlockScan, start, end, nil,
+			"chain", b.cfg.ChainID.String(),
+			"height", strconv.FormatInt(height, 10),
+			"chain_height", strconv.FormatInt(chainHeight, 10),
+			"tx", item.Tx)
+	}
+}
+
+// Rescan fetches the blocks in the given range again and sends their txs to the observer,
+// the scan position is left untouched. Only blocks which have been scanned already can be
+// rescanned, the observer dedupes the txs it has seen before.
+func (b *BlockScanner) Rescan(from, to int64) error {
+	if from <= 0 || to < from {
+		return fmt.Errorf("invalid height range %d-%d", from, to)
+	}
+	if to-from >= MaxRescanBlocks {
+		return fmt.Errorf("can't rescan more than %d blocks at once", MaxRescanBlocks)
+	}
+	if previous := b.PreviousHeight(); to > previous {
+		return fmt.Errorf("height %d has not been scanned yet, scanner is at %d", to, previous)
+	}
+	if b.globalTxsQueue == nil {
+		return errors.New("block scanner is not started")
+	}
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.logger.Info().Int64("from", from).Int64("to", to).Msg("start to rescan blocks")
+		for height := from; height <= to; height++ {
+			select {
+			case <-b.stopChan:
+				return
+			default:
+			}
+			latestHeight, err := b.chainScanner.GetHeight()
+			if err != nil {
+				b.logger.Error().Err(err).Int64("block height", height).Msg("fail to get chain block height, stop rescan")
+				return
+			}
+			txIn, err := b.chainScanner.FetchTxs(height, latestHeight)
+			if err != nil {
+				b.logger.Error().Err(err).Int64("block height", height).Msg("fail to rescan block")
+				continue
+			}
+			if len(txIn.TxArray) == 0 {
+				continue
+			}
+			select {
+			case <-b.stopChan:
+				return
+			case b.globalTxsQueue <- txIn:
+			}
+		}
+		b.logger.Info().Int64("from", from).Int64("to", to).Msg("finish to rescan blocks")
+	}()
+	return nil
+}
+
+// ScanRange fetches the blocks in the given range one after another and returns the txs
+// found in them. Unlike Rescan nothing is sent to the observer and the scanner doesn't
+// need to be started, it is used to scan recorded rpc traffic offline. The chain scanner
+// is expected to be configured not to report network fee or solvency, there is nobody
+// to report them to.
+func (b *BlockScanner) ScanRange(from, to int64) ([]types.TxIn, error) {
+	if from <= 0 || to < from {
+		return nil, fmt.Errorf("invalid height range %d-%d", from, to)
+	}
+	if to-from >= MaxRescanBlocks {
+		return nil, fmt.Errorf("can't scan more than %d blocks at once", MaxRescanBlocks)
+	}
+	latestHeight, err := b.chainScanner.GetHeight()
+	if err != nil {
+		return nil, fmt.Errorf("fail to get chain block height: %w", err)
+	}
+	if latestHeight < to {
+		return nil, fmt.Errorf("height %d is beyond the chain height %d", to, latestHeight)
+	}
+
+	var result []types.TxIn
+	for height := from; height <= to; height++ {
+		txIn, err := b.chainScanner.FetchTxs(height, latestHeight)
+		if err != nil {
+			return nil, fmt.Errorf("fail to fetch block %d: %w", height, err)
+		}
+		if len(txIn.TxArray) > 0 {
+			result = append(result, txIn)
+		}
+	}
+	return result, nil
+}
+
 // updateStaleNetworkFee broadcasts a network fee observation if the local scanner fee
 // does not match the fee published to THORNode. This can be called periodically to
 // ensure fee changes find consensus despite raciness on the observation height.
@@ -0,0 +1,39 @@
+package blockscanner
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/mapprotocol/compass-tss/common"
+)
+
+// localPauses records the chains paused by the node operator, scanning and signing
+// for a paused chain stop on this node only
+var localPauses sync.Map
+
+// PauseChain pauses the given chain locally
+func PauseChain(chain common.Chain) {
+	localPauses.Store(chain.String(), chain)
+}
+
+// ResumeChain resumes the given chain if it was paused locally
+func ResumeChain(chain common.Chain) {
+	localPauses.Delete(chain.String())
+}
+
+// IsChainPausedLocally returns true if the given chain has been paused locally
+func IsChainPausedLocally(chain common.Chain) bool {
+	_, ok := localPauses.Load(chain.String())
+	return ok
+}
+
+// PausedChains returns the chains paused locally
+func PausedChains() []common.Chain {
+	chains := make([]common.Chain, 0)
+	localPauses.Range(func(_, value any) bool {
+		chains = append(chains, value.(common.Chain))
+		return true
+	})
+	sort.Slice(chains, func(i, j int) bool { return chains[i].String() < chains[j].String() })
+	return chains
+}
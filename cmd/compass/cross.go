@@ -5,14 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
 
 	_ "github.com/mapprotocol/compass-tss/cmd/compass/docs"
 
+	tcommon "github.com/mapprotocol/compass-tss/common"
 	"github.com/mapprotocol/compass-tss/internal/cross"
+	"github.com/mapprotocol/compass-tss/internal/tracing"
+	mem "github.com/mapprotocol/compass-tss/x/memo"
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	"github.com/gorilla/mux"
@@ -29,6 +34,9 @@ type CrossServer struct {
 	logger    zerolog.Logger
 	s         *http.Server
 	dbStorage *cross.CrossStorage
+	tracer    *tracing.Tracer
+	resolver  mem.Resolver
+	heights   func(chain tcommon.Chain) (int64, error)
 }
 
 // NewCrossServer create a new instance of health server
@@ -47,6 +55,23 @@ func NewCrossServer(addr string, dbStorage *cross.CrossStorage) *CrossServer {
 	return hs
 }
 
+// SetTracer sets the tracer the stage timings of the orders are served from
+func (s *CrossServer) SetTracer(tracer *tracing.Tracer) {
+	s.tracer = tracer
+}
+
+// SetChainHeights sets where the current heights of the chains are read from, destination
+// tags can only be registered for the ledgers a chain has not reached yet
+func (s *CrossServer) SetChainHeights(heights func(chain tcommon.Chain) (int64, error)) {
+	s.heights = heights
+}
+
+// SetMemoResolver sets where the chains and the affiliates of the explained memos are
+// looked up, they are only checked locally without it
+func (s *CrossServer) SetMemoResolver(resolver mem.Resolver) {
+	s.resolver = resolver
+}
+
 func (s *CrossServer) newHandler() http.Handler {
 	router := mux.NewRouter()
 	router.Handle("/ping", http.HandlerFunc(s.pingHandler)).Methods(http.MethodGet)
@@ -56,6 +81,11 @@ func (s *CrossServer) newHandler() http.Handler {
 	router.Handle("/cross/pending/tx", http.HandlerFunc(s.pendingTx)).Methods(http.MethodGet)
 	router.Handle("/cross/height/range/txs", http.HandlerFunc(s.GetTxByHeightRange)).Methods(http.MethodGet)
 	router.Handle("/cross/tx", http.HandlerFunc(s.crossFindByTx)).Methods(http.MethodGet)
+	router.Handle("/cross/destination/tags", http.HandlerFunc(s.destinationTags)).Methods(http.MethodGet)
+	router.Handle("/cross/destination/tag", http.HandlerFunc(s.destinationTag)).Methods(http.MethodGet)
+	router.Handle("/cross/destination/tag", http.HandlerFunc(s.localOnly(s.registerDestinationTag))).Methods(http.MethodPost)
+	router.Handle("/cross/destination/tag", http.HandlerFunc(s.localOnly(s.retireDestinationTag))).Methods(http.MethodDelete)
+	router.Handle("/cross/memo/explain", http.HandlerFunc(s.explainMemo)).Methods(http.MethodGet)
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	return router
@@ -68,6 +98,8 @@ func (s *CrossServer) pingHandler(w http.ResponseWriter, _ *http.Request) {
 // CrossSignelResponse is the response for cross signel
 type CrossSignelResponse struct {
 	Data *cross.CrossSet `json:"data"`
+	// Stages are the timings of the stages the order passed on this node, earliest first
+	Stages []tracing.Span `json:"stages,omitempty"`
 }
 
 // ChainHeightResponse
@@ -86,9 +118,26 @@ type PendingTxResponse struct {
 	Txs []string `json:"txs"`
 }
 
+// DestinationTagResponse
+type DestinationTagResponse struct {
+	Data *cross.DestinationTag `json:"data"`
+}
+
+// DestinationTagsResponse
+type DestinationTagsResponse struct {
+	Tags []cross.DestinationTag `json:"tags"`
+	// Digest is the same on the nodes that resolve deposits to the same memos
+	Digest string `json:"digest"`
+}
+
+// MemoExplainResponse
+type MemoExplainResponse struct {
+	Data mem.Explanation `json:"data"`
+}
+
 // get tx record by orderId
 // @Summary      通过orderId获取交易记录
-// @Description  通过orderId获取交易记录
+// @Description  通过orderId获取交易记录, 开启 tracing 时 stages 为订单在本节点各阶段的耗时
 // @Tags         交易记录
 // @Accept       json
 // @Produce      json
@@ -108,8 +157,15 @@ func (s *CrossServer) crossSignel(w http.ResponseWriter, request *http.Request)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	stages, err := s.tracer.Spans(orderId)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to get order stages")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 	res := &CrossSignelResponse{
-		Data: crossData,
+		Data:   crossData,
+		Stages: stages,
 	}
 
 	s.writeSuccess(w, res)
@@ -304,6 +360,228 @@ func (s *CrossServer) GetTxByHeightRange(w http.ResponseWriter, request *http.Re
 	s.writeSuccess(w, sets)
 }
 
+// list the destination tags of a chain
+// @Summary      获取链上注册的 destination tag 列表
+// @Description  根据 chain 获取注册的 destination tag, 按 tag 和生效 ledger 排序, digest 相同的节点对充值解析出相同的 memo
+// @Tags         destination tag
+// @Accept       json
+// @Produce      json
+// @Param        chain query string true "XRP"
+// @Success      200  {object}  DestinationTagsResponse
+// @Failure      400  {object}  nil  "bad request"
+// @Router       /cross/destination/tags [get]
+func (s *CrossServer) destinationTags(w http.ResponseWriter, request *http.Request) {
+	chain, err := tcommon.NewChain(request.URL.Query().Get("chain"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid chain: %s", err), http.StatusBadRequest)
+		return
+	}
+	tags, err := s.dbStorage.ListDestinationTags(chain.String())
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to list destination tags")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.writeSuccess(w, &DestinationTagsResponse{Tags: tags, Digest: cross.DestinationTagsDigest(tags)})
+}
+
+// get the registration a destination tag routes the deposits of a ledger by
+// @Summary      获取 destination tag 对应的 memo
+// @Description  根据 chain 和 tag 获取在 ledger 生效的注册, 不传 ledger 时返回最新的注册, 未注册时 data 为空, 已停用时 memo 为空
+// @Tags         destination tag
+// @Accept       json
+// @Produce      json
+// @Param        chain query string true "XRP"
+// @Param        tag query string true "1024"
+// @Param        ledger query string false "99000000"
+// @Success      200  {object}  DestinationTagResponse
+// @Failure      400  {object}  nil  "bad request"
+// @Router       /cross/destination/tag [get]
+func (s *CrossServer) destinationTag(w http.ResponseWriter, request *http.Request) {
+	chain, tag, err := destinationTagQuery(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ledger := int64(math.MaxInt64)
+	if request.URL.Query().Get("ledger") != "" {
+		ledger, err = strconv.ParseInt(request.URL.Query().Get("ledger"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid ledger: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+	dt, err := s.dbStorage.GetDestinationTag(chain.String(), tag, ledger)
+	if err != nil {
+		if errors.Is(err, cross.ErrInvalidDestinationLedger) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logger.Error().Err(err).Msg("fail to get destination tag")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.writeSuccess(w, &DestinationTagResponse{Data: dt})
+}
+
+// register the memo a destination tag routes deposits by from a ledger on
+// @Summary      注册 destination tag 对应的 memo
+// @Description  注册 destination tag 从 ledger 起生效的 memo, ledger 必须高于链的当前高度, 所有节点需在 ledger 之前注册相同的内容, 只接受本机请求
+// @Tags         destination tag
+// @Accept       json
+// @Produce      json
+// @Param        body body cross.DestinationTag true "destination tag"
+// @Success      200  {object}  DestinationTagResponse
+// @Failure      400  {object}  nil  "bad request"
+// @Router       /cross/destination/tag [post]
+func (s *CrossServer) registerDestinationTag(w http.ResponseWriter, request *http.Request) {
+	dt := &cross.DestinationTag{}
+	if err := json.NewDecoder(request.Body).Decode(dt); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	chain, err := tcommon.NewChain(dt.Chain)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid chain: %s", err), http.StatusBadRequest)
+		return
+	}
+	dt.Chain = chain.String()
+	// only memos an inbound deposit can be observed with are accepted
+	memo, err := mem.ParseMemo(dt.Memo)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !memo.GetType().Equals(mem.TxOutbound) && !memo.GetType().Equals(mem.TxAdd) {
+		http.Error(w, fmt.Sprintf("memo type %s can not route a deposit", memo.GetType()), http.StatusBadRequest)
+		return
+	}
+	if !s.checkDestinationLedger(w, chain, dt.Ledger) {
+		return
+	}
+	if err = s.dbStorage.SetDestinationTag(dt); err != nil {
+		if errors.Is(err, cross.ErrInvalidDestinationTag) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logger.Error().Err(err).Msg("fail to register destination tag")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info().Str("chain", dt.Chain).Uint32("tag", dt.Tag).Int64("ledger", dt.Ledger).Str("memo", dt.Memo).
+		Str("intent", dt.Intent).Msg("destination tag registered")
+	s.writeSuccess(w, &DestinationTagResponse{Data: dt})
+}
+
+// retire a destination tag from a ledger on
+// @Summary      停用 destination tag
+// @Description  从 ledger 起停用 destination tag, 之后该 tag 的充值进入失败接收地址, ledger 必须高于链的当前高度, 只接受本机请求
+// @Tags         destination tag
+// @Accept       json
+// @Produce      json
+// @Param        chain query string true "XRP"
+// @Param        tag query string true "1024"
+// @Param        ledger query string true "99000000"
+// @Success      200  {object}  nil
+// @Failure      400  {object}  nil  "bad request"
+// @Router       /cross/destination/tag [delete]
+func (s *CrossServer) retireDestinationTag(w http.ResponseWriter, request *http.Request) {
+	chain, tag, err := destinationTagQuery(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ledger, err := strconv.ParseInt(request.URL.Query().Get("ledger"), 10, 64)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid ledger: %s", err), http.StatusBadRequest)
+		return
+	}
+	if !s.checkDestinationLedger(w, chain, ledger) {
+		return
+	}
+	if err = s.dbStorage.RetireDestinationTag(chain.String(), tag, ledger); err != nil {
+		if errors.Is(err, cross.ErrInvalidDestinationTag) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.logger.Error().Err(err).Msg("fail to retire destination tag")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	s.logger.Info().Str("chain", chain.String()).Uint32("tag", tag).Int64("ledger", ledger).Msg("destination tag retired")
+	s.writeSuccess(w, nil)
+}
+
+// checkDestinationLedger rejects registrations for ledgers the chain already reached, a deposit
+// that was observed before has to keep the memo it was resolved with
+func (s *CrossServer) checkDestinationLedger(w http.ResponseWriter, chain tcommon.Chain, ledger int64) bool {
+	if s.heights == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return false
+	}
+	height, err := s.heights(chain)
+	if err != nil {
+		s.logger.Error().Err(err).Str("chain", chain.String()).Msg("fail to get chain height")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return false
+	}
+	if ledger <= height {
+		http.Error(w, fmt.Sprintf("ledger %d is not above the current ledger %d of %s", ledger, height, chain), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func destinationTagQuery(request *http.Request) (tcommon.Chain, uint32, error) {
+	chain, err := tcommon.NewChain(request.URL.Query().Get("chain"))
+	if err != nil {
+		return tcommon.EmptyChain, 0, fmt.Errorf("invalid chain: %w", err)
+	}
+	tag, err := strconv.ParseUint(request.URL.Query().Get("tag"), 10, 32)
+	if err != nil {
+		return tcommon.EmptyChain, 0, fmt.Errorf("invalid tag: %w", err)
+	}
+	return chain, uint32(tag), nil
+}
+
+// localOnly rejects the requests of remote clients, the routes changing the destination tag
+// registry are not exposed beyond the host
+func (s *CrossServer) localOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, request *http.Request) {
+		host, _, err := net.SplitHostPort(request.RemoteAddr)
+		if err != nil {
+			host = request.RemoteAddr
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			s.logger.Warn().Str("remote", request.RemoteAddr).Str("path", request.URL.Path).Msg("remote client rejected")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		next(w, request)
+	}
+}
+
+// explain why a deposit with a memo is sent on, refunded or ignored
+// @Summary      解析 memo
+// @Description  按 observer 的解析方式解析源链上的 memo, 返回目标链, 接收地址, affiliate 及校验错误
+// @Tags         memo
+// @Accept       json
+// @Produce      json
+// @Param        memo query string true "Mx|Eth|USDT|0x4a4f0d7d412f1d47fa45c434cecf05f2f8a434f7|690943|bt0"
+// @Param        chain query string true "BTC"
+// @Success      200  {object}  MemoExplainResponse
+// @Failure      400  {object}  nil  "bad request"
+// @Router       /cross/memo/explain [get]
+func (s *CrossServer) explainMemo(w http.ResponseWriter, request *http.Request) {
+	query := request.URL.Query()
+	chain, err := tcommon.NewChain(query.Get("chain"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid chain: %s", err), http.StatusBadRequest)
+		return
+	}
+	s.writeSuccess(w, &MemoExplainResponse{Data: mem.Explain(query.Get("memo"), chain, s.resolver)})
+}
+
 func (s *CrossServer) writeSuccess(w http.ResponseWriter, data interface{}) {
 	jsonBytes, err := json.MarshalIndent(map[string]interface{}{
 		"data": data,
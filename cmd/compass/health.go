@@ -5,16 +5,18 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net"
 	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/mapprotocol/compass-tss/common"
-	openapi "github.com/mapprotocol/compass-tss/openapi/gen"
+	"github.com/mapprotocol/compass-tss/p2p"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/runners"
 	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
+	ctss "github.com/mapprotocol/compass-tss/tss"
 	"github.com/mapprotocol/compass-tss/tss/go-tss/tss"
 
 	"github.com/gorilla/mux"
@@ -27,23 +29,24 @@ import (
 // -------------------------------------------------------------------------------------
 
 type P2PStatusPeer struct {
-	Address string `json:"address"`
 	IP      string `json:"ip"`
 	Status  string `json:"status"`
+	Version string `json:"version"`
 
 	StoredPeerID   string `json:"stored_peer_id"`
-	NodesPeerID    string `json:"nodes_peer_id"`
 	ReturnedPeerID string `json:"returned_peer_id"`
 
-	P2PPortOpen bool  `json:"p2p_port_open"`
-	P2PDialMs   int64 `json:"p2p_dial_ms"`
+	RTTMs         int64             `json:"rtt_ms"`
+	Chains        []p2p.ChainStatus `json:"chains"`
+	LaggingChains []string          `json:"lagging_chains"`
 }
 
 type P2PStatusResponse struct {
-	RelayHeight int64           `json:"relay_height"`
-	Peers       []P2PStatusPeer `json:"peers"`
-	PeerCount   int             `json:"peer_count"`
-	Errors      []string        `json:"errors"`
+	RelayHeight int64               `json:"relay_height"`
+	Peers       []P2PStatusPeer     `json:"peers"`
+	PeerCount   int                 `json:"peer_count"`
+	Lagging     map[string][]string `json:"lagging"` // chain -> lagging peers
+	Errors      []string            `json:"errors"`
 }
 
 type ScannerResponse struct {
@@ -53,6 +56,29 @@ type ScannerResponse struct {
 	ScannerHeightDiff  int64  `json:"scanner_height_diff"`
 }
 
+// BalanceResponse is the balance of the local signing account on every tracked chain
+type BalanceResponse struct {
+	Balances  []runners.RelayerBalance `json:"balances"`
+	LowChains []string                 `json:"low_chains"`
+}
+
+// BlameResponse is how often each peer was blamed for failed keysigns and keygens within
+// each of the queried windows, peers blamed the most within the largest window first
+type BlameResponse struct {
+	Windows []string            `json:"windows"`
+	Peers   []BlamePeerResponse `json:"peers"`
+}
+
+type BlamePeerResponse struct {
+	Pubkey     string         `json:"pubkey"`
+	Address    string         `json:"address,omitempty"`
+	Failures   map[string]int `json:"failures"` // window -> failures
+	LastType   string         `json:"last_type"`
+	LastRound  string         `json:"last_round,omitempty"`
+	LastReason string         `json:"last_reason,omitempty"`
+	LastTime   time.Time      `json:"last_time"`
+}
+
 type signingChain struct {
 	Chain               string `json:"chain"`
 	LatestBroadcastedTx string `json:"latest_broadcasted_tx"`
@@ -64,6 +90,19 @@ type signingChain struct {
 // Health Server
 // -------------------------------------------------------------------------------------
 
+const (
+	// peerStatusTimeout bounds the node status query of a peer
+	peerStatusTimeout = 5 * time.Second
+	// peerLagThreshold a peer whose scanner is this far behind on a chain is lagging
+	peerLagThreshold = 2 * time.Minute
+	// nodeStatusCacheTime how long the status of the local node is cached
+	nodeStatusCacheTime = 10 * time.Second
+	// defaultBlameWindows the windows blame is counted over unless queried otherwise
+	defaultBlameWindows = "1h,24h,168h"
+	// defaultBlameSince how far back blame records are listed unless queried otherwise
+	defaultBlameSince = 24 * time.Hour
+)
+
 // HealthServer to provide something for health check and also p2pid
 type HealthServer struct {
 	logger    zerolog.Logger
@@ -71,6 +110,13 @@ type HealthServer struct {
 	tssServer tss.Server
 	chains    map[common.Chain]chainclients.ChainClient
 	bridge    shareTypes.Bridge
+
+	statusLock sync.Mutex
+	status     p2p.NodeStatus
+	statusTime time.Time
+
+	balances    *runners.BalanceMonitor
+	blameLedger *ctss.BlameLedger
 }
 
 // NewHealthServer create a new instance of health server
@@ -97,9 +143,22 @@ func (s *HealthServer) newHandler() http.Handler {
 	router.Handle("/p2pid", http.HandlerFunc(s.getP2pIDHandler)).Methods(http.MethodGet)
 	router.Handle("/status/p2p", http.HandlerFunc(s.p2pStatus)).Methods(http.MethodGet)
 	router.Handle("/status/scanner", http.HandlerFunc(s.chainScanner)).Methods(http.MethodGet)
+	router.Handle("/status/balance", http.HandlerFunc(s.relayerBalance)).Methods(http.MethodGet)
+	router.Handle("/status/blame", http.HandlerFunc(s.blameStatus)).Methods(http.MethodGet)
+	router.Handle("/status/blame/records", http.HandlerFunc(s.blameRecords)).Methods(http.MethodGet)
 	return router
 }
 
+// SetBalanceMonitor sets the monitor the relayer balances are served from
+func (s *HealthServer) SetBalanceMonitor(monitor *runners.BalanceMonitor) {
+	s.balances = monitor
+}
+
+// SetBlameLedger sets the ledger the blame of failed keysigns and keygens is served from
+func (s *HealthServer) SetBlameLedger(ledger *ctss.BlameLedger) {
+	s.blameLedger = ledger
+}
+
 func (s *HealthServer) pingHandler(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
@@ -112,17 +171,36 @@ func (s *HealthServer) getP2pIDHandler(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
-func (s *HealthServer) p2pStatus(w http.ResponseWriter, _ *http.Request) {
-	res := &P2PStatusResponse{Peers: make([]P2PStatusPeer, 0)}
+// peerStatusQuerier is implemented by the tss servers able to query the status of a peer
+type peerStatusQuerier interface {
+	GetPeerStatus(ctx context.Context, peerID string) (*p2p.NodeStatus, time.Duration, error)
+}
 
-	// get relay nodes
-	nodesByIP := map[string]openapi.Node{}
+func (s *HealthServer) p2pStatus(w http.ResponseWriter, _ *http.Request) {
+	res := &P2PStatusResponse{
+		Peers:   make([]P2PStatusPeer, 0),
+		Lagging: make(map[string][]string),
+		Errors:  make([]string, 0),
+	}
+	if height, err := s.bridge.GetBlockHeight(); err == nil {
+		res.RelayHeight = height
+	} else {
+		res.Errors = append(res.Errors, fmt.Sprintf("fail to get relay height: %s", err))
+	}
 
-	// get all connected peers
-	peerInfos := s.tssServer.GetKnownPeers()
+	// a peer can be connected more than once
+	peerInfos := make(map[string]tss.PeerInfo)
+	for _, pi := range s.tssServer.GetKnownPeers() {
+		peerInfos[pi.ID] = pi
+	}
 	res.PeerCount = len(peerInfos)
 
-	// ping and http get /p2pid on all peers
+	querier, ok := s.tssServer.(peerStatusQuerier)
+	if !ok {
+		res.Errors = append(res.Errors, "tss server can't query the status of peers")
+	}
+
+	// ask the peers for their status over the p2p network
 	mu := sync.Mutex{}
 	wg := sync.WaitGroup{}
 	for _, pi := range peerInfos {
@@ -140,46 +218,28 @@ func (s *HealthServer) p2pStatus(w http.ResponseWriter, _ *http.Request) {
 				wg.Done()
 			}()
 
-			// nothing to do if no addresses
-			if pi.Address == "" {
+			if querier == nil {
 				return
 			}
-
-			// check if the node is in thornode
-			if node, ok := nodesByIP[pi.Address]; ok {
-				peer.Address = node.NodeAddress
-				peer.Status = node.Status
-				peer.NodesPeerID = node.PeerId
-			}
-
-			// get the peer id
-			resp, err := http.Get(fmt.Sprintf("http://%s:6040/p2pid", pi.Address))
-			status := ""
-			if resp != nil {
-				status = resp.Status
-				peer.Status = resp.Status
-			}
+			ctx, cancel := context.WithTimeout(context.Background(), peerStatusTimeout)
+			defer cancel()
+			status, rtt, err := querier.GetPeerStatus(ctx, pi.ID)
 			if err != nil {
-				peer.ReturnedPeerID = fmt.Sprintf("failed, status=\"%s\"", status)
-			} else {
-				defer resp.Body.Close()
-				var b []byte
-				b, err = io.ReadAll(resp.Body)
-				if err != nil {
-					peer.ReturnedPeerID = fmt.Sprintf("failed to read body, status=\"%s\"", status)
-				} else {
-					peer.ReturnedPeerID = string(b)
-				}
+				peer.Status = fmt.Sprintf("unreachable: %s", err)
+				return
 			}
-
-			// check the p2p port
-			start := time.Now()
-			peer.P2PPortOpen = checkPortOpen(pi.Address, 5040)
-			peer.P2PDialMs = int64(time.Since(start) / time.Millisecond)
+			peer.Status = "online"
+			peer.Version = status.Version
+			peer.ReturnedPeerID = status.PeerID
+			peer.RTTMs = int64(rtt / time.Millisecond)
+			peer.Chains = status.Chains
 		}(pi)
 	}
 	wg.Wait()
 
+	sort.Slice(res.Peers, func(i, j int) bool { return res.Peers[i].StoredPeerID < res.Peers[j].StoredPeerID })
+	markLaggingPeers(s.nodeStatus(), res)
+
 	// write the response
 	jsonBytes, err := json.MarshalIndent(res, "", "  ")
 	if err != nil {
@@ -193,7 +253,205 @@ func (s *HealthServer) p2pStatus(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+// markLaggingPeers flags the peers whose scanner is behind the best scanner height seen
+// for a chain, among the peers and the local node, by more than peerLagThreshold
+func markLaggingPeers(local p2p.NodeStatus, res *P2PStatusResponse) {
+	best := make(map[string]int64)
+	observe := func(chains []p2p.ChainStatus) {
+		for _, cs := range chains {
+			if cs.ScannerHeight > best[cs.Chain] {
+				best[cs.Chain] = cs.ScannerHeight
+			}
+		}
+	}
+	observe(local.Chains)
+	for _, peer := range res.Peers {
+		observe(peer.Chains)
+	}
+
+	for i := range res.Peers {
+		peer := &res.Peers[i]
+		for _, cs := range peer.Chains {
+			if best[cs.Chain]-cs.ScannerHeight <= lagThresholdBlocks(common.Chain(cs.Chain)) {
+				continue
+			}
+			peer.LaggingChains = append(peer.LaggingChains, cs.Chain)
+			res.Lagging[cs.Chain] = append(res.Lagging[cs.Chain], peer.StoredPeerID)
+		}
+	}
+}
+
+// lagThresholdBlocks returns how many blocks a scanner can be behind before it is lagging
+func lagThresholdBlocks(chain common.Chain) int64 {
+	ms := chain.ApproximateBlockMilliseconds()
+	if ms <= 0 {
+		return 1
+	}
+	blocks := peerLagThreshold.Milliseconds() / ms
+	if blocks < 1 {
+		return 1
+	}
+	return blocks
+}
+
+// nodeStatus returns the status of the local node, it is also served to the peers so it
+// is cached for a little while
+func (s *HealthServer) nodeStatus() p2p.NodeStatus {
+	s.statusLock.Lock()
+	defer s.statusLock.Unlock()
+	if time.Since(s.statusTime) < nodeStatusCacheTime {
+		return s.status
+	}
+
+	status := p2p.NodeStatus{
+		Version: version,
+		PeerID:  s.tssServer.GetLocalPeerID(),
+		Chains:  make([]p2p.ChainStatus, 0, len(s.chains)+1),
+	}
+	for _, sr := range s.scannerStatus() {
+		healthy := sr.ScannerHeightDiff >= 0
+		if client, ok := s.chains[common.Chain(sr.Chain)]; ok {
+			healthy = healthy && client.IsBlockScannerHealthy()
+		}
+		status.Chains = append(status.Chains, p2p.ChainStatus{
+			Chain:         sr.Chain,
+			ChainHeight:   sr.ChainHeight,
+			ScannerHeight: sr.BlockScannerHeight,
+			Healthy:       healthy,
+		})
+	}
+	sort.Slice(status.Chains, func(i, j int) bool { return status.Chains[i].Chain < status.Chains[j].Chain })
+	s.status, s.statusTime = status, time.Now()
+	return status
+}
+
 func (s *HealthServer) chainScanner(w http.ResponseWriter, _ *http.Request) {
+	res := s.scannerStatus()
+
+	// write the response
+	jsonBytes, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to write to response")
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		_, err = w.Write(jsonBytes)
+		if err != nil {
+			s.logger.Error().Err(err).Msg("fail to write to response")
+		}
+	}
+}
+
+func (s *HealthServer) relayerBalance(w http.ResponseWriter, _ *http.Request) {
+	res := BalanceResponse{
+		Balances:  make([]runners.RelayerBalance, 0),
+		LowChains: make([]string, 0),
+	}
+	if s.balances != nil {
+		res.Balances = s.balances.Balances()
+	}
+	for _, balance := range res.Balances {
+		if balance.Low {
+			res.LowChains = append(res.LowChains, balance.Chain.String())
+		}
+	}
+
+	jsonBytes, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to write to response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if _, err = w.Write(jsonBytes); err != nil {
+		s.logger.Error().Err(err).Msg("fail to write to response")
+	}
+}
+
+func (s *HealthServer) blameStatus(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("windows")
+	if query == "" {
+		query = defaultBlameWindows
+	}
+	res := BlameResponse{
+		Windows: make([]string, 0),
+		Peers:   make([]BlamePeerResponse, 0),
+	}
+	windows := make([]time.Duration, 0)
+	for _, window := range strings.Split(query, ",") {
+		window = strings.TrimSpace(window)
+		d, err := time.ParseDuration(window)
+		if err != nil || d <= 0 {
+			http.Error(w, fmt.Sprintf("invalid window: %s", window), http.StatusBadRequest)
+			return
+		}
+		res.Windows = append(res.Windows, window)
+		windows = append(windows, d)
+	}
+
+	if s.blameLedger != nil {
+		peers, err := s.blameLedger.PeerFailures(windows, time.Now())
+		if err != nil {
+			s.logger.Error().Err(err).Msg("fail to count peer failures")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		for _, peer := range peers {
+			failures := make(map[string]int, len(windows))
+			for i, window := range res.Windows {
+				failures[window] = peer.Failures[i]
+			}
+			res.Peers = append(res.Peers, BlamePeerResponse{
+				Pubkey:     peer.Pubkey,
+				Address:    peer.Address,
+				Failures:   failures,
+				LastType:   peer.LastType,
+				LastRound:  peer.LastRound,
+				LastReason: peer.LastReason,
+				LastTime:   peer.LastTime,
+			})
+		}
+	}
+	s.writeJSON(w, res)
+}
+
+func (s *HealthServer) blameRecords(w http.ResponseWriter, r *http.Request) {
+	since := defaultBlameSince
+	if query := r.URL.Query().Get("since"); query != "" {
+		d, err := time.ParseDuration(query)
+		if err != nil || d <= 0 {
+			http.Error(w, fmt.Sprintf("invalid since: %s", query), http.StatusBadRequest)
+			return
+		}
+		since = d
+	}
+
+	res := make([]ctss.BlameRecord, 0)
+	if s.blameLedger != nil {
+		records, err := s.blameLedger.List(time.Now().Add(-since), r.URL.Query().Get("pubkey"))
+		if err != nil {
+			s.logger.Error().Err(err).Msg("fail to list blame records")
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		res = records
+	}
+	s.writeJSON(w, res)
+}
+
+// writeJSON writes the response indented
+func (s *HealthServer) writeJSON(w http.ResponseWriter, res interface{}) {
+	jsonBytes, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to write to response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if _, err = w.Write(jsonBytes); err != nil {
+		s.logger.Error().Err(err).Msg("fail to write to response")
+	}
+}
+
+// scannerStatus returns the chain and block scanner heights of every chain, keyed by chain
+func (s *HealthServer) scannerStatus() map[string]ScannerResponse {
 	res := make(map[string]ScannerResponse)
 
 	// Iterate through each chain client
@@ -260,18 +518,7 @@ func (s *HealthServer) chainScanner(w http.ResponseWriter, _ *http.Request) {
 		mu.Unlock()
 	}()
 	wg.Wait()
-
-	// write the response
-	jsonBytes, err := json.MarshalIndent(res, "", "  ")
-	if err != nil {
-		s.logger.Error().Err(err).Msg("fail to write to response")
-		w.WriteHeader(http.StatusInternalServerError)
-	} else {
-		_, err = w.Write(jsonBytes)
-		if err != nil {
-			s.logger.Error().Err(err).Msg("fail to write to response")
-		}
-	}
+	return res
 }
 
 // Start health server
@@ -297,13 +544,3 @@ func (s *HealthServer) Stop() error {
 	}
 	return err
 }
-
-func checkPortOpen(host string, port int) bool {
-	address := fmt.Sprintf("%s:%d", host, port)
-	conn, err := net.DialTimeout("tcp", address, 2*time.Second)
-	if err != nil {
-		return false
-	}
-	defer conn.Close()
-	return true
-}
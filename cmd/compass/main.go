@@ -2,25 +2,35 @@ package main
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	golog "github.com/ipfs/go-log"
 	tcommon "github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/common/relay"
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/internal/admin"
 	"github.com/mapprotocol/compass-tss/internal/cross"
 	"github.com/mapprotocol/compass-tss/internal/keys"
+	"github.com/mapprotocol/compass-tss/internal/tracing"
 	"github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/observer"
 	"github.com/mapprotocol/compass-tss/p2p"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/mapo"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/runners"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/utxo"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/xrp"
 	"github.com/mapprotocol/compass-tss/pubkeymanager"
 	"github.com/mapprotocol/compass-tss/signer"
 	ctss "github.com/mapprotocol/compass-tss/tss"
@@ -52,12 +62,19 @@ func main() {
 	showVersion := flag.Bool("version", false, "Shows version")
 	logLevel := flag.StringP("log-level", "l", "info", "Log Level")
 	pretty := flag.BoolP("pretty-log", "p", false, "Enables unstructured prettified logging. This is useful for local debugging")
+	fixture := flag.String("fixture", "", "With validate-config, check the rpc endpoints against this fixture instead of the live nodes")
+	record := flag.String("record", "", "With validate-config, save the traffic to the rpc endpoints into this fixture")
+	offline := flag.Bool("offline", false, "With validate-config, only check the configuration itself")
 	flag.Parse()
 
 	if *showVersion {
 		printVersion()
 		return
 	}
+	// check the configuration and exit before anything is started
+	if flag.Arg(0) == "validate-config" {
+		os.Exit(validateConfig(*fixture, *record, *offline, *logLevel))
+	}
 
 	initLog(*logLevel, *pretty)
 	config.Init()
@@ -82,6 +99,17 @@ func main() {
 	}
 
 	k := keys.NewKeysWithKeybase(kb, cfg.MAPRelay.SignerName, "", keyStore) // "cfg.MAPRelay.SignerPasswd"
+
+	// trace the stages of the orders, the spans are attributed to the node account
+	var tracer *tracing.Tracer
+	if cfg.Tracing.Enabled {
+		tracer, err = tracing.NewTracer(cfg.Tracing, keyStore.Address.Hex())
+		if err != nil {
+			log.Fatal().Err(err).Msg("fail to create tracer")
+		}
+		tracer.Start()
+		tracing.SetDefault(tracer)
+	}
 	// map bridge
 	mapBridge, err := mapo.NewBridge(cfg.MAPRelay, m, k)
 	if err != nil {
@@ -135,19 +163,32 @@ func main() {
 		log.Fatal().Err(err).Msg("fail to start p2p")
 	}
 
-	tssIns, err := tss.NewTss(
-		comm,
-		stateManager,
-		tmPrivateKey,
-		common.TssConfig{
-			EnableMonitor:   true,
-			KeyGenTimeout:   cfg.Signer.KeygenTimeout,
-			KeySignTimeout:  cfg.Signer.KeysignTimeout,
-			PartyTimeout:    cfg.Signer.PartyTimeout,
-			PreParamTimeout: cfg.Signer.PreParamTimeout,
-		},
-		nil,
-	)
+	tssConfig := common.TssConfig{
+		EnableMonitor:   true,
+		KeyGenTimeout:   cfg.Signer.KeygenTimeout,
+		KeySignTimeout:  cfg.Signer.KeysignTimeout,
+		PartyTimeout:    cfg.Signer.PartyTimeout,
+		PreParamTimeout: cfg.Signer.PreParamTimeout,
+	}
+	// every keygen takes fresh pre parameters from the pool when there is one
+	var preParamPool *ctss.PreParamPool
+	var tssIns *tss.TssServer
+	if cfg.Signer.PreParamPoolSize > 0 {
+		var passphrase string
+		passphrase, err = ctss.PreParamPoolPassphrase(tmPrivateKey.Bytes())
+		if err != nil {
+			log.Fatal().Err(err).Msg("fail to derive pre parameters pool passphrase")
+		}
+		preParamPool, err = ctss.NewPreParamPool(cfg.Signer.PreParamPoolPath, cfg.Signer.PreParamPoolSize,
+			cfg.Signer.PreParamTimeout, passphrase, m)
+		if err != nil {
+			log.Fatal().Err(err).Msg("fail to create pre parameters pool")
+		}
+		preParamPool.Start()
+		tssIns, err = tss.NewTssWithPreParamsPool(comm, stateManager, tmPrivateKey, tssConfig, preParamPool)
+	} else {
+		tssIns, err = tss.NewTss(comm, stateManager, tmPrivateKey, tssConfig, nil)
+	}
 	if err != nil {
 		log.Fatal().Err(err).Msg("fail to create tss instance")
 	}
@@ -156,6 +197,10 @@ func main() {
 		log.Err(err).Msg("fail to start tss instance")
 	}
 
+	// only let the maintainers connect to the tss p2p network
+	maintainerGater := ctss.NewMaintainerGater(mapBridge, comm, m)
+	maintainerGater.Start()
+
 	if err = mapBridge.SetTssKeyManager(tssIns); err != nil {
 		log.Fatal().Err(err).Msg("fail to set tss to bridge")
 	}
@@ -183,6 +228,24 @@ func main() {
 	}
 	tssKeysignMetricMgr := metrics.NewTssKeysignMetricMgr()
 	healthServer := NewHealthServer(cfg.TSS.InfoAddress, tssIns, chains, mapBridge)
+	// peers ask for our version and scanner status over the p2p network
+	comm.SetNodeStatusProvider(healthServer.nodeStatus)
+
+	// track the balance the local signing account pays outbound gas from, Tron counts as evm
+	relayerKey, err := relayerPubKey(k)
+	if err != nil {
+		log.Fatal().Err(err).Msg("fail to get relayer public key")
+	}
+	balanceSources := make(map[tcommon.Chain]runners.BalanceSource)
+	for chain, client := range chains {
+		if chain.IsEVM() {
+			balanceSources[chain] = client
+		}
+	}
+	balanceMonitor := runners.NewBalanceMonitor(cfg.RelayerBalance, relayerKey, balanceSources, m,
+		nodeRelayAlerter(cfg.RelayerBalance.AlertChannel))
+	balanceMonitor.Start()
+	healthServer.SetBalanceMonitor(balanceMonitor)
 	go func() {
 		defer log.Info().Msg("health server exit")
 		if err = healthServer.Start(); err != nil {
@@ -195,8 +258,26 @@ func main() {
 		log.Fatal().Err(err).Msg("fail to create cross storage")
 	}
 	crossStorage.Start()
+	for _, chain := range chains {
+		if utxoClient, ok := chain.(*utxo.Client); ok {
+			utxoClient.SetFeeBumpRecorder(crossStorage)
+		}
+	}
+	// xrp deposits without a memo are routed by the destination tags registered on the cross server
+	if xrpClient, ok := chains[tcommon.XRPChain].(*xrp.Client); ok {
+		xrpClient.SetDestinationTagRegistry(crossStorage)
+	}
 
 	crossServer := NewCrossServer(cfg.MAPRelay.CrossDataAddress, crossStorage)
+	crossServer.SetTracer(tracer)
+	crossServer.SetMemoResolver(mapBridge)
+	crossServer.SetChainHeights(func(chain tcommon.Chain) (int64, error) {
+		client, ok := chains[chain]
+		if !ok {
+			return 0, fmt.Errorf("chain %s is not enabled", chain)
+		}
+		return client.GetHeight()
+	})
 	go func() {
 		defer log.Info().Msg("cross server exit")
 		if err = crossServer.Start(); err != nil {
@@ -219,10 +300,38 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("fail to create instance of signer")
 	}
+	healthServer.SetBlameLedger(sign.BlameLedger())
+	for _, chain := range chains {
+		if utxoClient, ok := chain.(*utxo.Client); ok {
+			utxoClient.SetBlameRecorder(sign.BlameLedger())
+		}
+	}
+	sign.SetLimitAlerter(signer.Alerter(nodeRelayAlerter(cfg.Signer.LimitAlertChannel)))
 	if err = sign.Start(); err != nil {
 		log.Fatal().Err(err).Msg("fail to start signer")
 	}
 
+	// start admin api
+	var adminServer *admin.Server
+	if cfg.Admin.Enabled {
+		rescanners := make(map[tcommon.Chain]admin.Rescanner)
+		for chain, client := range chains {
+			if r, ok := client.(admin.Rescanner); ok {
+				rescanners[chain] = r
+			}
+		}
+		adminServer, err = admin.NewServer(cfg.Admin, sign, obs, rescanners)
+		if err != nil {
+			log.Fatal().Err(err).Msg("fail to create admin server")
+		}
+		go func() {
+			defer log.Info().Msg("admin server exit")
+			if err := adminServer.Start(); err != nil {
+				log.Error().Err(err).Msg("fail to start admin server")
+			}
+		}()
+	}
+
 	// wait....
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
@@ -231,6 +340,17 @@ func main() {
 	case <-restart:
 	}
 	log.Info().Msg("stop signal received")
+	if cfg.DrainTimeout > 0 {
+		drain(cfg.DrainTimeout, tssIns, obs, sign, crossStorage)
+	}
+
+	if adminServer != nil {
+		if err = adminServer.Stop(); err != nil {
+			log.Error().Err(err).Msg("fail to stop admin server")
+		}
+	}
+
+	balanceMonitor.Stop()
 
 	// stop observer
 	if err = obs.Stop(); err != nil {
@@ -241,6 +361,10 @@ func main() {
 		log.Fatal().Err(err).Msg("fail to stop signer")
 	}
 	// stop go tss
+	maintainerGater.Stop()
+	if preParamPool != nil {
+		preParamPool.Stop()
+	}
 	tssIns.Stop()
 	if err = healthServer.Stop(); err != nil {
 		log.Fatal().Err(err).Msg("fail to stop health server")
@@ -249,6 +373,76 @@ func main() {
 		log.Fatal().Err(err).Msg("fail to stop cross server")
 	}
 	crossStorage.Stop()
+	if err = crossStorage.Close(); err != nil {
+		log.Error().Err(err).Msg("fail to close cross storage")
+	}
+	if tracer != nil {
+		tracing.SetDefault(nil)
+		tracer.Stop()
+	}
+}
+
+// drain lets the work in progress complete before the node stops. The peers are told first
+// that the node is leaving, so they neither wait for it nor blame it.
+func drain(timeout time.Duration, tssIns *tss.TssServer, obs *observer.Observer, sign *signer.Signer,
+	crossStorage *cross.CrossStorage,
+) {
+	log.Info().Dur("timeout", timeout).Msg("draining")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	tssIns.Drain(ctx)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := sign.Drain(ctx); err != nil {
+			log.Error().Err(err).Msg("fail to drain signer")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := obs.Drain(ctx); err != nil {
+			log.Error().Err(err).Msg("fail to drain observer")
+		}
+	}()
+	wg.Wait()
+
+	// the signer and the observer record the cross data of what they completed
+	if err := crossStorage.Flush(ctx); err != nil {
+		log.Error().Err(err).Msg("fail to flush cross storage")
+	}
+	log.Info().Msg("drained")
+}
+
+// relayerPubKey returns the public key of the local signing account, which pays the gas of
+// the outbounds
+func relayerPubKey(k *keys.Keys) (tcommon.PubKey, error) {
+	priv, err := k.GetPrivateKey()
+	if err != nil {
+		return "", fmt.Errorf("fail to get private key: %w", err)
+	}
+	evmPrivateKey, err := evm.GetPrivateKey(priv)
+	if err != nil {
+		return "", err
+	}
+	return tcommon.NewPubKey(hex.EncodeToString(crypto.CompressPubkey(&evmPrivateKey.PublicKey)))
+}
+
+// nodeRelayAlerter returns an alerter posting to the given node relay channel, nil when the
+// channel or the node relay url is not configured
+func nodeRelayAlerter(channel string) runners.BalanceAlerter {
+	if channel == "" || config.GetMAPO().NodeRelayURL == "" {
+		return nil
+	}
+	return func(text string) error {
+		msg := relay.NewNodeRelay(channel, text)
+		if err := msg.Prepare(); err != nil {
+			return fmt.Errorf("fail to prepare node relay message: %w", err)
+		}
+		_, err := msg.Broadcast()
+		return err
+	}
 }
 
 func initLog(level string, pretty bool) {
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	tcommon "github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/internal/validate"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
+)
+
+// validateConfig runs `compass validate-config`: the configuration is loaded and checked,
+// its rpc endpoints are checked live, from a fixture or not at all, the report goes to
+// stdout and the exit code is returned. No key is loaded and the network is not joined.
+func validateConfig(fixture, record string, offline bool, logLevel string) int {
+	// the report goes to stdout, keep the logs out of it
+	l, err := zerolog.ParseLevel(logLevel)
+	if err != nil {
+		l = zerolog.WarnLevel
+	}
+	zerolog.SetGlobalLevel(l)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	if fixture != "" && record != "" {
+		log.Error().Msg("only one of --fixture and --record can be set")
+		return 2
+	}
+
+	// InitBifrost is skipped, it exits on the first invalid chain and creates directories
+	config.Init()
+	cfg := config.GetBifrost()
+
+	var recorder *rpcpool.Recorder
+	var transport func(tcommon.Chain) http.RoundTripper
+	switch {
+	case fixture != "":
+		f, err := rpcpool.LoadFixture(fixture)
+		if err != nil {
+			log.Error().Err(err).Msg("fail to load fixture")
+			return 2
+		}
+		transport = rpcpool.NewReplayer(f).Transport
+	case record != "":
+		recorder = rpcpool.NewRecorder()
+		transport = func(chain tcommon.Chain) http.RoundTripper {
+			return recorder.Transport(chain, http.DefaultTransport)
+		}
+	}
+
+	report := validate.Check(context.Background(), cfg, !offline, transport)
+	if err = report.Print(os.Stdout); err != nil {
+		log.Error().Err(err).Msg("fail to print report")
+		return 2
+	}
+	if recorder != nil {
+		if err = recorder.Traffic().Save(record); err != nil {
+			log.Error().Err(err).Msg("fail to save fixture")
+			return 2
+		}
+	}
+	if report.Failed() {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+// Command rescan scans a range of blocks of one chain and prints the TxIn items the
+// observer would vote for them. With --record the scan runs against the configured rpc
+// endpoints and their traffic is saved into a fixture, with --fixture the range of the
+// fixture is scanned again without network.
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	flag "github.com/spf13/pflag"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/internal/rescan"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/metrics"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
+)
+
+func main() {
+	chain := flag.String("chain", "", "Chain to scan when recording")
+	from := flag.Int64("from", 0, "First block of the range to record")
+	to := flag.Int64("to", 0, "Last block of the range to record")
+	record := flag.String("record", "", "Scan the live rpc endpoints and save their traffic into this fixture")
+	fixture := flag.String("fixture", "", "Scan the range of this fixture from its recorded traffic")
+	logLevel := flag.StringP("log-level", "l", "warn", "Log Level")
+	flag.Parse()
+
+	// the items go to stdout, keep the logs out of them
+	l, err := zerolog.ParseLevel(*logLevel)
+	if err != nil {
+		l = zerolog.WarnLevel
+	}
+	zerolog.SetGlobalLevel(l)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	if (*record == "") == (*fixture == "") {
+		log.Fatal().Msg("exactly one of --record and --fixture is required")
+	}
+
+	config.Init()
+	config.InitBifrost()
+	cfg := config.GetBifrost()
+	m, err := metrics.NewMetrics(cfg.Metrics)
+	if err != nil {
+		log.Fatal().Err(err).Msg("fail to create metric instance")
+	}
+
+	var txIns []types.TxIn
+	if *record != "" {
+		c, err := common.NewChain(*chain)
+		if err != nil {
+			log.Fatal().Err(err).Str("chain", *chain).Msg("invalid chain")
+		}
+		var f *rpcpool.Fixture
+		txIns, f, err = rescan.Record(cfg, m, c, *from, *to)
+		if err != nil {
+			log.Fatal().Err(err).Msg("fail to scan blocks")
+		}
+		if err = f.Save(*record); err != nil {
+			log.Fatal().Err(err).Msg("fail to save fixture")
+		}
+	} else {
+		f, err := rpcpool.LoadFixture(*fixture)
+		if err != nil {
+			log.Fatal().Err(err).Msg("fail to load fixture")
+		}
+		txIns, err = rescan.Replay(cfg, m, f)
+		if err != nil {
+			log.Fatal().Err(err).Msg("fail to scan blocks")
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(txIns); err != nil {
+		log.Fatal().Err(err).Msg("fail to print txs")
+	}
+}
@@ -35,6 +35,20 @@ var (
 	AVAXAsset = Asset{Chain: AVAXChain, Symbol: "AVAX", Ticker: "AVAX", Synth: false}
 	// XRPAsset XRP
 	XRPAsset = Asset{Chain: XRPChain, Symbol: "XRP", Ticker: "XRP", Synth: false}
+	// ArbETHAsset ETH on Arbitrum
+	ArbETHAsset = Asset{Chain: ARBChain, Symbol: "ETH", Ticker: "ETH", Synth: false}
+	// OptETHAsset ETH on Optimism
+	OptETHAsset = Asset{Chain: OPTChain, Symbol: "ETH", Ticker: "ETH", Synth: false}
+	// UniETHAsset ETH on Unichain
+	UniETHAsset = Asset{Chain: UNIChain, Symbol: "ETH", Ticker: "ETH", Synth: false}
+	// POLAsset POL
+	POLAsset = Asset{Chain: POLChain, Symbol: "POL", Ticker: "POL", Synth: false}
+	// OKBAsset OKB on X Layer
+	OKBAsset = Asset{Chain: XLAYERChain, Symbol: "OKB", Ticker: "OKB", Synth: false}
+	// TRXAsset TRX
+	TRXAsset = Asset{Chain: TRONChain, Symbol: "TRX", Ticker: "TRX", Synth: false}
+	// SOLAsset SOL
+	SOLAsset = Asset{Chain: SOLChain, Symbol: "SOL", Ticker: "SOL", Synth: false}
 	// RuneNative RUNE on relay
 	RuneNative = Asset{Chain: THORChain, Symbol: "RUNE", Ticker: "RUNE", Synth: false}
 	TCY        = Asset{Chain: THORChain, Symbol: "TCY", Ticker: "TCY", Synth: false}
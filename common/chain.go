@@ -1,7 +1,6 @@
 package common
 
 import (
-	"fmt"
 	"math/big"
 	"strings"
 
@@ -39,112 +38,22 @@ const (
 	SigningAlgoEd25519   = SigningAlgo("ed25519")
 )
 
-var AllChains = [...]Chain{
-	BSCChain,
-	ETHChain,
-	BTCChain,
-	LTCChain,
-	BCHChain,
-	DOGEChain,
-	GAIAChain,
-	AVAXChain,
-	BASEChain,
-	XRPChain,
-	MAPChain,
-	ARBChain,
-	TRONChain,
-	SOLChain,
-	OPTChain,
-	UNIChain,
-	XLAYERChain,
-	POLChain,
-}
+// AllChains every chain known to the registry
+var AllChains = RegisteredChains()
 
 var chainToNativeToken = map[Chain]string{
 	BTCChain: "BTC",
 }
 
-var chainToChainID = map[string]*big.Int{
-	// test network
-	getChainKey(BSCChain, TestNet):    big.NewInt(97),
-	getChainKey(ETHChain, TestNet):    big.NewInt(11155111),
-	getChainKey(BTCChain, TestNet):    big.NewInt(1360095883558914),
-	getChainKey(DOGEChain, TestNet):   big.NewInt(1360121653362690),
-	getChainKey(AVAXChain, TestNet):   big.NewInt(43113),
-	getChainKey(BASEChain, TestNet):   big.NewInt(84532),
-	getChainKey(MAPChain, TestNet):    big.NewInt(212),
-	getChainKey(ARBChain, TestNet):    big.NewInt(42170),
-	getChainKey(XRPChain, TestNet):    big.NewInt(1360117358395394),
-	getChainKey(OPTChain, TestNet):    big.NewInt(11),
-	getChainKey(UNIChain, TestNet):    big.NewInt(1337),
-	getChainKey(TRONChain, TestNet):   big.NewInt(728126427),
-	getChainKey(POLChain, TestNet):    big.NewInt(1101),
-	getChainKey(XLAYERChain, TestNet): big.NewInt(195),
-	getChainKey(SOLChain, TestNet):    big.NewInt(1360108768460800),
-
-	// main network
-	getChainKey(BSCChain, MainNet):    big.NewInt(56),
-	getChainKey(ETHChain, MainNet):    big.NewInt(1),
-	getChainKey(BTCChain, MainNet):    big.NewInt(1360095883558913),
-	getChainKey(DOGEChain, MainNet):   big.NewInt(1360121653362689),
-	getChainKey(AVAXChain, MainNet):   big.NewInt(43114),
-	getChainKey(BASEChain, MainNet):   big.NewInt(8453),
-	getChainKey(MAPChain, MainNet):    big.NewInt(22776),
-	getChainKey(ARBChain, MainNet):    big.NewInt(42161),
-	getChainKey(XRPChain, MainNet):    big.NewInt(1360117358395393),
-	getChainKey(OPTChain, MainNet):    big.NewInt(10),
-	getChainKey(UNIChain, MainNet):    big.NewInt(130),
-	getChainKey(TRONChain, MainNet):   big.NewInt(728126428),
-	getChainKey(POLChain, MainNet):    big.NewInt(137),
-	getChainKey(XLAYERChain, MainNet): big.NewInt(196),
-	getChainKey(SOLChain, MainNet):    big.NewInt(1360108768460801),
-}
-
-var chainIDToChain = map[string]Chain{
-	// test network
-	big.NewInt(97).String():               BSCChain,
-	big.NewInt(11155111).String():         ETHChain,
-	big.NewInt(1360095883558914).String(): BTCChain,
-	big.NewInt(1360095883558916).String(): DOGEChain,
-	big.NewInt(43113).String():            AVAXChain,
-	big.NewInt(84532).String():            BASEChain,
-	big.NewInt(212).String():              MAPChain,
-	big.NewInt(42170).String():            ARBChain,
-	big.NewInt(1360117358395394).String(): XRPChain,
-	big.NewInt(11).String():               OPTChain,
-	big.NewInt(1337).String():             UNIChain,
-	big.NewInt(728126427).String():        TRONChain,
-	big.NewInt(1360108768460800).String(): SOLChain,
-	big.NewInt(1101).String():             POLChain,
-	big.NewInt(195).String():              XLAYERChain,
-
-	// main network
-	big.NewInt(56).String():               BSCChain,
-	big.NewInt(1).String():                ETHChain,
-	big.NewInt(1360095883558913).String(): BTCChain,
-	big.NewInt(1360095883558915).String(): DOGEChain,
-	big.NewInt(43114).String():            AVAXChain,
-	big.NewInt(8453).String():             BASEChain,
-	big.NewInt(22776).String():            MAPChain,
-	big.NewInt(42161).String():            ARBChain,
-	big.NewInt(1360117358395393).String(): XRPChain,
-	big.NewInt(130).String():              UNIChain,
-	big.NewInt(10).String():               OPTChain,
-	big.NewInt(728126428).String():        TRONChain,
-	big.NewInt(1360108768460801).String(): SOLChain,
-	big.NewInt(137).String():              POLChain,
-	big.NewInt(196).String():              XLAYERChain,
-}
-
 func GetChainName(key *big.Int) (Chain, bool) {
 	if key == nil {
 		return EmptyChain, false
 	}
-	chain, ok := chainIDToChain[key.String()]
+	info, ok := chainsByID[key.String()]
 	if !ok {
 		return EmptyChain, false
 	}
-	return chain, ok
+	return info.Chain, ok
 }
 
 type SigningAlgo string
@@ -154,15 +63,10 @@ type Chain string
 // Chains represent a slice of Chain
 type Chains []Chain
 
-func getChainKey(chain Chain, network ChainNetwork) string {
-	return fmt.Sprintf("%v-%v", chain, network)
-}
-
-// Valid validates chain format, should consist only of uppercase letters
+// Valid validates the chain is registered and has a chain id on the current network
 func (c Chain) Valid() error {
-	_, ok := chainToChainID[getChainKey(c, CurrentChainNetwork)]
-	if !ok {
-		return UnsupportedChain
+	if _, err := c.ChainID(); err != nil {
+		return err
 	}
 	return nil
 }
@@ -193,24 +97,14 @@ func (c Chain) IsBSCChain() bool {
 // See working definition of an "EVM" chain in the
 // `GetEVMChains` function description
 func (c Chain) IsEVM() bool {
-	evmChains := GetEVMChains()
-	for _, evm := range evmChains {
-		if c.Equals(evm) {
-			return true
-		}
-	}
-	return false
+	info, ok := GetChainInfo(c)
+	return ok && info.Family == ChainFamilyEVM
 }
 
 // IsUTXO returns true if given chain is a UTXO chain.
 func (c Chain) IsUTXO() bool {
-	utxoChains := GetUTXOChains()
-	for _, utxo := range utxoChains {
-		if c.Equals(utxo) {
-			return true
-		}
-	}
-	return false
+	info, ok := GetChainInfo(c)
+	return ok && info.Family == ChainFamilyUTXO
 }
 
 // IsEmpty is to determinate whether the chain is empty
@@ -224,47 +118,39 @@ func (c Chain) String() string {
 }
 
 func (c Chain) ChainID() (*big.Int, error) {
-	id, ok := chainToChainID[getChainKey(c, CurrentChainNetwork)]
+	info, ok := GetChainInfo(c)
 	if !ok {
 		return nil, UnsupportedChain
 	}
+	id := info.ChainID(CurrentChainNetwork)
+	if id == nil {
+		return nil, UnsupportedChain
+	}
 	return id, nil
 }
 
+// GetDecimals returns the number of decimals of the chain's gas asset, 0 for an unknown chain
+func (c Chain) GetDecimals() int64 {
+	info, _ := GetChainInfo(c)
+	return info.Decimals
+}
+
 // GetSigningAlgo get the signing algorithm for the given chain
 func (c Chain) GetSigningAlgo() SigningAlgo {
-	// Only SigningAlgoSecp256k1 is supported for now
-	return SigningAlgoSecp256k1
+	info, ok := GetChainInfo(c)
+	if !ok {
+		return SigningAlgoSecp256k1
+	}
+	return info.SigningAlgo
 }
 
 // GetGasAsset chain's base asset
 func (c Chain) GetGasAsset() Asset {
-	switch c {
-	case BSCChain:
-		return BNBBEP20Asset
-	case BTCChain:
-		return BTCAsset
-	case LTCChain:
-		return LTCAsset
-	case BCHChain:
-		return BCHAsset
-	case DOGEChain:
-		return DOGEAsset
-	case ETHChain:
-		return ETHAsset
-	case AVAXChain:
-		return AVAXAsset
-	case GAIAChain:
-		return ATOMAsset
-	case BASEChain:
-		return BaseETHAsset
-	case XRPChain:
-		return XRPAsset
-	case MAPChain:
-		return MAPAsset
-	default:
+	info, ok := GetChainInfo(c)
+	if !ok {
 		return EmptyAsset
 	}
+	return info.GasAsset
 }
 
 // IsValidAddress make sure the address is correct for the chain
@@ -444,13 +330,12 @@ func (c Chain) DecodeAddress(address string) ([]byte, error) {
 // - uses 0x as an address prefix
 // - has a "Router" Smart Contract
 func GetEVMChains() []Chain {
-	return []Chain{ETHChain, AVAXChain, BSCChain, BASEChain,
-		MAPChain, ARBChain, OPTChain, UNIChain, XLAYERChain, TRONChain, POLChain}
+	return chainsOfFamily(ChainFamilyEVM)
 }
 
 // GetUTXOChains returns all "UTXO" chains connected to mapo.
 func GetUTXOChains() []Chain {
-	return []Chain{BTCChain, LTCChain, BCHChain, DOGEChain}
+	return chainsOfFamily(ChainFamilyUTXO)
 }
 
 func NewChains(raw []string) (Chains, error) {
@@ -0,0 +1,214 @@
+package common
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// ChainFamily groups the chains sharing an address format and transaction model
+type ChainFamily string
+
+const (
+	ChainFamilyEVM    = ChainFamily("evm")
+	ChainFamilyUTXO   = ChainFamily("utxo")
+	ChainFamilyXRP    = ChainFamily("xrp")
+	ChainFamilyCosmos = ChainFamily("cosmos")
+	ChainFamilySolana = ChainFamily("solana")
+)
+
+// ChainClientKind is the chain client bifrost runs for a chain
+type ChainClientKind string
+
+const (
+	// ChainClientNone the chain is known but bifrost has no client for it
+	ChainClientNone     = ChainClientKind("")
+	ChainClientEthereum = ChainClientKind("ethereum")
+	ChainClientEVM      = ChainClientKind("evm")
+	ChainClientUTXO     = ChainClientKind("utxo")
+	ChainClientXRP      = ChainClientKind("xrp")
+	ChainClientTron     = ChainClientKind("tron")
+)
+
+// ChainInfo records the facts about a chain the rest of the code base is built from
+type ChainInfo struct {
+	Chain Chain
+	// MainnetID and TestnetID are the chain ids on the MAP relay chain, nil when the chain
+	// has no id assigned on that network
+	MainnetID *big.Int
+	TestnetID *big.Int
+	// LegacyIDs are former chain ids relay data may still carry, they resolve to the chain
+	// but are never used for it
+	LegacyIDs   []*big.Int
+	Decimals    int64
+	SigningAlgo SigningAlgo
+	GasAsset    Asset
+	Family      ChainFamily
+	Client      ChainClientKind
+	// OptIn chains are only run when their section of the configuration is filled in and
+	// enabled, an unset section leaves them disabled
+	OptIn bool
+}
+
+// ChainID returns the MAP chain id of the chain on the given network, nil when it has none
+func (i ChainInfo) ChainID(network ChainNetwork) *big.Int {
+	if network == MainNet {
+		return i.MainnetID
+	}
+	return i.TestnetID
+}
+
+// chainRegistry is the single source of chain metadata, new chains are added here
+var chainRegistry = []ChainInfo{
+	{
+		Chain: BSCChain, MainnetID: big.NewInt(56), TestnetID: big.NewInt(97),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: BNBBEP20Asset,
+		Family: ChainFamilyEVM, Client: ChainClientEVM,
+	},
+	{
+		Chain: ETHChain, MainnetID: big.NewInt(1), TestnetID: big.NewInt(11155111),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: ETHAsset,
+		Family: ChainFamilyEVM, Client: ChainClientEthereum,
+	},
+	{
+		Chain: BTCChain, MainnetID: big.NewInt(1360095883558913), TestnetID: big.NewInt(1360095883558914),
+		Decimals: 8, SigningAlgo: SigningAlgoSecp256k1, GasAsset: BTCAsset,
+		Family: ChainFamilyUTXO, Client: ChainClientUTXO,
+	},
+	// LTC, BCH and GAIA have no chain id assigned on the relay chain and bifrost runs no
+	// client for them. They are only registered for their assets and address formats, and
+	// the relay data of no network can refer to them
+	{
+		Chain:    LTCChain,
+		Decimals: 8, SigningAlgo: SigningAlgoSecp256k1, GasAsset: LTCAsset,
+		Family: ChainFamilyUTXO, Client: ChainClientNone,
+	},
+	{
+		Chain:    BCHChain,
+		Decimals: 8, SigningAlgo: SigningAlgoSecp256k1, GasAsset: BCHAsset,
+		Family: ChainFamilyUTXO, Client: ChainClientNone,
+	},
+	{
+		Chain: DOGEChain, MainnetID: big.NewInt(1360121653362689), TestnetID: big.NewInt(1360121653362690),
+		// the mainnet and testnet ids DOGE was mapped back from before the registry
+		LegacyIDs: []*big.Int{big.NewInt(1360095883558915), big.NewInt(1360095883558916)},
+		Decimals:  8, SigningAlgo: SigningAlgoSecp256k1, GasAsset: DOGEAsset,
+		Family: ChainFamilyUTXO, Client: ChainClientUTXO, OptIn: true,
+	},
+	{
+		Chain:    GAIAChain,
+		Decimals: 6, SigningAlgo: SigningAlgoSecp256k1, GasAsset: ATOMAsset,
+		Family: ChainFamilyCosmos, Client: ChainClientNone,
+	},
+	{
+		Chain: AVAXChain, MainnetID: big.NewInt(43114), TestnetID: big.NewInt(43113),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: AVAXAsset,
+		Family: ChainFamilyEVM, Client: ChainClientEVM,
+	},
+	{
+		Chain: BASEChain, MainnetID: big.NewInt(8453), TestnetID: big.NewInt(84532),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: BaseETHAsset,
+		Family: ChainFamilyEVM, Client: ChainClientEVM,
+	},
+	{
+		Chain: XRPChain, MainnetID: big.NewInt(1360117358395393), TestnetID: big.NewInt(1360117358395394),
+		Decimals: 6, SigningAlgo: SigningAlgoSecp256k1, GasAsset: XRPAsset,
+		Family: ChainFamilyXRP, Client: ChainClientXRP, OptIn: true,
+	},
+	{
+		// the relay chain itself, it is handled by the bridge and not by a chain client
+		Chain: MAPChain, MainnetID: big.NewInt(22776), TestnetID: big.NewInt(212),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: MAPAsset,
+		Family: ChainFamilyEVM, Client: ChainClientNone,
+	},
+	{
+		Chain: ARBChain, MainnetID: big.NewInt(42161), TestnetID: big.NewInt(42170),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: ArbETHAsset,
+		Family: ChainFamilyEVM, Client: ChainClientEVM,
+	},
+	{
+		// tron is handled like an evm chain, its addresses are converted to evm ones
+		Chain: TRONChain, MainnetID: big.NewInt(728126428), TestnetID: big.NewInt(728126427),
+		Decimals: 6, SigningAlgo: SigningAlgoSecp256k1, GasAsset: TRXAsset,
+		Family: ChainFamilyEVM, Client: ChainClientTron,
+	},
+	{
+		Chain: SOLChain, MainnetID: big.NewInt(1360108768460801), TestnetID: big.NewInt(1360108768460800),
+		Decimals: 9, SigningAlgo: SigningAlgoEd25519, GasAsset: SOLAsset,
+		Family: ChainFamilySolana, Client: ChainClientNone,
+	},
+	{
+		Chain: OPTChain, MainnetID: big.NewInt(10), TestnetID: big.NewInt(11),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: OptETHAsset,
+		Family: ChainFamilyEVM, Client: ChainClientEVM,
+	},
+	{
+		Chain: UNIChain, MainnetID: big.NewInt(130), TestnetID: big.NewInt(1337),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: UniETHAsset,
+		Family: ChainFamilyEVM, Client: ChainClientEVM,
+	},
+	{
+		Chain: XLAYERChain, MainnetID: big.NewInt(196), TestnetID: big.NewInt(195),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: OKBAsset,
+		Family: ChainFamilyEVM, Client: ChainClientEVM,
+	},
+	{
+		Chain: POLChain, MainnetID: big.NewInt(137), TestnetID: big.NewInt(1101),
+		Decimals: 18, SigningAlgo: SigningAlgoSecp256k1, GasAsset: POLAsset,
+		Family: ChainFamilyEVM, Client: ChainClientEVM,
+	},
+}
+
+// chainsByName indexes the registry by lower case chain name, chainsByID by MAP chain id of
+// every network and by legacy id
+var chainsByName, chainsByID = indexChainRegistry(chainRegistry)
+
+// indexChainRegistry builds the lookup indexes of the registry, it panics when a chain or a
+// chain id is registered twice
+func indexChainRegistry(registry []ChainInfo) (map[string]ChainInfo, map[string]ChainInfo) {
+	byName := make(map[string]ChainInfo, len(registry))
+	byID := make(map[string]ChainInfo, 2*len(registry))
+	for _, info := range registry {
+		name := strings.ToLower(info.Chain.String())
+		if _, ok := byName[name]; ok {
+			panic(fmt.Sprintf("chain %s registered twice", info.Chain))
+		}
+		byName[name] = info
+		for _, id := range append([]*big.Int{info.MainnetID, info.TestnetID}, info.LegacyIDs...) {
+			if id == nil {
+				continue
+			}
+			if other, ok := byID[id.String()]; ok {
+				panic(fmt.Sprintf("chain id %s registered for both %s and %s", id, other.Chain, info.Chain))
+			}
+			byID[id.String()] = info
+		}
+	}
+	return byName, byID
+}
+
+// GetChainInfo returns the registry entry of the given chain, the lookup is case-insensitive
+func GetChainInfo(chain Chain) (ChainInfo, bool) {
+	info, ok := chainsByName[strings.ToLower(chain.String())]
+	return info, ok
+}
+
+// RegisteredChains returns every chain of the registry, in registration order
+func RegisteredChains() Chains {
+	chains := make(Chains, 0, len(chainRegistry))
+	for _, info := range chainRegistry {
+		chains = append(chains, info.Chain)
+	}
+	return chains
+}
+
+// chainsOfFamily returns the chains of the registry in the given family
+func chainsOfFamily(family ChainFamily) []Chain {
+	chains := make([]Chain, 0)
+	for _, info := range chainRegistry {
+		if info.Family == family {
+			chains = append(chains, info.Chain)
+		}
+	}
+	return chains
+}
@@ -0,0 +1,126 @@
+package common
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestChainRegistryLookups(t *testing.T) {
+	tests := []struct {
+		chain     Chain
+		mainnetID *big.Int
+		testnetID *big.Int
+		gasAsset  Asset
+		// noID marks the chains registered without MAP chain ids, left out of the round trip
+		noID bool
+	}{
+		{chain: BSCChain, mainnetID: big.NewInt(56), testnetID: big.NewInt(97), gasAsset: BNBBEP20Asset},
+		{chain: ETHChain, mainnetID: big.NewInt(1), testnetID: big.NewInt(11155111), gasAsset: ETHAsset},
+		{chain: BTCChain, mainnetID: big.NewInt(1360095883558913), testnetID: big.NewInt(1360095883558914), gasAsset: BTCAsset},
+		{chain: LTCChain, gasAsset: LTCAsset, noID: true},
+		{chain: BCHChain, gasAsset: BCHAsset, noID: true},
+		{chain: DOGEChain, mainnetID: big.NewInt(1360121653362689), testnetID: big.NewInt(1360121653362690), gasAsset: DOGEAsset},
+		{chain: GAIAChain, gasAsset: ATOMAsset, noID: true},
+		{chain: AVAXChain, mainnetID: big.NewInt(43114), testnetID: big.NewInt(43113), gasAsset: AVAXAsset},
+		{chain: BASEChain, mainnetID: big.NewInt(8453), testnetID: big.NewInt(84532), gasAsset: BaseETHAsset},
+		{chain: XRPChain, mainnetID: big.NewInt(1360117358395393), testnetID: big.NewInt(1360117358395394), gasAsset: XRPAsset},
+		{chain: MAPChain, mainnetID: big.NewInt(22776), testnetID: big.NewInt(212), gasAsset: MAPAsset},
+		{chain: ARBChain, mainnetID: big.NewInt(42161), testnetID: big.NewInt(42170), gasAsset: ArbETHAsset},
+		{chain: TRONChain, mainnetID: big.NewInt(728126428), testnetID: big.NewInt(728126427), gasAsset: TRXAsset},
+		{chain: SOLChain, mainnetID: big.NewInt(1360108768460801), testnetID: big.NewInt(1360108768460800), gasAsset: SOLAsset},
+		{chain: OPTChain, mainnetID: big.NewInt(10), testnetID: big.NewInt(11), gasAsset: OptETHAsset},
+		{chain: UNIChain, mainnetID: big.NewInt(130), testnetID: big.NewInt(1337), gasAsset: UniETHAsset},
+		{chain: XLAYERChain, mainnetID: big.NewInt(196), testnetID: big.NewInt(195), gasAsset: OKBAsset},
+		{chain: POLChain, mainnetID: big.NewInt(137), testnetID: big.NewInt(1101), gasAsset: POLAsset},
+	}
+	if len(tests) != len(AllChains) {
+		t.Fatalf("registry has %d chains, test covers %d", len(AllChains), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.chain.String(), func(t *testing.T) {
+			info, ok := GetChainInfo(tt.chain)
+			if !ok {
+				t.Fatalf("chain %s is not registered", tt.chain)
+			}
+			if _, ok = GetChainInfo(Chain(tt.chain.String() + "x")); ok {
+				t.Fatal("unexpected registry entry")
+			}
+			if upper, _ := GetChainInfo(Chain(strings.ToUpper(tt.chain.String()))); upper.Chain != tt.chain {
+				t.Fatalf("upper case lookup returned %s", upper.Chain)
+			}
+			if got := tt.chain.GetGasAsset(); !got.Equals(tt.gasAsset) {
+				t.Fatalf("gas asset = %s, want %s", got, tt.gasAsset)
+			}
+			if tt.noID {
+				if info.ChainID(MainNet) != nil || info.ChainID(TestNet) != nil || len(info.LegacyIDs) != 0 {
+					t.Fatalf("chain %s has an id", tt.chain)
+				}
+				return
+			}
+			for network, want := range map[string]*big.Int{"mainnet": tt.mainnetID, "testnet": tt.testnetID} {
+				got := info.ChainID(TestNet)
+				if network == "mainnet" {
+					got = info.ChainID(MainNet)
+				}
+				if got == nil || got.Cmp(want) != 0 {
+					t.Fatalf("%s id = %v, want %s", network, got, want)
+				}
+				// the reverse lookup must lead back to the same chain
+				chain, ok := GetChainName(want)
+				if !ok || !chain.Equals(tt.chain) {
+					t.Fatalf("chain of %s id %s = %s, want %s", network, want, chain, tt.chain)
+				}
+			}
+		})
+	}
+}
+
+func TestChainRegistryLegacyIDs(t *testing.T) {
+	for _, id := range []int64{1360095883558915, 1360095883558916} {
+		chain, ok := GetChainName(big.NewInt(id))
+		if !ok || !chain.Equals(DOGEChain) {
+			t.Fatalf("chain of legacy id %d = %s, want %s", id, chain, DOGEChain)
+		}
+	}
+	// a legacy id is never sent
+	info, _ := GetChainInfo(DOGEChain)
+	if info.ChainID(MainNet).Int64() != 1360121653362689 || info.ChainID(TestNet).Int64() != 1360121653362690 {
+		t.Fatalf("unexpected DOGE ids %s %s", info.ChainID(MainNet), info.ChainID(TestNet))
+	}
+}
+
+func TestChainRegistryLookupIsCaseInsensitive(t *testing.T) {
+	for _, name := range []string{"eth", "ETH", "Eth"} {
+		chain, err := NewChain(name)
+		if err != nil {
+			t.Fatalf("NewChain(%s): %s", name, err)
+		}
+		if chain.GetGasAsset() != ETHAsset || !chain.IsEVM() {
+			t.Fatalf("unexpected metadata for %s", name)
+		}
+	}
+	if _, ok := GetChainName(big.NewInt(1360121653362691)); ok {
+		t.Fatal("unknown chain id should not resolve")
+	}
+}
+
+func TestIndexChainRegistryRejectsDuplicates(t *testing.T) {
+	for name, registry := range map[string][]ChainInfo{
+		"chain": {{Chain: ETHChain}, {Chain: "ETH"}},
+		"id":    {{Chain: ETHChain, MainnetID: big.NewInt(1)}, {Chain: BSCChain, TestnetID: big.NewInt(1)}},
+		"legacy id": {
+			{Chain: ETHChain, MainnetID: big.NewInt(1)},
+			{Chain: BSCChain, MainnetID: big.NewInt(56), LegacyIDs: []*big.Int{big.NewInt(1)}},
+		},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("duplicate %s should panic", name)
+				}
+			}()
+			indexChainRegistry(registry)
+		}()
+	}
+}
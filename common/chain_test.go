@@ -36,6 +36,8 @@ func (s ChainSuite) TestChain(c *C) {
 	c.Assert(LTCChain.GetGasAsset(), Equals, LTCAsset)
 	c.Assert(BCHChain.GetGasAsset(), Equals, BCHAsset)
 	c.Assert(DOGEChain.GetGasAsset(), Equals, DOGEAsset)
+	c.Assert(ARBChain.GetGasAsset(), Equals, ArbETHAsset)
+	c.Assert(TRONChain.GetGasAsset(), Equals, TRXAsset)
 	c.Assert(EmptyChain.GetGasAsset(), Equals, EmptyAsset)
 
 	c.Assert(BTCChain.AddressPrefix(TestNet), Equals, chaincfg.TestNet3Params.Bech32HRPSegwit)
@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
@@ -554,7 +555,13 @@ type Bifrost struct {
 	Signer   BifrostSignerConfiguration  `mapstructure:"signer"`
 	MAPRelay BifrostClientConfiguration  `mapstructure:"mapo"`
 	Metrics  BifrostMetricsConfiguration `mapstructure:"metrics"`
-	Chains   struct {
+	Admin    BifrostAdminConfiguration   `mapstructure:"admin"`
+	// RelayerBalance configures the tracking of the balance the local signing account pays
+	// outbound gas from
+	RelayerBalance BifrostRelayerBalanceConfiguration `mapstructure:"relayer_balance"`
+	// Tracing configures the spans recorded for the stages an order passes through
+	Tracing BifrostTracingConfiguration `mapstructure:"tracing"`
+	Chains  struct {
 		BSC    BifrostChainConfiguration `mapstructure:"bsc"`
 		BTC    BifrostChainConfiguration `mapstructure:"btc"`
 		XRP    BifrostChainConfiguration `mapstructure:"xrp"`
@@ -571,23 +578,30 @@ type Bifrost struct {
 	TSS             BifrostTSSConfiguration `mapstructure:"tss"`
 	ObserverLevelDB LevelDBOptions          `mapstructure:"observer_leveldb"`
 	ObserverWorkers int                     `mapstructure:"observer_workers"` // start how much goroutine to handler other2map tx save in storage
+	// DrainTimeout is how long the node drains its work on shutdown or restart before it stops
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
 }
 
+// GetChains returns the configuration of every chain of Chains, keyed by the chain it is
+// registered as in common. The field's mapstructure tag is the chain name. The opt-in
+// chains are disabled unless their section sets the chain id.
 func (b Bifrost) GetChains() map[common.Chain]BifrostChainConfiguration {
-	// add chain, first add this config
-	return map[common.Chain]BifrostChainConfiguration{
-		common.BSCChain: b.Chains.BSC,
-		common.BTCChain: b.Chains.BTC,
-		// common.XRPChain: b.Chains.XRP,
-		// common.DOGEChain:   b.Chains.DOGE,
-		common.UNIChain:    b.Chains.UNI,
-		common.ETHChain:    b.Chains.ETH,
-		common.BASEChain:   b.Chains.BASE,
-		common.ARBChain:    b.Chains.ARB,
-		common.TRONChain:   b.Chains.TRON,
-		common.POLChain:    b.Chains.POL,
-		common.XLAYERChain: b.Chains.XLAYER,
+	// add chain, first register it in common and add a field to Chains
+	chains := make(map[common.Chain]BifrostChainConfiguration)
+	v := reflect.ValueOf(b.Chains)
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("mapstructure")
+		info, ok := common.GetChainInfo(common.Chain(name))
+		if !ok {
+			continue
+		}
+		cfg := v.Field(i).Interface().(BifrostChainConfiguration)
+		if info.OptIn && cfg.ChainID.IsEmpty() {
+			cfg.Disabled = true
+		}
+		chains[info.Chain] = cfg
 	}
+	return chains
 }
 
 // LevelDBOptions are a superset of the options passed to the LevelDB constructor.
@@ -647,6 +661,35 @@ type BifrostSignerConfiguration struct {
 	KeysignTimeout  time.Duration `mapstructure:"keysign_timeout"`
 	PartyTimeout    time.Duration `mapstructure:"party_timeout"`
 	PreParamTimeout time.Duration `mapstructure:"pre_param_timeout"`
+
+	// PreParamPoolSize is the number of pre parameters generated ahead of keygens, each
+	// keygen takes its own. Zero generates one set at startup reused by every keygen.
+	PreParamPoolSize int `mapstructure:"pre_param_pool_size"`
+
+	// PreParamPoolPath is the folder the pooled pre parameters are stored in, encrypted.
+	PreParamPoolPath string `mapstructure:"pre_param_pool_path"`
+
+	// Oracle bounds the retries of the oracle submissions to the relay chain.
+	Oracle struct {
+		// MaxAttempts is the number of failed submissions after which an oracle item is
+		// moved to the dead letters.
+		MaxAttempts int `mapstructure:"max_attempts"`
+
+		// RetryBackoff is the wait after the first failed submission, doubled after each
+		// failure.
+		RetryBackoff time.Duration `mapstructure:"retry_backoff"`
+
+		// MaxRetryBackoff caps the wait between two submissions.
+		MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"`
+	} `mapstructure:"oracle"`
+
+	// BlameRetention is how long the blame records of failed keysigns and keygens are
+	// kept, zero keeps them forever.
+	BlameRetention time.Duration `mapstructure:"blame_retention"`
+
+	// LimitAlertChannel is the node relay channel the breaches of the outbound limits of
+	// the chains are reported to, nothing is sent when it or the node relay url is empty.
+	LimitAlertChannel string `mapstructure:"limit_alert_channel"`
 }
 
 type BifrostAttestationGossipConfig struct {
@@ -695,6 +738,7 @@ type BifrostChainConfiguration struct {
 	Password            string                           `mapstructure:"password"`
 	APIHost             string                           `mapstructure:"api_host"`
 	RPCHost             string                           `mapstructure:"rpc_host"`
+	RPCHosts            []string                         `mapstructure:"rpc_hosts"`      // additional endpoints used for failover
 	HTTPostMode         bool                             `mapstructure:"http_post_mode"` // Bitcoin core only supports HTTP POST mode
 	DisableTLS          bool                             `mapstructure:"disable_tls"`    // Bitcoin core does not provide TLS by default
 	OptToRetire         bool                             `mapstructure:"opt_to_retire"`  // don't emit support for this chain during keygen process
@@ -722,6 +766,10 @@ type BifrostChainConfiguration struct {
 	// dynamic fee EVM transactions.
 	MaxGasTipPercentage int `mapstructure:"max_gas_tip_percentage"`
 
+	// DynamicFee sends EVM outbounds as EIP-1559 dynamic fee transactions priced from the
+	// base fee of the latest block. Chains without a base fee keep sending legacy ones.
+	DynamicFee bool `mapstructure:"dynamic_fee"`
+
 	// LimitMultiplier is the multiplier that needs to be multiplied after gasLimit is
 	// calculated, but it cannot exceed MaxGasLimit in maximum and MinGasLimit in minimum.
 	LimitMultiplier int `mapstructure:"limit_multiplier"`
@@ -733,10 +781,39 @@ type BifrostChainConfiguration struct {
 	// new signing attempts.
 	MaxPendingNonces uint64 `mapstructure:"max_pending_nonces"`
 
+	// SubmitterTakeoverBlocks is the number of relay chain blocks the node elected to
+	// submit an outbound has before the next node in the rotation takes over. Zero lets
+	// every node submit every outbound.
+	SubmitterTakeoverBlocks int64 `mapstructure:"submitter_takeover_blocks"`
+
+	// MinRelayerBalance is the balance of the gas asset, in 1e8 units, below which the
+	// local signing account is reported as unable to keep paying for outbounds. Zero
+	// disables the alert.
+	MinRelayerBalance uint64 `mapstructure:"min_relayer_balance"`
+
 	// AuthorizationBearer can be set to configure the RPC client with an API token that
 	// will be provided to the backend in an Authorization header.
 	AuthorizationBearer string `mapstructure:"authorization_bearer"`
 
+	// Transport is the transport the rpc traffic of the chain is sent over, nil for the
+	// default one. It isn't read from the configuration, tools that record or replay the
+	// traffic of a chain set it.
+	Transport http.RoundTripper `mapstructure:"-"`
+
+	// RPCPool configures health tracking and failover across RPCHost and RPCHosts.
+	RPCPool struct {
+		// MaxFailures is the number of consecutive failed requests before an endpoint is
+		// taken out of rotation.
+		MaxFailures int `mapstructure:"max_failures"`
+
+		// Cooldown is how long an unhealthy endpoint is skipped before it is retried.
+		Cooldown time.Duration `mapstructure:"cooldown"`
+
+		// Quorum is the number of endpoints that must agree on critical reads (block hash
+		// at height, receipt status, order executed). Values below 2 disable quorum reads.
+		Quorum int `mapstructure:"quorum"`
+	} `mapstructure:"rpc_pool"`
+
 	// UTXO contains UTXO chain specific configuration.
 	UTXO struct {
 		// BlockCacheCount is the number of blocks to cache in storage.
@@ -785,7 +862,101 @@ type BifrostChainConfiguration struct {
 		// MaxUTXOsToSpend is the maximum number of UTXOs to spend in a single transaction.
 		// This is overridden at runtime by the `MaxUTXOsToSpend` mimir value.
 		MaxUTXOsToSpend int64 `mapstructure:"max_utxos_to_spend"`
+
+		// CoinSelection is the strategy picking the UTXOs spent by an outbound, one of
+		// oldest_first, largest_first, branch_and_bound or smallest_first.
+		CoinSelection string `mapstructure:"coin_selection"`
+
+		// FeeBump re-prices outbounds that stay unconfirmed in the mempool. Only BTC and
+		// DOGE outbounds are bumped.
+		FeeBump struct {
+			// Method is how a stuck outbound is bumped. With "rbf" outbounds signal
+			// replaceability and are re-signed with a higher fee from the same inputs, with
+			// "cpfp" the vault change output is spent by a child paying for both. Empty
+			// disables fee bumping.
+			Method string `mapstructure:"method"`
+
+			// StuckBlocks is the number of relay chain blocks an outbound may stay
+			// unconfirmed before its fee is bumped, and again between subsequent bumps. They
+			// are counted from the relay height the outbound was emitted at.
+			StuckBlocks int64 `mapstructure:"stuck_blocks"`
+
+			// FeeRateIncreasePercent is the percentage each bump raises the fee rate by, at
+			// least by the incremental relay fee.
+			FeeRateIncreasePercent int64 `mapstructure:"fee_rate_increase_percent"`
+
+			// MaxBumps is the maximum number of times the fee of an outbound is bumped.
+			MaxBumps int `mapstructure:"max_bumps"`
+		} `mapstructure:"fee_bump"`
+
+		// Batch groups the pending outbounds of a vault into one transaction with an
+		// output per order. Only BTC and DOGE outbounds are batched.
+		Batch struct {
+			// MaxOutputs is the maximum number of orders paid by one transaction, at most 9.
+			// Zero or one disables batching.
+			MaxOutputs int `mapstructure:"max_outputs"`
+
+			// WindowBlocks is the number of relay chain blocks outbounds are grouped over,
+			// a window is signed once the relay chain has passed its last block.
+			WindowBlocks int64 `mapstructure:"window_blocks"`
+		} `mapstructure:"batch"`
 	} `mapstructure:"utxo"`
+
+	// XRP contains XRP chain specific configuration.
+	XRP struct {
+		// IOUs are the issued currencies observed and sent besides XRP, a payment of any
+		// other issued currency to a vault is ignored.
+		IOUs []BifrostXRPIOUConfiguration `mapstructure:"ious"`
+	} `mapstructure:"xrp"`
+
+	// OutboundLimits are local safeguards on the outbounds signed for this chain. An
+	// outbound breaching them holds the signing of its chain or vault, and the held
+	// outbounds stay queued until an operator releases them.
+	OutboundLimits struct {
+		// Window is the rolling window the outflows of a vault and of the chain are summed
+		// over.
+		Window time.Duration `mapstructure:"window"`
+
+		// Tokens are the only tokens outbounds may send, with their limits. Empty disables
+		// the limits of the chain.
+		Tokens []BifrostOutboundTokenLimit `mapstructure:"tokens"`
+	} `mapstructure:"outbound_limits"`
+}
+
+// BifrostOutboundTokenLimit are the limits of the outbounds of a token, the amounts are in
+// the units of the outbound amount emitted by the relay chain. Empty or zero amounts are
+// not limited.
+type BifrostOutboundTokenLimit struct {
+	// Token is the hex address of the token on the chain.
+	Token string `mapstructure:"token"`
+
+	// MaxTx is the maximum amount of a single outbound.
+	MaxTx string `mapstructure:"max_tx"`
+
+	// MaxVaultOutflow is the maximum amount sent by a vault within the window.
+	MaxVaultOutflow string `mapstructure:"max_vault_outflow"`
+
+	// MaxChainOutflow is the maximum amount sent on the chain within the window.
+	MaxChainOutflow string `mapstructure:"max_chain_outflow"`
+}
+
+// BifrostXRPIOUConfiguration maps an XRP issued currency to its token on MAP
+type BifrostXRPIOUConfiguration struct {
+	// Symbol is the token nickname used in memos and in the relay payload.
+	Symbol string `mapstructure:"symbol"`
+
+	// Currency is the currency code, either a 3 character code or its 40 character hex form.
+	// Longer codes like RLUSD are converted to the hex form.
+	Currency string `mapstructure:"currency"`
+
+	// Issuer is the address of the account issuing the currency.
+	Issuer string `mapstructure:"issuer"`
+
+	// Decimals is the precision the currency value is converted to and from on MAP.
+	Decimals int64 `mapstructure:"decimals"`
+
+	// Token is the hex address the token is registered with for this chain on MAP.
+	Token string `mapstructure:"token"`
 }
 
 func (b *BifrostChainConfiguration) Validate() {
@@ -794,6 +965,21 @@ func (b *BifrostChainConfiguration) Validate() {
 	}
 }
 
+// GetRPCHosts returns RPCHost followed by any additional RPCHosts, without duplicates.
+func (b BifrostChainConfiguration) GetRPCHosts() []string {
+	hosts := make([]string, 0, len(b.RPCHosts)+1)
+	seen := make(map[string]bool)
+	for _, host := range append([]string{b.RPCHost}, b.RPCHosts...) {
+		host = strings.TrimSpace(host)
+		if host == "" || seen[host] {
+			continue
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
 type BifrostBlockScannerConfiguration struct {
 	StartBlockHeight           int64         `mapstructure:"start_block_height"`
 	BlockScanProcessors        int           `mapstructure:"block_scan_processors"`
@@ -899,6 +1085,10 @@ type BifrostClientConfiguration struct {
 	CrossDataPath    string       `mapstructure:"cross_data_path"`
 	CrossDataAddress string       `mapstructure:"cross_data_address"`
 	IncreaseGasLimit int64        `mapstructure:"increase_gas_limit"`
+
+	// Transport is the transport the rpc traffic of the relay chain is sent over, nil for
+	// the default one. It isn't read from the configuration.
+	Transport http.RoundTripper `mapstructure:"-"`
 }
 
 type BifrostMetricsConfiguration struct {
@@ -910,6 +1100,48 @@ type BifrostMetricsConfiguration struct {
 	Chains       []common.Chain `mapstructure:"chains"`
 }
 
+type BifrostAdminConfiguration struct {
+	Enabled       bool   `mapstructure:"enabled"`
+	ListenAddress string `mapstructure:"listen_address"`
+	Token         string `mapstructure:"token"`
+	AuditLogPath  string `mapstructure:"audit_log_path"`
+	BackupPath    string `mapstructure:"backup_path"`
+}
+
+type BifrostRelayerBalanceConfiguration struct {
+	// CheckInterval is how often the balances are fetched.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+
+	// AlertInterval is how often a balance still below its minimum is reported again.
+	AlertInterval time.Duration `mapstructure:"alert_interval"`
+
+	// AlertChannel is the node relay channel low balances are reported to, nothing is sent
+	// when it or the node relay url is empty.
+	AlertChannel string `mapstructure:"alert_channel"`
+}
+
+type BifrostTracingConfiguration struct {
+	Enabled bool `mapstructure:"enabled"`
+
+	// DBPath is the leveldb the finished spans are kept in, the cross server serves the
+	// stage timings of an order from it.
+	DBPath string `mapstructure:"db_path"`
+
+	// Retention is how long the finished spans are kept, zero keeps them forever.
+	Retention time.Duration `mapstructure:"retention"`
+
+	// FilePath is the file the finished spans are appended to, one JSON object per line.
+	// Nothing is written when it is empty.
+	FilePath string `mapstructure:"file_path"`
+
+	// OTLPEndpoint is the base url of an OTLP/HTTP collector the finished spans are posted
+	// to, e.g. http://localhost:4318. Nothing is posted when it is empty.
+	OTLPEndpoint string `mapstructure:"otlp_endpoint"`
+
+	// ExportInterval is how often the finished spans are sent to the file and collector.
+	ExportInterval time.Duration `mapstructure:"export_interval"`
+}
+
 type BifrostTSSConfiguration struct {
 	BootstrapPeers []string `mapstructure:"bootstrap_peers"`
 	Rendezvous     string   `mapstructure:"rendezvous"`
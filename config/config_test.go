@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	. "gopkg.in/check.v1"
+
+	"github.com/mapprotocol/compass-tss/common"
 	"gopkg.in/yaml.v2"
 )
 
@@ -20,6 +22,18 @@ func (Test) TestAllChainsReturned(c *C) {
 	c.Assert(len(b.GetChains()), Equals, reflect.TypeOf(b.Chains).NumField())
 }
 
+func (Test) TestOptInChainsDisabled(c *C) {
+	b := Bifrost{}
+	chains := b.GetChains()
+	c.Assert(chains[common.XRPChain].Disabled, Equals, true)
+	c.Assert(chains[common.DOGEChain].Disabled, Equals, true)
+	c.Assert(chains[common.BSCChain].Disabled, Equals, false)
+
+	// a filled in section enables them
+	b.Chains.XRP.ChainID = common.XRPChain
+	c.Assert(b.GetChains()[common.XRPChain].Disabled, Equals, false)
+}
+
 func (Test) TestAllDefaultDefined(c *C) {
 	// In order to override configuration values, defaults must first be defined
 	// in the default YAML file. This test ensures all fields have defaults defined.
@@ -48,6 +62,9 @@ func check(c *C, path []string, cm map[interface{}]interface{}, t reflect.Type)
 		if t.Field(i).Name == "SignerPasswd" {
 			continue
 		}
+		if tag == "-" {
+			continue
+		}
 
 		// assert the field is defined in config
 		if _, ok := cm[tag]; !ok {
@@ -150,6 +150,10 @@ const (
 
 var ErrorOfOrderExecuted = errors.New("order executed")
 
+// ErrorOfNotSubmitter is returned when another node is elected to submit the outbound, the
+// outbound is kept and retried in case the elected node doesn't submit it in time
+var ErrorOfNotSubmitter = errors.New("not the elected submitter")
+
 var ToMapIgnoreError = map[string]struct{}{
 	"0x2dd1d0c8":                 {}, // order exist
 	"0x7ce72949":                 {}, // order_executed
@@ -6,6 +6,7 @@ package constants
 
 const (
 	ElectionEpoch      = "electionEpoch"
+	CurrentEpoch       = "currentEpoch"
 	GetEpochInfo       = "getEpochInfo"
 	GetMaintainerInfos = "getMaintainerInfos"
 	Register           = "register"
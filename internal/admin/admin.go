@@ -0,0 +1,534 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mapprotocol/compass-tss/blockscanner"
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/signer"
+)
+
+// DefaultBackupFolder is where the keyshare backups are written when no backup path is configured
+const DefaultBackupFolder = "./build/keyshare_backups"
+
+// Signer is the part of the signer the admin API operates on
+type Signer interface {
+	ListQueue(oracle bool, chain, vault string) ([]signer.QueueItem, error)
+	RequeueStoreItem(oracle bool, key string) (signer.TxOutStoreItem, error)
+	DropStoreItem(oracle bool, key string) (signer.TxOutStoreItem, error)
+	RebroadcastStoreItem(key string) (signer.TxOutStoreItem, string, error)
+	ClearRound7Retry(key string) (signer.TxOutStoreItem, error)
+	ArchiveStoreItem(key string) (signer.TxOutStoreItem, error)
+	ListArchived() []signer.TxOutStoreItem
+	ListBreakers() []signer.Breaker
+	ReleaseBreaker(scope string) (signer.Breaker, error)
+	ListDeadLetters() []signer.TxOutStoreItem
+	RequeueDeadLetter(key string) (signer.TxOutStoreItem, error)
+	DropDeadLetter(key string) (signer.TxOutStoreItem, error)
+	BackupKeyShares(folder string) ([]string, error)
+}
+
+// Observer is the part of the observer the admin API operates on
+type Observer interface {
+	OnDeckTxs() []types.TxIn
+	ResubmitOnDeck(orderID string) (int, error)
+}
+
+// Rescanner is implemented by the chain clients able to rescan a range of blocks
+type Rescanner interface {
+	Rescan(from, to int64) error
+}
+
+// Server is the operator admin API, it only listens on loopback unless a token is set
+type Server struct {
+	logger   zerolog.Logger
+	cfg      config.BifrostAdminConfiguration
+	s        *http.Server
+	signer   Signer
+	observer Observer
+	chains   map[common.Chain]Rescanner
+	auditMu  sync.Mutex
+	audit    *os.File
+}
+
+// NewServer create a new instance of the admin server
+func NewServer(cfg config.BifrostAdminConfiguration, signer Signer, observer Observer, chains map[common.Chain]Rescanner) (*Server, error) {
+	if cfg.Token == "" && !isLoopbackAddress(cfg.ListenAddress) {
+		return nil, fmt.Errorf("admin api without token must listen on loopback, got %s", cfg.ListenAddress)
+	}
+	as := &Server{
+		logger:   log.With().Str("module", "admin").Logger(),
+		cfg:      cfg,
+		signer:   signer,
+		observer: observer,
+		chains:   chains,
+	}
+	if cfg.AuditLogPath != "" {
+		if err := os.MkdirAll(filepath.Dir(cfg.AuditLogPath), 0o700); err != nil {
+			return nil, fmt.Errorf("fail to create audit log folder: %w", err)
+		}
+		f, err := os.OpenFile(cfg.AuditLogPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("fail to open audit log: %w", err)
+		}
+		as.audit = f
+	}
+	as.s = &http.Server{
+		Addr:              cfg.ListenAddress,
+		Handler:           as.newHandler(),
+		ReadHeaderTimeout: 2 * time.Second,
+	}
+	return as, nil
+}
+
+func (s *Server) newHandler() http.Handler {
+	router := mux.NewRouter()
+	router.Handle("/admin/signer/items", http.HandlerFunc(s.listStoreItems)).Methods(http.MethodGet)
+	router.Handle("/admin/signer/items/{key}", http.HandlerFunc(s.dropStoreItem)).Methods(http.MethodDelete)
+	router.Handle("/admin/signer/items/{key}/requeue", http.HandlerFunc(s.requeueStoreItem)).Methods(http.MethodPost)
+	router.Handle("/admin/signer/items/{key}/rebroadcast", http.HandlerFunc(s.signerStoreOnly(s.rebroadcastStoreItem))).Methods(http.MethodPost)
+	router.Handle("/admin/signer/items/{key}/clear-round7", http.HandlerFunc(s.signerStoreOnly(s.clearRound7Retry))).Methods(http.MethodPost)
+	router.Handle("/admin/signer/items/{key}/archive", http.HandlerFunc(s.signerStoreOnly(s.archiveStoreItem))).Methods(http.MethodPost)
+	router.Handle("/admin/signer/archive", http.HandlerFunc(s.listArchived)).Methods(http.MethodGet)
+	router.Handle("/admin/signer/breakers", http.HandlerFunc(s.listBreakers)).Methods(http.MethodGet)
+	router.Handle("/admin/signer/breakers/{scope}/release", http.HandlerFunc(s.releaseBreaker)).Methods(http.MethodPost)
+	router.Handle("/admin/oracle/deadletters", http.HandlerFunc(s.listDeadLetters)).Methods(http.MethodGet)
+	router.Handle("/admin/oracle/deadletters/{key}/requeue", http.HandlerFunc(s.requeueDeadLetter)).Methods(http.MethodPost)
+	router.Handle("/admin/oracle/deadletters/{key}", http.HandlerFunc(s.dropDeadLetter)).Methods(http.MethodDelete)
+	router.Handle("/admin/observer/ondeck", http.HandlerFunc(s.listOnDeck)).Methods(http.MethodGet)
+	router.Handle("/admin/observer/ondeck/{orderId}/resubmit", http.HandlerFunc(s.resubmitOnDeck)).Methods(http.MethodPost)
+	router.Handle("/admin/chains/paused", http.HandlerFunc(s.pausedChains)).Methods(http.MethodGet)
+	router.Handle("/admin/chains/{chain}/pause", http.HandlerFunc(s.pauseChain)).Methods(http.MethodPost)
+	router.Handle("/admin/chains/{chain}/resume", http.HandlerFunc(s.resumeChain)).Methods(http.MethodPost)
+	router.Handle("/admin/chains/{chain}/rescan", http.HandlerFunc(s.rescanChain)).Methods(http.MethodPost)
+	router.Handle("/admin/keyshares/backup", http.HandlerFunc(s.backupKeyShares)).Methods(http.MethodPost)
+	router.Use(s.authenticate)
+	return router
+}
+
+// authenticate requires the bearer token when one is configured, otherwise only
+// local clients are served
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.cfg.Token != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+				s.auditLog(r, "unauthorized", nil, nil, errors.New("invalid token"))
+				s.writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+				return
+			}
+		} else if !isLoopbackAddress(r.RemoteAddr) {
+			s.auditLog(r, "unauthorized", nil, nil, errors.New("remote client without token"))
+			s.writeError(w, http.StatusForbidden, errors.New("forbidden"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// StoreItem is a signer store item along with its key
+type StoreItem struct {
+	Key  string                `json:"key"`
+	Item signer.TxOutStoreItem `json:"item"`
+}
+
+func isOracleStore(r *http.Request) bool {
+	return r.URL.Query().Get("store") == "oracle"
+}
+
+// listStoreItems lists the items of the signer (or oracle) store with their age and attempt
+// state, the chain and vault query parameters filter them
+func (s *Server) listStoreItems(w http.ResponseWriter, r *http.Request) {
+	items, err := s.signer.ListQueue(isOracleStore(r), r.URL.Query().Get("chain"), r.URL.Query().Get("vault"))
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.writeSuccess(w, items)
+}
+
+// signerStoreOnly rejects the actions on the items of the oracle store, they only apply to
+// the outbounds of the signer store
+func (s *Server) signerStoreOnly(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if isOracleStore(r) {
+			s.writeError(w, http.StatusBadRequest, errors.New("only the items of the signer store can be acted on"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) requeueStoreItem(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	params := map[string]string{"key": key, "store": r.URL.Query().Get("store")}
+	item, err := s.signer.RequeueStoreItem(isOracleStore(r), key)
+	s.auditLog(r, "signer_requeue", params, nil, err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, StoreItem{Key: key, Item: item})
+}
+
+func (s *Server) dropStoreItem(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	params := map[string]string{"key": key, "store": r.URL.Query().Get("store")}
+	item, err := s.signer.DropStoreItem(isOracleStore(r), key)
+	s.auditLog(r, "signer_drop", params, item.TxOutItem.OrderId.Hex(), err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, StoreItem{Key: key, Item: item})
+}
+
+// RebroadcastResult is the item whose signed tx was broadcast again and the hash of the tx
+type RebroadcastResult struct {
+	StoreItem
+	Hash string `json:"hash"`
+}
+
+func (s *Server) rebroadcastStoreItem(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	item, hash, err := s.signer.RebroadcastStoreItem(key)
+	s.auditLog(r, "signer_rebroadcast", map[string]string{"key": key}, hash, err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, RebroadcastResult{StoreItem: StoreItem{Key: key, Item: item}, Hash: hash})
+}
+
+func (s *Server) clearRound7Retry(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	item, err := s.signer.ClearRound7Retry(key)
+	s.auditLog(r, "signer_clear_round7", map[string]string{"key": key}, item.TxOutItem.OrderId.Hex(), err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, StoreItem{Key: key, Item: item})
+}
+
+func (s *Server) archiveStoreItem(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	item, err := s.signer.ArchiveStoreItem(key)
+	s.auditLog(r, "signer_archive", map[string]string{"key": key}, item.TxOutItem.OrderId.Hex(), err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, StoreItem{Key: key, Item: item})
+}
+
+func (s *Server) listArchived(w http.ResponseWriter, _ *http.Request) {
+	items := s.signer.ListArchived()
+	result := make([]StoreItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, StoreItem{Key: item.Key(), Item: item})
+	}
+	s.writeSuccess(w, result)
+}
+
+// listBreakers lists the tripped outbound limits holding the signing of a chain or vault
+func (s *Server) listBreakers(w http.ResponseWriter, _ *http.Request) {
+	s.writeSuccess(w, s.signer.ListBreakers())
+}
+
+func (s *Server) releaseBreaker(w http.ResponseWriter, r *http.Request) {
+	scope := mux.Vars(r)["scope"]
+	breaker, err := s.signer.ReleaseBreaker(scope)
+	s.auditLog(r, "signer_release_breaker", map[string]string{"scope": scope}, breaker.OrderIds, err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, breaker)
+}
+
+func (s *Server) listDeadLetters(w http.ResponseWriter, _ *http.Request) {
+	items := s.signer.ListDeadLetters()
+	result := make([]StoreItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, StoreItem{Key: item.Key(), Item: item})
+	}
+	s.writeSuccess(w, result)
+}
+
+func (s *Server) requeueDeadLetter(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	item, err := s.signer.RequeueDeadLetter(key)
+	s.auditLog(r, "oracle_requeue", map[string]string{"key": key}, nil, err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, StoreItem{Key: key, Item: item})
+}
+
+func (s *Server) dropDeadLetter(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	item, err := s.signer.DropDeadLetter(key)
+	s.auditLog(r, "oracle_drop", map[string]string{"key": key}, item.TxOutItem.OrderId.Hex(), err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, StoreItem{Key: key, Item: item})
+}
+
+func (s *Server) listOnDeck(w http.ResponseWriter, _ *http.Request) {
+	s.writeSuccess(w, s.observer.OnDeckTxs())
+}
+
+func (s *Server) resubmitOnDeck(w http.ResponseWriter, r *http.Request) {
+	orderID := mux.Vars(r)["orderId"]
+	sent, err := s.observer.ResubmitOnDeck(orderID)
+	s.auditLog(r, "observer_resubmit", map[string]string{"order_id": orderID}, sent, err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, map[string]int{"sent": sent})
+}
+
+// getChain resolves the chain of the request, the chain name is case insensitive
+func (s *Server) getChain(r *http.Request) (common.Chain, error) {
+	name := mux.Vars(r)["chain"]
+	for chain := range s.chains {
+		if strings.EqualFold(chain.String(), name) {
+			return chain, nil
+		}
+	}
+	return common.EmptyChain, fmt.Errorf("chain %s is not loaded", name)
+}
+
+func (s *Server) pausedChains(w http.ResponseWriter, _ *http.Request) {
+	s.writeSuccess(w, blockscanner.PausedChains())
+}
+
+func (s *Server) pauseChain(w http.ResponseWriter, r *http.Request) {
+	chain, err := s.getChain(r)
+	if err == nil {
+		blockscanner.PauseChain(chain)
+	}
+	s.auditLog(r, "chain_pause", map[string]string{"chain": mux.Vars(r)["chain"]}, nil, err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, blockscanner.PausedChains())
+}
+
+func (s *Server) resumeChain(w http.ResponseWriter, r *http.Request) {
+	chain, err := s.getChain(r)
+	if err == nil {
+		blockscanner.ResumeChain(chain)
+	}
+	s.auditLog(r, "chain_resume", map[string]string{"chain": mux.Vars(r)["chain"]}, nil, err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, blockscanner.PausedChains())
+}
+
+func (s *Server) rescanChain(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := map[string]string{"chain": mux.Vars(r)["chain"], "from": query.Get("from"), "to": query.Get("to")}
+	err := s.rescan(r, query.Get("from"), query.Get("to"))
+	s.auditLog(r, "chain_rescan", params, nil, err)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.writeSuccess(w, params)
+}
+
+func (s *Server) rescan(r *http.Request, fromStr, toStr string) error {
+	chain, err := s.getChain(r)
+	if err != nil {
+		return err
+	}
+	from, err := strconv.ParseInt(fromStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid from height: %w", err)
+	}
+	to, err := strconv.ParseInt(toStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid to height: %w", err)
+	}
+	return s.chains[chain].Rescan(from, to)
+}
+
+func (s *Server) backupKeyShares(w http.ResponseWriter, r *http.Request) {
+	folder, err := s.backupFolder(r.URL.Query().Get("folder"))
+	if err != nil {
+		s.auditLog(r, "keyshare_backup", map[string]string{"folder": r.URL.Query().Get("folder")}, nil, err)
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	backups, err := s.signer.BackupKeyShares(folder)
+	s.auditLog(r, "keyshare_backup", map[string]string{"folder": folder}, backups, err)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.writeSuccess(w, backups)
+}
+
+// backupFolder resolves the requested folder under the configured backup path, the
+// keyshares are secret and must not be written anywhere else
+func (s *Server) backupFolder(folder string) (string, error) {
+	base := s.cfg.BackupPath
+	if base == "" {
+		base = DefaultBackupFolder
+	}
+	if folder == "" {
+		return base, nil
+	}
+	if filepath.IsAbs(folder) {
+		return "", fmt.Errorf("backup folder %s must be relative to the backup path", folder)
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(folder), "/") {
+		if elem == ".." {
+			return "", fmt.Errorf("backup folder %s must not leave the backup path", folder)
+		}
+	}
+	return filepath.Join(base, folder), nil
+}
+
+// auditEntry is a line of the audit log
+type auditEntry struct {
+	Time   time.Time         `json:"time"`
+	Action string            `json:"action"`
+	Remote string            `json:"remote"`
+	Params map[string]string `json:"params,omitempty"`
+	Result interface{}       `json:"result,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// auditLog records the action in the log and appends it to the audit log file
+func (s *Server) auditLog(r *http.Request, action string, params map[string]string, result interface{}, err error) {
+	entry := auditEntry{
+		Time:   time.Now().UTC(),
+		Action: action,
+		Remote: r.RemoteAddr,
+		Params: params,
+		Result: result,
+	}
+	event := s.logger.Info()
+	if err != nil {
+		entry.Error = err.Error()
+		event = s.logger.Warn().Err(err)
+	}
+	event.Str("action", action).Str("remote", entry.Remote).Interface("params", params).Msg("admin action")
+
+	if s.audit == nil {
+		return
+	}
+	buf, mErr := json.Marshal(entry)
+	if mErr != nil {
+		s.logger.Error().Err(mErr).Msg("fail to marshal audit entry")
+		return
+	}
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+	if _, wErr := s.audit.Write(append(buf, '\n')); wErr != nil {
+		s.logger.Error().Err(wErr).Msg("fail to write audit log")
+	}
+}
+
+func (s *Server) writeSuccess(w http.ResponseWriter, data interface{}) {
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data": data,
+		"code": 0,
+		"msg":  "success",
+	})
+}
+
+func (s *Server) writeError(w http.ResponseWriter, status int, err error) {
+	s.writeJSON(w, status, map[string]interface{}{
+		"code": status,
+		"msg":  err.Error(),
+	})
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	jsonBytes, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to write to response")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err = w.Write(jsonBytes); err != nil {
+		s.logger.Error().Err(err).Msg("fail to write to response")
+	}
+}
+
+// isLoopbackAddress returns true when the host of the given address is a loopback ip or localhost
+func isLoopbackAddress(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Start admin server
+func (s *Server) Start() error {
+	if s.s == nil {
+		return errors.New("invalid http server instance")
+	}
+	s.logger.Info().Str("address", s.cfg.ListenAddress).Bool("token", s.cfg.Token != "").Msg("starting admin server")
+	if err := s.s.ListenAndServe(); err != nil {
+		if err != http.ErrServerClosed {
+			return fmt.Errorf("fail to start http server: %w", err)
+		}
+	}
+	return nil
+}
+
+// Stop admin server
+func (s *Server) Stop() error {
+	s.logger.Info().Msg("shutting down admin server...")
+	c, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err := s.s.Shutdown(c)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to shutdown the admin server gracefully")
+	}
+	if s.audit != nil {
+		s.auditMu.Lock()
+		defer s.auditMu.Unlock()
+		if cErr := s.audit.Close(); cErr != nil {
+			s.logger.Error().Err(cErr).Msg("fail to close audit log")
+		}
+	}
+	return err
+}
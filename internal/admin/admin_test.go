@@ -0,0 +1,531 @@
+package admin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mapprotocol/compass-tss/blockscanner"
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/signer"
+)
+
+type fakeSigner struct {
+	items       map[string]signer.TxOutStoreItem
+	deadLetters map[string]signer.TxOutStoreItem
+	archived    map[string]signer.TxOutStoreItem
+	breakers    map[string]signer.Breaker
+	backups     []string
+}
+
+func (f *fakeSigner) RequeueStoreItem(_ bool, key string) (signer.TxOutStoreItem, error) {
+	item, ok := f.items[key]
+	if !ok {
+		return item, errors.New("not found")
+	}
+	item.Status = signer.TxAvailable
+	item.Round7Retry = false
+	f.items[key] = item
+	return item, nil
+}
+
+func (f *fakeSigner) DropStoreItem(_ bool, key string) (signer.TxOutStoreItem, error) {
+	item, ok := f.items[key]
+	if !ok {
+		return item, errors.New("not found")
+	}
+	delete(f.items, key)
+	return item, nil
+}
+
+func (f *fakeSigner) ListDeadLetters() []signer.TxOutStoreItem {
+	result := make([]signer.TxOutStoreItem, 0, len(f.deadLetters))
+	for key, item := range f.deadLetters {
+		item.RetrievalKey = key
+		result = append(result, item)
+	}
+	return result
+}
+
+func (f *fakeSigner) RequeueDeadLetter(key string) (signer.TxOutStoreItem, error) {
+	item, ok := f.deadLetters[key]
+	if !ok {
+		return item, errors.New("not found")
+	}
+	delete(f.deadLetters, key)
+	item.Attempts = 0
+	f.items[key] = item
+	return item, nil
+}
+
+func (f *fakeSigner) DropDeadLetter(key string) (signer.TxOutStoreItem, error) {
+	item, ok := f.deadLetters[key]
+	if !ok {
+		return item, errors.New("not found")
+	}
+	delete(f.deadLetters, key)
+	return item, nil
+}
+
+func (f *fakeSigner) ListQueue(_ bool, chain, _ string) ([]signer.QueueItem, error) {
+	result := make([]signer.QueueItem, 0, len(f.items))
+	for key, item := range f.items {
+		if chain != "" && chain != "BSC" {
+			continue
+		}
+		result = append(result, signer.QueueItem{Key: key, Chain: "BSC", Round7Retry: item.Round7Retry, HasSignedTx: len(item.SignedTx) > 0})
+	}
+	return result, nil
+}
+
+func (f *fakeSigner) RebroadcastStoreItem(key string) (signer.TxOutStoreItem, string, error) {
+	item, ok := f.items[key]
+	if !ok {
+		return item, "", errors.New("not found")
+	}
+	if len(item.SignedTx) == 0 {
+		return item, "", errors.New("no signed tx")
+	}
+	delete(f.items, key)
+	return item, "0x1", nil
+}
+
+func (f *fakeSigner) ClearRound7Retry(key string) (signer.TxOutStoreItem, error) {
+	item, ok := f.items[key]
+	if !ok || !item.Round7Retry {
+		return item, errors.New("not in round 7 retry")
+	}
+	item.Round7Retry = false
+	f.items[key] = item
+	return item, nil
+}
+
+func (f *fakeSigner) ArchiveStoreItem(key string) (signer.TxOutStoreItem, error) {
+	item, ok := f.items[key]
+	if !ok {
+		return item, errors.New("not found")
+	}
+	delete(f.items, key)
+	f.archived[key] = item
+	return item, nil
+}
+
+func (f *fakeSigner) ListArchived() []signer.TxOutStoreItem {
+	result := make([]signer.TxOutStoreItem, 0, len(f.archived))
+	for key, item := range f.archived {
+		item.RetrievalKey = key
+		result = append(result, item)
+	}
+	return result
+}
+
+func (f *fakeSigner) ListBreakers() []signer.Breaker {
+	result := make([]signer.Breaker, 0, len(f.breakers))
+	for _, breaker := range f.breakers {
+		result = append(result, breaker)
+	}
+	return result
+}
+
+func (f *fakeSigner) ReleaseBreaker(scope string) (signer.Breaker, error) {
+	breaker, ok := f.breakers[scope]
+	if !ok {
+		return breaker, errors.New("not found")
+	}
+	delete(f.breakers, scope)
+	return breaker, nil
+}
+
+func (f *fakeSigner) BackupKeyShares(folder string) ([]string, error) {
+	f.backups = append(f.backups, folder)
+	return nil, nil
+}
+
+type fakeObserver struct{}
+
+func (fakeObserver) OnDeckTxs() []types.TxIn { return nil }
+
+func (fakeObserver) ResubmitOnDeck(string) (int, error) { return 1, nil }
+
+type fakeRescanner struct {
+	from, to int64
+}
+
+func (f *fakeRescanner) Rescan(from, to int64) error {
+	f.from, f.to = from, to
+	return nil
+}
+
+func newTestServer(t *testing.T, token string) (*Server, *fakeSigner, *fakeRescanner) {
+	t.Helper()
+	fs := &fakeSigner{
+		items: map[string]signer.TxOutStoreItem{
+			"txout-v4-1": {Status: signer.TxUnavailable, Round7Retry: true},
+		},
+		deadLetters: map[string]signer.TxOutStoreItem{
+			"txout-v4-2": {Attempts: 20, LastError: "fail to get oracle std tx"},
+			"txout-v4-3": {Attempts: 20, LastError: "fail to broadcast tx"},
+		},
+		archived: map[string]signer.TxOutStoreItem{},
+		breakers: map[string]signer.Breaker{
+			"BSC": {Scope: "BSC", Chain: "BSC", Reason: "token 0x01 is not allowed", OrderIds: []string{"0x1"}},
+		},
+	}
+	rescanner := &fakeRescanner{}
+	s, err := NewServer(config.BifrostAdminConfiguration{
+		ListenAddress: "127.0.0.1:0",
+		Token:         token,
+		AuditLogPath:  filepath.Join(t.TempDir(), "audit", "admin_audit.log"),
+		BackupPath:    "/var/backups/keyshares",
+	}, fs, fakeObserver{}, map[common.Chain]Rescanner{common.BSCChain: rescanner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = s.audit.Close() })
+	return s, fs, rescanner
+}
+
+func serve(s *Server, method, target, remote, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	req.RemoteAddr = remote
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.newHandler().ServeHTTP(w, req)
+	return w
+}
+
+func readAudit(t *testing.T, s *Server) []auditEntry {
+	t.Helper()
+	f, err := os.Open(s.cfg.AuditLogPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry auditEntry
+		if err = json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestNewServerRequiresTokenOffLoopback(t *testing.T) {
+	_, err := NewServer(config.BifrostAdminConfiguration{ListenAddress: "0.0.0.0:6042"}, nil, nil, nil)
+	if err == nil {
+		t.Fatal("expected an error without token on a public address")
+	}
+	_, err = NewServer(config.BifrostAdminConfiguration{ListenAddress: "0.0.0.0:6042", Token: "secret"}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	s, _, _ := newTestServer(t, "")
+	if w := serve(s, http.MethodGet, "/admin/chains/paused", "10.0.0.1:1234", ""); w.Code != http.StatusForbidden {
+		t.Fatalf("remote client without token: got %d", w.Code)
+	}
+	if w := serve(s, http.MethodGet, "/admin/chains/paused", "127.0.0.1:1234", ""); w.Code != http.StatusOK {
+		t.Fatalf("local client without token: got %d", w.Code)
+	}
+
+	s, _, _ = newTestServer(t, "secret")
+	if w := serve(s, http.MethodGet, "/admin/chains/paused", "127.0.0.1:1234", ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("missing token: got %d", w.Code)
+	}
+	if w := serve(s, http.MethodGet, "/admin/chains/paused", "127.0.0.1:1234", "wrong"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: got %d", w.Code)
+	}
+	if w := serve(s, http.MethodGet, "/admin/chains/paused", "10.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("valid token: got %d", w.Code)
+	}
+	entries := readAudit(t, s)
+	if len(entries) != 2 || entries[0].Action != "unauthorized" {
+		t.Fatalf("unexpected audit entries: %+v", entries)
+	}
+}
+
+func TestPauseResumeChain(t *testing.T) {
+	s, _, _ := newTestServer(t, "secret")
+	defer blockscanner.ResumeChain(common.BSCChain)
+
+	if w := serve(s, http.MethodPost, "/admin/chains/bsc/pause", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("pause: got %d", w.Code)
+	}
+	if !blockscanner.IsChainPausedLocally(common.BSCChain) {
+		t.Fatal("chain should be paused")
+	}
+	if w := serve(s, http.MethodPost, "/admin/chains/eth/pause", "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("pause unknown chain: got %d", w.Code)
+	}
+	if w := serve(s, http.MethodPost, "/admin/chains/bsc/resume", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("resume: got %d", w.Code)
+	}
+	if blockscanner.IsChainPausedLocally(common.BSCChain) {
+		t.Fatal("chain should be resumed")
+	}
+
+	entries := readAudit(t, s)
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 audit entries, got %d", len(entries))
+	}
+	if entries[0].Action != "chain_pause" || entries[0].Params["chain"] != "bsc" || entries[0].Error != "" {
+		t.Fatalf("unexpected audit entry: %+v", entries[0])
+	}
+	if entries[1].Error == "" {
+		t.Fatal("failed action should be audited with its error")
+	}
+}
+
+func TestRescanChain(t *testing.T) {
+	s, _, rescanner := newTestServer(t, "secret")
+	if w := serve(s, http.MethodPost, "/admin/chains/Bsc/rescan?from=10&to=20", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("rescan: got %d", w.Code)
+	}
+	if rescanner.from != 10 || rescanner.to != 20 {
+		t.Fatalf("unexpected rescan range %d-%d", rescanner.from, rescanner.to)
+	}
+	if w := serve(s, http.MethodPost, "/admin/chains/Bsc/rescan?from=a&to=20", "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid rescan: got %d", w.Code)
+	}
+}
+
+func TestSignerItems(t *testing.T) {
+	s, fs, _ := newTestServer(t, "secret")
+	w := serve(s, http.MethodGet, "/admin/signer/items", "127.0.0.1:1234", "secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: got %d", w.Code)
+	}
+	var resp struct {
+		Data []signer.QueueItem `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].Key != "txout-v4-1" {
+		t.Fatalf("unexpected items: %+v", resp.Data)
+	}
+
+	if w = serve(s, http.MethodPost, "/admin/signer/items/txout-v4-1/requeue", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("requeue: got %d", w.Code)
+	}
+	if item := fs.items["txout-v4-1"]; item.Status != signer.TxAvailable || item.Round7Retry {
+		t.Fatalf("item not requeued: %+v", item)
+	}
+	if w = serve(s, http.MethodDelete, "/admin/signer/items/txout-v4-1", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("drop: got %d", w.Code)
+	}
+	if len(fs.items) != 0 {
+		t.Fatal("item should be dropped")
+	}
+	if w = serve(s, http.MethodDelete, "/admin/signer/items/txout-v4-1", "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("drop missing item: got %d", w.Code)
+	}
+
+	actions := make([]string, 0)
+	for _, entry := range readAudit(t, s) {
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 3 || actions[0] != "signer_requeue" || actions[1] != "signer_drop" {
+		t.Fatalf("unexpected audit actions: %v", actions)
+	}
+}
+
+func TestSignerQueue(t *testing.T) {
+	s, fs, _ := newTestServer(t, "secret")
+	fs.items["txout-v4-4"] = signer.TxOutStoreItem{Status: signer.TxAvailable, SignedTx: []byte{1}}
+	fs.items["txout-v4-5"] = signer.TxOutStoreItem{Status: signer.TxAvailable}
+
+	w := serve(s, http.MethodGet, "/admin/signer/items?chain=BSC", "127.0.0.1:1234", "secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: got %d", w.Code)
+	}
+	var resp struct {
+		Data []signer.QueueItem `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 3 {
+		t.Fatalf("unexpected queue: %+v", resp.Data)
+	}
+	w = serve(s, http.MethodGet, "/admin/signer/items?chain=ETH", "127.0.0.1:1234", "secret")
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 0 {
+		t.Fatalf("expected no items of another chain, got %+v", resp.Data)
+	}
+
+	if w = serve(s, http.MethodPost, "/admin/signer/items/txout-v4-1/clear-round7", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("clear round 7: got %d", w.Code)
+	}
+	if fs.items["txout-v4-1"].Round7Retry {
+		t.Fatal("round 7 retry should be cleared")
+	}
+	if w = serve(s, http.MethodPost, "/admin/signer/items/txout-v4-1/clear-round7", "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("clear round 7 twice: got %d", w.Code)
+	}
+	if w = serve(s, http.MethodPost, "/admin/signer/items/txout-v4-4/rebroadcast?store=oracle", "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("rebroadcast of an oracle item: got %d", w.Code)
+	}
+	if w = serve(s, http.MethodPost, "/admin/signer/items/txout-v4-5/rebroadcast", "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("rebroadcast without signed tx: got %d", w.Code)
+	}
+	w = serve(s, http.MethodPost, "/admin/signer/items/txout-v4-4/rebroadcast", "127.0.0.1:1234", "secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("rebroadcast: got %d", w.Code)
+	}
+	var rebroadcast struct {
+		Data RebroadcastResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &rebroadcast); err != nil {
+		t.Fatal(err)
+	}
+	if rebroadcast.Data.Hash != "0x1" || rebroadcast.Data.Key != "txout-v4-4" {
+		t.Fatalf("unexpected rebroadcast result: %+v", rebroadcast.Data)
+	}
+	if w = serve(s, http.MethodPost, "/admin/signer/items/txout-v4-5/archive", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("archive: got %d", w.Code)
+	}
+	if _, ok := fs.items["txout-v4-5"]; ok {
+		t.Fatal("archived item should leave the queue")
+	}
+	w = serve(s, http.MethodGet, "/admin/signer/archive", "127.0.0.1:1234", "secret")
+	var archived struct {
+		Data []StoreItem `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &archived); err != nil {
+		t.Fatal(err)
+	}
+	if len(archived.Data) != 1 || archived.Data[0].Key != "txout-v4-5" {
+		t.Fatalf("unexpected archived items: %+v", archived.Data)
+	}
+
+	entries := readAudit(t, s)
+	actions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		actions = append(actions, entry.Action)
+	}
+	expected := []string{"signer_clear_round7", "signer_clear_round7", "signer_rebroadcast", "signer_rebroadcast", "signer_archive"}
+	if len(actions) != len(expected) {
+		t.Fatalf("unexpected audit actions: %v", actions)
+	}
+	for i := range expected {
+		if actions[i] != expected[i] {
+			t.Fatalf("unexpected audit actions: %v", actions)
+		}
+	}
+	if entries[1].Error == "" || entries[3].Result != "0x1" || entries[4].Params["key"] != "txout-v4-5" {
+		t.Fatalf("unexpected audit entries: %+v", entries)
+	}
+}
+
+func TestOracleDeadLetters(t *testing.T) {
+	s, fs, _ := newTestServer(t, "secret")
+	w := serve(s, http.MethodGet, "/admin/oracle/deadletters", "127.0.0.1:1234", "secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: got %d", w.Code)
+	}
+	var resp struct {
+		Data []StoreItem `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 2 || resp.Data[0].Item.LastError == "" {
+		t.Fatalf("unexpected dead letters: %+v", resp.Data)
+	}
+
+	if w = serve(s, http.MethodPost, "/admin/oracle/deadletters/txout-v4-2/requeue", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("requeue: got %d", w.Code)
+	}
+	if item, ok := fs.items["txout-v4-2"]; !ok || item.Attempts != 0 {
+		t.Fatalf("dead letter not requeued: %+v", fs.items)
+	}
+	if w = serve(s, http.MethodDelete, "/admin/oracle/deadletters/txout-v4-3", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("drop: got %d", w.Code)
+	}
+	if len(fs.deadLetters) != 0 {
+		t.Fatal("dead letters should be empty")
+	}
+	if w = serve(s, http.MethodPost, "/admin/oracle/deadletters/txout-v4-3/requeue", "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("requeue missing dead letter: got %d", w.Code)
+	}
+
+	actions := make([]string, 0)
+	for _, entry := range readAudit(t, s) {
+		actions = append(actions, entry.Action)
+	}
+	if len(actions) != 3 || actions[0] != "oracle_requeue" || actions[1] != "oracle_drop" {
+		t.Fatalf("unexpected audit actions: %v", actions)
+	}
+}
+
+func TestBreakers(t *testing.T) {
+	s, fs, _ := newTestServer(t, "secret")
+	w := serve(s, http.MethodGet, "/admin/signer/breakers", "127.0.0.1:1234", "secret")
+	if w.Code != http.StatusOK {
+		t.Fatalf("list: got %d", w.Code)
+	}
+	var resp struct {
+		Data []signer.Breaker `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 1 || resp.Data[0].Scope != "BSC" {
+		t.Fatalf("unexpected breakers: %+v", resp.Data)
+	}
+
+	if w = serve(s, http.MethodPost, "/admin/signer/breakers/BSC/release", "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+		t.Fatalf("release: got %d", w.Code)
+	}
+	if len(fs.breakers) != 0 {
+		t.Fatal("breaker should be released")
+	}
+	if w = serve(s, http.MethodPost, "/admin/signer/breakers/BSC/release", "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+		t.Fatalf("release missing breaker: got %d", w.Code)
+	}
+
+	entries := readAudit(t, s)
+	if len(entries) != 2 || entries[0].Action != "signer_release_breaker" || entries[0].Params["scope"] != "BSC" {
+		t.Fatalf("unexpected audit entries: %+v", entries)
+	}
+	if entries[1].Error == "" {
+		t.Fatal("failed release should be audited with its error")
+	}
+}
+
+func TestBackupKeyShares(t *testing.T) {
+	s, fs, _ := newTestServer(t, "secret")
+	for _, folder := range []string{"", "2024-01-01"} {
+		if w := serve(s, http.MethodPost, "/admin/keyshares/backup?folder="+folder, "127.0.0.1:1234", "secret"); w.Code != http.StatusOK {
+			t.Fatalf("backup to %q: got %d", folder, w.Code)
+		}
+	}
+	for _, folder := range []string{"/tmp", "../keys", "daily/../../keys"} {
+		if w := serve(s, http.MethodPost, "/admin/keyshares/backup?folder="+folder, "127.0.0.1:1234", "secret"); w.Code != http.StatusBadRequest {
+			t.Fatalf("backup to %q: got %d", folder, w.Code)
+		}
+	}
+	if len(fs.backups) != 2 || fs.backups[0] != "/var/backups/keyshares" || fs.backups[1] != "/var/backups/keyshares/2024-01-01" {
+		t.Fatalf("unexpected backup folders: %v", fs.backups)
+	}
+	if entries := readAudit(t, s); len(entries) != 5 || entries[4].Error == "" {
+		t.Fatalf("rejected backups should be audited: %+v", entries)
+	}
+}
@@ -0,0 +1,162 @@
+package cross
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	mem "github.com/mapprotocol/compass-tss/x/memo"
+)
+
+const (
+	KeyOfDestinationTag    = "meta:dtag:%s" // chain:tag:ledger
+	prefixOfDestinationTag = "meta:dtag:"
+)
+
+var (
+	ErrInvalidDestinationTag    = errors.New("destination tag must be greater than zero")
+	ErrInvalidDestinationLedger = errors.New("destination tag ledger must be greater than zero")
+)
+
+// DestinationTag binds the destination tag of a deposit to the memo it is observed with, for
+// senders such as exchanges that cannot attach a memo to their payments.
+//
+// Registrations are versioned by ledger, a registration applies to the deposits from its ledger
+// on and deposits in earlier ledgers keep the memo the tag had before. A deposit is resolved at
+// the ledger it was included in, so every observer holding the same registrations votes the same
+// memo no matter when it scans the ledger
+type DestinationTag struct {
+	Chain string `json:"chain" example:"XRP"`
+	Tag   uint32 `json:"tag" example:"1024"`
+	// Memo is empty for a tag retired from Ledger on
+	Memo   string `json:"memo" example:"Mx|Tron|USDT|TXcb8NicbbiT1sfSuNRZH19XggX1ph3Aoz|690943|bt0"`
+	Ledger int64  `json:"ledger" example:"99000000"`
+	// Intent is the id of the order intent the tag was registered for, empty for standing routes
+	Intent    string `json:"intent,omitempty" example:""`
+	Timestamp int64  `json:"timestamp" example:"1767097427"`
+}
+
+// DestinationTagRegistry returns the registration of a destination tag in effect at a ledger,
+// nil when the tag had none
+type DestinationTagRegistry interface {
+	GetDestinationTag(chain string, tag uint32, ledger int64) (*DestinationTag, error)
+}
+
+func (s *CrossStorage) destinationTagPrefix(chain string, tag uint32) string {
+	return fmt.Sprintf(KeyOfDestinationTag, fmt.Sprintf("%s:%010d:", strings.ToUpper(chain), tag))
+}
+
+// createDestinationTagKey pads the tag and the ledger, the versions of a tag sort by ledger
+func (s *CrossStorage) createDestinationTagKey(chain string, tag uint32, ledger int64) string {
+	return fmt.Sprintf("%s%020d", s.destinationTagPrefix(chain, tag), ledger)
+}
+
+// SetDestinationTag registers the memo of a destination tag from its ledger on, a registration
+// or retirement of the same tag at the same ledger is replaced
+func (s *CrossStorage) SetDestinationTag(dt *DestinationTag) error {
+	if dt.Memo == "" {
+		return errors.New("destination tag requires a memo")
+	}
+	return s.putDestinationTag(dt)
+}
+
+// RetireDestinationTag stops routing deposits by a destination tag from ledger on, the
+// deposits with it go to the failed receiver
+func (s *CrossStorage) RetireDestinationTag(chain string, tag uint32, ledger int64) error {
+	return s.putDestinationTag(&DestinationTag{Chain: chain, Tag: tag, Ledger: ledger})
+}
+
+func (s *CrossStorage) putDestinationTag(dt *DestinationTag) error {
+	if dt.Tag == 0 {
+		return ErrInvalidDestinationTag
+	}
+	if dt.Ledger <= 0 {
+		return ErrInvalidDestinationLedger
+	}
+	if dt.Chain == "" {
+		return errors.New("destination tag requires a chain")
+	}
+	dt.Chain = strings.ToUpper(dt.Chain)
+	dt.Timestamp = time.Now().Unix()
+	data, err := json.Marshal(dt)
+	if err != nil {
+		return fmt.Errorf("fail to marshal destination tag: %w", err)
+	}
+	return s.db.Put([]byte(s.createDestinationTagKey(dt.Chain, dt.Tag, dt.Ledger)), data, nil)
+}
+
+// GetDestinationTag returns the registration of a destination tag in effect at ledger, nil when
+// the tag had none. The memo of the registration is empty when the tag was retired
+func (s *CrossStorage) GetDestinationTag(chain string, tag uint32, ledger int64) (*DestinationTag, error) {
+	if ledger <= 0 {
+		return nil, ErrInvalidDestinationLedger
+	}
+	// the keys of a tag have the same length, the registration at ledger sorts below the limit
+	limit := append([]byte(s.createDestinationTagKey(chain, tag, ledger)), 0)
+	iter := s.db.NewIterator(&util.Range{Start: []byte(s.destinationTagPrefix(chain, tag)), Limit: limit}, nil)
+	defer iter.Release()
+	if !iter.Last() {
+		return nil, iter.Error()
+	}
+	ret := &DestinationTag{}
+	if err := json.Unmarshal(iter.Value(), ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ListDestinationTags returns the registrations of a chain ordered by tag and ledger
+func (s *CrossStorage) ListDestinationTags(chain string) ([]DestinationTag, error) {
+	iter := s.db.NewIterator(util.BytesPrefix([]byte(prefixOfDestinationTag+strings.ToUpper(chain)+":")), nil)
+	defer iter.Release()
+	ret := make([]DestinationTag, 0)
+	for iter.Next() {
+		var dt DestinationTag
+		if err := json.Unmarshal(iter.Value(), &dt); err != nil {
+			return nil, fmt.Errorf("fail to unmarshal destination tag %s: %w", iter.Key(), err)
+		}
+		ret = append(ret, dt)
+	}
+	return ret, iter.Error()
+}
+
+// DestinationTagsDigest hashes the routing of a list of registrations, the intent and the time
+// they were registered at are left out. Observers resolve deposits to the same memos when the
+// digests of their registrations match
+func DestinationTagsDigest(tags []DestinationTag) string {
+	h := sha256.New()
+	for _, dt := range tags {
+		_, _ = fmt.Fprintf(h, "%s:%d:%d:%s\n", strings.ToUpper(dt.Chain), dt.Tag, dt.Ledger, dt.Memo)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ResolveDestinationTag returns the memo a destination tag routes the deposits of a ledger by,
+// empty when the tag is unknown or retired at the ledger. A memo that cannot route a deposit
+// leaves the tag unknown
+func ResolveDestinationTag(registry DestinationTagRegistry, chain string, tag uint32, ledger int64) (string, error) {
+	if tag == 0 {
+		return "", ErrInvalidDestinationTag
+	}
+	dt, err := registry.GetDestinationTag(chain, tag, ledger)
+	if err != nil {
+		return "", fmt.Errorf("fail to get destination tag %d: %w", tag, err)
+	}
+	if dt == nil || len(dt.Memo) == 0 {
+		return "", nil
+	}
+	memo, err := mem.ParseMemo(dt.Memo)
+	if err != nil || !memo.IsValid() {
+		return "", nil
+	}
+	if !memo.GetType().Equals(mem.TxOutbound) && !memo.GetType().Equals(mem.TxAdd) {
+		return "", nil
+	}
+	return dt.Memo, nil
+}
@@ -0,0 +1,89 @@
+package cross_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/internal/cross"
+)
+
+func TestCrossStorage_DestinationTag(t *testing.T) {
+	s, err := cross.NewStorage(t.TempDir(), config.LevelDBOptions{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	dt, err := s.GetDestinationTag("XRP", 1024, 100)
+	require.NoError(t, err)
+	assert.Nil(t, dt)
+
+	const (
+		memo  = "Mx|Tron|USDT|TXcb8NicbbiT1sfSuNRZH19XggX1ph3Aoz|690943|bt0"
+		memo2 = "Mx|Tron|USDT|TXcb8NicbbiT1sfSuNRZH19XggX1ph3Aoz|690943|bt1"
+	)
+	require.NoError(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "Xrp", Tag: 1024, Memo: memo, Ledger: 100, Intent: "order-1"}))
+	require.NoError(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "XRP", Tag: 1024, Memo: memo2, Ledger: 200}))
+	require.NoError(t, s.RetireDestinationTag("XRP", 1024, 1000))
+	require.NoError(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "XRP", Tag: 7, Memo: memo, Ledger: 150}))
+	require.NoError(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "DOGE", Tag: 7, Memo: memo, Ledger: 1}))
+	assert.ErrorIs(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "XRP", Memo: memo, Ledger: 1}), cross.ErrInvalidDestinationTag)
+	assert.ErrorIs(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "XRP", Tag: 8, Memo: memo}), cross.ErrInvalidDestinationLedger)
+	assert.Error(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "XRP", Tag: 8, Ledger: 1}))
+
+	// a deposit is resolved with the registration in effect at its ledger
+	for ledger, expected := range map[int64]string{99: "", 100: memo, 199: memo, 200: memo2, 999: memo2, 1000: "", 5000: ""} {
+		got, err := cross.ResolveDestinationTag(s, "xrp", 1024, ledger)
+		require.NoError(t, err)
+		assert.Equal(t, expected, got, "ledger %d", ledger)
+	}
+	dt, err = s.GetDestinationTag("Xrp", 1024, 150)
+	require.NoError(t, err)
+	require.NotNil(t, dt)
+	assert.Equal(t, "XRP", dt.Chain)
+	assert.Equal(t, "order-1", dt.Intent)
+
+	tags, err := s.ListDestinationTags("xrp")
+	require.NoError(t, err)
+	require.Len(t, tags, 4)
+	assert.Equal(t, uint32(7), tags[0].Tag)
+	assert.Equal(t, []int64{100, 200, 1000}, []int64{tags[1].Ledger, tags[2].Ledger, tags[3].Ledger})
+
+	// the digest only covers the routing
+	digest := cross.DestinationTagsDigest(tags)
+	tags[1].Intent, tags[1].Timestamp = "", 0
+	assert.Equal(t, digest, cross.DestinationTagsDigest(tags))
+	tags[1].Ledger = 101
+	assert.NotEqual(t, digest, cross.DestinationTagsDigest(tags))
+
+	// registering again at a ledger replaces the registration
+	require.NoError(t, s.RetireDestinationTag("XRP", 1024, 200))
+	got, err := cross.ResolveDestinationTag(s, "XRP", 1024, 500)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	tags, err = s.ListDestinationTags("XRP")
+	require.NoError(t, err)
+	assert.Len(t, tags, 4)
+}
+
+func TestResolveDestinationTag(t *testing.T) {
+	s, err := cross.NewStorage(t.TempDir(), config.LevelDBOptions{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "XRP", Tag: 7, Memo: "not a memo", Ledger: 1}))
+	require.NoError(t, s.SetDestinationTag(&cross.DestinationTag{Chain: "XRP", Tag: 8, Memo: "M*|100|0|1", Ledger: 1}))
+
+	// unset tags and memos that cannot route a deposit leave the tag unknown
+	for _, tag := range []uint32{2048, 7, 8} {
+		got, err := cross.ResolveDestinationTag(s, "XRP", tag, 10)
+		require.NoError(t, err)
+		assert.Empty(t, got, "tag %d", tag)
+	}
+
+	_, err = cross.ResolveDestinationTag(s, "XRP", 0, 10)
+	assert.ErrorIs(t, err, cross.ErrInvalidDestinationTag)
+	_, err = cross.ResolveDestinationTag(s, "XRP", 7, 0)
+	assert.ErrorIs(t, err, cross.ErrInvalidDestinationLedger)
+}
@@ -0,0 +1,41 @@
+package cross_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/internal/cross"
+)
+
+func TestCrossStorage_HandlerCrossData_BumpDst(t *testing.T) {
+	s, err := cross.NewStorage(t.TempDir(), config.LevelDBOptions{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	const orderID = "0x015be4c33f51fbee02e13b93f5bc2089e2cde770810a5510225de4ce7a8375a1"
+	handle := func(data *cross.CrossData, _type string) {
+		data.OrderId = orderID
+		data.Chain = "1360095883558913"
+		require.NoError(t, s.HandlerCrossData(&cross.ChanStruct{CrossData: data, Type: _type}))
+	}
+
+	handle(&cross.CrossData{TxHash: "tx1"}, cross.TypeOfSendDst)
+	handle(&cross.CrossData{TxHash: "child1", FeeBump: cross.FeeBumpCPFP, Replaces: "tx1"}, cross.TypeOfBumpDst)
+	ret, err := s.GetCrossData(orderID)
+	require.NoError(t, err)
+	assert.Equal(t, "tx1", ret.Dest.TxHash)
+	require.Len(t, ret.DestBumps, 1)
+	assert.Equal(t, "child1", ret.DestBumps[0].TxHash)
+
+	// a replacement is tracked as the dest tx
+	handle(&cross.CrossData{TxHash: "tx2", FeeBump: cross.FeeBumpRBF, Replaces: "tx1"}, cross.TypeOfBumpDst)
+	ret, err = s.GetCrossData(orderID)
+	require.NoError(t, err)
+	assert.Equal(t, "tx2", ret.Dest.TxHash)
+	assert.Equal(t, cross.StatusOfSend, ret.Status)
+	require.Len(t, ret.DestBumps, 2)
+	assert.Equal(t, "tx1", ret.DestBumps[1].Replaces)
+}
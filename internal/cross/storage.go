@@ -1,12 +1,14 @@
 package cross
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mapprotocol/compass-tss/config"
@@ -28,6 +30,8 @@ type CrossStorage struct {
 	mu   sync.Mutex
 	ch   chan *ChanStruct
 	stop chan struct{}
+	// queued is the number of cross data added and not yet written
+	queued atomic.Int64
 }
 
 const (
@@ -71,6 +75,12 @@ const (
 	TypeOfSendDst          = "send_dst"
 	TypeOfDstChain         = "dst"
 	TypeOfMapDstChain      = "map_dst"
+	TypeOfBumpDst          = "bump_dst"
+)
+
+const (
+	FeeBumpRBF  = "rbf"
+	FeeBumpCPFP = "cpfp"
 )
 
 // CrossData
@@ -84,6 +94,12 @@ type CrossData struct {
 	ChainAndGasLimit string `json:"chain_and_gas_limit" example:"" `
 	Timestamp        int64  `json:"timestamp" example:"1767097427" `
 	IsMemoized       bool   `json:"is_memoized" example:"false" `
+	// FeeBump is how the tx bumped the fee of the dest tx Replaces, rbf replaces it and cpfp spends it
+	FeeBump  string `json:"fee_bump,omitempty" example:"rbf" `
+	Replaces string `json:"replaces,omitempty" example:"" `
+	// BatchId is the batch of the dest tx when it pays several orders, Vout is the output paying the order
+	BatchId string `json:"batch_id,omitempty" example:"" `
+	Vout    uint32 `json:"vout,omitempty" example:"0" `
 }
 
 type ChanStruct struct {
@@ -102,6 +118,8 @@ type CrossSet struct {
 	Status      StatusOfCross `json:"status"`
 	StatusStr   string        `json:"status_str"`
 	OrderId     string        `json:"order_id"`
+	// DestBumps are the fee bumps of the target chain transaction, oldest first
+	DestBumps []*CrossData `json:"dest_bumps,omitempty"`
 }
 
 // NewStorage create a new instance of LevelDBScannerStorage
@@ -135,11 +153,24 @@ func (s *CrossStorage) Start() {
 				if err != nil {
 					log.Error().Any("ele", ele).Err(err).Msg("fail to handle cross data")
 				}
+				s.queued.Add(-1)
 			}
 		}
 	}()
 }
 
+// Flush waits until the cross data added so far is written or the context is done
+func (s *CrossStorage) Flush(ctx context.Context) error {
+	for s.queued.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d cross data not written: %w", s.queued.Load(), ctx.Err())
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return nil
+}
+
 func (s *CrossStorage) Stop() {
 	log.Info().Msg("stop cross storage")
 	close(s.stop)
@@ -208,6 +239,7 @@ func TxOutConvertCross(txOut *types.TxOutItem) *CrossData {
 
 // AddOrUpdateTx adds or updates a single TxIn in storage
 func (s *CrossStorage) AddOrUpdateTx(insertData *CrossData, _type string) {
+	s.queued.Add(1)
 	s.ch <- &ChanStruct{
 		CrossData: insertData,
 		Type:      _type,
@@ -254,6 +286,12 @@ func (s *CrossStorage) HandlerCrossData(ele *ChanStruct) error {
 	case TypeOfDstChain:
 		ret.Dest = ele.CrossData
 		changeStatus = StatusOfCompleted
+	case TypeOfBumpDst:
+		ret.DestBumps = append(ret.DestBumps, ele.CrossData)
+		// a replacement is the dest tx from now on, a child leaves it in place
+		if ele.CrossData.FeeBump == FeeBumpRBF {
+			ret.Dest = ele.CrossData
+		}
 	case TypeOfMapDstChain:
 		if ret.Relay == nil {
 			ret.Relay = ele.CrossData
@@ -1,8 +1,11 @@
 package cross_test
 
 import (
+	"context"
 	"encoding/json"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/internal/cross"
@@ -21,7 +24,7 @@ func TestCrossStorage_HandlerCrossData(t *testing.T) {
 	}{
 		{
 			name: "success",
-			path: "./testdata/cross_storage_range_success",
+			path: "cross_storage_range_success",
 			opts: config.LevelDBOptions{
 				BlockCacheCapacity:            1 << 20,
 				CompactOnInit:                 true,
@@ -70,7 +73,7 @@ func TestCrossStorage_HandlerCrossData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s, err := cross.NewStorage(tt.path, tt.opts)
+			s, err := cross.NewStorage(filepath.Join(t.TempDir(), tt.path), tt.opts)
 			if err != nil {
 				t.Fatalf("could not construct receiver type: %v", err)
 			}
@@ -102,7 +105,7 @@ func TestCrossStorage_GetCrossData(t *testing.T) {
 	}{
 		{
 			name: "success",
-			path: "./testdata/cross_storage_range_success",
+			path: "cross_storage_range_success",
 			opts: config.LevelDBOptions{
 				BlockCacheCapacity:            1 << 20,
 				CompactOnInit:                 true,
@@ -115,7 +118,7 @@ func TestCrossStorage_GetCrossData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s, err := cross.NewStorage(tt.path, tt.opts)
+			s, err := cross.NewStorage(filepath.Join(t.TempDir(), tt.path), tt.opts)
 			if err != nil {
 				t.Fatalf("could not construct receiver type: %v", err)
 			}
@@ -151,7 +154,7 @@ func TestCrossStorage_GetChainHeight(t *testing.T) {
 	}{
 		{
 			name: "success",
-			path: "./testdata/cross_storage_range_success",
+			path: "cross_storage_range_success",
 			opts: config.LevelDBOptions{
 				BlockCacheCapacity:            1 << 20,
 				CompactOnInit:                 true,
@@ -165,7 +168,7 @@ func TestCrossStorage_GetChainHeight(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			s, err := cross.NewStorage(tt.path, tt.opts)
+			s, err := cross.NewStorage(filepath.Join(t.TempDir(), tt.path), tt.opts)
 			if err != nil {
 				t.Fatalf("could not construct receiver type: %v", err)
 			}
@@ -186,3 +189,34 @@ func TestCrossStorage_GetChainHeight(t *testing.T) {
 		})
 	}
 }
+
+func TestCrossStorage_Flush(t *testing.T) {
+	s, err := cross.NewStorage(t.TempDir(), config.LevelDBOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// nothing is written before the storage is started
+	s.AddOrUpdateTx(&cross.CrossData{OrderId: "0x01", Chain: "56", TxHash: "0xaa", Height: 1}, cross.TypeOfSrcChain)
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err = s.Flush(ctx); err == nil {
+		t.Fatal("expected the flush to time out")
+	}
+
+	s.Start()
+	defer s.Stop()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err = s.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	set, err := s.GetCrossData("0x01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set.Src == nil || set.Src.TxHash != "0xaa" {
+		t.Fatalf("unexpected cross data: %+v", set)
+	}
+}
@@ -0,0 +1,249 @@
+package e2e
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	ecrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients"
+	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
+	"github.com/mapprotocol/compass-tss/tss"
+	gotss "github.com/mapprotocol/compass-tss/tss/go-tss/tss"
+)
+
+// chainPollInterval is how often the chain clients look for new blocks
+const chainPollInterval = 100 * time.Millisecond
+
+// Chain is an in-memory chain shared by the nodes of a harness, deposits and the
+// outbounds broadcast by the nodes each land in a block of their own
+type Chain struct {
+	chain  common.Chain
+	lock   sync.Mutex
+	blocks [][]types.TxInItem
+	sent   map[ecommon.Hash]string
+}
+
+// NewChain create a new instance of Chain
+func NewChain(chain common.Chain) *Chain {
+	return &Chain{
+		chain: chain,
+		sent:  make(map[ecommon.Hash]string),
+	}
+}
+
+// Height returns the height of the last block
+func (c *Chain) Height() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return int64(len(c.blocks))
+}
+
+// Deposit adds a block with the deposit into the vault, it returns the tx hash
+func (c *Chain) Deposit(item types.TxInItem) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	item.Method = constants.VoteTxIn
+	item.Height = big.NewInt(int64(len(c.blocks)) + 1)
+	if item.Tx == "" {
+		item.Tx = ecrypto.Keccak256Hash([]byte(c.chain), item.OrderId.Bytes(), item.Height.Bytes()).Hex()
+	}
+	c.blocks = append(c.blocks, []types.TxInItem{item})
+	return item.Tx
+}
+
+// Outbound returns the hash of the tx that paid the order
+func (c *Chain) Outbound(orderId ecommon.Hash) (string, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	hash, ok := c.sent[orderId]
+	return hash, ok
+}
+
+// execute adds a block with the outbound of the order, the order is paid once only
+func (c *Chain) execute(tx types.TxOutItem) string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if hash, ok := c.sent[tx.OrderId]; ok {
+		return hash
+	}
+	chainID, _ := c.chain.ChainID()
+	height := big.NewInt(int64(len(c.blocks)) + 1)
+	hash := ecrypto.Keccak256Hash([]byte(c.chain), tx.OrderId.Bytes(), height.Bytes()).Hex()
+	c.sent[tx.OrderId] = hash
+	c.blocks = append(c.blocks, []types.TxInItem{{
+		Tx:        hash,
+		FromChain: chainID,
+		ToChain:   tx.ToChain,
+		Height:    height,
+		Amount:    tx.Amount,
+		OrderId:   tx.OrderId,
+		Token:     tx.Token,
+		Vault:     tx.Vault,
+		To:        tx.To,
+		Method:    constants.VoteTxOut,
+	}})
+	return hash
+}
+
+func (c *Chain) block(height int64) []types.TxInItem {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]types.TxInItem{}, c.blocks[height-1]...)
+}
+
+// outboundHash is the message the vault signs for an outbound
+func outboundHash(tx types.TxOutItem) []byte {
+	var toChain, amount []byte
+	if tx.ToChain != nil {
+		toChain = tx.ToChain.Bytes()
+	}
+	if tx.Amount != nil {
+		amount = tx.Amount.Bytes()
+	}
+	return ecrypto.Keccak256(tx.OrderId.Bytes(), toChain, tx.To, tx.Token, amount)
+}
+
+// chainClient is the chains.ChainClient of a single node backed by a shared chain, the
+// outbounds are signed by the vault through tss
+type chainClient struct {
+	chain    *Chain
+	keySign  *tss.KeySign
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	scanned  int64
+}
+
+var _ chainclients.ChainClient = &chainClient{}
+
+func newChainClient(chain *Chain, server *gotss.TssServer, bridge shareTypes.Bridge) (*chainClient, error) {
+	keySign, err := tss.NewKeySign(server, bridge)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create tss signer: %w", err)
+	}
+	return &chainClient{
+		chain:    chain,
+		keySign:  keySign,
+		stopChan: make(chan struct{}),
+	}, nil
+}
+
+func (c *chainClient) Start(globalTxsQueue chan types.TxIn, _ chan types.ErrataBlock, _ chan types.Solvency,
+	_ chan types.NetworkFee) {
+	c.keySign.Start()
+	c.wg.Add(1)
+	go c.scan(globalTxsQueue)
+}
+
+// scan sends the txs of every new block to the observer
+func (c *chainClient) scan(globalTxsQueue chan types.TxIn) {
+	defer c.wg.Done()
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case <-time.After(chainPollInterval):
+		}
+		for c.scanned < c.chain.Height() {
+			items := c.chain.block(c.scanned + 1)
+			txIn := types.TxIn{
+				Count: fmt.Sprintf("%d", len(items)),
+				Chain: c.chain.chain,
+			}
+			for i := range items {
+				txIn.TxArray = append(txIn.TxArray, &items[i])
+			}
+			select {
+			case <-c.stopChan:
+				return
+			case globalTxsQueue <- txIn:
+			}
+			c.scanned++
+		}
+	}
+}
+
+func (c *chainClient) Stop() {
+	close(c.stopChan)
+	c.wg.Wait()
+	c.keySign.Stop()
+}
+
+func (c *chainClient) IsBlockScannerHealthy() bool {
+	return true
+}
+
+// SignTx signs the outbound with the vault, nodes outside the signing party get no
+// signature back
+func (c *chainClient) SignTx(tx types.TxOutItem, _ int64) ([]byte, []byte, *types.TxInItem, error) {
+	sig, recovery, err := c.keySign.RemoteSign(outboundHash(tx), tx.VaultPubKey.String())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if sig == nil {
+		return nil, nil, nil, nil
+	}
+	return append(sig, recovery...), nil, nil, nil
+}
+
+// BroadcastTx checks the outbound was signed by the vault before paying the order
+func (c *chainClient) BroadcastTx(tx types.TxOutItem, signed []byte) (string, error) {
+	if len(signed) < 64 {
+		return "", errors.New("signature is too short")
+	}
+	pubKey := ecommon.Hex2Bytes(tx.VaultPubKey.String())
+	if !ecrypto.VerifySignature(pubKey, outboundHash(tx), signed[:64]) {
+		return "", errors.New("signature is not from the vault")
+	}
+	return c.chain.execute(tx), nil
+}
+
+func (c *chainClient) GetHeight() (int64, error) {
+	return c.chain.Height(), nil
+}
+
+func (c *chainClient) GetAddress(common.PubKey) string {
+	return ""
+}
+
+func (c *chainClient) GetAccount(common.PubKey, *big.Int) (common.Account, error) {
+	return common.Account{}, nil
+}
+
+func (c *chainClient) GetAccountByAddress(string, *big.Int) (common.Account, error) {
+	return common.Account{}, nil
+}
+
+func (c *chainClient) GetChain() common.Chain {
+	return c.chain.chain
+}
+
+func (c *chainClient) GetConfig() config.BifrostChainConfiguration {
+	return config.BifrostChainConfiguration{ChainID: c.chain.chain}
+}
+
+func (c *chainClient) OnObservedTxIn(types.TxInItem, int64) {}
+
+func (c *chainClient) GetConfirmationCount(types.TxIn) int64 {
+	return 0
+}
+
+func (c *chainClient) ConfirmationCountReady(types.TxIn) bool {
+	return true
+}
+
+func (c *chainClient) GetBlockScannerHeight() (int64, error) {
+	return c.chain.Height(), nil
+}
+
+func (c *chainClient) GetLatestTxForVault(string) (string, string, error) {
+	return "", "", nil
+}
@@ -0,0 +1,174 @@
+package e2e
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	ecrypto "github.com/ethereum/go-ethereum/crypto"
+	. "gopkg.in/check.v1"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/internal/cross"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/tss"
+	"github.com/mapprotocol/compass-tss/tss/tsstest"
+)
+
+func TestE2E(t *testing.T) { TestingT(t) }
+
+// waitFor polls the condition until it holds or the timeout is reached
+func waitFor(c *C, timeout time.Duration, what string, cond func() bool) {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			c.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
+
+type CrossChainSuite struct {
+	harness *Harness
+	vault   common.PubKey
+}
+
+var _ = Suite(&CrossChainSuite{})
+
+func (s *CrossChainSuite) SetUpSuite(c *C) {
+	var err error
+	s.harness, err = NewHarness(Config{
+		Home:      c.MkDir(),
+		PreParams: tsstest.PreParams(c),
+	})
+	c.Assert(err, IsNil)
+
+	s.harness.Relay.StartKeygen(1, s.harness.Members())
+	waitFor(c, 2*time.Minute, "the vault of the keygen", func() bool {
+		_, ok := s.harness.Relay.Vault()
+		return ok
+	})
+	s.vault, _ = s.harness.Relay.Vault()
+}
+
+func (s *CrossChainSuite) TearDownSuite(c *C) {
+	if s.harness != nil {
+		s.harness.Stop()
+	}
+}
+
+func (s *CrossChainSuite) TestKeygen(c *C) {
+	results := s.harness.Relay.KeygenResults(1)
+	c.Assert(results, HasLen, len(s.harness.Nodes))
+	ethPubKey, err := ecrypto.DecompressPubkey(ecommon.Hex2Bytes(s.vault.String()))
+	c.Assert(err, IsNil)
+	hash := ecrypto.Keccak256(ecrypto.FromECDSAPub(ethPubKey)[1:])
+	for _, result := range results {
+		c.Check(result.PubKey, Equals, s.vault)
+		c.Check(result.Blames, HasLen, 0)
+		// every member proves the vault signs before it is accepted
+		c.Assert(result.Signature, HasLen, 65)
+		c.Check(ecrypto.VerifySignature(ecommon.Hex2Bytes(s.vault.String()), hash, result.Signature[:64]), Equals, true)
+	}
+}
+
+func (s *CrossChainSuite) TestDepositCompletes(c *C) {
+	ethPubKey, err := ecrypto.DecompressPubkey(ecommon.Hex2Bytes(s.vault.String()))
+	c.Assert(err, IsNil)
+	bscID, err := common.BSCChain.ChainID()
+	c.Assert(err, IsNil)
+	orderId := ecrypto.Keccak256Hash([]byte("e2e deposit"))
+
+	s.harness.Chains[common.ETHChain].Deposit(types.TxInItem{
+		OrderId:          orderId,
+		ToChain:          bscID,
+		Amount:           big.NewInt(1e18),
+		Token:            ecommon.HexToAddress("0x01").Bytes(),
+		From:             ecommon.HexToAddress("0x02").Bytes(),
+		To:               ecommon.HexToAddress("0x03").Bytes(),
+		Vault:            ecrypto.FromECDSAPub(ethPubKey)[1:],
+		ChainAndGasLimit: big.NewInt(0),
+	})
+
+	waitFor(c, 2*time.Minute, "the order to complete on every node", func() bool {
+		for _, node := range s.harness.Nodes {
+			status, err := node.CrossStatus(orderId)
+			if err != nil || status != cross.StatusOfCompleted {
+				return false
+			}
+		}
+		return true
+	})
+	_, ok := s.harness.Chains[common.BSCChain].Outbound(orderId)
+	c.Check(ok, Equals, true)
+	waitFor(c, time.Minute, "the relay to see the order executed", func() bool {
+		return s.harness.Relay.OrderExecuted(orderId)
+	})
+}
+
+type DropoutSuite struct {
+	harness *Harness
+}
+
+var _ = Suite(&DropoutSuite{})
+
+func (s *DropoutSuite) SetUpSuite(c *C) {
+	var err error
+	s.harness, err = NewHarness(Config{
+		Home:      c.MkDir(),
+		PreParams: tsstest.PreParams(c),
+	})
+	c.Assert(err, IsNil)
+}
+
+func (s *DropoutSuite) TearDownSuite(c *C) {
+	if s.harness != nil {
+		s.harness.Stop()
+	}
+}
+
+func (s *DropoutSuite) TestKeygenBlamesMissingMaintainer(c *C) {
+	dropped := s.harness.Nodes[len(s.harness.Nodes)-1]
+	c.Assert(s.harness.StopNode(dropped.Index), IsNil)
+
+	s.harness.Relay.StartKeygen(1, s.harness.Members())
+	waitFor(c, 2*time.Minute, "the keygen results of the remaining nodes", func() bool {
+		return len(s.harness.Relay.KeygenResults(1)) == len(s.harness.Nodes)-1
+	})
+
+	_, ok := s.harness.Relay.Vault()
+	c.Check(ok, Equals, false)
+	// when the party fails to form with a leader go-tss blames the leader as well, so the
+	// missing maintainer is not necessarily the only one blamed
+	for _, result := range s.harness.Relay.KeygenResults(1) {
+		c.Check(result.PubKey.IsEmpty(), Equals, true)
+		c.Check(containsAddress(result.Blames, dropped.Address), Equals, true)
+	}
+	for _, node := range s.harness.Nodes[:dropped.Index] {
+		records, err := node.Blames()
+		c.Assert(err, IsNil)
+		c.Assert(records, HasLen, 1)
+		c.Check(records[0].Type, Equals, tss.BlameKeygen)
+		c.Check(records[0].Epoch, Equals, int64(1))
+		c.Check(containsString(records[0].Culprits, dropped.PubKey.String()), Equals, true)
+	}
+}
+
+func containsAddress(addrs []ecommon.Address, addr ecommon.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+// Package e2e runs several compass nodes in one process against an in-memory relay chain
+// and in-memory chains, the nodes talk tss to each other over a real p2p network.
+package e2e
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	maddr "github.com/multiformats/go-multiaddr"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/metrics"
+)
+
+// Config is the configuration of a Harness
+type Config struct {
+	// Home is the folder the nodes keep their data in
+	Home string
+
+	// PreParams are the tss pre parameters of the nodes, one per node. A node keeps using
+	// its pre parameters for every keygen.
+	PreParams []*bkeygen.LocalPreParams
+
+	// Chains are the chains the nodes observe and send outbounds to
+	Chains []common.Chain
+
+	KeygenTimeout  time.Duration
+	KeysignTimeout time.Duration
+	PartyTimeout   time.Duration
+}
+
+// Harness is a network of compass nodes sharing a relay and the chains
+type Harness struct {
+	cfg     Config
+	metrics *metrics.Metrics
+
+	Relay  *Relay
+	Chains map[common.Chain]*Chain
+	Nodes  []*Node
+}
+
+var (
+	sharedMetrics    *metrics.Metrics
+	sharedMetricsErr error
+	sharedMetricsMu  sync.Once
+)
+
+// harnessMetrics returns the metrics of the process, they can only be registered once so
+// every harness and node shares them
+func harnessMetrics() (*metrics.Metrics, error) {
+	sharedMetricsMu.Do(func() {
+		sharedMetrics, sharedMetricsErr = metrics.NewMetrics(config.BifrostMetricsConfiguration{
+			Chains: common.AllChains,
+		})
+	})
+	return sharedMetrics, sharedMetricsErr
+}
+
+// NewHarness starts a node for each of the pre parameters, the first node is the bootstrap
+// peer of the others
+func NewHarness(cfg Config) (*Harness, error) {
+	if len(cfg.PreParams) == 0 {
+		return nil, errors.New("no pre parameters for the nodes")
+	}
+	if len(cfg.Chains) == 0 {
+		cfg.Chains = common.Chains{common.ETHChain, common.BSCChain}
+	}
+	if cfg.KeygenTimeout == 0 {
+		cfg.KeygenTimeout = time.Minute
+	}
+	if cfg.KeysignTimeout == 0 {
+		cfg.KeysignTimeout = time.Minute
+	}
+	if cfg.PartyTimeout == 0 {
+		cfg.PartyTimeout = 10 * time.Second
+	}
+	m, err := harnessMetrics()
+	if err != nil {
+		return nil, fmt.Errorf("fail to create metrics: %w", err)
+	}
+	h := &Harness{
+		cfg:     cfg,
+		metrics: m,
+		Relay:   NewRelay(),
+		Chains:  make(map[common.Chain]*Chain, len(cfg.Chains)),
+	}
+	for _, chain := range cfg.Chains {
+		h.Chains[chain] = NewChain(chain)
+	}
+
+	// every maintainer is registered on the relay before the signers look themselves up
+	var bootstrap []maddr.Multiaddr
+	for i, preParams := range cfg.PreParams {
+		node, k, err := newNode(i, h.Relay)
+		if err != nil {
+			h.Stop()
+			return nil, err
+		}
+		if err = node.start(h, k, preParams, bootstrap); err != nil {
+			h.Stop()
+			return nil, fmt.Errorf("fail to start node %d: %w", i, err)
+		}
+		h.Nodes = append(h.Nodes, node)
+		if i == 0 {
+			addr, err := node.multiaddr()
+			if err != nil {
+				h.Stop()
+				return nil, err
+			}
+			bootstrap = []maddr.Multiaddr{addr}
+		}
+	}
+	return h, nil
+}
+
+// Members returns the addresses of the nodes
+func (h *Harness) Members() []ecommon.Address {
+	members := make([]ecommon.Address, 0, len(h.Nodes))
+	for _, node := range h.Nodes {
+		members = append(members, node.Address)
+	}
+	return members
+}
+
+// StopNode stops the node, the other nodes keep running
+func (h *Harness) StopNode(index int) error {
+	if index < 0 || index >= len(h.Nodes) {
+		return fmt.Errorf("no node %d", index)
+	}
+	return h.Nodes[index].Stop()
+}
+
+// Stop stops every node
+func (h *Harness) Stop() {
+	for _, node := range h.Nodes {
+		_ = node.Stop()
+	}
+}
@@ -0,0 +1,222 @@
+package e2e
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	ckeys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	ekeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	ecrypto "github.com/ethereum/go-ethereum/crypto"
+	maddr "github.com/multiformats/go-multiaddr"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/internal/cross"
+	"github.com/mapprotocol/compass-tss/internal/keys"
+	"github.com/mapprotocol/compass-tss/metrics"
+	"github.com/mapprotocol/compass-tss/observer"
+	"github.com/mapprotocol/compass-tss/p2p"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients"
+	"github.com/mapprotocol/compass-tss/pubkeymanager"
+	"github.com/mapprotocol/compass-tss/signer"
+	"github.com/mapprotocol/compass-tss/tss"
+	gocommon "github.com/mapprotocol/compass-tss/tss/go-tss/common"
+	gotss "github.com/mapprotocol/compass-tss/tss/go-tss/tss"
+)
+
+// Node is a compass instance of the harness, it runs the tss server, the observer and the
+// signer of one maintainer against the shared relay and chains
+type Node struct {
+	Index   int
+	Address ecommon.Address
+	// PubKey is the compressed secp256k1 key the node takes part in tss with
+	PubKey common.PubKey
+
+	port         int
+	comm         *p2p.Communication
+	tssServer    *gotss.TssServer
+	crossStorage *cross.CrossStorage
+	observer     *observer.Observer
+	signer       *signer.Signer
+	stopped      bool
+}
+
+// newNode creates the keys of a maintainer and registers it on the relay, the services
+// are created by start
+func newNode(index int, relay *Relay) (*Node, *keys.Keys, error) {
+	key, err := ecrypto.GenerateKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to generate key: %w", err)
+	}
+	k, err := newKeys(fmt.Sprintf("node-%d", index), key)
+	if err != nil {
+		return nil, nil, err
+	}
+	addr, err := k.GetEthAddress()
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to get address: %w", err)
+	}
+	relay.Register(addr, ecrypto.FromECDSAPub(&key.PublicKey)[1:])
+	return &Node{
+		Index:   index,
+		Address: addr,
+		PubKey:  common.PubKey(ecommon.Bytes2Hex(ecrypto.CompressPubkey(&key.PublicKey))),
+	}, k, nil
+}
+
+// newKeys imports the key into an in-memory keyring the way the keystore of a node is
+func newKeys(name string, key *ecdsa.PrivateKey) (*keys.Keys, error) {
+	registry := codectypes.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(registry)
+	kb := ckeys.NewInMemory(codec.NewProtoCodec(registry))
+	err := kb.ImportPrivKeyHex(name, ecommon.Bytes2Hex(ecrypto.FromECDSA(key)), string(hd.Secp256k1.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("fail to import key: %w", err)
+	}
+	return keys.NewKeysWithKeybase(kb, name, "", &ekeystore.Key{
+		Address:    ecrypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}), nil
+}
+
+// start brings up the p2p network, tss, observer and signer of the node
+func (n *Node) start(h *Harness, k *keys.Keys, preParams *bkeygen.LocalPreParams, bootstrap []maddr.Multiaddr) error {
+	home := filepath.Join(h.cfg.Home, fmt.Sprintf("node-%d", n.Index))
+	priKey, err := k.GetPrivateKey()
+	if err != nil {
+		return fmt.Errorf("fail to get private key: %w", err)
+	}
+	n.port, err = freePort()
+	if err != nil {
+		return err
+	}
+	comm, stateManager, err := p2p.StartP2P(&p2p.Config{
+		RendezvousString: "compass-e2e",
+		Port:             n.port,
+		BootstrapPeers:   bootstrap,
+		ExternalIP:       "127.0.0.1",
+	}, common.CosmosPrivateKeyToTMPrivateKey(priKey), filepath.Join(home, "tss"))
+	if err != nil {
+		return fmt.Errorf("fail to start p2p: %w", err)
+	}
+	n.comm = comm
+	n.tssServer, err = gotss.NewTss(n.comm, stateManager, common.CosmosPrivateKeyToTMPrivateKey(priKey), gocommon.TssConfig{
+		KeyGenTimeout:   h.cfg.KeygenTimeout,
+		KeySignTimeout:  h.cfg.KeysignTimeout,
+		PartyTimeout:    h.cfg.PartyTimeout,
+		PreParamTimeout: time.Minute,
+	}, preParams)
+	if err != nil {
+		return fmt.Errorf("fail to create tss instance: %w", err)
+	}
+	if err = n.tssServer.Start(); err != nil {
+		return fmt.Errorf("fail to start tss instance: %w", err)
+	}
+
+	bridge := h.Relay.Bridge(n.Address)
+	pubkeyMgr, err := pubkeymanager.NewPubKeyManager(bridge, h.metrics)
+	if err != nil {
+		return fmt.Errorf("fail to create pubkey manager: %w", err)
+	}
+	if err = pubkeyMgr.Start(); err != nil {
+		return fmt.Errorf("fail to start pubkey manager: %w", err)
+	}
+	chains := make(map[common.Chain]chainclients.ChainClient, len(h.Chains))
+	for name, chain := range h.Chains {
+		chains[name], err = newChainClient(chain, n.tssServer, bridge)
+		if err != nil {
+			return err
+		}
+	}
+
+	n.crossStorage, err = cross.NewStorage(filepath.Join(home, "cross"), config.LevelDBOptions{})
+	if err != nil {
+		return fmt.Errorf("fail to create cross storage: %w", err)
+	}
+	n.crossStorage.Start()
+
+	tssKeysignMetricMgr := metrics.NewTssKeysignMetricMgr()
+	n.observer, err = observer.NewObserver(pubkeyMgr, chains, bridge, h.metrics, filepath.Join(home, "observer"),
+		tssKeysignMetricMgr, n.crossStorage)
+	if err != nil {
+		return fmt.Errorf("fail to create observer: %w", err)
+	}
+	if err = n.observer.Start(context.Background()); err != nil {
+		return fmt.Errorf("fail to start observer: %w", err)
+	}
+
+	cfg := config.Bifrost{}
+	cfg.Signer.SignerDbPath = filepath.Join(home, "signer")
+	cfg.Signer.OracleDbPath = filepath.Join(home, "oracle")
+	cfg.Signer.BlockScanner = config.BifrostBlockScannerConfiguration{
+		StartBlockHeight:           relayStartHeight,
+		BlockHeightDiscoverBackoff: chainPollInterval,
+		BlockRetryInterval:         chainPollInterval,
+	}
+	cfg.Signer.Oracle.MaxAttempts = 10
+	cfg.Signer.Oracle.RetryBackoff = time.Second
+	cfg.Signer.Oracle.MaxRetryBackoff = 5 * time.Second
+	cfg.Signer.BlameRetention = time.Hour
+	n.signer, err = signer.NewSigner(cfg, bridge, k, pubkeyMgr, n.tssServer, chains, h.metrics, tssKeysignMetricMgr,
+		n.observer, n.crossStorage)
+	if err != nil {
+		return fmt.Errorf("fail to create signer: %w", err)
+	}
+	return n.signer.Start()
+}
+
+// multiaddr is the address the other nodes bootstrap their p2p network from
+func (n *Node) multiaddr() (maddr.Multiaddr, error) {
+	return maddr.NewMultiaddr(fmt.Sprintf("/ip4/127.0.0.1/tcp/%d/p2p/%s", n.port, n.comm.GetLocalPeerID()))
+}
+
+// CrossStatus returns the status of the order in the cross storage of the node
+func (n *Node) CrossStatus(orderId ecommon.Hash) (cross.StatusOfCross, error) {
+	set, err := n.crossStorage.GetCrossData(orderId.String())
+	if err != nil {
+		return cross.StatusOfInit, err
+	}
+	return set.Status, nil
+}
+
+// Blames returns the blame records in the ledger of the node
+func (n *Node) Blames() ([]tss.BlameRecord, error) {
+	return n.signer.BlameLedger().List(time.Time{}, "")
+}
+
+// Stop stops the services of the node, stopping a node twice is a no-op
+func (n *Node) Stop() error {
+	if n.stopped {
+		return nil
+	}
+	n.stopped = true
+	if err := n.observer.Stop(); err != nil {
+		return fmt.Errorf("fail to stop observer: %w", err)
+	}
+	if err := n.signer.Stop(); err != nil {
+		return fmt.Errorf("fail to stop signer: %w", err)
+	}
+	n.tssServer.Stop()
+	n.crossStorage.Stop()
+	return n.crossStorage.Close()
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("fail to find a free port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
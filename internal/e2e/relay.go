@@ -0,0 +1,646 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	ecrypto "github.com/ethereum/go-ethereum/crypto"
+
+	btypes "github.com/mapprotocol/compass-tss/blockscanner/types"
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/internal/ctx"
+	"github.com/mapprotocol/compass-tss/internal/structure"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/p2p/messages"
+	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
+	gotss "github.com/mapprotocol/compass-tss/tss/go-tss/tss"
+	stypes "github.com/mapprotocol/compass-tss/x/types"
+)
+
+// relayStartHeight is the height the relay starts at, the keysign parties elect their
+// leader from the height rounded down to 20 blocks so it must not be zero
+const relayStartHeight = 100
+
+const (
+	relayTxObservation = "observation"
+	relayTxOracle      = "oracle"
+)
+
+// relayTx is the transaction a node sends to the relay, its hash is the keccak of the
+// json encoding
+type relayTx struct {
+	Kind   string           `json:"kind"`
+	Signer ecommon.Address  `json:"signer"`
+	TxIn   *types.TxIn      `json:"tx_in,omitempty"`
+	TxOut  *types.TxOutItem `json:"tx_out,omitempty"`
+}
+
+// KeygenResult is what a member of a keygen reported to the relay
+type KeygenResult struct {
+	Member    ecommon.Address
+	PubKey    common.PubKey
+	Signature []byte
+	Blames    []ecommon.Address
+}
+
+type keygenRound struct {
+	epoch     *big.Int
+	members   []ecommon.Address
+	delivered map[ecommon.Address]bool
+	results   []KeygenResult
+}
+
+type vault struct {
+	epoch   *big.Int
+	pubKey  []byte // uncompressed without the 0x04 prefix, as stored by the relay contracts
+	members []ecommon.Address
+}
+
+// Relay is an in-memory relay chain shared by the nodes of a harness. Its height only
+// moves when an event is emitted, the observations and oracle submissions of the nodes
+// are tallied and emit the relay events once two thirds of the maintainers agree.
+type Relay struct {
+	lock        sync.Mutex
+	height      int64
+	blocks      map[int64][]types.TxArrayItem
+	maintainers []structure.MaintainerInfo
+	votes       map[string]map[ecommon.Address]bool
+	emitted     map[string]bool
+	txs         map[string]bool
+	executed    map[ecommon.Hash]bool
+	keygen      *keygenRound
+	keygens     map[int64]*keygenRound
+	vaults      []vault
+}
+
+// NewRelay create a new instance of Relay
+func NewRelay() *Relay {
+	return &Relay{
+		height:   relayStartHeight,
+		blocks:   make(map[int64][]types.TxArrayItem),
+		votes:    make(map[string]map[ecommon.Address]bool),
+		emitted:  make(map[string]bool),
+		txs:      make(map[string]bool),
+		executed: make(map[ecommon.Hash]bool),
+		keygens:  make(map[int64]*keygenRound),
+	}
+}
+
+// Register adds a maintainer, the pubkey is the uncompressed secp256k1 key without prefix
+func (r *Relay) Register(addr ecommon.Address, pubKey []byte) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.maintainers = append(r.maintainers, structure.MaintainerInfo{
+		Status:        uint8(constants.NodeStatus_Active),
+		Account:       addr,
+		Secp256Pubkey: pubKey,
+	})
+}
+
+// Bridge returns the view of the relay of the given maintainer
+func (r *Relay) Bridge(self ecommon.Address) shareTypes.Bridge {
+	return &bridge{relay: r, self: self}
+}
+
+// Height returns the current height of the relay
+func (r *Relay) Height() int64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.height
+}
+
+// StartKeygen elects the members for the epoch, each of them picks the keygen up once
+func (r *Relay) StartKeygen(epoch int64, members []ecommon.Address) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	round := &keygenRound{
+		epoch:     big.NewInt(epoch),
+		members:   members,
+		delivered: make(map[ecommon.Address]bool),
+	}
+	r.keygen = round
+	r.keygens[epoch] = round
+	r.mint(nil)
+}
+
+// KeygenResults returns what the members reported for the keygen of the epoch
+func (r *Relay) KeygenResults(epoch int64) []KeygenResult {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	round, ok := r.keygens[epoch]
+	if !ok {
+		return nil
+	}
+	return append([]KeygenResult{}, round.results...)
+}
+
+// Vault returns the compressed pubkey of the active vault
+func (r *Relay) Vault() (common.PubKey, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.vaults) == 0 {
+		return common.EmptyPubKey, false
+	}
+	pk, err := common.CompressPubKey(r.vaults[len(r.vaults)-1].pubKey)
+	if err != nil {
+		return common.EmptyPubKey, false
+	}
+	return common.PubKey(pk), true
+}
+
+// OrderExecuted returns true once the outbound of the order was observed by a quorum
+func (r *Relay) OrderExecuted(orderId ecommon.Hash) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.executed[orderId]
+}
+
+// mint emits a new block with the given events, the caller holds the lock
+func (r *Relay) mint(items []types.TxArrayItem) {
+	r.height++
+	r.blocks[r.height] = items
+}
+
+// quorum returns the number of votes needed, the caller holds the lock
+func (r *Relay) quorum() int {
+	n := len(r.maintainers)
+	if len(r.vaults) > 0 {
+		n = len(r.vaults[len(r.vaults)-1].members)
+	}
+	return n*2/3 + 1
+}
+
+// vote records the vote of the signer and returns true when the key just reached the
+// quorum, the caller holds the lock
+func (r *Relay) vote(key string, signer ecommon.Address) bool {
+	if r.emitted[key] {
+		return false
+	}
+	voters, ok := r.votes[key]
+	if !ok {
+		voters = make(map[ecommon.Address]bool)
+		r.votes[key] = voters
+	}
+	voters[signer] = true
+	if len(voters) < r.quorum() {
+		return false
+	}
+	r.emitted[key] = true
+	delete(r.votes, key)
+	return true
+}
+
+func (r *Relay) broadcast(data []byte) (string, error) {
+	var tx relayTx
+	if err := json.Unmarshal(data, &tx); err != nil {
+		return "", fmt.Errorf("fail to unmarshal relay tx: %w", err)
+	}
+	hash := ecrypto.Keccak256Hash(data).Hex()
+	mapChainID, _ := common.MAPChain.ChainID()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.txs[hash] = true
+
+	var events []types.TxArrayItem
+	switch tx.Kind {
+	case relayTxObservation:
+		if tx.TxIn == nil {
+			return "", errors.New("observation without tx in")
+		}
+		for _, item := range tx.TxIn.TxArray {
+			if !r.vote(tx.TxIn.Method+item.OrderId.Hex(), tx.Signer) {
+				continue
+			}
+			switch tx.TxIn.Method {
+			case constants.VoteTxIn:
+				if len(r.vaults) == 0 {
+					continue
+				}
+				events = append(events, types.TxArrayItem{
+					Chain:            mapChainID,
+					LogIndex:         item.LogIndex,
+					TxHash:           hash,
+					Topics:           constants.EventOfBridgeRelay.GetTopic().Hex(),
+					Method:           constants.RelaySigned,
+					FromChain:        item.FromChain,
+					ToChain:          item.ToChain,
+					OrderId:          item.OrderId,
+					ChainAndGasLimit: item.ChainAndGasLimit,
+					Vault:            r.vaults[len(r.vaults)-1].pubKey,
+					To:               item.To,
+					Token:            item.Token,
+					Amount:           item.Amount,
+					Sequence:         item.Sequence,
+					From:             item.From,
+				})
+			case constants.VoteTxOut:
+				r.executed[item.OrderId] = true
+			}
+		}
+	case relayTxOracle:
+		if tx.TxOut == nil {
+			return "", errors.New("oracle without tx out")
+		}
+		item := tx.TxOut
+		if r.vote(relayTxOracle+item.OrderId.Hex(), tx.Signer) {
+			events = append(events, types.TxArrayItem{
+				Chain:            mapChainID,
+				LogIndex:         item.LogIndex,
+				TxHash:           hash,
+				Topics:           constants.EventOfBridgeRelaySigned.GetTopic().Hex(),
+				Method:           constants.BridgeIn,
+				FromChain:        item.FromChain,
+				ToChain:          item.ToChain,
+				OrderId:          item.OrderId,
+				ChainAndGasLimit: item.ChainAndGasLimit,
+				Vault:            item.Vault,
+				To:               item.To,
+				Token:            item.Token,
+				Amount:           item.Amount,
+				Sequence:         item.Sequence,
+				From:             item.From,
+			})
+		}
+	default:
+		return "", fmt.Errorf("unknown relay tx kind: %s", tx.Kind)
+	}
+	if len(events) > 0 {
+		r.mint(events)
+	}
+	return hash, nil
+}
+
+func (r *Relay) maintainer(addr ecommon.Address) (structure.MaintainerInfo, bool) {
+	for _, m := range r.maintainers {
+		if m.Account == addr {
+			return m, true
+		}
+	}
+	return structure.MaintainerInfo{}, false
+}
+
+func (r *Relay) pubKeys() []shareTypes.PubKeyContractAddressPair {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ret := make([]shareTypes.PubKeyContractAddressPair, 0, len(r.vaults))
+	for _, v := range r.vaults {
+		compressed, err := common.CompressPubKey(v.pubKey)
+		if err != nil {
+			continue
+		}
+		ret = append(ret, shareTypes.PubKeyContractAddressPair{
+			PubKey:           common.PubKey("04" + ecommon.Bytes2Hex(v.pubKey)),
+			CompressedPubKey: common.PubKey(compressed),
+			Contracts:        make(map[common.Chain]common.Address),
+		})
+	}
+	return ret
+}
+
+// bridge is the shareTypes.Bridge of a single maintainer backed by the shared relay
+type bridge struct {
+	relay *Relay
+	self  ecommon.Address
+}
+
+var _ shareTypes.Bridge = &bridge{}
+
+func (b *bridge) HeartBeat() error                                  { return nil }
+func (b *bridge) EnsureNodeWhitelisted() error                      { return nil }
+func (b *bridge) EnsureNodeWhitelistedWithTimeout() error           { return nil }
+func (b *bridge) InitBlockScanner(...shareTypes.BridgeOption) error { return nil }
+func (b *bridge) SetTssKeyManager(*gotss.TssServer) error           { return nil }
+func (b *bridge) IsSyncing() (bool, error)                          { return false, nil }
+func (b *bridge) WaitSync() error                                   { return nil }
+func (b *bridge) GetMapVersion() (string, error)                    { return messages.VERSIONOFLATEST, nil }
+func (b *bridge) GetChain() common.Chain                            { return common.MAPChain }
+func (b *bridge) GetContext() ctx.Context                           { return ctx.Context{} }
+func (b *bridge) GetGasPrice() *big.Int                             { return big.NewInt(1) }
+func (b *bridge) GetFusionReceiver() ecommon.Address                { return ecommon.Address{} }
+func (b *bridge) GetKeyShare() ([]byte, []byte, error)              { return nil, nil, nil }
+func (b *bridge) GetMimir(string) (int64, error)                    { return 0, nil }
+func (b *bridge) GetMimirWithRef(_, _ string) (int64, error)        { return 0, nil }
+func (b *bridge) GetMimirWithBytes(_, _ string) ([]byte, error)     { return nil, nil }
+func (b *bridge) HasNetworkFee(common.Chain) (bool, error)          { return true, nil }
+func (b *bridge) GetAffiliateIDByName(string) (uint16, error)       { return 0, nil }
+func (b *bridge) GetAffiliateIDByAlias(string) (uint16, error)      { return 0, nil }
+
+func (b *bridge) GetConfig() config.BifrostClientConfiguration {
+	return config.BifrostClientConfiguration{ChainID: common.MAPChain}
+}
+
+func (b *bridge) FetchNodeStatus() (constants.NodeStatus, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	if _, ok := b.relay.maintainer(b.self); !ok {
+		return constants.NodeStatus_Unknown, nil
+	}
+	return constants.NodeStatus_Active, nil
+}
+
+func (b *bridge) FetchActiveNodes() ([]common.PubKey, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	ret := make([]common.PubKey, 0, len(b.relay.maintainers))
+	for _, m := range b.relay.maintainers {
+		ret = append(ret, common.PubKey(ecommon.Bytes2Hex(m.Secp256Pubkey)))
+	}
+	return ret, nil
+}
+
+func (b *bridge) GetNodeAccount(addr string) (*structure.MaintainerInfo, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	m, ok := b.relay.maintainer(ecommon.HexToAddress(addr))
+	if !ok {
+		return nil, nil
+	}
+	return &m, nil
+}
+
+func (b *bridge) GetNodeAccounts(addrs []ecommon.Address) ([]structure.MaintainerInfo, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	ret := make([]structure.MaintainerInfo, 0, len(addrs))
+	for _, addr := range addrs {
+		if m, ok := b.relay.maintainer(addr); ok {
+			ret = append(ret, m)
+		}
+	}
+	return ret, nil
+}
+
+// GetKeygenBlock hands the elected keygen to each of its members once, with the
+// compressed pubkeys of the members like the relay bridge does
+func (b *bridge) GetKeygenBlock() (*structure.KeyGen, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	round := b.relay.keygen
+	if round == nil || round.delivered[b.self] {
+		return nil, nil
+	}
+	isMember := false
+	ms := make([]structure.MaintainerInfo, 0, len(round.members))
+	for _, addr := range round.members {
+		m, ok := b.relay.maintainer(addr)
+		if !ok {
+			return nil, fmt.Errorf("member %s is not a maintainer", addr)
+		}
+		epk, err := ecrypto.UnmarshalPubkey(append([]byte{4}, m.Secp256Pubkey...))
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ECDSA public key: %w", err)
+		}
+		m.Secp256Pubkey = ecrypto.CompressPubkey(epk)
+		ms = append(ms, m)
+		if addr == b.self {
+			isMember = true
+		}
+	}
+	if !isMember {
+		return nil, nil
+	}
+	round.delivered[b.self] = true
+	return &structure.KeyGen{
+		Epoch: new(big.Int).Set(round.epoch),
+		Ms:    ms,
+	}, nil
+}
+
+// SendKeyGenStdTx records the keygen result of the member, the vault becomes active once
+// every member reported the same pubkey
+func (b *bridge) SendKeyGenStdTx(epoch *big.Int, poolPubKey common.PubKey, signature, _ []byte, blame []ecommon.Address,
+	members []ecommon.Address) (string, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	round, ok := b.relay.keygens[epoch.Int64()]
+	if !ok {
+		return "", fmt.Errorf("no keygen for epoch %s", epoch)
+	}
+	round.results = append(round.results, KeygenResult{
+		Member:    b.self,
+		PubKey:    poolPubKey,
+		Signature: signature,
+		Blames:    blame,
+	})
+	hash := ecrypto.Keccak256Hash(epoch.Bytes(), b.self.Bytes(), []byte(poolPubKey)).Hex()
+	b.relay.txs[hash] = true
+
+	if len(round.results) != len(round.members) {
+		return hash, nil
+	}
+	for _, result := range round.results {
+		if result.PubKey.IsEmpty() || result.PubKey != poolPubKey {
+			return hash, nil
+		}
+	}
+	ethPubKey, err := ecrypto.DecompressPubkey(ecommon.Hex2Bytes(poolPubKey.String()))
+	if err != nil {
+		return "", fmt.Errorf("failed to unmarshal ECDSA public key: %w", err)
+	}
+	b.relay.vaults = append(b.relay.vaults, vault{
+		epoch:   round.epoch,
+		pubKey:  ecrypto.FromECDSAPub(ethPubKey)[1:],
+		members: members,
+	})
+	return hash, nil
+}
+
+func (b *bridge) GetVault(pubkey []byte) (*shareTypes.Vault, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	for _, v := range b.relay.vaults {
+		if string(v.pubKey) == string(pubkey) {
+			return &shareTypes.Vault{PubKey: v.pubKey, Members: v.members}, nil
+		}
+	}
+	return nil, errors.New("vault not found")
+}
+
+func (b *bridge) GetPubKeys() ([]shareTypes.PubKeyContractAddressPair, error) {
+	return b.relay.pubKeys(), nil
+}
+
+func (b *bridge) GetAsgardPubKeys() ([]shareTypes.PubKeyContractAddressPair, error) {
+	return b.relay.pubKeys(), nil
+}
+
+func (b *bridge) GetAsgards() (shareTypes.Vaults, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	ret := make(shareTypes.Vaults, 0, len(b.relay.vaults))
+	for _, v := range b.relay.vaults {
+		ret = append(ret, shareTypes.Vault{PubKey: v.pubKey, Members: v.members})
+	}
+	return ret, nil
+}
+
+func (b *bridge) GetKeysignParty(common.PubKey) (common.PubKeys, error) {
+	return common.PubKeys{}, nil
+}
+
+func (b *bridge) GetLastObservedInHeight(common.Chain) (int64, error) {
+	return 0, nil
+}
+
+func (b *bridge) GetConstants() (map[string]int64, error) {
+	return map[string]int64{
+		"ChurnInterval":            43200,
+		"SigningTransactionPeriod": 300,
+	}, nil
+}
+
+func (b *bridge) GetNetworkFee(common.Chain) (uint64, uint64, uint64, error) {
+	return 0, 0, 0, nil
+}
+
+func (b *bridge) PostNetworkFee(context.Context, int64, *big.Int, uint64, uint64, uint64) (string, error) {
+	return "", nil
+}
+
+func (b *bridge) GetBlockHeight() (int64, error) {
+	return b.relay.Height(), nil
+}
+
+func (b *bridge) GetBlockScannerHeight() int64 {
+	return b.relay.Height()
+}
+
+func (b *bridge) GetTxByBlockNumber(blockHeight int64) (types.TxOut, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	if blockHeight > b.relay.height {
+		return types.TxOut{}, btypes.ErrUnavailableBlock
+	}
+	return types.TxOut{
+		Height:  blockHeight,
+		TxArray: append([]types.TxArrayItem{}, b.relay.blocks[blockHeight]...),
+	}, nil
+}
+
+func (b *bridge) GetTxOutsInRange(from, to int64) ([]types.TxOut, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	if to > b.relay.height {
+		return nil, btypes.ErrUnavailableBlock
+	}
+	var txOuts []types.TxOut
+	for height := from; height <= to; height++ {
+		if len(b.relay.blocks[height]) == 0 {
+			continue
+		}
+		txOuts = append(txOuts, types.TxOut{
+			Height:  height,
+			TxArray: append([]types.TxArrayItem{}, b.relay.blocks[height]...),
+		})
+	}
+	return txOuts, nil
+}
+
+func (b *bridge) Broadcast(hexTx []byte) (string, error) {
+	return b.relay.broadcast(hexTx)
+}
+
+func (b *bridge) TxStatus(txHash string) error {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	if !b.relay.txs[txHash] {
+		return errors.New("tx not found")
+	}
+	return nil
+}
+
+func (b *bridge) GetObservationsStdTx(txIn *types.TxIn) ([]byte, error) {
+	return json.Marshal(relayTx{Kind: relayTxObservation, Signer: b.self, TxIn: txIn})
+}
+
+func (b *bridge) GetOracleStdTx(txOut *types.TxOutItem) ([]byte, error) {
+	if b.relay.OrderExecuted(txOut.OrderId) {
+		return nil, constants.ErrorOfOrderExecuted
+	}
+	return json.Marshal(relayTx{Kind: relayTxOracle, Signer: b.self, TxOut: txOut})
+}
+
+func (b *bridge) OrderExecuted(orderId ecommon.Hash, _ bool) (bool, error) {
+	return b.relay.OrderExecuted(orderId), nil
+}
+
+func (b *bridge) PostKeysignFailure(stypes.Blame, int64, string, common.Coins, common.PubKey) (string, error) {
+	return "", nil
+}
+
+func (b *bridge) GetEpochInfo(epoch *big.Int) (*structure.EpochInfo, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	ret := &structure.EpochInfo{}
+	if round, ok := b.relay.keygens[epoch.Int64()]; ok {
+		ret.Maintainers = append(ret.Maintainers, round.members...)
+	}
+	return ret, nil
+}
+
+func (b *bridge) GetMaintainerEpochs() (*big.Int, *big.Int, error) {
+	b.relay.lock.Lock()
+	defer b.relay.lock.Unlock()
+	current, election := big.NewInt(0), big.NewInt(0)
+	if len(b.relay.vaults) > 0 {
+		current.Set(b.relay.vaults[len(b.relay.vaults)-1].epoch)
+	}
+	if b.relay.keygen != nil {
+		election.Set(b.relay.keygen.epoch)
+	}
+	return current, election, nil
+}
+
+func (b *bridge) GetEpochPubKeys(epoch *big.Int) ([]common.PubKey, error) {
+	info, err := b.GetEpochInfo(epoch)
+	if err != nil {
+		return nil, err
+	}
+	ms, err := b.GetNodeAccounts(info.Maintainers)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]common.PubKey, 0, len(ms))
+	for _, m := range ms {
+		pk, err := common.CompressPubKey(m.Secp256Pubkey)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, common.PubKey(pk))
+	}
+	return ret, nil
+}
+
+func (b *bridge) GetChainID(name string) (*big.Int, error) {
+	for _, chain := range common.AllChains {
+		if strings.EqualFold(chain.String(), name) {
+			return chain.ChainID()
+		}
+	}
+	return nil, fmt.Errorf("unknown chain %s", name)
+}
+
+func (b *bridge) GetChainName(chain *big.Int) (string, error) {
+	name, ok := common.GetChainName(chain)
+	if !ok {
+		return "", fmt.Errorf("unknown chain id %s", chain)
+	}
+	return name.String(), nil
+}
+
+func (b *bridge) GetTokenAddress(*big.Int, string) ([]byte, error) {
+	return nil, nil
+}
+
+func (b *bridge) GetTokenDecimals(*big.Int, []byte) (*big.Int, error) {
+	return big.NewInt(18), nil
+}
@@ -0,0 +1,144 @@
+// Package rescan scans a range of blocks of a chain the way the observer does, either
+// against the live rpc endpoints while recording their traffic into a fixture, or
+// offline from such a fixture. Replaying a fixture gives the TxIn items a node would
+// vote for those blocks, which lets scanner regressions be locked in as golden tests.
+package rescan
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	ckeys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	ekeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	ecrypto "github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/internal/keys"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/metrics"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/mapo"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/utxo"
+	"github.com/mapprotocol/compass-tss/pubkeymanager"
+)
+
+// signerName is the name of the throwaway key the scan runs with, the key is derived
+// from a fixed seed so the requests signed or sent from it match between record and
+// replay.
+const signerName = "rescan"
+
+// Scanner is implemented by the chain clients able to scan a range of blocks offline
+type Scanner interface {
+	ScanRange(from, to int64) ([]types.TxIn, error)
+}
+
+// Record scans the range of blocks against the live rpc endpoints of the chain and the
+// map relay chain, it returns the txs found together with the fixture of the traffic.
+func Record(cfg config.Bifrost, m *metrics.Metrics, chain common.Chain, from, to int64) ([]types.TxIn, *rpcpool.Fixture, error) {
+	recorder := rpcpool.NewRecorder()
+	txIns, err := scan(cfg, m, chain, from, to, func(c common.Chain) http.RoundTripper {
+		return recorder.Transport(c, http.DefaultTransport)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return txIns, recorder.Fixture(chain, from, to), nil
+}
+
+// Replay scans the range of blocks of the fixture from its recorded traffic, no request
+// leaves the process. A request the fixture has no response for fails the scan.
+func Replay(cfg config.Bifrost, m *metrics.Metrics, fixture *rpcpool.Fixture) ([]types.TxIn, error) {
+	replayer := rpcpool.NewReplayer(fixture)
+	return scan(cfg, m, fixture.Chain, fixture.From, fixture.To, replayer.Transport)
+}
+
+// scan creates the bridge and the client of the chain over the given transports and
+// scans the range with it.
+func scan(cfg config.Bifrost, m *metrics.Metrics, chain common.Chain, from, to int64,
+	transport func(common.Chain) http.RoundTripper,
+) ([]types.TxIn, error) {
+	var chainCfg config.BifrostChainConfiguration
+	found := false
+	for c, ccfg := range cfg.GetChains() {
+		if c.Equals(chain) {
+			chain, chainCfg, found = c, ccfg, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("chain %s is not configured", chain)
+	}
+	info, _ := common.GetChainInfo(chain)
+	switch info.Client {
+	case common.ChainClientEVM, common.ChainClientEthereum, common.ChainClientUTXO, common.ChainClientXRP,
+		common.ChainClientTron:
+	default:
+		return nil, fmt.Errorf("chain %s doesn't support offline scans", chain)
+	}
+
+	chainCfg.Transport = transport(chain)
+	cfg.MAPRelay.Transport = transport(common.MAPChain)
+
+	k, err := scanKeys()
+	if err != nil {
+		return nil, err
+	}
+	bridge, err := mapo.NewBridge(cfg.MAPRelay, m, k)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create map bridge: %w", err)
+	}
+	pubkeyMgr, err := pubkeymanager.NewPubKeyManager(bridge, m)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create pubkey manager: %w", err)
+	}
+	if err = pubkeyMgr.Start(); err != nil {
+		return nil, fmt.Errorf("fail to start pubkey manager: %w", err)
+	}
+	defer func() {
+		_ = pubkeyMgr.Stop()
+	}()
+
+	// the scanner starts from the range instead of asking the relay where to resume, keeps
+	// its blocks in memory and never reports network fee or solvency, the client isn't
+	// started so there is nobody to report them to
+	chainCfg.BlockScanner.StartBlockHeight = from
+	chainCfg.BlockScanner.DBPath = ""
+	chainCfg.BlockScanner.ObservationFlexibilityBlocks = -1
+	client, err := chainclients.NewChainClient(k, chainCfg, nil, bridge, m, pubkeyMgr)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create %s client: %w", chain, err)
+	}
+	if utxoClient, ok := client.(*utxo.Client); ok {
+		pubkeyMgr.RegisterCallback(utxoClient.RegisterPublicKey)
+	}
+	scanner, ok := client.(Scanner)
+	if !ok {
+		return nil, fmt.Errorf("chain %s doesn't support offline scans", chain)
+	}
+	return scanner.ScanRange(from, to)
+}
+
+// scanKeys imports the throwaway key of the scan into an in-memory keyring
+func scanKeys() (*keys.Keys, error) {
+	key, err := ecrypto.ToECDSA(ecrypto.Keccak256([]byte("compass-tss " + signerName)))
+	if err != nil {
+		return nil, fmt.Errorf("fail to derive scan key: %w", err)
+	}
+	registry := codectypes.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(registry)
+	kb := ckeys.NewInMemory(codec.NewProtoCodec(registry))
+	err = kb.ImportPrivKeyHex(signerName, ecommon.Bytes2Hex(ecrypto.FromECDSA(key)), string(hd.Secp256k1.Name()))
+	if err != nil {
+		return nil, fmt.Errorf("fail to import scan key: %w", err)
+	}
+	return keys.NewKeysWithKeybase(kb, signerName, "", &ekeystore.Key{
+		Address:    ecrypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	}), nil
+}
@@ -0,0 +1,195 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileExporter appends the spans to a file, one JSON object per line
+type FileExporter struct {
+	lock sync.Mutex
+	f    *os.File
+}
+
+// NewFileExporter create a new instance of FileExporter appending to the given file
+func NewFileExporter(path string) (*FileExporter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("fail to create span file folder: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open span file: %w", err)
+	}
+	return &FileExporter{f: f}, nil
+}
+
+// Export appends the spans to the file
+func (e *FileExporter) Export(spans []Span) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, span := range spans {
+		if err := enc.Encode(span); err != nil {
+			return fmt.Errorf("fail to marshal span: %w", err)
+		}
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if _, err := e.f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("fail to write spans: %w", err)
+	}
+	return nil
+}
+
+// Close the file
+func (e *FileExporter) Close() error {
+	return e.f.Close()
+}
+
+const (
+	otlpServiceName = "compass-tss"
+	otlpScopeName   = "github.com/mapprotocol/compass-tss/internal/tracing"
+	otlpTracesPath  = "/v1/traces"
+
+	// otlpSpanKindInternal, otlpStatusOk and otlpStatusError are the values of the
+	// SpanKind and StatusCode enums of the OTLP trace protocol
+	otlpSpanKindInternal = 1
+	otlpStatusOk         = 1
+	otlpStatusError      = 2
+)
+
+// OTLPExporter posts the spans to an OpenTelemetry collector with the JSON encoding of
+// the OTLP/HTTP protocol
+type OTLPExporter struct {
+	url    string
+	node   string
+	client *http.Client
+}
+
+// NewOTLPExporter create a new instance of OTLPExporter posting to the collector at the
+// given endpoint, the spans are attributed to the given node
+func NewOTLPExporter(endpoint, node string) *OTLPExporter {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, otlpTracesPath) {
+		url += otlpTracesPath
+	}
+	return &OTLPExporter{
+		url:    url,
+		node:   node,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type otlpTracesRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func otlpString(key, value string) otlpKeyValue {
+	return otlpKeyValue{Key: key, Value: otlpAnyValue{StringValue: value}}
+}
+
+// Export posts the spans to the collector
+func (e *OTLPExporter) Export(spans []Span) error {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		attrs := []otlpKeyValue{otlpString("order.id", span.OrderID)}
+		for _, key := range sortedAttributes(span.Attributes) {
+			attrs = append(attrs, otlpString(key, span.Attributes[key]))
+		}
+		status := otlpStatus{Code: otlpStatusOk}
+		if span.Error != "" {
+			status = otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+		otlpSpans = append(otlpSpans, otlpSpan{
+			TraceID:           span.TraceID,
+			SpanID:            span.SpanID,
+			Name:              span.Stage,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        attrs,
+			Status:            status,
+		})
+	}
+	buf, err := json.Marshal(otlpTracesRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{Attributes: []otlpKeyValue{
+				otlpString("service.name", otlpServiceName),
+				otlpString("service.instance.id", e.node),
+			}},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: otlpScopeName},
+				Spans: otlpSpans,
+			}},
+		}},
+	})
+	if err != nil {
+		return fmt.Errorf("fail to marshal otlp request: %w", err)
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(buf))
+	if err != nil {
+		return fmt.Errorf("fail to post spans to collector: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("collector responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close does nothing, the spans are posted as they are exported
+func (e *OTLPExporter) Close() error {
+	return nil
+}
@@ -0,0 +1,479 @@
+// Package tracing records how long an order spends in each stage between the block it
+// was observed in and the broadcast of its outbound on the destination chain. A span is
+// recorded per order and stage, the order id is the trace id, so the spans the nodes
+// record for the same order line up in a collector. The keysign spans carry the ids of
+// the tss messages signed meanwhile, which are the same on every node of the keysign.
+package tracing
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/mapprotocol/compass-tss/config"
+)
+
+// The stages of an order, in the order it passes them
+const (
+	// StageBlockScan is the fetch of the block the order was observed in until its txs are
+	// handed to the observer
+	StageBlockScan = "block_scan"
+	// StageOnDeck is the wait of the observation on deck until it is confirmed
+	StageOnDeck = "on_deck"
+	// StageRelayVote is the vote of the observation on the relay chain until it is mined
+	StageRelayVote = "relay_vote"
+	// StageMapScan is the wait for the relay chain to emit the outbound of the order
+	StageMapScan = "map_scan"
+	// StageKeysign is the tss keysign of the outbound
+	StageKeysign = "tss_keysign"
+	// StageBroadcast is the broadcast of the outbound to the destination chain
+	StageBroadcast = "dest_broadcast"
+)
+
+const (
+	// AttrTSSMsgID is the attribute of the keysign spans listing the ids of the tss messages
+	AttrTSSMsgID = "tss_msg_id"
+
+	// maxOpenSpans bounds the stages waiting to be finished, orders which never finish a
+	// stage on this node must not grow the tracer forever
+	maxOpenSpans = 10_000
+	// openSpanTimeout is how long a stage may wait to be finished before it is dropped
+	openSpanTimeout = 24 * time.Hour
+	// maxPendingSpans bounds the finished spans waiting to be exported
+	maxPendingSpans = 10_000
+	// maxKeysigns is the number of recent tss keysigns kept to link to the keysign spans
+	maxKeysigns = 256
+	// pruneInterval is how often the spans past the retention are deleted
+	pruneInterval = time.Hour
+
+	spanTimePrefix  = "span-t-"
+	spanOrderPrefix = "span-o-"
+)
+
+// Span is the time an order spent in a stage on a node
+type Span struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	OrderID    string            `json:"order_id"`
+	Stage      string            `json:"stage"`
+	Node       string            `json:"node"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	DurationMs int64             `json:"duration_ms"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// Exporter sends the finished spans out of the process
+type Exporter interface {
+	Export(spans []Span) error
+	Close() error
+}
+
+type spanKey struct {
+	orderID string
+	stage   string
+}
+
+type keysign struct {
+	msgID string
+	start time.Time
+	end   time.Time
+}
+
+// Tracer keeps the stages waiting to be finished in memory and the finished spans in
+// leveldb, the finished spans are exported in the background. The methods of a nil
+// Tracer do nothing, so tracing can be left unconfigured.
+type Tracer struct {
+	logger    zerolog.Logger
+	node      string
+	db        *leveldb.DB
+	retention time.Duration
+	interval  time.Duration
+	exporters []Exporter
+
+	lock      sync.Mutex
+	seq       uint64
+	open      map[spanKey]*Span
+	keysigns  []keysign
+	pending   []Span
+	lastPrune time.Time
+
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewTracer create a new instance of Tracer, the spans recorded are attributed to the
+// given node
+func NewTracer(cfg config.BifrostTracingConfiguration, node string) (*Tracer, error) {
+	if cfg.DBPath == "" {
+		return nil, fmt.Errorf("tracing db path is empty")
+	}
+	db, err := leveldb.OpenFile(cfg.DBPath, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fail to open tracing db: %w", err)
+	}
+	var exporters []Exporter
+	if cfg.FilePath != "" {
+		fe, err := NewFileExporter(cfg.FilePath)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		exporters = append(exporters, fe)
+	}
+	if cfg.OTLPEndpoint != "" {
+		exporters = append(exporters, NewOTLPExporter(cfg.OTLPEndpoint, node))
+	}
+	interval := cfg.ExportInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &Tracer{
+		logger:    log.With().Str("module", "tracing").Logger(),
+		node:      node,
+		db:        db,
+		retention: cfg.Retention,
+		interval:  interval,
+		exporters: exporters,
+		open:      make(map[spanKey]*Span),
+		lastPrune: time.Now(),
+		stopChan:  make(chan struct{}),
+	}, nil
+}
+
+// Start exporting the finished spans
+func (t *Tracer) Start() {
+	t.wg.Add(1)
+	go t.process()
+}
+
+// Stop exports the spans finished so far and closes the tracer
+func (t *Tracer) Stop() {
+	close(t.stopChan)
+	t.wg.Wait()
+	t.export()
+	for _, e := range t.exporters {
+		if err := e.Close(); err != nil {
+			t.logger.Error().Err(err).Msg("fail to close span exporter")
+		}
+	}
+	if err := t.db.Close(); err != nil {
+		t.logger.Error().Err(err).Msg("fail to close tracing db")
+	}
+}
+
+func (t *Tracer) process() {
+	defer t.wg.Done()
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stopChan:
+			return
+		case <-ticker.C:
+			t.export()
+			t.dropStale(time.Now().Add(-openSpanTimeout))
+			if t.retention > 0 && time.Since(t.lastPrune) >= pruneInterval {
+				t.lastPrune = time.Now()
+				if err := t.Prune(t.lastPrune.Add(-t.retention)); err != nil {
+					t.logger.Error().Err(err).Msg("fail to prune spans")
+				}
+			}
+		}
+	}
+}
+
+// Begin starts timing the stage of the order, a stage already started keeps its start.
+// The attributes are given as key and value pairs.
+func (t *Tracer) Begin(orderID, stage string, attrs ...string) {
+	if t == nil || orderID == "" {
+		return
+	}
+	key := spanKey{orderID: normalizeOrderID(orderID), stage: stage}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, ok := t.open[key]; ok {
+		return
+	}
+	if len(t.open) >= maxOpenSpans {
+		t.logger.Warn().Str("order_id", key.orderID).Str("stage", stage).Msg("too many open spans, stage not traced")
+		return
+	}
+	t.open[key] = &Span{
+		OrderID:    key.orderID,
+		Stage:      stage,
+		Start:      time.Now(),
+		Attributes: attributes(nil, attrs),
+	}
+}
+
+// Finish stops timing the stage of the order and records it, nothing is recorded when
+// the stage was not started on this node
+func (t *Tracer) Finish(orderID, stage string, err error, attrs ...string) {
+	if t == nil || orderID == "" {
+		return
+	}
+	key := spanKey{orderID: normalizeOrderID(orderID), stage: stage}
+	t.lock.Lock()
+	span, ok := t.open[key]
+	delete(t.open, key)
+	t.lock.Unlock()
+	if !ok {
+		return
+	}
+	span.End = time.Now()
+	span.Attributes = attributes(span.Attributes, attrs)
+	if err != nil {
+		span.Error = err.Error()
+	}
+	t.record(span)
+}
+
+// Record records a stage of the order timed by the caller
+func (t *Tracer) Record(orderID, stage string, start, end time.Time, err error, attrs ...string) {
+	if t == nil || orderID == "" {
+		return
+	}
+	span := &Span{
+		OrderID:    normalizeOrderID(orderID),
+		Stage:      stage,
+		Start:      start,
+		End:        end,
+		Attributes: attributes(nil, attrs),
+	}
+	if err != nil {
+		span.Error = err.Error()
+	}
+	t.record(span)
+}
+
+// Keysign notes a tss keysign of the local node, the keysign spans of the orders carry
+// the ids of the messages signed within them. The id of a message is the same on every
+// node taking part in its keysign.
+func (t *Tracer) Keysign(msgID string, start, end time.Time) {
+	if t == nil || msgID == "" {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.keysigns = append(t.keysigns, keysign{msgID: msgID, start: start, end: end})
+	if len(t.keysigns) > maxKeysigns {
+		t.keysigns = t.keysigns[len(t.keysigns)-maxKeysigns:]
+	}
+}
+
+// Spans returns the spans recorded for the order, the earliest first
+func (t *Tracer) Spans(orderID string) ([]Span, error) {
+	if t == nil {
+		return nil, nil
+	}
+	prefix := util.BytesPrefix([]byte(spanOrderPrefix + normalizeOrderID(orderID) + "-"))
+	iterator := t.db.NewIterator(prefix, nil)
+	defer iterator.Release()
+	spans := make([]Span, 0)
+	for iterator.Next() {
+		var span Span
+		if err := json.Unmarshal(iterator.Value(), &span); err != nil {
+			t.logger.Error().Err(err).Str("key", string(iterator.Key())).Msg("fail to unmarshal span")
+			continue
+		}
+		spans = append(spans, span)
+	}
+	return spans, iterator.Error()
+}
+
+// Prune deletes the spans finished before the given time
+func (t *Tracer) Prune(before time.Time) error {
+	prefix := util.BytesPrefix([]byte(spanTimePrefix))
+	iterator := t.db.NewIterator(&util.Range{Start: prefix.Start, Limit: spanTimeKey(before, 0)}, nil)
+	defer iterator.Release()
+	batch := new(leveldb.Batch)
+	for iterator.Next() {
+		batch.Delete(iterator.Key())
+		// the value is the key of the span by order
+		batch.Delete(iterator.Value())
+	}
+	if err := iterator.Error(); err != nil {
+		return fmt.Errorf("fail to iterate spans: %w", err)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return t.db.Write(batch, nil)
+}
+
+func (t *Tracer) record(span *Span) {
+	span.TraceID = TraceID(span.OrderID)
+	span.SpanID = newSpanID()
+	span.Node = t.node
+	span.DurationMs = span.End.Sub(span.Start).Milliseconds()
+
+	t.lock.Lock()
+	if span.Stage == StageKeysign {
+		var ids []string
+		for _, ks := range t.keysigns {
+			if !ks.start.Before(span.Start) && !ks.end.After(span.End) {
+				ids = append(ids, ks.msgID)
+			}
+		}
+		if len(ids) > 0 {
+			span.Attributes = attributes(span.Attributes, []string{AttrTSSMsgID, strings.Join(ids, ",")})
+		}
+	}
+	t.seq++
+	seq := t.seq
+	if len(t.exporters) > 0 {
+		if len(t.pending) >= maxPendingSpans {
+			t.logger.Warn().Int("spans", len(t.pending)).Msg("span exporters are behind, dropping the oldest span")
+			t.pending = t.pending[1:]
+		}
+		t.pending = append(t.pending, *span)
+	}
+	t.lock.Unlock()
+
+	buf, err := json.Marshal(span)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to marshal span")
+		return
+	}
+	orderKey := spanOrderKey(span.OrderID, span.Start, seq)
+	batch := new(leveldb.Batch)
+	batch.Put(orderKey, buf)
+	batch.Put(spanTimeKey(span.End, seq), orderKey)
+	if err = t.db.Write(batch, nil); err != nil {
+		t.logger.Error().Err(err).Str("order_id", span.OrderID).Str("stage", span.Stage).Msg("fail to save span")
+	}
+}
+
+func (t *Tracer) export() {
+	t.lock.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.lock.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+	for _, e := range t.exporters {
+		if err := e.Export(spans); err != nil {
+			t.logger.Error().Err(err).Int("spans", len(spans)).Msg("fail to export spans")
+		}
+	}
+}
+
+// dropStale forgets the stages started before the given time, they are not finished on
+// this node
+func (t *Tracer) dropStale(before time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for key, span := range t.open {
+		if span.Start.Before(before) {
+			t.logger.Debug().Str("order_id", key.orderID).Str("stage", key.stage).Msg("drop stale span")
+			delete(t.open, key)
+		}
+	}
+}
+
+// spanTimeKey sorts the spans by the time they finished, the sequence keeps spans of the
+// same instant apart
+func spanTimeKey(t time.Time, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d-%020d", spanTimePrefix, t.UnixNano(), seq))
+}
+
+// spanOrderKey sorts the spans of an order by the time they started
+func spanOrderKey(orderID string, start time.Time, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%s-%020d-%020d", spanOrderPrefix, orderID, start.UnixNano(), seq))
+}
+
+func normalizeOrderID(orderID string) string {
+	return strings.ToLower(strings.TrimSpace(orderID))
+}
+
+// TraceID returns the trace id of the order, the first 16 bytes of the order id or of its
+// hash when the order id is not a 32 bytes hex string
+func TraceID(orderID string) string {
+	orderID = normalizeOrderID(orderID)
+	if b, err := hex.DecodeString(strings.TrimPrefix(orderID, "0x")); err == nil && len(b) >= 16 {
+		return hex.EncodeToString(b[:16])
+	}
+	h := sha256.Sum256([]byte(orderID))
+	return hex.EncodeToString(h[:16])
+}
+
+func newSpanID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// attributes adds the key and value pairs to the attributes
+func attributes(attrs map[string]string, pairs []string) map[string]string {
+	if len(pairs) == 0 {
+		return attrs
+	}
+	if attrs == nil {
+		attrs = make(map[string]string, (len(pairs)+1)/2)
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		value := ""
+		if i+1 < len(pairs) {
+			value = pairs[i+1]
+		}
+		attrs[pairs[i]] = value
+	}
+	return attrs
+}
+
+// sortedAttributes returns the keys of the attributes in order
+func sortedAttributes(attrs map[string]string) []string {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var defaultTracer atomic.Pointer[Tracer]
+
+// SetDefault sets the tracer the stages are recorded with by the package functions
+func SetDefault(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+// Default returns the tracer set with SetDefault, nil when tracing is disabled
+func Default() *Tracer {
+	return defaultTracer.Load()
+}
+
+// Begin starts timing the stage of the order with the default tracer
+func Begin(orderID, stage string, attrs ...string) {
+	Default().Begin(orderID, stage, attrs...)
+}
+
+// Finish stops timing the stage of the order with the default tracer
+func Finish(orderID, stage string, err error, attrs ...string) {
+	Default().Finish(orderID, stage, err, attrs...)
+}
+
+// Record records a stage of the order timed by the caller with the default tracer
+func Record(orderID, stage string, start, end time.Time, err error, attrs ...string) {
+	Default().Record(orderID, stage, start, end, err, attrs...)
+}
+
+// Keysign notes a tss keysign of the local node with the default tracer
+func Keysign(msgID string, start, end time.Time) {
+	Default().Keysign(msgID, start, end)
+}
@@ -0,0 +1,166 @@
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mapprotocol/compass-tss/config"
+)
+
+const testOrderID = "0xAB00000000000000000000000000000000000000000000000000000000000001"
+
+func newTestTracer(t *testing.T, cfg config.BifrostTracingConfiguration) *Tracer {
+	t.Helper()
+	cfg.DBPath = filepath.Join(t.TempDir(), "tracing")
+	tracer, err := NewTracer(cfg, "node-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tracer
+}
+
+func TestTracerStages(t *testing.T) {
+	spanFile := filepath.Join(t.TempDir(), "spans", "spans.json")
+	tracer := newTestTracer(t, config.BifrostTracingConfiguration{FilePath: spanFile})
+
+	scanned := time.Now().Add(-time.Minute)
+	tracer.Record(testOrderID, StageBlockScan, scanned, scanned.Add(time.Second), nil, "chain", "ETH")
+	tracer.Begin(testOrderID, StageKeysign, "chain", "BSC")
+	// a stage already started keeps its start
+	tracer.Begin(testOrderID, StageKeysign, "chain", "ETH")
+	tracer.Keysign("before", time.Now().Add(-time.Hour), time.Now())
+	now := time.Now()
+	tracer.Keysign("msg-1", now, now)
+	tracer.Finish(testOrderID, StageKeysign, errors.New("keysign failed"))
+	tracer.Keysign("after", time.Now(), time.Now())
+	// never started on this node
+	tracer.Finish(testOrderID, StageBroadcast, nil)
+
+	spans, err := tracer.Spans("0xab00000000000000000000000000000000000000000000000000000000000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	scan, sign := spans[0], spans[1]
+	if scan.Stage != StageBlockScan || scan.DurationMs != 1000 || scan.Attributes["chain"] != "ETH" {
+		t.Fatalf("unexpected block scan span: %+v", scan)
+	}
+	if sign.Stage != StageKeysign || sign.Error != "keysign failed" || sign.Attributes["chain"] != "BSC" {
+		t.Fatalf("unexpected keysign span: %+v", sign)
+	}
+	if sign.Attributes[AttrTSSMsgID] != "msg-1" {
+		t.Fatalf("expected the keysign within the span, got %q", sign.Attributes[AttrTSSMsgID])
+	}
+	for _, span := range spans {
+		if span.TraceID != "ab000000000000000000000000000000" || span.Node != "node-1" || len(span.SpanID) != 16 {
+			t.Fatalf("unexpected span ids: %+v", span)
+		}
+	}
+
+	// the spans are exported when the tracer stops
+	tracer.Stop()
+	f, err := os.Open(spanFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var span Span
+		if err = json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatal(err)
+		}
+		lines++
+	}
+	if lines != 2 {
+		t.Fatalf("expected 2 exported spans, got %d", lines)
+	}
+}
+
+func TestTracerPrune(t *testing.T) {
+	tracer := newTestTracer(t, config.BifrostTracingConfiguration{})
+	defer tracer.Stop()
+	old := time.Now().Add(-2 * time.Hour)
+	tracer.Record(testOrderID, StageOnDeck, old, old.Add(time.Minute), nil)
+	tracer.Record(testOrderID, StageRelayVote, time.Now(), time.Now(), nil)
+	if err := tracer.Prune(time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	spans, err := tracer.Spans(testOrderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spans) != 1 || spans[0].Stage != StageRelayVote {
+		t.Fatalf("expected only the recent span, got %+v", spans)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	received := make(chan otlpTracesRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req otlpTracesRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received <- req
+	}))
+	defer srv.Close()
+
+	tracer := newTestTracer(t, config.BifrostTracingConfiguration{OTLPEndpoint: srv.URL + "/"})
+	tracer.Record(testOrderID, StageBroadcast, time.Unix(1, 0), time.Unix(2, 0), errors.New("nonce too low"), "hash", "0x1")
+	tracer.Stop()
+
+	req := <-received
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	if got := req.ResourceSpans[0].Resource.Attributes[1]; got.Key != "service.instance.id" || got.Value.StringValue != "node-1" {
+		t.Fatalf("unexpected resource attribute: %+v", got)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != StageBroadcast || span.TraceID != "ab000000000000000000000000000000" ||
+		span.StartTimeUnixNano != "1000000000" || span.EndTimeUnixNano != "2000000000" {
+		t.Fatalf("unexpected span: %+v", span)
+	}
+	if span.Status.Code != otlpStatusError || span.Status.Message != "nonce too low" {
+		t.Fatalf("unexpected status: %+v", span.Status)
+	}
+	if len(span.Attributes) != 2 || span.Attributes[1].Key != "hash" {
+		t.Fatalf("unexpected attributes: %+v", span.Attributes)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	tracer.Begin(testOrderID, StageOnDeck)
+	tracer.Finish(testOrderID, StageOnDeck, nil)
+	tracer.Keysign("msg", time.Now(), time.Now())
+	spans, err := tracer.Spans(testOrderID)
+	if err != nil || spans != nil {
+		t.Fatalf("expected nothing from a nil tracer, got %v %v", spans, err)
+	}
+	// the package functions use the default tracer, none is set
+	Begin(testOrderID, StageOnDeck)
+	Finish(testOrderID, StageOnDeck, nil)
+}
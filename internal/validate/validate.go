@@ -0,0 +1,494 @@
+// Package validate checks a bifrost configuration before a node is started with it. The
+// configuration is first checked on its own, then against the rpc endpoints it points
+// to: each endpoint has to report the chain id of the chain it is configured for and the
+// map relay contracts have to be deployed. Nothing is signed or sent and no key is loaded.
+package validate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+)
+
+// requestTimeout bounds every request sent to an rpc endpoint
+const requestTimeout = 15 * time.Second
+
+// Level is the severity of a finding
+type Level string
+
+const (
+	LevelOK    Level = "ok"
+	LevelWarn  Level = "warn"
+	LevelError Level = "error"
+)
+
+// Finding is the outcome of a single check, Scope is "mapo", "signer" or the chain checked
+type Finding struct {
+	Scope   string `json:"scope"`
+	Level   Level  `json:"level"`
+	Message string `json:"message"`
+}
+
+// Report is the list of findings of a validation
+type Report struct {
+	Findings []Finding `json:"findings"`
+}
+
+func (r *Report) add(scope string, level Level, format string, args ...interface{}) {
+	r.Findings = append(r.Findings, Finding{
+		Scope:   scope,
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *Report) errorf(scope, format string, args ...interface{}) {
+	r.add(scope, LevelError, format, args...)
+}
+
+// Count returns the number of findings of the given level
+func (r *Report) Count(level Level) int {
+	count := 0
+	for _, f := range r.Findings {
+		if f.Level == level {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed returns whether a node should not be started with the configuration
+func (r *Report) Failed() bool {
+	return r.Count(LevelError) > 0
+}
+
+// Print writes the findings as a table followed by a summary line
+func (r *Report) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, f := range r.Findings {
+		if _, err := fmt.Fprintf(tw, "%s\t%s\t%s\n", f.Scope, strings.ToUpper(string(f.Level)), f.Message); err != nil {
+			return err
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	result := "configuration is valid"
+	if r.Failed() {
+		result = "configuration is NOT valid"
+	}
+	_, err := fmt.Fprintf(w, "\n%s: %d ok, %d warnings, %d errors\n", result,
+		r.Count(LevelOK), r.Count(LevelWarn), r.Count(LevelError))
+	return err
+}
+
+// Check validates the configuration, the rpc endpoints are only contacted when online is
+// set. The requests to the endpoints of a chain are sent over the transport returned for
+// it, so they can be recorded into a fixture or replayed from one. A nil transport func
+// sends them over the transports of the configuration.
+func Check(ctx context.Context, cfg config.Bifrost, online bool, transport func(common.Chain) http.RoundTripper) *Report {
+	r := &Report{}
+	if transport != nil {
+		cfg.MAPRelay.Transport = transport(common.MAPChain)
+	}
+	if checkMAPRelay(r, cfg.MAPRelay) && online {
+		checkMAPRelayEndpoint(ctx, r, cfg.MAPRelay)
+	}
+	checkSigner(r, cfg)
+
+	configured := cfg.GetChains()
+	chains := make([]common.Chain, 0, len(configured))
+	for chain := range configured {
+		chains = append(chains, chain)
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i] < chains[j] })
+	for _, chain := range chains {
+		chainCfg := configured[chain]
+		if transport != nil {
+			chainCfg.Transport = transport(chain)
+		}
+		if checkChain(r, chain, chainCfg) && online {
+			checkChainEndpoints(ctx, r, chain, chainCfg)
+		}
+	}
+	return r
+}
+
+// relayContracts returns the map relay contracts the bridge calls, by config key
+func relayContracts(cfg config.BifrostClientConfiguration) [][2]string {
+	return [][2]string{
+		{"relay", cfg.Relay},
+		{"maintainer", cfg.Maintainer},
+		{"tss_manager", cfg.TssManager},
+		{"view_controller", cfg.ViewController},
+		{"fusion_receiver", cfg.FusionReceiver},
+		{"configuration", cfg.Configuration},
+	}
+}
+
+// checkMAPRelay checks the map relay chain configuration, it returns whether its
+// endpoint can be checked
+func checkMAPRelay(r *Report, cfg config.BifrostClientConfiguration) bool {
+	const scope = "mapo"
+	ok := true
+	if cfg.ChainID.IsEmpty() {
+		r.errorf(scope, "chain_id is not set")
+	} else if !cfg.ChainID.Equals(common.MAPChain) {
+		r.errorf(scope, "chain_id is %s, expected %s", cfg.ChainID, common.MAPChain)
+	}
+	if cfg.ChainHost == "" {
+		r.errorf(scope, "chain_host is not set")
+		ok = false
+	} else if _, err := endpointURL(cfg.ChainHost); err != nil {
+		r.errorf(scope, "chain_host is not a valid url: %s", err)
+		ok = false
+	}
+	if cfg.SignerName == "" {
+		r.errorf(scope, "signer_name is not set")
+	}
+	if cfg.KeystorePath == "" {
+		r.errorf(scope, "keystore_path is not set")
+	} else if _, err := os.Stat(cfg.KeystorePath); err != nil {
+		r.errorf(scope, "keystore_path cannot be read: %s", err)
+	}
+	if cfg.CrossDataPath == "" {
+		r.errorf(scope, "cross_data_path is not set")
+	}
+	for _, contract := range relayContracts(cfg) {
+		switch {
+		case contract[1] == "":
+			r.errorf(scope, "%s contract is not set", contract[0])
+			ok = false
+		case !ecommon.IsHexAddress(contract[1]):
+			r.errorf(scope, "%s contract %s is not a hex address", contract[0], contract[1])
+			ok = false
+		}
+	}
+	return ok
+}
+
+// checkSigner checks the settings shared by every chain
+func checkSigner(r *Report, cfg config.Bifrost) {
+	if cfg.Signer.SignerDbPath == "" {
+		r.errorf("signer", "signer_db_path is not set")
+	}
+	// the observer keeps its storage next to the btc scanner database
+	if cfg.Chains.BTC.BlockScanner.DBPath == "" {
+		r.errorf("observer", "chains.btc.block_scanner.db_path is not set, the observer storage is kept there")
+	}
+}
+
+// checkChain checks the configuration of the chain, it returns whether its endpoints can
+// be checked
+func checkChain(r *Report, chain common.Chain, cfg config.BifrostChainConfiguration) bool {
+	scope := chain.String()
+	if cfg.Disabled {
+		r.add(scope, LevelOK, "disabled")
+		return false
+	}
+	failed := r.Count(LevelError)
+	info, _ := common.GetChainInfo(chain)
+
+	switch {
+	case cfg.ChainID.IsEmpty():
+		r.errorf(scope, "chain_id is not set")
+	case !cfg.ChainID.Equals(chain):
+		r.errorf(scope, "chain_id is %s, but the chain is configured under %s", cfg.ChainID, chain)
+	}
+	switch {
+	case cfg.BlockScanner.ChainID.IsEmpty():
+		r.errorf(scope, "block_scanner.chain_id is not set")
+	case !cfg.BlockScanner.ChainID.Equals(chain):
+		r.errorf(scope, "block_scanner.chain_id is %s, but the chain is configured under %s", cfg.BlockScanner.ChainID, chain)
+	}
+	if cfg.BlockScanner.DBPath == "" {
+		r.errorf(scope, "block_scanner.db_path is not set")
+	}
+	if info.Client == common.ChainClientNone {
+		r.errorf(scope, "no chain client supports %s", chain)
+	}
+
+	if cfg.RPCHost == "" {
+		r.errorf(scope, "rpc_host is not set")
+	}
+	for _, host := range cfg.GetRPCHosts() {
+		if _, err := endpointURL(host); err != nil {
+			r.errorf(scope, "rpc host is not a valid url: %s", err)
+		}
+	}
+
+	switch info.Family {
+	case common.ChainFamilyUTXO:
+		switch cfg.UTXO.FeeBump.Method {
+		case "", "rbf", "cpfp":
+		default:
+			r.errorf(scope, "utxo.fee_bump.method %q is not one of rbf or cpfp", cfg.UTXO.FeeBump.Method)
+		}
+		switch cfg.UTXO.CoinSelection {
+		case "", "oldest_first", "largest_first", "branch_and_bound", "smallest_first":
+		default:
+			r.errorf(scope, "utxo.coin_selection %q is not a known strategy", cfg.UTXO.CoinSelection)
+		}
+	case common.ChainFamilyXRP:
+		if cfg.ChainNetwork != "" {
+			if _, err := strconv.ParseUint(cfg.ChainNetwork, 10, 32); err != nil {
+				r.errorf(scope, "chain_network %q is not a network id", cfg.ChainNetwork)
+			}
+		}
+	}
+
+	for _, limit := range cfg.OutboundLimits.Tokens {
+		if !ecommon.IsHexAddress(limit.Token) {
+			r.errorf(scope, "outbound_limits token %q is not a hex address", limit.Token)
+		}
+		for _, amount := range []string{limit.MaxTx, limit.MaxVaultOutflow, limit.MaxChainOutflow} {
+			if amount == "" {
+				continue
+			}
+			if v, ok := new(big.Int).SetString(amount, 10); !ok || v.Sign() < 0 {
+				r.errorf(scope, "outbound_limits amount %q of token %s is not a non negative integer", amount, limit.Token)
+			}
+		}
+	}
+
+	if r.Count(LevelError) > failed {
+		return false
+	}
+	r.add(scope, LevelOK, "configuration")
+	return true
+}
+
+// checkMAPRelayEndpoint checks the map relay chain endpoint reports the map chain id and
+// the relay contracts are deployed on it
+func checkMAPRelayEndpoint(ctx context.Context, r *Report, cfg config.BifrostClientConfiguration) {
+	const scope = "mapo"
+	host, _ := endpointURL(cfg.ChainHost)
+	client, err := dialEVM(ctx, host, cfg.Transport, "")
+	if err != nil {
+		r.errorf(scope, "fail to dial rpc host %s: %s", host.Host, err)
+		return
+	}
+	defer client.Close()
+	if !checkEVMChainID(ctx, r, scope, common.MAPChain, host, client) {
+		return
+	}
+	for _, contract := range relayContracts(cfg) {
+		reqCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		code, err := client.CodeAt(reqCtx, ecommon.HexToAddress(contract[1]), nil)
+		cancel()
+		switch {
+		case err != nil:
+			r.errorf(scope, "fail to get the code of the %s contract: %s", contract[0], err)
+		case len(code) == 0:
+			r.errorf(scope, "%s contract %s has no code on %s", contract[0], contract[1], host.Host)
+		default:
+			r.add(scope, LevelOK, "%s contract %s is deployed", contract[0], contract[1])
+		}
+	}
+}
+
+// checkChainEndpoints checks every rpc endpoint of the chain is on the chain
+func checkChainEndpoints(ctx context.Context, r *Report, chain common.Chain, cfg config.BifrostChainConfiguration) {
+	info, _ := common.GetChainInfo(chain)
+	for _, raw := range cfg.GetRPCHosts() {
+		host, _ := endpointURL(raw)
+		switch info.Family {
+		case common.ChainFamilyEVM:
+			client, err := dialEVM(ctx, host, cfg.Transport, cfg.AuthorizationBearer)
+			if err != nil {
+				r.errorf(chain.String(), "fail to dial rpc host %s: %s", host.Host, err)
+				continue
+			}
+			checkEVMChainID(ctx, r, chain.String(), chain, host, client)
+			client.Close()
+		case common.ChainFamilyUTXO:
+			checkUTXONetwork(ctx, r, chain, host, cfg)
+		case common.ChainFamilyXRP:
+			checkXRPNetwork(ctx, r, chain, host, cfg)
+		default:
+			r.add(chain.String(), LevelWarn, "rpc host %s of a %s chain is not checked", host.Host, info.Family)
+		}
+	}
+}
+
+// dialEVM creates the client of an evm endpoint sending its requests over the transport,
+// nil for the default one
+func dialEVM(ctx context.Context, host *url.URL, transport http.RoundTripper, bearer string) (*ethclient.Client, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	opts := []rpc.ClientOption{rpc.WithHTTPClient(&http.Client{Transport: transport})}
+	if bearer != "" {
+		opts = append(opts, rpc.WithHTTPAuth(func(h http.Header) error {
+			h.Set("Authorization", "Bearer "+bearer)
+			return nil
+		}))
+	}
+	c, err := rpc.DialOptions(ctx, host.String(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(c), nil
+}
+
+// checkEVMChainID checks the endpoint reports the chain id of the chain
+func checkEVMChainID(ctx context.Context, r *Report, scope string, chain common.Chain, host *url.URL, client *ethclient.Client) bool {
+	expected, err := chain.ChainID()
+	if err != nil {
+		r.errorf(scope, "fail to get the chain id of %s: %s", chain, err)
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	reported, err := client.ChainID(ctx)
+	if err != nil {
+		r.errorf(scope, "fail to get the chain id of rpc host %s: %s", host.Host, err)
+		return false
+	}
+	if reported.Cmp(expected) != 0 {
+		r.errorf(scope, "rpc host %s is on chain id %s, expected %s", host.Host, reported, expected)
+		return false
+	}
+	r.add(scope, LevelOK, "rpc host %s is on chain id %s", host.Host, expected)
+	return true
+}
+
+// checkUTXONetwork checks the node is on the network of the build
+func checkUTXONetwork(ctx context.Context, r *Report, chain common.Chain, host *url.URL, cfg config.BifrostChainConfiguration) {
+	auth := func(req *http.Request) {
+		req.SetBasicAuth(cfg.UserName, cfg.Password)
+	}
+	var info struct {
+		Chain string `json:"chain"`
+	}
+	if err := call(ctx, cfg.Transport, host, auth, "getblockchaininfo", []interface{}{}, &info); err != nil {
+		r.errorf(chain.String(), "fail to get the network of rpc host %s: %s", host.Host, err)
+		return
+	}
+	mainnet := common.CurrentChainNetwork == common.MainNet
+	if (info.Chain == "main") != mainnet {
+		expected := "a test network"
+		if mainnet {
+			expected = `"main"`
+		}
+		r.errorf(chain.String(), "rpc host %s is on network %q, expected %s", host.Host, info.Chain, expected)
+		return
+	}
+	r.add(chain.String(), LevelOK, "rpc host %s is on network %q", host.Host, info.Chain)
+}
+
+// checkXRPNetwork checks the server is on the configured network id
+func checkXRPNetwork(ctx context.Context, r *Report, chain common.Chain, host *url.URL, cfg config.BifrostChainConfiguration) {
+	var info struct {
+		Info struct {
+			// the network id is left out by the servers of the main network
+			NetworkID uint64 `json:"network_id"`
+		} `json:"info"`
+	}
+	if err := call(ctx, cfg.Transport, host, nil, "server_info", []interface{}{map[string]interface{}{}}, &info); err != nil {
+		r.errorf(chain.String(), "fail to get the network of rpc host %s: %s", host.Host, err)
+		return
+	}
+	if cfg.ChainNetwork == "" {
+		r.add(chain.String(), LevelWarn, "rpc host %s is on network id %d, chain_network is not set", host.Host, info.Info.NetworkID)
+		return
+	}
+	expected, _ := strconv.ParseUint(cfg.ChainNetwork, 10, 32)
+	if info.Info.NetworkID != expected {
+		r.errorf(chain.String(), "rpc host %s is on network id %d, expected %d", host.Host, info.Info.NetworkID, expected)
+		return
+	}
+	r.add(chain.String(), LevelOK, "rpc host %s is on network id %d", host.Host, expected)
+}
+
+// endpointURL parses an rpc host, a host without a scheme is reached over http
+func endpointURL(host string) (*url.URL, error) {
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%s has no host", host)
+	}
+	return u, nil
+}
+
+// call sends a json-rpc request to a utxo or xrp endpoint over the transport, nil for the
+// default one, and decodes its result into result. Their servers don't answer json-rpc 2.0
+// the way the go-ethereum client expects, so the evm endpoints go through dialEVM instead.
+func call(ctx context.Context, transport http.RoundTripper, host *url.URL, auth func(*http.Request), method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("fail to encode request: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, host.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("fail to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth != nil {
+		auth(req)
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	client := &http.Client{Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("fail to read response: %w", err)
+	}
+
+	var out struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err = json.Unmarshal(buf, &out); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status %s", resp.Status)
+		}
+		return fmt.Errorf("fail to decode response: %w", err)
+	}
+	if out.Error != nil {
+		return fmt.Errorf("%s (code %d)", out.Error.Message, out.Error.Code)
+	}
+	if err = json.Unmarshal(out.Result, result); err != nil {
+		return fmt.Errorf("fail to decode %s result: %w", method, err)
+	}
+	return nil
+}
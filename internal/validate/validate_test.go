@@ -0,0 +1,227 @@
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
+)
+
+// newNode serves eth_chainId with the given chain id and eth_getCode with code for the
+// addresses in deployed
+func newNode(t *testing.T, chainID string, deployed map[string]bool) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []interface{}   `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var result string
+		switch req.Method {
+		case "eth_chainId":
+			result = chainID
+		case "eth_getCode":
+			result = "0x"
+			if deployed[strings.ToLower(req.Params[0].(string))] {
+				result = "0x6080"
+			}
+		default:
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0", "id": req.ID,
+				"error": map[string]interface{}{"code": -32601, "message": "method not found"},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func chainConfig(chain common.Chain, host string) config.BifrostChainConfiguration {
+	var cfg config.BifrostChainConfiguration
+	cfg.ChainID = chain
+	cfg.RPCHost = host
+	cfg.BlockScanner.ChainID = chain
+	cfg.BlockScanner.DBPath = "/tmp/scanner"
+	return cfg
+}
+
+func errors(r *Report) string {
+	var msgs []string
+	for _, f := range r.Findings {
+		if f.Level == LevelError {
+			msgs = append(msgs, f.Message)
+		}
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func TestCheckChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		chain    common.Chain
+		modify   func(cfg *config.BifrostChainConfiguration)
+		errorHas string
+	}{
+		{
+			name:  "valid",
+			chain: common.BSCChain,
+		},
+		{
+			name:  "disabled",
+			chain: common.BSCChain,
+			modify: func(cfg *config.BifrostChainConfiguration) {
+				cfg.Disabled = true
+				cfg.RPCHost = ""
+			},
+		},
+		{
+			name:  "mismatched chain id",
+			chain: common.BSCChain,
+			modify: func(cfg *config.BifrostChainConfiguration) {
+				cfg.ChainID = common.ETHChain
+			},
+			errorHas: "chain_id is Eth, but the chain is configured under Bsc",
+		},
+		{
+			name:  "mismatched scanner chain id",
+			chain: common.BSCChain,
+			modify: func(cfg *config.BifrostChainConfiguration) {
+				cfg.BlockScanner.ChainID = ""
+			},
+			errorHas: "block_scanner.chain_id is not set",
+		},
+		{
+			name:  "missing db path",
+			chain: common.BSCChain,
+			modify: func(cfg *config.BifrostChainConfiguration) {
+				cfg.BlockScanner.DBPath = ""
+			},
+			errorHas: "block_scanner.db_path is not set",
+		},
+		{
+			name:  "missing rpc host",
+			chain: common.BSCChain,
+			modify: func(cfg *config.BifrostChainConfiguration) {
+				cfg.RPCHost = ""
+			},
+			errorHas: "rpc_host is not set",
+		},
+		{
+			name:  "unknown fee bump method",
+			chain: common.BTCChain,
+			modify: func(cfg *config.BifrostChainConfiguration) {
+				cfg.UTXO.FeeBump.Method = "bump"
+			},
+			errorHas: `utxo.fee_bump.method "bump"`,
+		},
+		{
+			name:  "invalid xrp network",
+			chain: common.XRPChain,
+			modify: func(cfg *config.BifrostChainConfiguration) {
+				cfg.ChainNetwork = "main"
+			},
+			errorHas: `chain_network "main" is not a network id`,
+		},
+		{
+			name:  "invalid outbound limit",
+			chain: common.BSCChain,
+			modify: func(cfg *config.BifrostChainConfiguration) {
+				cfg.OutboundLimits.Tokens = []config.BifrostOutboundTokenLimit{{
+					Token: "0x55d398326f99059fF775485246999027B3197955",
+					MaxTx: "1e18",
+				}}
+			},
+			errorHas: `outbound_limits amount "1e18"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := chainConfig(tt.chain, "http://localhost:8545")
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+			r := &Report{}
+			checkable := checkChain(r, tt.chain, cfg)
+			if tt.errorHas == "" {
+				if r.Failed() {
+					t.Fatalf("unexpected errors: %s", errors(r))
+				}
+				if checkable == cfg.Disabled {
+					t.Fatalf("unexpected checkable %v", checkable)
+				}
+				return
+			}
+			if checkable || !strings.Contains(errors(r), tt.errorHas) {
+				t.Fatalf("expected an error with %q, got %q", tt.errorHas, errors(r))
+			}
+		})
+	}
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	ctx := context.Background()
+	bsc := newNode(t, "0x38", nil)
+	eth := newNode(t, "0x1", nil)
+
+	r := &Report{}
+	cfg := chainConfig(common.BSCChain, bsc.URL)
+	cfg.RPCHosts = []string{eth.URL}
+	checkChainEndpoints(ctx, r, common.BSCChain, cfg)
+	if len(r.Findings) != 2 || r.Findings[0].Level != LevelOK {
+		t.Fatalf("unexpected findings: %+v", r.Findings)
+	}
+	if !strings.Contains(errors(r), "is on chain id 1, expected 56") {
+		t.Fatalf("expected the eth host to be reported, got %q", errors(r))
+	}
+
+	relay := config.BifrostClientConfiguration{
+		Relay:          "0x00004080D86e1077ce96E67C1B167fF105025307",
+		Maintainer:     "0xBfb6B7d0d5Fc120703F7B57CC18157d79a50a7e5",
+		TssManager:     "0xf3Fa35B6e3753cFe88Da86c71B2283F75EB64BE9",
+		ViewController: "0x8c98bA0a11Cbb0DB3C52e4CD91B0844B39BC1F11",
+		FusionReceiver: "0xFe6Fc65c1B47be20bD776db55a412dF7520438F3",
+		Configuration:  "0xE45C548c066184894ABF542C7D223D58D443C1c9",
+	}
+	deployed := make(map[string]bool)
+	for _, contract := range relayContracts(relay) {
+		deployed[strings.ToLower(contract[1])] = contract[0] != "view_controller"
+	}
+	relay.ChainHost = newNode(t, "0x58f8", deployed).URL
+
+	// record the checks of the relay chain and replay them without the node
+	recorder := rpcpool.NewRecorder()
+	relay.Transport = recorder.Transport(common.MAPChain, http.DefaultTransport)
+	r = &Report{}
+	checkMAPRelayEndpoint(ctx, r, relay)
+	if r.Count(LevelOK) != 6 || !strings.Contains(errors(r), "view_controller contract 0x8c98bA0a11Cbb0DB3C52e4CD91B0844B39BC1F11 has no code") {
+		t.Fatalf("unexpected findings: %+v", r.Findings)
+	}
+
+	relay.Transport = rpcpool.NewReplayer(recorder.Traffic()).Transport(common.MAPChain)
+	replayed := &Report{}
+	checkMAPRelayEndpoint(ctx, replayed, relay)
+	if len(replayed.Findings) != len(r.Findings) || errors(replayed) != errors(r) {
+		t.Fatalf("replayed findings differ: %+v", replayed.Findings)
+	}
+
+	// a node on the wrong chain stops the contract checks
+	relay.Transport = nil
+	relay.ChainHost = eth.URL
+	r = &Report{}
+	checkMAPRelayEndpoint(ctx, r, relay)
+	if len(r.Findings) != 1 || !strings.Contains(errors(r), "is on chain id 1, expected 22776") {
+		t.Fatalf("unexpected findings: %+v", r.Findings)
+	}
+}
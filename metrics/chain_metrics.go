@@ -54,6 +54,14 @@ func GasPriceSuggested(chain common.Chain) MetricName {
 	return MetricName(chain + "_gas_price_suggested")
 }
 
+func RelayerBalance(chain common.Chain) MetricName {
+	return MetricName(chain + "_relayer_balance")
+}
+
+func RelayerBalanceLow(chain common.Chain) MetricName {
+	return MetricName(chain + "_relayer_balance_low")
+}
+
 func AddChainMetrics(chain common.Chain, counters map[MetricName]prometheus.Counter, counterVecs map[MetricName]*prometheus.CounterVec, gauges map[MetricName]prometheus.Gauge, histograms map[MetricName]prometheus.Histogram) {
 	counters[BlockWithoutTx(chain)] = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "block_scanner",
@@ -132,4 +140,16 @@ func AddChainMetrics(chain common.Chain, counters map[MetricName]prometheus.Coun
 		Name:      "gas_price_suggested",
 		Help:      "suggested gas price from client library or last block as heuristic",
 	})
+	gauges[RelayerBalance(chain)] = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "signer",
+		Subsystem: chain.String(),
+		Name:      "relayer_balance",
+		Help:      "gas asset balance of the local signing account, in 1e8 units",
+	})
+	gauges[RelayerBalanceLow(chain)] = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "signer",
+		Subsystem: chain.String(),
+		Name:      "relayer_balance_low",
+		Help:      "1 when the balance of the local signing account is below its configured minimum",
+	})
 }
@@ -43,6 +43,21 @@ const (
 	MessagesBatched MetricName = `messages_batched`
 	BatchSize       MetricName = `batch_size`
 	BatchSendTime   MetricName = `batch_send_time`
+
+	RPCEndpointRequests MetricName = `rpc_endpoint_requests`
+	RPCEndpointLatency  MetricName = `rpc_endpoint_latency`
+
+	P2PRejectedConnections MetricName = `p2p_rejected_connections`
+	P2PAllowedPeers        MetricName = `p2p_allowed_peers`
+
+	TSSBlame MetricName = `tss_blame`
+
+	TSSPreParamsPoolSize MetricName = `tss_preparams_pool_size`
+	TSSPreParamsBacklog  MetricName = `tss_preparams_backlog`
+
+	OracleQueueDepth  MetricName = `oracle_queue_depth`
+	OracleQueueAge    MetricName = `oracle_queue_age`
+	OracleDeadLetters MetricName = `oracle_dead_letters`
 )
 
 // Metrics used to provide promethus metrics
@@ -157,6 +172,30 @@ var (
 		}, []string{
 			"message_type",
 		}),
+		RPCEndpointRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "chain_client",
+			Subsystem: "rpc_pool",
+			Name:      "requests_total",
+			Help:      "requests sent to each chain rpc endpoint",
+		}, []string{
+			"chain", "endpoint", "result",
+		}),
+		P2PRejectedConnections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "tss",
+			Subsystem: "p2p",
+			Name:      "rejected_connections_total",
+			Help:      "connections rejected because the peer is not a maintainer",
+		}, []string{
+			"direction",
+		}),
+		TSSBlame: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "tss",
+			Subsystem: "blame",
+			Name:      "culprits_total",
+			Help:      "tss failures each peer was blamed for",
+		}, []string{
+			"pubkey", "type", "round",
+		}),
 	}
 
 	histograms = map[MetricName]prometheus.Histogram{
@@ -192,7 +231,55 @@ var (
 		}),
 	}
 
-	gauges = map[MetricName]prometheus.Gauge{}
+	histogramVecs = map[MetricName]*prometheus.HistogramVec{
+		RPCEndpointLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "chain_client",
+			Subsystem: "rpc_pool",
+			Name:      "request_duration_seconds",
+			Help:      "latency of requests to each chain rpc endpoint",
+		}, []string{
+			"chain", "endpoint",
+		}),
+	}
+
+	gauges = map[MetricName]prometheus.Gauge{
+		P2PAllowedPeers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "tss",
+			Subsystem: "p2p",
+			Name:      "allowed_peers",
+			Help:      "number of maintainer peers allowed to connect",
+		}),
+		TSSPreParamsPoolSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "tss",
+			Subsystem: "preparams",
+			Name:      "pool_size",
+			Help:      "number of pre parameters ready for keygens",
+		}),
+		TSSPreParamsBacklog: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "tss",
+			Subsystem: "preparams",
+			Name:      "backlog",
+			Help:      "number of pre parameters left to generate to fill the pool",
+		}),
+		OracleQueueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "signer",
+			Subsystem: "oracle",
+			Name:      "queue_depth",
+			Help:      "number of oracle items waiting to be submitted",
+		}),
+		OracleQueueAge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "signer",
+			Subsystem: "oracle",
+			Name:      "queue_age_seconds",
+			Help:      "how long the oldest oracle item has been waiting",
+		}),
+		OracleDeadLetters: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "signer",
+			Subsystem: "oracle",
+			Name:      "dead_letters",
+			Help:      "number of oracle items moved to the dead letters",
+		}),
+	}
 )
 
 // NewMetrics create a new instance of Metrics
@@ -211,6 +298,9 @@ func NewMetrics(cfg config.BifrostMetricsConfiguration) (*Metrics, error) {
 	for _, item := range histograms {
 		prometheus.MustRegister(item)
 	}
+	for _, item := range histogramVecs {
+		prometheus.MustRegister(item)
+	}
 	for _, item := range gauges {
 		prometheus.MustRegister(item)
 	}
@@ -272,6 +362,14 @@ func (m *Metrics) GetGauge(name MetricName) prometheus.Gauge {
 	return nil
 }
 
+// GetHistogramVec return a histogram vec by name
+func (m *Metrics) GetHistogramVec(name MetricName) *prometheus.HistogramVec {
+	if h, ok := histogramVecs[name]; ok {
+		return h
+	}
+	return nil
+}
+
 func (m *Metrics) GetCounterVec(name MetricName) *prometheus.CounterVec {
 	if c, ok := counterVecs[name]; ok {
 		return c
@@ -11,11 +11,13 @@ import (
 	"time"
 
 	"github.com/cenkalti/backoff"
+	ecommon "github.com/ethereum/go-ethereum/common"
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/mapprotocol/compass-tss/common"
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
 	"github.com/mapprotocol/compass-tss/internal/cross"
+	"github.com/mapprotocol/compass-tss/internal/tracing"
 	"github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients"
@@ -72,6 +74,7 @@ type Observer struct {
 	signedTxOutCacheMu    sync.Mutex
 	observerWorkers       int
 	crossStorage          *cross.CrossStorage
+	stopChainsOnce        sync.Once
 }
 
 // NewObserver create a new instance of Observer for chain
@@ -180,6 +183,9 @@ func (o *Observer) sendDeck(ctx context.Context) {
 	defer o.lock.Unlock()
 
 	for _, deck := range o.onDeck {
+		if ctx.Err() != nil {
+			return
+		}
 		chainClient, err := o.getChain(deck.Chain)
 		if err != nil {
 			o.logger.Error().Err(err).Any("txHash", deck.TxArray[0].Tx).
@@ -267,6 +273,7 @@ func (o *Observer) signAndSendToMapRelay(txIn *types.TxIn) error {
 					Msgf("ignore this error, Continue to the next: %s", err.Error())
 				txIn.IsRemove = true
 				txIn.RemoveReason = fmt.Sprintf("ignore error: %s", err.Error())
+				traceStage(txIn.TxArray, tracing.StageOnDeck, "", errors.New(txIn.RemoveReason))
 				return nil
 			}
 		}
@@ -284,6 +291,8 @@ func (o *Observer) signAndSendToMapRelay(txIn *types.TxIn) error {
 		}
 		o.logger.Info().Str("srcTxHash", txIn.TxArray[0].Tx).Str("method", txIn.Method).Str("mapHash", txID).Msg("send to relay successfully")
 		txIn.MapRelayHash = txID
+		traceStage(txIn.TxArray, tracing.StageOnDeck, tracing.StageRelayVote, nil,
+			"chain", txIn.Chain.String(), "method", txIn.Method, "map_hash", txID)
 		return nil
 	}, bf)
 }
@@ -300,6 +309,8 @@ func (o *Observer) checkTxConfirmation() {
 					o.logger.Info().Any("isRemove", deck.IsRemove).Any("memPool", deck.MemPool).
 						Any("pendingCount", deck.PendingCount).Any("mapHash", deck.MapRelayHash).
 						Msg("removing tx from onDeck")
+					traceStage(deck.TxArray, tracing.StageOnDeck, "", errors.New("removed from deck"),
+						"remove_reason", deck.RemoveReason)
 					k := TxInKey(deck)
 					o.removeConfirmedTx(k)
 					continue
@@ -317,6 +328,9 @@ func (o *Observer) checkTxConfirmation() {
 					}
 					continue
 				}
+				// the vote is mined, the relay chain emits the outbound once enough nodes voted
+				traceStage(deck.TxArray, tracing.StageRelayVote, tracing.StageMapScan, nil,
+					"chain", deck.Chain.String(), "method", deck.Method, "map_hash", deck.MapRelayHash)
 				k := TxInKey(deck)
 				o.removeConfirmedTx(k)
 			}
@@ -336,6 +350,55 @@ func (o *Observer) removeConfirmedTx(k txInKey) {
 	}
 }
 
+// OnDeckTxs returns a copy of the observations waiting to be sent to the relay chain
+func (o *Observer) OnDeckTxs() []types.TxIn {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	result := make([]types.TxIn, 0, len(o.onDeck))
+	for _, deck := range o.onDeck {
+		result = append(result, *deck)
+	}
+	return result
+}
+
+// ResubmitOnDeck forgets the relay tx of the on deck observations carrying the given
+// order id and sends them to the relay chain again, it returns how many were resubmitted
+func (o *Observer) ResubmitOnDeck(orderID string) (int, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	found, sent := 0, 0
+	for _, deck := range o.onDeck {
+		if !deckHasOrder(deck, orderID) {
+			continue
+		}
+		found++
+		chainClient, err := o.getChain(deck.Chain)
+		if err != nil {
+			return sent, fmt.Errorf("fail to retrieve chain client of %s: %w", deck.Chain, err)
+		}
+		deck.MapRelayHash = ""
+		deck.PendingCount = 0
+		if o.chunkifyAndSendToMapRelay(deck, chainClient, false) != nil {
+			sent++
+		}
+	}
+	if found == 0 {
+		return 0, fmt.Errorf("order %s is not on deck", orderID)
+	}
+	return sent, nil
+}
+
+func deckHasOrder(deck *types.TxIn, orderID string) bool {
+	for _, item := range deck.TxArray {
+		if strings.EqualFold(item.OrderId.Hex(), orderID) {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *Observer) processTxIns() {
 	// Create a semaphore to limit concurrency
 	sem := make(chan struct{}, o.observerWorkers)
@@ -473,6 +536,7 @@ func (o *Observer) addToOnDeck(txIn *types.TxIn) {
 		}
 		o.logger.Debug().Msgf("dedupe took %s", time.Since(dedupeStart))
 		if len(newTxs) > 0 {
+			traceStage(newTxs, "", tracing.StageOnDeck, nil, "chain", in.Chain.String(), "method", in.Method)
 			in.TxArray = append(in.TxArray, newTxs...)
 			setDeckStart := time.Now()
 			if err := o.storage.AddOrUpdateTx(in); err != nil {
@@ -484,6 +548,7 @@ func (o *Observer) addToOnDeck(txIn *types.TxIn) {
 		return
 	}
 	o.onDeck[k] = txIn
+	traceStage(txIn.TxArray, "", tracing.StageOnDeck, nil, "chain", txIn.Chain.String(), "method", txIn.Method)
 
 	setDeckStart := time.Now()
 	if err := o.storage.AddOrUpdateTx(txIn); err != nil {
@@ -492,6 +557,22 @@ func (o *Observer) addToOnDeck(txIn *types.TxIn) {
 	o.logger.Debug().Msgf("addOrUpdateTx new took %s", time.Since(setDeckStart))
 }
 
+// traceStage finishes the given stage of the orders of the items and begins the next
+// one, either may be empty
+func traceStage(items []*types.TxInItem, finished, next string, err error, attrs ...string) {
+	for _, item := range items {
+		if item.OrderId == (ecommon.Hash{}) {
+			continue
+		}
+		if finished != "" {
+			tracing.Finish(item.OrderId.Hex(), finished, err, attrs...)
+		}
+		if next != "" {
+			tracing.Begin(item.OrderId.Hex(), next, attrs...)
+		}
+	}
+}
+
 func (o *Observer) processNetworkFeeQueue(ctx context.Context) {
 	for {
 		select {
@@ -527,13 +608,45 @@ func (o *Observer) processNetworkFeeQueue(ctx context.Context) {
 }
 
 // Stop the observer
+// stopChains stops the scanners of the chains, once
+func (o *Observer) stopChains() {
+	o.stopChainsOnce.Do(func() {
+		for _, chain := range o.chains {
+			chain.Stop()
+		}
+	})
+}
+
+// Drain stops the scanners of the chains and sends the observations on deck to the relay
+// chain until the context is done. The deck is saved with the relay tx of each observation
+// so none is voted again after the restart.
+func (o *Observer) Drain(ctx context.Context) error {
+	o.logger.Info().Msg("draining observer")
+	o.stopChains()
+	o.sendDeck(ctx)
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	var pending int
+	for _, deck := range o.onDeck {
+		if err := o.storage.AddOrUpdateTx(deck); err != nil {
+			o.logger.Error().Err(err).Msg("fail to save ondeck tx")
+		}
+		if deck.MapRelayHash == "" && !deck.IsRemove {
+			pending++
+		}
+	}
+	o.logger.Info().Int("ondeck", len(o.onDeck)).Int("unsent", pending).Msg("observer drained")
+	if err := ctx.Err(); err != nil && pending > 0 {
+		return fmt.Errorf("%d observations not sent: %w", pending, err)
+	}
+	return nil
+}
+
 func (o *Observer) Stop() error {
 	o.logger.Info().Msg("request to stop observer")
 	defer o.logger.Info().Msg("observer stopped")
 
-	for _, chain := range o.chains {
-		chain.Stop()
-	}
+	o.stopChains()
 
 	close(o.stopChan)
 	if err := o.pubkeyMgr.Stop(); err != nil {
@@ -1,10 +1,12 @@
 package p2p
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -38,6 +40,9 @@ var (
 var (
 	TSSProtocolID        protocol.ID = "/p2p/tss"
 	ObservedTxProtocolID protocol.ID = "/p2p/observed-tx"
+	// TSSEnvelopeProtocolID is negotiated before TSSProtocolID, peers running an older
+	// version fall back to the json encoded messages on TSSProtocolID
+	TSSEnvelopeProtocolID = protocol.ID("/p2p/tss/envelope/" + messages.VERSIONOFLATEST)
 )
 
 const (
@@ -46,12 +51,17 @@ const (
 
 	StreamUnknown = "UNKNOWN"
 	StreamMsgDone = "done"
+
+	// deliveryQueueSize is the number of messages of a peer waiting for a subscriber,
+	// more are dropped
+	deliveryQueueSize = 256
 )
 
 // Message that get transfer across the wire
 type Message struct {
 	PeerID  peer.ID
-	Payload []byte
+	Payload []byte // json encoded WrappedMessage, only set for the legacy protocol
+	Wrapped *messages.WrappedMessage
 }
 
 // Communication use p2p to broadcast messages among all the TSS nodes
@@ -69,6 +79,18 @@ type Communication struct {
 	BroadcastMsgChan chan *messages.BroadcastMsgChan
 	externalAddr     maddr.Multiaddr
 	streamMgr        *StreamMgr
+	gater            *PeerGater
+	peerStreams      map[peer.ID]*peerStream
+	peerStreamsLock  *sync.Mutex
+	deliveryQueues   map[deliveryKey]chan *Message
+	deliveryLock     *sync.Mutex
+	statusProvider   atomic.Value // NodeStatusProvider
+}
+
+// deliveryKey identifies the queue of the messages of a peer for one subscriber
+type deliveryKey struct {
+	peer    peer.ID
+	channel chan *Message
 }
 
 type P2PConfig interface {
@@ -131,6 +153,11 @@ func NewCommunication(cfg P2PConfig, stateManager *storage.FileStateMgr) (*Commu
 		BroadcastMsgChan: make(chan *messages.BroadcastMsgChan, 1024),
 		externalAddr:     externalAddr,
 		streamMgr:        NewStreamMgr(),
+		gater:            NewPeerGater(),
+		peerStreams:      make(map[peer.ID]*peerStream),
+		peerStreamsLock:  &sync.Mutex{},
+		deliveryQueues:   make(map[deliveryKey]chan *Message),
+		deliveryLock:     &sync.Mutex{},
 	}, nil
 }
 
@@ -139,22 +166,45 @@ func (c *Communication) GetHost() host.Host {
 	return c.host
 }
 
+// GetGater return the connection gater of the host
+func (c *Communication) GetGater() *PeerGater {
+	return c.gater
+}
+
+// UpdateAllowedPeers replaces the peers allowed to connect to the host, and closes
+// the connections to the peers which are no longer allowed.
+func (c *Communication) UpdateAllowedPeers(peers []peer.ID) {
+	c.gater.SetAllowedPeers(peers)
+	if c.host == nil {
+		return
+	}
+	for _, p := range c.host.Network().Peers() {
+		if c.gater.IsAllowed(p) {
+			continue
+		}
+		c.logger.Info().Msgf("close connection to peer(%s) which is not a maintainer", p)
+		if err := c.host.Network().ClosePeer(p); err != nil {
+			c.logger.Error().Err(err).Msgf("fail to close connection to peer(%s)", p)
+		}
+	}
+}
+
 // GetLocalPeerID from p2p host
 func (c *Communication) GetLocalPeerID() string {
 	return c.host.ID().String()
 }
 
 // Broadcast message to Peers
-func (c *Communication) Broadcast(peers []peer.ID, msg []byte, msgID string) {
+func (c *Communication) Broadcast(peers []peer.ID, msg *messages.WrappedMessage) {
 	if len(peers) == 0 {
 		return
 	}
 	// try to discover all peers and then broadcast the messages
 	c.wg.Add(1)
-	go c.broadcastToPeers(peers, msg, msgID)
+	go c.broadcastToPeers(peers, msg)
 }
 
-func (c *Communication) broadcastToPeers(peers []peer.ID, msg []byte, msgID string) {
+func (c *Communication) broadcastToPeers(peers []peer.ID, msg *messages.WrappedMessage) {
 	defer c.wg.Done()
 	defer func() {
 		c.logger.Debug().Msgf("finished sending message to peer(%v)", peers)
@@ -164,7 +214,7 @@ func (c *Communication) broadcastToPeers(peers []peer.ID, msg []byte, msgID stri
 	for _, p := range peers {
 		go func(p peer.ID) {
 			defer wgSend.Done()
-			if err := c.writeToStream(p, msg, msgID); nil != err {
+			if err := c.writeToStream(p, msg); nil != err {
 				c.logger.Error().Err(err).Msg("fail to write to stream")
 			}
 		}(p)
@@ -172,25 +222,85 @@ func (c *Communication) broadcastToPeers(peers []peer.ID, msg []byte, msgID stri
 	wgSend.Wait()
 }
 
-func (c *Communication) writeToStream(pID peer.ID, msg []byte, msgID string) error {
+func (c *Communication) writeToStream(pID peer.ID, msg *messages.WrappedMessage) error {
 	// don't send to ourselves
 	if pID == c.host.ID() {
 		return nil
 	}
+	var err error
+	// the long-lived stream may have been reset by the peer, in which case we open a new one
+	for i := 0; i < 2; i++ {
+		var ps *peerStream
+		var legacyStream network.Stream
+		ps, legacyStream, err = c.getPeerStream(pID)
+		if err != nil {
+			return fmt.Errorf("fail to open stream to peer(%s): %w", pID, err)
+		}
+		if legacyStream != nil {
+			return c.writeLegacy(legacyStream, msg)
+		}
+		c.logger.Debug().Msgf(">>>writing messages to peer(%s)", pID)
+		if err = ps.write(msg); err == nil {
+			return nil
+		}
+		c.dropPeerStream(pID, ps)
+	}
+	return err
+}
+
+// writeLegacy writes the json encoded message to a fresh stream, for peers which don't speak
+// the envelope protocol yet
+func (c *Communication) writeLegacy(stream network.Stream, msg *messages.WrappedMessage) error {
+	defer func() {
+		c.streamMgr.AddStream(msg.MsgID, stream)
+	}()
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("fail to marshal a wrapped message to json bytes: %w", err)
+	}
+	c.logger.Debug().Msgf(">>>writing legacy messages to peer(%s)", stream.Conn().RemotePeer())
+	return WriteStreamWithBuffer(buf, stream)
+}
+
+// getPeerStream returns the long-lived envelope stream to the given peer, opening it if needed.
+// When the peer only supports the legacy protocol the negotiated stream is returned instead.
+func (c *Communication) getPeerStream(pID peer.ID) (*peerStream, network.Stream, error) {
+	c.peerStreamsLock.Lock()
+	ps, ok := c.peerStreams[pID]
+	c.peerStreamsLock.Unlock()
+	if ok {
+		return ps, nil, nil
+	}
+
 	stream, err := c.connectToOnePeer(pID)
 	if err != nil {
-		return fmt.Errorf("fail to open stream to peer(%s): %w", pID, err)
+		return nil, nil, err
 	}
-	if nil == stream {
-		return nil
+	if stream.Protocol() != TSSEnvelopeProtocolID {
+		return nil, stream, nil
 	}
 
-	defer func() {
-		c.streamMgr.AddStream(msgID, stream)
-	}()
-	c.logger.Debug().Msgf(">>>writing messages to peer(%s)", pID)
+	c.peerStreamsLock.Lock()
+	defer c.peerStreamsLock.Unlock()
+	if existing, ok := c.peerStreams[pID]; ok {
+		// another writer opened a stream in the meantime
+		_ = stream.Close()
+		return existing, nil, nil
+	}
+	ps = newPeerStream(stream)
+	c.peerStreams[pID] = ps
+	return ps, nil, nil
+}
 
-	return WriteStreamWithBuffer(msg, stream)
+func (c *Communication) dropPeerStream(pID peer.ID, ps *peerStream) {
+	c.peerStreamsLock.Lock()
+	if c.peerStreams[pID] == ps {
+		delete(c.peerStreams, pID)
+	}
+	c.peerStreamsLock.Unlock()
+	if err := ps.stream.Reset(); err != nil {
+		c.logger.Debug().Err(err).Msgf("fail to reset the stream to peer(%s)", pID)
+	}
 }
 
 func (c *Communication) handleStreamTss(stream network.Stream) {
@@ -214,20 +324,110 @@ func (c *Communication) handleStreamTss(stream network.Stream) {
 			c.streamMgr.AddStream(StreamUnknown, stream)
 			return
 		}
-		c.logger.Info().Msgf(">>>>>>>[%s]", wrappedMsg.MessageType)
 		c.streamMgr.AddStream(wrappedMsg.MsgID, stream)
-		channel := c.getSubscriber(wrappedMsg.MessageType, wrappedMsg.MsgID)
-		if nil == channel {
-			c.logger.Debug().Msgf("no MsgID %s found for this message", wrappedMsg.MsgID)
-			c.logger.Debug().Msgf("no MsgID %s found for this message", wrappedMsg.MessageType)
+		c.deliver(stream.Conn().RemotePeer(), &wrappedMsg, dataBuf)
+	}
+}
+
+// handleStreamEnvelope reads the envelopes sent by a peer over a long-lived stream
+func (c *Communication) handleStreamEnvelope(stream network.Stream) {
+	remotePeer := stream.Conn().RemotePeer()
+	c.logger.Debug().Msgf("reading from tss envelope stream of peer: %s", remotePeer)
+	defer func() {
+		if err := stream.Close(); err != nil {
+			c.logger.Debug().Err(err).Msgf("fail to close envelope stream of peer: %s", remotePeer)
+		}
+	}()
+	reader := bufio.NewReader(stream)
+	for {
+		select {
+		case <-c.stopChan:
 			return
+		default:
 		}
-		c.logger.Debug().Msg("insert tss message")
-		channel <- &Message{
-			PeerID:  stream.Conn().RemotePeer(),
-			Payload: dataBuf,
+		dataBuf, err := ReadFrame(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				c.logger.Error().Err(err).Msgf("fail to read from envelope stream,peerID: %s", remotePeer)
+				_ = stream.Reset()
+			}
+			return
 		}
+		env, err := messages.UnmarshalEnvelope(dataBuf)
+		if err != nil {
+			c.logger.Error().Err(err).Msgf("fail to unmarshal envelope from peer: %s", remotePeer)
+			continue
+		}
+		wrappedMsg, err := env.WrappedMessage()
+		if err != nil {
+			c.logger.Error().Err(err).Msgf("fail to open envelope(%s) from peer: %s", env.Version, remotePeer)
+			continue
+		}
+		c.deliver(remotePeer, wrappedMsg, nil)
+	}
+}
 
+// deliver hands the message to the subscriber of its type and msg id
+func (c *Communication) deliver(remotePeer peer.ID, wrappedMsg *messages.WrappedMessage, payload []byte) {
+	c.logger.Info().Msgf(">>>>>>>[%s]", wrappedMsg.MessageType)
+	channel := c.getSubscriber(wrappedMsg.MessageType, wrappedMsg.MsgID)
+	if nil == channel {
+		c.logger.Debug().Msgf("no MsgID %s found for this message", wrappedMsg.MsgID)
+		c.logger.Debug().Msgf("no MsgID %s found for this message", wrappedMsg.MessageType)
+		return
+	}
+	c.logger.Debug().Msg("insert tss message")
+	msg := &Message{
+		PeerID:  remotePeer,
+		Payload: payload,
+		Wrapped: wrappedMsg,
+	}
+	// the messages of a peer are handed to a subscriber in order by their own queue, so
+	// a subscriber busy or already done with its party holds up neither the stream of the
+	// peer nor its messages for the other subscribers
+	key := deliveryKey{peer: remotePeer, channel: channel}
+	c.deliveryLock.Lock()
+	defer c.deliveryLock.Unlock()
+	queue, ok := c.deliveryQueues[key]
+	if !ok {
+		queue = make(chan *Message, deliveryQueueSize)
+		c.deliveryQueues[key] = queue
+		c.wg.Add(1)
+		go c.deliverQueued(key, queue)
+	}
+	select {
+	case queue <- msg:
+	default:
+		c.logger.Warn().Msgf("delivery queue of peer %s is full, drop %s message(%s)", remotePeer, wrappedMsg.MessageType, wrappedMsg.MsgID)
+	}
+}
+
+// deliverQueued hands the queued messages to the subscriber until the queue is empty. Once
+// a message is not read within TimeoutReadPayload the subscriber is considered done and
+// the messages queued for it are dropped.
+func (c *Communication) deliverQueued(key deliveryKey, queue chan *Message) {
+	defer c.wg.Done()
+	for {
+		c.deliveryLock.Lock()
+		if len(queue) == 0 {
+			delete(c.deliveryQueues, key)
+			c.deliveryLock.Unlock()
+			return
+		}
+		c.deliveryLock.Unlock()
+		msg := <-queue
+		select {
+		case key.channel <- msg:
+		case <-c.stopChan:
+			return
+		case <-time.After(TimeoutReadPayload):
+			dropped := 1
+			for len(queue) > 0 {
+				<-queue
+				dropped++
+			}
+			c.logger.Debug().Msgf("drop %d messages(%s) nobody is reading", dropped, msg.Wrapped.MsgID)
+		}
 	}
 }
 
@@ -313,10 +513,25 @@ func (c *Communication) startChannel(privKeyBytes []byte) error {
 		return addrs
 	}
 
+	// bootstrap peers are always allowed through the gater, they tell us about the other nodes
+	selfID, err := peer.IDFromPrivateKey(p2pPriKey)
+	if err != nil {
+		return fmt.Errorf("startChannel fail to get peer id: %w", err)
+	}
+	c.gater.Pin(selfID)
+	if bootstrapPeers, err := c.config.GetBootstrapPeers(); err == nil {
+		for _, el := range bootstrapPeers {
+			if pi, err := peer.AddrInfoFromP2pAddr(el); err == nil {
+				c.gater.Pin(pi.ID)
+			}
+		}
+	}
+
 	h, err := libp2p.New(ctx,
 		libp2p.ListenAddrs([]maddr.Multiaddr{c.listenAddr}...),
 		libp2p.Identity(p2pPriKey),
 		libp2p.AddrsFactory(addressFactory),
+		libp2p.ConnectionGater(c.gater),
 	)
 	if err != nil {
 		return fmt.Errorf("startChannel fail to create p2p host: %w", err)
@@ -324,6 +539,8 @@ func (c *Communication) startChannel(privKeyBytes []byte) error {
 	c.host = h
 	c.logger.Info().Msgf("startChannel Host created, we are: %s, at: %s", h.ID(), h.Addrs())
 	h.SetStreamHandler(TSSProtocolID, c.handleStreamTss)
+	h.SetStreamHandler(TSSEnvelopeProtocolID, c.handleStreamEnvelope)
+	h.SetStreamHandler(NodeStatusProtocolID, c.handleStreamNodeStatus)
 	// Start a DHT, for use in peer discovery. We can't just make a new DHT
 	// client because we want each peer to maintain its own local copy of the
 	// DHT, so that the bootstrapping node of the DHT can go down without
@@ -372,7 +589,7 @@ func (c *Communication) connectToOnePeer(pID peer.ID) (network.Stream, error) {
 	c.logger.Debug().Msgf("connect to peer : %s", pID.String())
 	ctx, cancel := context.WithTimeout(context.Background(), TimeoutConnecting)
 	defer cancel()
-	stream, err := c.host.NewStream(ctx, pID, TSSProtocolID)
+	stream, err := c.host.NewStream(ctx, pID, TSSEnvelopeProtocolID, TSSProtocolID)
 	if err != nil {
 		return nil, fmt.Errorf("fail to create new stream to peer: %s, %w", pID, err)
 	}
@@ -487,14 +704,10 @@ func (c *Communication) ProcessBroadcast() {
 	for {
 		select {
 		case msg := <-c.BroadcastMsgChan:
-			wrappedMsgBytes, err := json.Marshal(msg.WrappedMessage)
-			if err != nil {
-				c.logger.Error().Err(err).Msg("fail to marshal a wrapped message to json bytes")
-				continue
-			}
 			c.logger.Info().Msg("ProcessBroadcast writer stream ")
 			c.logger.Debug().Msgf("broadcast message %s to %+v", msg.WrappedMessage, msg.PeersID)
-			c.Broadcast(msg.PeersID, wrappedMsgBytes, msg.WrappedMessage.MsgID)
+			wrappedMsg := msg.WrappedMessage
+			c.Broadcast(msg.PeersID, &wrappedMsg)
 
 		case <-c.stopChan:
 			return
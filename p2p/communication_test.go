@@ -1,8 +1,10 @@
 package p2p
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"time"
 
 	"github.com/libp2p/go-libp2p-core/peer"
 	maddr "github.com/multiformats/go-multiaddr"
@@ -101,3 +103,153 @@ func (CommunicationTestSuite) TestEstablishP2pCommunication(c *C) {
 	ps = comm4.host.Peerstore()
 	c.Assert(checkExist(ps.Addrs(comm.host.ID()), fakeExternalMultiAddr), Equals, true)
 }
+
+func (CommunicationTestSuite) TestEnvelopeAndLegacyDelivery(c *C) {
+	newComm := func(port int) *Communication {
+		sk, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+		c.Assert(err, IsNil)
+		skRaw, err := sk.Raw()
+		c.Assert(err, IsNil)
+		comm, err := NewCommunication(&Config{Port: port, RendezvousString: "envelopeTest"}, nil)
+		c.Assert(err, IsNil)
+		c.Assert(comm.Start(skRaw), IsNil)
+		return comm
+	}
+	receiver := newComm(2240)
+	defer func() {
+		c.Assert(receiver.Stop(), IsNil)
+	}()
+	sender := newComm(2241)
+	defer func() {
+		c.Assert(sender.Stop(), IsNil)
+	}()
+	sender.host.Peerstore().AddAddrs(receiver.host.ID(), receiver.host.Addrs(), time.Hour)
+
+	received := make(chan *Message, 4)
+	receiver.SetSubscribe(messages.TSSKeySignMsg, "envelope", received)
+	msg := messages.WrappedMessage{MessageType: messages.TSSKeySignMsg, MsgID: "envelope", Payload: []byte(`{"a":1}`)}
+
+	// two messages are multiplexed over one envelope stream
+	for i := 0; i < 2; i++ {
+		c.Assert(sender.writeToStream(receiver.host.ID(), &msg), IsNil)
+		select {
+		case m := <-received:
+			c.Assert(m.PeerID, Equals, sender.host.ID())
+			c.Assert(m.Payload, IsNil)
+			c.Assert(*m.Wrapped, DeepEquals, msg)
+		case <-time.After(5 * time.Second):
+			c.Fatal("envelope message not received")
+		}
+	}
+	c.Assert(sender.peerStreams, HasLen, 1)
+
+	// a subscriber nobody reads from doesn't hold up the messages behind it on the stream
+	receiver.SetSubscribe(messages.TSSKeySignMsg, "stalled", make(chan *Message))
+	stalled := messages.WrappedMessage{MessageType: messages.TSSKeySignMsg, MsgID: "stalled", Payload: []byte(`{"a":2}`)}
+	c.Assert(sender.writeToStream(receiver.host.ID(), &stalled), IsNil)
+	c.Assert(sender.writeToStream(receiver.host.ID(), &msg), IsNil)
+	select {
+	case m := <-received:
+		c.Assert(*m.Wrapped, DeepEquals, msg)
+	case <-time.After(5 * time.Second):
+		c.Fatal("envelope message held up by a stalled subscriber")
+	}
+
+	// a peer without the envelope protocol gets json on the legacy protocol
+	legacy := newComm(2242)
+	defer func() {
+		c.Assert(legacy.Stop(), IsNil)
+	}()
+	legacy.host.RemoveStreamHandler(TSSEnvelopeProtocolID)
+	legacy.SetSubscribe(messages.TSSKeySignMsg, "envelope", received)
+	sender.host.Peerstore().AddAddrs(legacy.host.ID(), legacy.host.Addrs(), time.Hour)
+	c.Assert(sender.writeToStream(legacy.host.ID(), &msg), IsNil)
+	select {
+	case m := <-received:
+		c.Assert(m.PeerID, Equals, sender.host.ID())
+		c.Assert(m.Payload, NotNil)
+		c.Assert(*m.Wrapped, DeepEquals, msg)
+	case <-time.After(5 * time.Second):
+		c.Fatal("legacy message not received")
+	}
+	c.Assert(sender.peerStreams, HasLen, 1)
+}
+
+func (CommunicationTestSuite) TestQueryNodeStatus(c *C) {
+	newComm := func(port int) *Communication {
+		sk, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+		c.Assert(err, IsNil)
+		skRaw, err := sk.Raw()
+		c.Assert(err, IsNil)
+		comm, err := NewCommunication(&Config{Port: port, RendezvousString: "nodeStatusTest"}, nil)
+		c.Assert(err, IsNil)
+		c.Assert(comm.Start(skRaw), IsNil)
+		return comm
+	}
+	remote := newComm(2243)
+	defer func() {
+		c.Assert(remote.Stop(), IsNil)
+	}()
+	local := newComm(2244)
+	defer func() {
+		c.Assert(local.Stop(), IsNil)
+	}()
+	local.host.Peerstore().AddAddrs(remote.host.ID(), remote.host.Addrs(), time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// without a provider only the peer id is reported
+	status, _, err := local.QueryNodeStatus(ctx, remote.host.ID())
+	c.Assert(err, IsNil)
+	c.Assert(status.PeerID, Equals, remote.host.ID().String())
+	c.Assert(status.Chains, HasLen, 0)
+
+	remote.SetNodeStatusProvider(func() NodeStatus {
+		return NodeStatus{
+			Version: "1.2.3",
+			Chains:  []ChainStatus{{Chain: "Bsc", ChainHeight: 100, ScannerHeight: 98, Healthy: true}},
+		}
+	})
+	status, rtt, err := local.QueryNodeStatus(ctx, remote.host.ID())
+	c.Assert(err, IsNil)
+	c.Assert(rtt > 0, Equals, true)
+	c.Assert(status.Version, Equals, "1.2.3")
+	c.Assert(status.PeerID, Equals, remote.host.ID().String())
+	c.Assert(status.Chains, DeepEquals, []ChainStatus{{Chain: "Bsc", ChainHeight: 100, ScannerHeight: 98, Healthy: true}})
+}
+
+func (CommunicationTestSuite) TestDeliverQueue(c *C) {
+	comm, err := NewCommunication(&Config{Port: 6689, RendezvousString: "rendezvous"}, nil)
+	c.Assert(err, IsNil)
+	defer close(comm.stopChan)
+	sk, _, err := crypto.GenerateSecp256k1Key(rand.Reader)
+	c.Assert(err, IsNil)
+	remotePeer, err := peer.IDFromPrivateKey(sk)
+	c.Assert(err, IsNil)
+
+	// a subscriber nobody reads does not block the caller, the queue is bounded
+	channel := make(chan *Message)
+	comm.SetSubscribe(messages.TSSKeySignMsg, "busy", channel)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < deliveryQueueSize*2; i++ {
+			comm.deliver(remotePeer, &messages.WrappedMessage{MessageType: messages.TSSKeySignMsg, MsgID: "busy", Payload: []byte{byte(i)}}, nil)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatal("deliver blocked on a busy subscriber")
+	}
+
+	// the messages of the peer arrive in order
+	for i := 0; i < deliveryQueueSize; i++ {
+		select {
+		case msg := <-channel:
+			c.Assert(msg.Wrapped.Payload, DeepEquals, []byte{byte(i)})
+		case <-time.After(5 * time.Second):
+			c.Fatal("message was not delivered")
+		}
+	}
+}
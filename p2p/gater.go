@@ -0,0 +1,134 @@
+package p2p
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/control"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	maddr "github.com/multiformats/go-multiaddr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// PeerGater is a libp2p connection gater which only lets the peers of the active
+// maintainer set connect to the TSS host. Until the first allow list is set the
+// gater is permissive, so a node can still bootstrap before it reaches the chain.
+type PeerGater struct {
+	logger   zerolog.Logger
+	lock     sync.RWMutex
+	allowed  map[peer.ID]struct{}
+	pinned   map[peer.ID]struct{} // always allowed, e.g. ourselves and the bootstrap peers
+	enforced bool
+	rejected *prometheus.CounterVec
+}
+
+// NewPeerGater create a new instance of PeerGater
+func NewPeerGater() *PeerGater {
+	return &PeerGater{
+		logger:  log.With().Str("module", "peer_gater").Logger(),
+		allowed: make(map[peer.ID]struct{}),
+		pinned:  make(map[peer.ID]struct{}),
+	}
+}
+
+// SetRejectedCounter sets the counter used to record rejected connections, labelled by direction
+func (g *PeerGater) SetRejectedCounter(counter *prometheus.CounterVec) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.rejected = counter
+}
+
+// Pin always allows the given peers, regardless of the allow list
+func (g *PeerGater) Pin(peers ...peer.ID) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	for _, p := range peers {
+		g.pinned[p] = struct{}{}
+	}
+}
+
+// SetAllowedPeers replaces the allow list. An empty list is ignored, so a failed
+// lookup of the maintainer set never locks the node out of the network.
+func (g *PeerGater) SetAllowedPeers(peers []peer.ID) {
+	if len(peers) == 0 {
+		return
+	}
+	allowed := make(map[peer.ID]struct{}, len(peers))
+	for _, p := range peers {
+		allowed[p] = struct{}{}
+	}
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.allowed = allowed
+	g.enforced = true
+}
+
+// AllowedPeers returns the peers currently on the allow list
+func (g *PeerGater) AllowedPeers() []peer.ID {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	peers := make([]peer.ID, 0, len(g.allowed))
+	for p := range g.allowed {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+// IsAllowed returns true when the given peer may connect to us
+func (g *PeerGater) IsAllowed(p peer.ID) bool {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	if !g.enforced {
+		return true
+	}
+	if _, ok := g.pinned[p]; ok {
+		return true
+	}
+	_, ok := g.allowed[p]
+	return ok
+}
+
+func (g *PeerGater) check(p peer.ID, direction string) bool {
+	if g.IsAllowed(p) {
+		return true
+	}
+	g.logger.Debug().Str("peer", p.String()).Str("direction", direction).Msg("reject connection from non maintainer peer")
+	g.lock.RLock()
+	rejected := g.rejected
+	g.lock.RUnlock()
+	if rejected != nil {
+		rejected.WithLabelValues(direction).Inc()
+	}
+	return false
+}
+
+// InterceptPeerDial tests whether we're permitted to dial the specified peer
+func (g *PeerGater) InterceptPeerDial(p peer.ID) bool {
+	return g.check(p, "outbound")
+}
+
+// InterceptAddrDial is always allowed, the peer has been checked by InterceptPeerDial
+func (g *PeerGater) InterceptAddrDial(peer.ID, maddr.Multiaddr) bool {
+	return true
+}
+
+// InterceptAccept is always allowed, the remote peer is not known until the handshake
+func (g *PeerGater) InterceptAccept(network.ConnMultiaddrs) bool {
+	return true
+}
+
+// InterceptSecured tests whether the authenticated remote peer is permitted
+func (g *PeerGater) InterceptSecured(dir network.Direction, p peer.ID, _ network.ConnMultiaddrs) bool {
+	if dir == network.DirOutbound {
+		// outbound connections have been checked by InterceptPeerDial
+		return true
+	}
+	return g.check(p, "inbound")
+}
+
+// InterceptUpgraded is always allowed
+func (g *PeerGater) InterceptUpgraded(network.Conn) (bool, control.DisconnectReason) {
+	return true, 0
+}
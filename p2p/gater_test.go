@@ -0,0 +1,44 @@
+package p2p
+
+import (
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "gopkg.in/check.v1"
+)
+
+type PeerGaterTestSuite struct{}
+
+var _ = Suite(&PeerGaterTestSuite{})
+
+func (s *PeerGaterTestSuite) TestPeerGater(c *C) {
+	peers := generateRandomPeers(c, 4)
+	gater := NewPeerGater()
+	rejected := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "rejected"}, []string{"direction"})
+	gater.SetRejectedCounter(rejected)
+
+	// permissive until the allow list is set
+	c.Assert(gater.InterceptSecured(network.DirInbound, peers[3], nil), Equals, true)
+	gater.SetAllowedPeers(nil)
+	c.Assert(gater.InterceptPeerDial(peers[3]), Equals, true)
+
+	gater.Pin(peers[2])
+	gater.SetAllowedPeers(peers[:2])
+	c.Assert(gater.AllowedPeers(), HasLen, 2)
+	c.Assert(gater.InterceptSecured(network.DirInbound, peers[0], nil), Equals, true)
+	c.Assert(gater.InterceptPeerDial(peers[1]), Equals, true)
+	c.Assert(gater.InterceptSecured(network.DirInbound, peers[2], nil), Equals, true)
+	c.Assert(gater.InterceptSecured(network.DirInbound, peers[3], nil), Equals, false)
+	c.Assert(gater.InterceptPeerDial(peers[3]), Equals, false)
+	// outbound connections are checked on dial only
+	c.Assert(gater.InterceptSecured(network.DirOutbound, peers[3], nil), Equals, true)
+	c.Assert(testutil.ToFloat64(rejected.WithLabelValues("inbound")), Equals, float64(1))
+	c.Assert(testutil.ToFloat64(rejected.WithLabelValues("outbound")), Equals, float64(1))
+
+	// an empty update keeps the previous allow list
+	gater.SetAllowedPeers(nil)
+	c.Assert(gater.IsAllowed(peers[0]), Equals, true)
+	gater.SetAllowedPeers(peers[3:])
+	c.Assert(gater.IsAllowed(peers[0]), Equals, false)
+	c.Assert(gater.IsAllowed(peers[3]), Equals, true)
+}
@@ -0,0 +1,210 @@
+package p2p
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// LeavingProtocolID is used by a node draining before it shuts down to tell its peers it is
+// leaving, the peers no longer wait for it to join a party and do not blame it
+var LeavingProtocolID protocol.ID = "/p2p/leaving/1.0.0"
+
+// SessionProtocolID is used to ask a peer for the session it started with, a leaving peer
+// answering with another session than it announced has restarted
+var SessionProtocolID protocol.ID = "/p2p/session/1.0.0"
+
+const (
+	// leavingPeerTimeout is how long a peer is considered leaving when it does not come back
+	leavingPeerTimeout = 30 * time.Minute
+	// leavingPeerGrace is how long a leaving peer may stay connected, a drain takes the
+	// drain timeout, so a peer still connected past it is not shutting down
+	leavingPeerGrace = 5 * time.Minute
+	// leavingAnnounceInterval is how often a peer may announce it is leaving, the
+	// announcements in between are ignored so a peer cannot renew its mark to never be blamed
+	leavingAnnounceInterval = 10 * time.Minute
+)
+
+// leavingPeer is a peer which announced it is leaving, with the session it announced from
+type leavingPeer struct {
+	since   time.Time
+	session string
+}
+
+// leavingPeers are the peers which announced they are leaving, a peer is back once it
+// answers with a new session or stays connected past the grace period
+type leavingPeers struct {
+	lock      *sync.Mutex
+	peers     map[peer.ID]leavingPeer
+	announced map[peer.ID]time.Time
+	grace     time.Duration
+	interval  time.Duration
+}
+
+func newLeavingPeers() *leavingPeers {
+	return &leavingPeers{
+		lock:      &sync.Mutex{},
+		peers:     make(map[peer.ID]leavingPeer),
+		announced: make(map[peer.ID]time.Time),
+		grace:     leavingPeerGrace,
+		interval:  leavingAnnounceInterval,
+	}
+}
+
+// add marks the peer as leaving, it returns false when the peer already announced within
+// the announce interval
+func (l *leavingPeers) add(p peer.ID, session string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := time.Now()
+	if last, ok := l.announced[p]; ok && now.Sub(last) < l.interval {
+		return false
+	}
+	for id, last := range l.announced {
+		if now.Sub(last) >= l.interval {
+			delete(l.announced, id)
+		}
+	}
+	l.announced[p] = now
+	l.peers[p] = leavingPeer{since: now, session: session}
+	return true
+}
+
+// restarted forgets the peer when the session differs from the one it announced leaving from
+func (l *leavingPeers) restarted(p peer.ID, session string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	item, ok := l.peers[p]
+	if !ok || item.session == session {
+		return false
+	}
+	delete(l.peers, p)
+	return true
+}
+
+// contains returns whether the peer is leaving, the mark is forgotten when the peer is still
+// connected past the grace period
+func (l *leavingPeers) contains(p peer.ID, connected bool) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	item, ok := l.peers[p]
+	if ok && (time.Since(item.since) > leavingPeerTimeout || connected && time.Since(item.since) > l.grace) {
+		delete(l.peers, p)
+		return false
+	}
+	return ok
+}
+
+// watchLeavingPeers asks a leaving peer for its session when it connects, libp2p opens
+// more connections to a peer still draining, so the peer is only back once its session
+// changed
+func (pc *PartyCoordinator) watchLeavingPeers() {
+	pc.host.Network().Notify(&network.NotifyBundle{
+		ConnectedF: func(_ network.Network, conn network.Conn) {
+			remotePeer := conn.RemotePeer()
+			if !pc.leaving.contains(remotePeer, false) {
+				return
+			}
+			go pc.checkSession(remotePeer)
+		},
+	})
+}
+
+// checkSession asks the peer for its session and forgets it as leaving once it restarted
+func (pc *PartyCoordinator) checkSession(p peer.ID) {
+	ctx, cancel := context.WithTimeout(context.Background(), pc.timeout)
+	defer cancel()
+	stream, err := pc.host.NewStream(ctx, p, SessionProtocolID)
+	if err != nil {
+		pc.logger.Debug().Err(err).Str("peer", p.String()).Msg("fail to open session stream")
+		return
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			pc.logger.Debug().Err(err).Msg("fail to close session stream")
+		}
+	}()
+	session, err := ReadStreamWithBuffer(stream)
+	if err != nil {
+		pc.logger.Debug().Err(err).Str("peer", p.String()).Msg("fail to read session")
+		return
+	}
+	if pc.leaving.restarted(p, string(session)) {
+		pc.logger.Info().Str("peer", p.String()).Msg("leaving peer is back")
+	}
+}
+
+// handleStreamSession answers the remote peer with the local session
+func (pc *PartyCoordinator) handleStreamSession(stream network.Stream) {
+	defer func() {
+		if err := stream.Close(); err != nil {
+			pc.logger.Debug().Err(err).Msg("fail to close session stream")
+		}
+	}()
+	if err := WriteStreamWithBuffer([]byte(pc.session), stream); err != nil {
+		pc.logger.Error().Err(err).Str("peer", stream.Conn().RemotePeer().String()).Msg("fail to write session")
+	}
+}
+
+// handleStreamLeaving records the remote peer as leaving
+func (pc *PartyCoordinator) handleStreamLeaving(stream network.Stream) {
+	defer func() {
+		if err := stream.Close(); err != nil {
+			pc.logger.Debug().Err(err).Msg("fail to close leaving stream")
+		}
+	}()
+	remotePeer := stream.Conn().RemotePeer()
+	session, err := ReadStreamWithBuffer(stream)
+	if err != nil {
+		pc.logger.Error().Err(err).Str("peer", remotePeer.String()).Msg("fail to read leaving announcement")
+		return
+	}
+	if !pc.leaving.add(remotePeer, string(session)) {
+		pc.logger.Warn().Str("peer", remotePeer.String()).Msg("peer announced leaving again within the announce interval, ignored")
+		return
+	}
+	pc.logger.Info().Str("peer", remotePeer.String()).Msg("peer is leaving")
+}
+
+// AnnounceLeaving tells every connected peer the local node is leaving, it returns the
+// number of peers told
+func (pc *PartyCoordinator) AnnounceLeaving(ctx context.Context) int {
+	var wg sync.WaitGroup
+	var told int
+	var lock sync.Mutex
+	for _, p := range pc.host.Network().Peers() {
+		wg.Add(1)
+		go func(p peer.ID) {
+			defer wg.Done()
+			stream, err := pc.host.NewStream(ctx, p, LeavingProtocolID)
+			if err != nil {
+				pc.logger.Error().Err(err).Str("peer", p.String()).Msg("fail to open leaving stream")
+				return
+			}
+			defer func() {
+				if err := stream.Close(); err != nil {
+					pc.logger.Debug().Err(err).Msg("fail to close leaving stream")
+				}
+			}()
+			if err = WriteStreamWithBuffer([]byte(pc.session), stream); err != nil {
+				pc.logger.Error().Err(err).Str("peer", p.String()).Msg("fail to announce leaving")
+				return
+			}
+			lock.Lock()
+			told++
+			lock.Unlock()
+		}(p)
+	}
+	wg.Wait()
+	return told
+}
+
+// IsLeaving returns whether the peer announced it is leaving. A peer still connected past the
+// grace period is no longer leaving
+func (pc *PartyCoordinator) IsLeaving(p peer.ID) bool {
+	return pc.leaving.contains(p, pc.host.Network().Connectedness(p) == network.Connected)
+}
@@ -0,0 +1,73 @@
+package p2p
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnounceLeaving(t *testing.T) {
+	ApplyDeadline = false
+	hosts := setupHostsLocally(t, 3)
+	var pcs []*PartyCoordinator
+	var peers []string
+	for _, h := range hosts {
+		pcs = append(pcs, NewPartyCoordinator(h, time.Second))
+		peers = append(peers, h.ID().String())
+	}
+	defer func() {
+		for _, pc := range pcs {
+			pc.Stop()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Equal(t, 2, pcs[0].AnnounceLeaving(ctx))
+	assert.Eventually(t, func() bool {
+		return pcs[1].IsLeaving(hosts[0].ID()) && pcs[2].IsLeaving(hosts[0].ID())
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.False(t, pcs[0].IsLeaving(hosts[1].ID()))
+
+	// another connection of the draining peer does not bring it back
+	assert.NoError(t, hosts[1].Network().ClosePeer(hosts[0].ID()))
+	_, err := hosts[0].Network().DialPeer(ctx, hosts[1].ID())
+	assert.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, pcs[1].IsLeaving(hosts[0].ID()))
+
+	// the peer is back once it restarted with a new session
+	pcs[0].Stop()
+	pcs[0] = NewPartyCoordinator(hosts[0], time.Second)
+	assert.NoError(t, hosts[1].Network().ClosePeer(hosts[0].ID()))
+	_, err = hosts[0].Network().DialPeer(ctx, hosts[1].ID())
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return !pcs[1].IsLeaving(hosts[0].ID())
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.True(t, pcs[2].IsLeaving(hosts[0].ID()))
+}
+
+func TestLeavingPeersGraceAndInterval(t *testing.T) {
+	l := newLeavingPeers()
+	l.grace, l.interval = 100*time.Millisecond, 300*time.Millisecond
+	p := peer.ID("peer")
+
+	assert.True(t, l.add(p, "1"))
+	assert.True(t, l.contains(p, true))
+	// a disconnected peer stays leaving past the grace period
+	time.Sleep(150 * time.Millisecond)
+	assert.True(t, l.contains(p, false))
+	// a connected one is forgotten
+	assert.False(t, l.contains(p, true))
+
+	// announcing again within the interval does not renew the mark
+	assert.False(t, l.add(p, "1"))
+	assert.False(t, l.contains(p, false))
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, l.add(p, "1"))
+	assert.True(t, l.contains(p, true))
+}
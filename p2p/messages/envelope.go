@@ -0,0 +1,92 @@
+package messages
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Compression is the algorithm used to compress the payload of an Envelope
+type Compression = Envelope_Compression
+
+const (
+	CompressionNone = Envelope_None
+	CompressionGzip = Envelope_Gzip
+)
+
+// CompressThreshold payloads larger than this are compressed when compression is enabled
+const CompressThreshold = 1024
+
+// maxDecompressedPayload bounds the decompressed payload, same as the max p2p payload
+const maxDecompressedPayload = 20000000
+
+// NewEnvelope wraps the given message, compressing the payload if asked to and it is large enough
+func NewEnvelope(msg WrappedMessage, compress bool) (*Envelope, error) {
+	env := &Envelope{
+		Version:     VERSIONOFLATEST,
+		MessageType: uint32(msg.MessageType),
+		MsgId:       msg.MsgID,
+		Compression: CompressionNone,
+		Payload:     msg.Payload,
+	}
+	if !compress || len(msg.Payload) <= CompressThreshold {
+		return env, nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(msg.Payload); err != nil {
+		return nil, fmt.Errorf("fail to compress payload: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("fail to compress payload: %w", err)
+	}
+	env.Compression = CompressionGzip
+	env.Payload = buf.Bytes()
+	return env, nil
+}
+
+// WrappedMessage returns the message carried by the envelope
+func (e *Envelope) WrappedMessage() (*WrappedMessage, error) {
+	payload := e.Payload
+	switch e.Compression {
+	case CompressionNone:
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(e.Payload))
+		if err != nil {
+			return nil, fmt.Errorf("fail to decompress payload: %w", err)
+		}
+		payload, err = io.ReadAll(io.LimitReader(r, maxDecompressedPayload+1))
+		if err != nil {
+			return nil, fmt.Errorf("fail to decompress payload: %w", err)
+		}
+		if len(payload) > maxDecompressedPayload {
+			return nil, fmt.Errorf("decompressed payload exceed max payload length:%d", maxDecompressedPayload)
+		}
+	default:
+		return nil, fmt.Errorf("unknown compression: %d", e.Compression)
+	}
+	messageType := THORChainTSSMessageType(e.MessageType)
+	if e.MessageType > uint32(Unknown) {
+		messageType = Unknown
+	}
+	return &WrappedMessage{
+		MessageType: messageType,
+		MsgID:       e.MsgId,
+		Payload:     payload,
+	}, nil
+}
+
+// UnmarshalEnvelope decodes an envelope, unknown fields are skipped so newer senders can
+// add fields
+func UnmarshalEnvelope(b []byte) (*Envelope, error) {
+	var e Envelope
+	if err := e.Unmarshal(b); err != nil {
+		return nil, fmt.Errorf("fail to decode envelope: %w", err)
+	}
+	if e.Version == "" {
+		return nil, errors.New("envelope without version")
+	}
+	return &e, nil
+}
@@ -0,0 +1,525 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: p2p/messages/envelope.proto
+
+package messages
+
+import (
+	fmt "fmt"
+	proto "github.com/cosmos/gogoproto/proto"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type Envelope_Compression int32
+
+const (
+	Envelope_None Envelope_Compression = 0
+	Envelope_Gzip Envelope_Compression = 1
+)
+
+var Envelope_Compression_name = map[int32]string{
+	0: "None",
+	1: "Gzip",
+}
+
+var Envelope_Compression_value = map[string]int32{
+	"None": 0,
+	"Gzip": 1,
+}
+
+func (x Envelope_Compression) String() string {
+	return proto.EnumName(Envelope_Compression_name, int32(x))
+}
+
+func (Envelope_Compression) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_b8e03b992ecc1b72, []int{0, 0}
+}
+
+// Envelope carries a tss message over a long-lived p2p stream, it replaces the
+// json encoded WrappedMessage.
+type Envelope struct {
+	// version of the sender, VERSIONOFLATEST
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// message_type is a THORChainTSSMessageType
+	MessageType uint32               `protobuf:"varint,2,opt,name=message_type,json=messageType,proto3" json:"message_type,omitempty"`
+	MsgId       string               `protobuf:"bytes,3,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
+	Compression Envelope_Compression `protobuf:"varint,4,opt,name=compression,proto3,enum=p2p.messages.Envelope_Compression" json:"compression,omitempty"`
+	Payload     []byte               `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *Envelope) Reset()         { *m = Envelope{} }
+func (m *Envelope) String() string { return proto.CompactTextString(m) }
+func (*Envelope) ProtoMessage()    {}
+func (*Envelope) Descriptor() ([]byte, []int) {
+	return fileDescriptor_b8e03b992ecc1b72, []int{0}
+}
+func (m *Envelope) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Envelope) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Envelope.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Envelope) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Envelope.Merge(m, src)
+}
+func (m *Envelope) XXX_Size() int {
+	return m.Size()
+}
+func (m *Envelope) XXX_DiscardUnknown() {
+	xxx_messageInfo_Envelope.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Envelope proto.InternalMessageInfo
+
+func (m *Envelope) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Envelope) GetMessageType() uint32 {
+	if m != nil {
+		return m.MessageType
+	}
+	return 0
+}
+
+func (m *Envelope) GetMsgId() string {
+	if m != nil {
+		return m.MsgId
+	}
+	return ""
+}
+
+func (m *Envelope) GetCompression() Envelope_Compression {
+	if m != nil {
+		return m.Compression
+	}
+	return Envelope_None
+}
+
+func (m *Envelope) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("p2p.messages.Envelope_Compression", Envelope_Compression_name, Envelope_Compression_value)
+	proto.RegisterType((*Envelope)(nil), "p2p.messages.Envelope")
+}
+
+func init() { proto.RegisterFile("p2p/messages/envelope.proto", fileDescriptor_b8e03b992ecc1b72) }
+
+var fileDescriptor_b8e03b992ecc1b72 = []byte{
+	// 271 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0xcd, 0x4a, 0xc3, 0x40,
+	0x14, 0x85, 0x33, 0xda, 0xd6, 0x3a, 0x8d, 0x52, 0x06, 0x84, 0x80, 0x30, 0xa4, 0x59, 0x65, 0xe3,
+	0x0c, 0xc4, 0x37, 0xf0, 0x07, 0xe9, 0xc6, 0x45, 0x70, 0xe5, 0xa6, 0xa4, 0xc9, 0x10, 0x03, 0x99,
+	0xcc, 0x25, 0x37, 0x16, 0xe2, 0x53, 0xf8, 0x58, 0x2e, 0xbb, 0x74, 0xa9, 0xc9, 0x8b, 0x48, 0x42,
+	0x82, 0xd9, 0xcd, 0x99, 0x7b, 0xbe, 0x7b, 0x0f, 0x87, 0x5e, 0x43, 0x00, 0x52, 0x2b, 0xc4, 0x28,
+	0x55, 0x28, 0x55, 0x71, 0x50, 0xb9, 0x01, 0x25, 0xa0, 0x34, 0x95, 0x61, 0x36, 0x04, 0x20, 0xc6,
+	0xa1, 0xf7, 0x4b, 0xe8, 0xf2, 0x71, 0x30, 0x30, 0x87, 0x9e, 0x1d, 0x54, 0x89, 0x99, 0x29, 0x1c,
+	0xe2, 0x12, 0xff, 0x3c, 0x1c, 0x25, 0xdb, 0x50, 0x7b, 0x40, 0x76, 0x55, 0x0d, 0xca, 0x39, 0x71,
+	0x89, 0x7f, 0x11, 0xae, 0x86, 0xbf, 0x97, 0x1a, 0x14, 0xbb, 0xa2, 0x0b, 0x8d, 0xe9, 0x2e, 0x4b,
+	0x9c, 0xd3, 0x9e, 0x9d, 0x6b, 0x4c, 0xb7, 0x09, 0x7b, 0xa0, 0xab, 0xd8, 0x68, 0x28, 0x15, 0xf6,
+	0x7b, 0x67, 0x2e, 0xf1, 0x2f, 0x03, 0x4f, 0x4c, 0x43, 0x88, 0x31, 0x80, 0xb8, 0xff, 0x77, 0x86,
+	0x53, 0xac, 0x4b, 0x06, 0x51, 0x9d, 0x9b, 0x28, 0x71, 0xe6, 0x2e, 0xf1, 0xed, 0x70, 0x94, 0xde,
+	0x86, 0xae, 0x26, 0x14, 0x5b, 0xd2, 0xd9, 0xb3, 0x29, 0xd4, 0xda, 0xea, 0x5e, 0x4f, 0x1f, 0x19,
+	0xac, 0xc9, 0xdd, 0xf6, 0xab, 0xe1, 0xe4, 0xd8, 0x70, 0xf2, 0xd3, 0x70, 0xf2, 0xd9, 0x72, 0xeb,
+	0xd8, 0x72, 0xeb, 0xbb, 0xe5, 0xd6, 0xab, 0x4c, 0xb3, 0xea, 0xed, 0x7d, 0x2f, 0x62, 0xa3, 0xa5,
+	0x8e, 0xa0, 0x2f, 0x28, 0x36, 0xb9, 0xec, 0x4e, 0x47, 0x88, 0x37, 0x15, 0xa2, 0x9c, 0x76, 0xb9,
+	0x5f, 0xf4, 0x96, 0xdb, 0xbf, 0x01, 0x00, 0x4c, 0xbe, 0xd6, 0x51, 0x62, 0x01, 0x00, 0x00,
+}
+
+func (m *Envelope) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Envelope) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Envelope) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Payload) > 0 {
+		i -= len(m.Payload)
+		copy(dAtA[i:], m.Payload)
+		i = encodeVarintEnvelope(dAtA, i, uint64(len(m.Payload)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if m.Compression != 0 {
+		i = encodeVarintEnvelope(dAtA, i, uint64(m.Compression))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.MsgId) > 0 {
+		i -= len(m.MsgId)
+		copy(dAtA[i:], m.MsgId)
+		i = encodeVarintEnvelope(dAtA, i, uint64(len(m.MsgId)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.MessageType != 0 {
+		i = encodeVarintEnvelope(dAtA, i, uint64(m.MessageType))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Version) > 0 {
+		i -= len(m.Version)
+		copy(dAtA[i:], m.Version)
+		i = encodeVarintEnvelope(dAtA, i, uint64(len(m.Version)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintEnvelope(dAtA []byte, offset int, v uint64) int {
+	offset -= sovEnvelope(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Envelope) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Version)
+	if l > 0 {
+		n += 1 + l + sovEnvelope(uint64(l))
+	}
+	if m.MessageType != 0 {
+		n += 1 + sovEnvelope(uint64(m.MessageType))
+	}
+	l = len(m.MsgId)
+	if l > 0 {
+		n += 1 + l + sovEnvelope(uint64(l))
+	}
+	if m.Compression != 0 {
+		n += 1 + sovEnvelope(uint64(m.Compression))
+	}
+	l = len(m.Payload)
+	if l > 0 {
+		n += 1 + l + sovEnvelope(uint64(l))
+	}
+	return n
+}
+
+func sovEnvelope(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozEnvelope(x uint64) (n int) {
+	return sovEnvelope(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *Envelope) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowEnvelope
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Envelope: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Envelope: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Version = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MessageType", wireType)
+			}
+			m.MessageType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MessageType |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MsgId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.MsgId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compression", wireType)
+			}
+			m.Compression = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Compression |= Envelope_Compression(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Payload", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Payload = append(m.Payload[:0], dAtA[iNdEx:postIndex]...)
+			if m.Payload == nil {
+				m.Payload = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipEnvelope(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthEnvelope
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipEnvelope(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowEnvelope
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowEnvelope
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthEnvelope
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupEnvelope
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthEnvelope
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthEnvelope        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowEnvelope          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupEnvelope = fmt.Errorf("proto: unexpected end of group")
+)
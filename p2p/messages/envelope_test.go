@@ -0,0 +1,70 @@
+package messages
+
+import (
+	"bytes"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	. "gopkg.in/check.v1"
+)
+
+type EnvelopeSuite struct{}
+
+var _ = Suite(&EnvelopeSuite{})
+
+func (EnvelopeSuite) TestEnvelopeRoundTrip(c *C) {
+	small := WrappedMessage{MessageType: TSSKeySignMsg, MsgID: "msg-1", Payload: []byte(`{"hello":"world"}`)}
+	large := WrappedMessage{MessageType: TSSKeyGenVerMsg, MsgID: "msg-2", Payload: bytes.Repeat([]byte("a"), CompressThreshold*4)}
+
+	for _, tc := range []struct {
+		msg         WrappedMessage
+		compress    bool
+		compression Compression
+	}{
+		{msg: small, compress: true, compression: CompressionNone},
+		{msg: large, compress: false, compression: CompressionNone},
+		{msg: large, compress: true, compression: CompressionGzip},
+	} {
+		env, err := NewEnvelope(tc.msg, tc.compress)
+		c.Assert(err, IsNil)
+		c.Assert(env.Compression, Equals, tc.compression)
+		c.Assert(env.Version, Equals, VERSIONOFLATEST)
+
+		buf, err := env.Marshal()
+		c.Assert(err, IsNil)
+		decoded, err := UnmarshalEnvelope(buf)
+		c.Assert(err, IsNil)
+		c.Assert(*decoded, DeepEquals, *env)
+		wrapped, err := decoded.WrappedMessage()
+		c.Assert(err, IsNil)
+		c.Assert(*wrapped, DeepEquals, tc.msg)
+	}
+	env, err := NewEnvelope(large, true)
+	c.Assert(err, IsNil)
+	c.Assert(len(env.Payload) < len(large.Payload), Equals, true)
+}
+
+func (EnvelopeSuite) TestEnvelopeUnmarshal(c *C) {
+	env, err := NewEnvelope(WrappedMessage{MessageType: TSSTaskDone, MsgID: "id"}, false)
+	c.Assert(err, IsNil)
+
+	// fields added by a newer version are skipped
+	buf, err := env.Marshal()
+	c.Assert(err, IsNil)
+	buf = protowire.AppendTag(buf, 99, protowire.BytesType)
+	buf = protowire.AppendString(buf, "future")
+	decoded, err := UnmarshalEnvelope(buf)
+	c.Assert(err, IsNil)
+	wrapped, err := decoded.WrappedMessage()
+	c.Assert(err, IsNil)
+	c.Assert(wrapped.MessageType, Equals, TSSTaskDone)
+	c.Assert(wrapped.MsgID, Equals, "id")
+
+	_, err = UnmarshalEnvelope(nil)
+	c.Assert(err, NotNil)
+	_, err = UnmarshalEnvelope([]byte{0x0a, 0x05, 'a'})
+	c.Assert(err, NotNil)
+
+	decoded = &Envelope{Version: VERSIONOFLATEST, Compression: Compression(7)}
+	_, err = decoded.WrappedMessage()
+	c.Assert(err, NotNil)
+}
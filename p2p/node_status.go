@@ -0,0 +1,89 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// NodeStatusProtocolID is used by the peers to exchange their version and scanner status
+// over the tss p2p connection, so no other port of the peer needs to be reachable
+var NodeStatusProtocolID protocol.ID = "/p2p/node-status/1.0.0"
+
+// ChainStatus is the scanner status of a chain on a node
+type ChainStatus struct {
+	Chain         string `json:"chain"`
+	ChainHeight   int64  `json:"chain_height"`
+	ScannerHeight int64  `json:"scanner_height"`
+	Healthy       bool   `json:"healthy"`
+}
+
+// NodeStatus is what a node reports about itself to its peers
+type NodeStatus struct {
+	Version string        `json:"version"`
+	PeerID  string        `json:"peer_id"`
+	Chains  []ChainStatus `json:"chains"`
+}
+
+// NodeStatusProvider returns the status of the local node
+type NodeStatusProvider func() NodeStatus
+
+// SetNodeStatusProvider sets the function used to answer the node status queries of the peers
+func (c *Communication) SetNodeStatusProvider(provider NodeStatusProvider) {
+	c.statusProvider.Store(provider)
+}
+
+func (c *Communication) localNodeStatus() NodeStatus {
+	var status NodeStatus
+	if provider, ok := c.statusProvider.Load().(NodeStatusProvider); ok && provider != nil {
+		status = provider()
+	}
+	status.PeerID = c.host.ID().String()
+	return status
+}
+
+// handleStreamNodeStatus answers a node status query with the status of the local node
+func (c *Communication) handleStreamNodeStatus(stream network.Stream) {
+	defer func() {
+		if err := stream.Close(); err != nil {
+			c.logger.Debug().Err(err).Msg("fail to close node status stream")
+		}
+	}()
+	buf, err := json.Marshal(c.localNodeStatus())
+	if err != nil {
+		c.logger.Error().Err(err).Msg("fail to marshal node status")
+		return
+	}
+	if err = WriteStreamWithBuffer(buf, stream); err != nil {
+		c.logger.Error().Err(err).Str("peer", stream.Conn().RemotePeer().String()).Msg("fail to write node status")
+	}
+}
+
+// QueryNodeStatus asks the given peer for its status, it returns the status and the round trip time
+func (c *Communication) QueryNodeStatus(ctx context.Context, p peer.ID) (*NodeStatus, time.Duration, error) {
+	start := time.Now()
+	stream, err := c.host.NewStream(ctx, p, NodeStatusProtocolID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fail to open node status stream: %w", err)
+	}
+	defer func() {
+		if err := stream.Close(); err != nil {
+			c.logger.Debug().Err(err).Msg("fail to close node status stream")
+		}
+	}()
+	buf, err := ReadStreamWithBufferWithContext(ctx, stream)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fail to read node status: %w", err)
+	}
+	rtt := time.Since(start)
+	var status NodeStatus
+	if err = json.Unmarshal(buf, &status); err != nil {
+		return nil, rtt, fmt.Errorf("fail to unmarshal node status: %w", err)
+	}
+	return &status, rtt, nil
+}
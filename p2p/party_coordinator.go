@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -35,6 +36,8 @@ type PartyCoordinator struct {
 	peersGroup         map[string]*peerStatus
 	joinPartyGroupLock *sync.Mutex
 	streamMgr          *StreamMgr
+	leaving            *leavingPeers
+	session            string
 }
 
 // NewPartyCoordinator create a new instance of PartyCoordinator
@@ -51,9 +54,14 @@ func NewPartyCoordinator(host host.Host, timeout time.Duration) *PartyCoordinato
 		peersGroup:         make(map[string]*peerStatus),
 		joinPartyGroupLock: &sync.Mutex{},
 		streamMgr:          NewStreamMgr(),
+		leaving:            newLeavingPeers(),
+		session:            strconv.FormatInt(time.Now().UnixNano(), 10),
 	}
 	host.SetStreamHandler(joinPartyProtocol, pc.HandleStream)
 	host.SetStreamHandler(joinPartyProtocolWithLeader, pc.HandleStreamWithLeader)
+	host.SetStreamHandler(LeavingProtocolID, pc.handleStreamLeaving)
+	host.SetStreamHandler(SessionProtocolID, pc.handleStreamSession)
+	pc.watchLeavingPeers()
 	return pc
 }
 
@@ -62,6 +70,8 @@ func (pc *PartyCoordinator) Stop() {
 	defer pc.logger.Info().Msg("stopping party coordinator")
 	pc.host.RemoveStreamHandler(joinPartyProtocol)
 	pc.host.RemoveStreamHandler(joinPartyProtocolWithLeader)
+	pc.host.RemoveStreamHandler(LeavingProtocolID)
+	pc.host.RemoveStreamHandler(SessionProtocolID)
 	close(pc.stopChan)
 }
 
@@ -0,0 +1,38 @@
+package p2p
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/network"
+
+	"github.com/mapprotocol/compass-tss/p2p/messages"
+)
+
+// peerStream is a long-lived outbound stream to a peer speaking TSSEnvelopeProtocolID,
+// all the messages to the peer are multiplexed over it
+type peerStream struct {
+	stream network.Stream
+	lock   *sync.Mutex
+}
+
+func newPeerStream(stream network.Stream) *peerStream {
+	return &peerStream{
+		stream: stream,
+		lock:   &sync.Mutex{},
+	}
+}
+
+func (ps *peerStream) write(msg *messages.WrappedMessage) error {
+	env, err := messages.NewEnvelope(*msg, true)
+	if err != nil {
+		return fmt.Errorf("fail to create envelope: %w", err)
+	}
+	buf, err := env.Marshal()
+	if err != nil {
+		return fmt.Errorf("fail to marshal envelope: %w", err)
+	}
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+	return WriteStreamWithBuffer(buf, ps.stream)
+}
@@ -263,3 +263,26 @@ func WriteStreamWithBufferWithContext(ctx context.Context, msg []byte, stream ne
 		return ctx.Err()
 	}
 }
+
+// ReadFrame reads one length prefixed message from the given reader. Unlike ReadStreamWithBuffer
+// it applies no deadline and keeps the reader, so it can be used on long-lived streams.
+func ReadFrame(reader *bufio.Reader) ([]byte, error) {
+	lengthBytes := make([]byte, LengthHeader)
+	n, err := io.ReadFull(reader, lengthBytes)
+	if err == io.EOF {
+		return nil, err
+	}
+	if n != LengthHeader || err != nil {
+		return nil, fmt.Errorf("error in read the message head: %w", err)
+	}
+	length := binary.LittleEndian.Uint32(lengthBytes)
+	if length > MaxPayload {
+		return nil, fmt.Errorf("payload length:%d exceed max payload length:%d", length, MaxPayload)
+	}
+	dataBuf := make([]byte, length)
+	n, err = io.ReadFull(reader, dataBuf)
+	if uint32(n) != length || err != nil {
+		return nil, fmt.Errorf("short read err(%w), we would like to read: %d, however we only read: %d", err, length, n)
+	}
+	return dataBuf, nil
+}
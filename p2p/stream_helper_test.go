@@ -1,9 +1,11 @@
 package p2p
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"errors"
+	"io"
 	"strconv"
 	"testing"
 	"time"
@@ -217,6 +219,31 @@ func TestReadPayload(t *testing.T) {
 	}
 }
 
+func TestReadFrame(t *testing.T) {
+	ApplyDeadline = true
+	stream := NewMockNetworkStream()
+	inputs := [][]byte{[]byte("hello"), []byte("world")}
+	for _, input := range inputs {
+		if err := WriteStreamWithBuffer(input, stream); err != nil {
+			t.Fatalf("fail to write the data to stream: %s", err)
+		}
+	}
+	// both frames are read through the same reader
+	reader := bufio.NewReader(stream)
+	for _, expected := range inputs {
+		data, err := ReadFrame(reader)
+		if err != nil {
+			t.Fatalf("fail to read frame: %s", err)
+		}
+		if !bytes.Equal(expected, data) {
+			t.Fatalf("expecting %s, however got :%s", expected, data)
+		}
+	}
+	if _, err := ReadFrame(reader); !errors.Is(err, io.EOF) {
+		t.Fatalf("expecting EOF, however got: %v", err)
+	}
+}
+
 func TestStreamManager(t *testing.T) {
 	streamMgr := NewStreamMgr()
 	stream := NewMockNetworkStream()
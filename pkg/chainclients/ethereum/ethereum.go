@@ -3,10 +3,12 @@ package ethereum
 import (
 	"context"
 	_ "embed"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +32,7 @@ import (
 	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/runners"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/signercache"
 	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
@@ -66,6 +69,7 @@ type Client struct {
 	globalSolvencyQueue     chan stypes.Solvency
 	signerCacheManager      *signercache.CacheManager
 	lastSolvencyCheckHeight int64
+	submitter               *evm.SubmitterElection
 }
 
 // NewClient create new instance of Ethereum client
@@ -106,10 +110,34 @@ func NewClient(thorKeys *keys.Keys,
 		return nil, fmt.Errorf("failed to get pub key: %w", err)
 	}
 
-	ethClient, err := ethclient.Dial(cfg.RPCHost)
+	// create an endpoint pool over every configured rpc host, failing over between them
+	pool, err := rpcpool.NewPoolFromConfig(cfg, m)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create rpc pool: %w", err)
+	}
+	dialOpts := []rpc.ClientOption{
+		rpc.WithHTTPClient(pool.HTTPClient(cfg.BlockScanner.HTTPRequestTimeout)),
+	}
+	switch {
+	case cfg.AuthorizationBearer != "":
+		authFn := func(h http.Header) error {
+			h.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.AuthorizationBearer))
+			return nil
+		}
+		dialOpts = append(dialOpts, rpc.WithHTTPAuth(authFn))
+	case cfg.UserName != "" && cfg.Password != "":
+		authFn := func(h http.Header) error {
+			auth := base64.StdEncoding.EncodeToString([]byte(cfg.UserName + ":" + cfg.Password))
+			h.Set("Authorization", fmt.Sprintf("Basic %s", auth))
+			return nil
+		}
+		dialOpts = append(dialOpts, rpc.WithHTTPAuth(authFn))
+	}
+	dialed, err := rpc.DialOptions(context.Background(), pool.URL(), dialOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("fail to dial ETH rpc host(%s): %w", cfg.RPCHost, err)
 	}
+	ethClient := ethclient.NewClient(dialed)
 	chainID, err := getChainID(ethClient, cfg.BlockScanner.HTTPRequestTimeout)
 	if err != nil {
 		return nil, err
@@ -136,6 +164,7 @@ func NewClient(thorKeys *keys.Keys,
 		tssKeySigner: tssKm,
 		wg:           &sync.WaitGroup{},
 		stopchan:     make(chan struct{}),
+		submitter:    evm.NewSubmitterElection(bridge, pk, cfg.SubmitterTakeoverBlocks),
 	}
 
 	c.logger.Info().Msgf("current chain id: %d", chainID.Uint64())
@@ -205,6 +234,16 @@ func (c *Client) IsBlockScannerHealthy() bool {
 	return c.blockScanner.IsHealthy()
 }
 
+// Rescan scans the given range of blocks again
+func (c *Client) Rescan(from, to int64) error {
+	return c.blockScanner.Rescan(from, to)
+}
+
+// ScanRange fetches the txs of the given range of blocks without sending them to the observer
+func (c *Client) ScanRange(from, to int64) ([]stypes.TxIn, error) {
+	return c.blockScanner.ScanRange(from, to)
+}
+
 // GetConfig return the configurations used by ETH chain
 func (c *Client) GetConfig() config.BifrostChainConfiguration {
 	return c.cfg
@@ -403,6 +442,11 @@ func (c *Client) SignTx(tx stypes.TxOutItem, height int64) ([]byte, []byte, *sty
 		return nil, nil, nil, constants.ErrorOfOrderExecuted
 	}
 
+	// only the elected node submits, the others take over if it doesn't land in time
+	if !c.submitter.IsMyTurn(tx.OrderId, height) {
+		return nil, nil, nil, constants.ErrorOfNotSubmitter
+	}
+
 	cgl, err := evm.ParseChainAndGasLimit(ecommon.BytesToHash(common.Completion(tx.ChainAndGasLimit.Bytes(), 32)))
 	if err != nil {
 		c.logger.Err(err).Msg("fail to parse chain and gas limit")
@@ -447,13 +491,17 @@ func (c *Client) SignTx(tx stypes.TxOutItem, height int64) ([]byte, []byte, *sty
 		gasRate = big.NewInt(cgl.Third.Int64())
 	}
 
-	// tip cap at configured percentage of max fee
-	tipCap := new(big.Int).Mul(gasRate, big.NewInt(int64(c.cfg.MaxGasTipPercentage)))
-	tipCap.Div(tipCap, big.NewInt(100))
+	// dynamic fee transactions need the base fee, until a block is scanned send a legacy one
+	var feeCap, tipCap *big.Int
+	baseFee := c.ethScanner.GetBaseFee()
+	dynamicFee := c.cfg.DynamicFee && c.cfg.BlockScanner.FixedGasRate == 0 && baseFee != nil
+	if dynamicFee {
+		feeCap, tipCap = evm.DynamicFee(baseFee, gasRate, c.cfg.MaxGasTipPercentage)
+	}
 	if uint64(gasRate.Cmp(cgl.Third)) != 0 {
 		c.logger.Info().Str("inHash", tx.TxHash).Str("outboundRate", cgl.Third.String()).
 			Str("currentRate", c.GetGasPrice().String()).Str("effectiveRate", gasRate.String()).
-			Str("tipCap", tipCap.String()).Msg("gas rate")
+			Str("feeCap", feeCap.String()).Str("tipCap", tipCap.String()).Msg("gas rate")
 	}
 
 	to := c.cfg.BlockScanner.Mos
@@ -462,16 +510,16 @@ func (c *Client) SignTx(tx stypes.TxOutItem, height int64) ([]byte, []byte, *sty
 		return nil, nil, nil, fmt.Errorf("fail to get outbound tx data: %w", err)
 	}
 	var createdTx *etypes.Transaction
-	if c.cfg.BlockScanner.FixedGasRate == 0 {
+	if dynamicFee {
 		to := ecommon.HexToAddress(to)
 		createdTx = etypes.NewTx(&etypes.DynamicFeeTx{
 			ChainID:   c.chainID,
 			Nonce:     nonce,
 			To:        &to,
 			Value:     big.NewInt(0),
-			Gas:       gasRate.Uint64(),
-			GasFeeCap: gasRate, // maxFeePerGas
-			GasTipCap: tipCap,  // maxPriorityFeePerGas
+			Gas:       c.cfg.BlockScanner.MaxGasLimit,
+			GasFeeCap: feeCap, // maxFeePerGas
+			GasTipCap: tipCap, // maxPriorityFeePerGas
 			Data:      data,
 		})
 	} else {
@@ -492,7 +540,7 @@ func (c *Client) SignTx(tx stypes.TxOutItem, height int64) ([]byte, []byte, *sty
 	var estimatedFee *big.Int
 	estimatedGas = c.calGasLimit(estimatedGas, cgl.End.Uint64(), uint64(c.cfg.MaxGasLimit),
 		c.cfg.LimitMultiplier)
-	if c.cfg.BlockScanner.FixedGasRate == 0 {
+	if dynamicFee {
 		// if estimated gas is more than the planned gas,
 		// abort and let relay reschedule
 		if estimatedGas > cgl.End.Uint64() {
@@ -501,8 +549,8 @@ func (c *Client) SignTx(tx stypes.TxOutItem, height int64) ([]byte, []byte, *sty
 				Msg("max gas exceeded, aborting to let relay reschedule")
 		}
 
-		c.logger.Info().Str("in_hash", tx.TxHash).Stringer("rate", gasRate).
-			Stringer("tipCap", tipCap).Uint64("estimated_gas_units", estimatedGas).
+		c.logger.Info().Str("in_hash", tx.TxHash).Stringer("rate", gasRate).Stringer("baseFee", baseFee).
+			Stringer("feeCap", feeCap).Stringer("tipCap", tipCap).Uint64("estimated_gas_units", estimatedGas).
 			Msg("will send tx with dynamic fee")
 
 		to := ecommon.HexToAddress(c.cfg.BlockScanner.Mos)
@@ -512,7 +560,7 @@ func (c *Client) SignTx(tx stypes.TxOutItem, height int64) ([]byte, []byte, *sty
 			To:        &to,
 			Value:     big.NewInt(0),
 			Gas:       estimatedGas,
-			GasFeeCap: gasRate,
+			GasFeeCap: feeCap,
 			GasTipCap: tipCap,
 			Data:      data,
 		})
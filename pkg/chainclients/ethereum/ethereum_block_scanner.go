@@ -74,6 +74,7 @@ type ETHScanner struct {
 	eipSigner                                      etypes.Signer
 	currentBlockHeight, reqTime, cacheLatestHeight int64
 	gasCache                                       []*big.Int
+	baseFee                                        evm.BaseFeeTracker
 	solvencyReporter                               SolvencyReporter
 	whitelistTokens                                []tokenlist.ERC20Token
 	signerCacheManager                             *signercache.CacheManager
@@ -151,6 +152,11 @@ func (e *ETHScanner) GetGasPrice() *big.Int {
 	return e.gasPrice
 }
 
+// GetBaseFee returns the base fee of the latest scanned block, nil if it is not known yet
+func (e *ETHScanner) GetBaseFee() *big.Int {
+	return e.baseFee.BaseFee()
+}
+
 func (e *ETHScanner) getContext() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), e.cfg.HTTPRequestTimeout)
 }
@@ -334,6 +340,7 @@ func (e *ETHScanner) processBlock(block *etypes.Block, logs []etypes.Log) (stype
 		priorityFees = append(priorityFees, tipCap)
 	}
 	e.updateGasPrice(block.BaseFee(), priorityFees)
+	e.baseFee.Update(block.Header())
 
 	reorgedTxIns, err := e.processReorg(block.Header())
 	if err != nil {
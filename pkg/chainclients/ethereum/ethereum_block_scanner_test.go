@@ -38,20 +38,28 @@ func getConfigForTest() config.BifrostBlockScannerConfiguration {
 	}
 }
 
+// m is shared by the tests, the metrics can only be registered once
+var m *metrics.Metrics
+
 func GetMetricForTest() (*metrics.Metrics, error) {
-	return metrics.NewMetrics(config.BifrostMetricsConfiguration{
+	if m != nil {
+		return m, nil
+	}
+	var err error
+	m, err = metrics.NewMetrics(config.BifrostMetricsConfiguration{
 		Enabled:      false,
 		ListenPort:   9000,
 		ReadTimeout:  time.Second,
 		WriteTimeout: time.Second,
 		Chains:       common.Chains{common.ETHChain},
 	})
+	return m, err
 }
 
 func Test_Scanner(t *testing.T) {
 	cfg := getConfigForTest()
 
-	storage, err := blockscanner.NewBlockScannerStorage("./db", config.LevelDBOptions{})
+	storage, err := blockscanner.NewBlockScannerStorage(t.TempDir(), config.LevelDBOptions{})
 	assert.Nil(t, err)
 
 	ethClient, err := ethclient.Dial("https://eth-sepolia.blastapi.io/d741b6cb-21ce-42d7-a758-76983d6302aa")
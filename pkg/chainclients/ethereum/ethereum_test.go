@@ -1 +1,93 @@
 package ethereum
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/codec"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cryptocodec "github.com/cosmos/cosmos-sdk/crypto/codec"
+	"github.com/cosmos/cosmos-sdk/crypto/hd"
+	cKeys "github.com/cosmos/cosmos-sdk/crypto/keyring"
+	ekeystore "github.com/ethereum/go-ethereum/accounts/keystore"
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/internal/keys"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
+	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
+	"github.com/mapprotocol/compass-tss/pubkeymanager"
+)
+
+var update = flag.Bool("update-golden", false, "rewrite the golden files of the fixture scans")
+
+// scanBridge is the relay bridge of an offline scan, the scanner never calls it
+type scanBridge struct {
+	shareTypes.Bridge
+}
+
+// newScanClient creates a client sending its rpc traffic over transport, configured the
+// way the rescan command configures it
+func newScanClient(t *testing.T, host string, from int64, transport http.RoundTripper) *Client {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte("compass-tss ethereum test")))
+	require.NoError(t, err)
+	registry := codectypes.NewInterfaceRegistry()
+	cryptocodec.RegisterInterfaces(registry)
+	kb := cKeys.NewInMemory(codec.NewProtoCodec(registry))
+	require.NoError(t, kb.ImportPrivKeyHex("test-eth", ecommon.Bytes2Hex(crypto.FromECDSA(key)), string(hd.Secp256k1.Name())))
+	k := keys.NewKeysWithKeybase(kb, "test-eth", "", &ekeystore.Key{
+		Address:    crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey: key,
+	})
+
+	m, err := GetMetricForTest()
+	require.NoError(t, err)
+
+	var cfg config.BifrostChainConfiguration
+	cfg.ChainID = common.ETHChain
+	cfg.RPCHost = host
+	cfg.Transport = transport
+	cfg.BlockScanner = getConfigForTest()
+	cfg.BlockScanner.ChainID = common.ETHChain
+	cfg.BlockScanner.StartBlockHeight = from
+	cfg.BlockScanner.ObservationFlexibilityBlocks = -1
+	client, err := NewClient(k, cfg, nil, scanBridge{}, m, &pubkeymanager.MockPoolAddressValidator{})
+	require.NoError(t, err)
+	return client
+}
+
+func TestScanRangeGolden(t *testing.T) {
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*.fixture.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+	for _, path := range fixtures {
+		name := filepath.Base(path[:len(path)-len(".fixture.json")])
+		t.Run(name, func(t *testing.T) {
+			f, err := rpcpool.LoadFixture(path)
+			require.NoError(t, err)
+
+			// the host is never contacted, every request is answered from the fixture
+			client := newScanClient(t, "http://localhost:8545", f.From, rpcpool.NewReplayer(f).Transport(f.Chain))
+			txIns, err := client.ScanRange(f.From, f.To)
+			require.NoError(t, err)
+			got, err := json.MarshalIndent(txIns, "", "  ")
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", name+".golden.json")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, append(got, '\n'), 0o600))
+			}
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(got))
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
 	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm/types"
 )
 
@@ -151,12 +152,15 @@ func (c *Client) unstuckTx(clog zerolog.Logger, item types.SignedTxItem) error {
 	}
 
 	var cancelTx *etypes.Transaction
-	if c.cfg.BlockScanner.FixedGasRate == 0 {
+	baseFee := c.ethScanner.GetBaseFee()
+	if c.cfg.DynamicFee && c.cfg.BlockScanner.FixedGasRate == 0 && baseFee != nil {
 		to := ecommon.HexToAddress(address.String())
 
-		// tip cap at configured percentage of max fee
-		tipCap := new(big.Int).Mul(currentGasRate, big.NewInt(int64(c.cfg.MaxGasTipPercentage)))
-		tipCap.Div(tipCap, big.NewInt(100))
+		// the replacement must raise both the fee cap and the tip of the stuck transaction
+		feeCap, tipCap := evm.DynamicFee(baseFee, currentGasRate, c.cfg.MaxGasTipPercentage)
+		feeCap, tipCap = evm.BumpDynamicFee(tx, feeCap, tipCap)
+		clog.Info().Stringer("baseFee", baseFee).Stringer("feeCap", feeCap).Stringer("tipCap", tipCap).
+			Msg("cancel tx with dynamic fee")
 
 		cancelTx = etypes.NewTx(&etypes.DynamicFeeTx{
 			ChainID:   c.chainID,
@@ -164,7 +168,7 @@ func (c *Client) unstuckTx(clog zerolog.Logger, item types.SignedTxItem) error {
 			To:        &to,
 			Value:     big.NewInt(0),
 			Gas:       c.cfg.BlockScanner.MaxGasLimit,
-			GasFeeCap: currentGasRate,
+			GasFeeCap: feeCap,
 			GasTipCap: tipCap,
 		})
 	} else {
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"math/big"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/ethereum/go-ethereum"
@@ -20,12 +21,14 @@ import (
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/mapprotocol/compass-tss/blockscanner"
 	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/common/cosmos"
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
 	"github.com/mapprotocol/compass-tss/internal/keys"
 	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/runners"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/signercache"
 	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
@@ -46,8 +49,10 @@ type EVMClient struct {
 	logger                  zerolog.Logger
 	cfg                     config.BifrostChainConfiguration
 	localPubKey             common.PubKey
+	chainID                 *big.Int
 	kw                      *evm.KeySignWrapper
 	ethClient               *ethclient.Client
+	rpcPool                 *rpcpool.Pool
 	evmScanner              *EVMScanner
 	bridge                  shareTypes.Bridge
 	blockScanner            *blockscanner.BlockScanner
@@ -59,6 +64,7 @@ type EVMClient struct {
 	globalSolvencyQueue     chan stypes.Solvency
 	signerCacheManager      *signercache.CacheManager
 	lastSolvencyCheckHeight int64
+	submitter               *evm.SubmitterElection
 }
 
 // NewEVMClient creates a new EVMClient.
@@ -103,8 +109,16 @@ func NewEVMClient(
 
 	clog := log.With().Str("module", "evm").Stringer("chain", cfg.ChainID).Logger()
 
-	// create rpc client based on what authentication config is set
-	var ethClient *ethclient.Client
+	// create an endpoint pool over every configured rpc host, failing over between them
+	pool, err := rpcpool.NewPoolFromConfig(cfg, m)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create rpc pool: %w", err)
+	}
+	dialOpts := []rpc.ClientOption{
+		rpc.WithHTTPClient(pool.HTTPClient(cfg.BlockScanner.HTTPRequestTimeout)),
+	}
+
+	// authenticate the rpc client based on what authentication config is set
 	switch {
 	case cfg.AuthorizationBearer != "":
 		clog.Info().Msg("initializing evm client with bearer token")
@@ -112,16 +126,7 @@ func NewEVMClient(
 			h.Set("Authorization", fmt.Sprintf("Bearer %s", cfg.AuthorizationBearer))
 			return nil
 		}
-		var rpcClient *rpc.Client
-		rpcClient, err = rpc.DialOptions(
-			context.Background(),
-			cfg.RPCHost,
-			rpc.WithHTTPAuth(authFn),
-		)
-		if err != nil {
-			return nil, err
-		}
-		ethClient = ethclient.NewClient(rpcClient)
+		dialOpts = append(dialOpts, rpc.WithHTTPAuth(authFn))
 
 	case cfg.UserName != "" && cfg.Password != "":
 		clog.Info().Msg("initializing evm client with http basic auth")
@@ -131,23 +136,15 @@ func NewEVMClient(
 			h.Set("Authorization", fmt.Sprintf("Basic %s", auth))
 			return nil
 		}
-		var rpcClient *rpc.Client
-		rpcClient, err = rpc.DialOptions(
-			context.Background(),
-			cfg.RPCHost,
-			rpc.WithHTTPAuth(authFn),
-		)
-		if err != nil {
-			return nil, err
-		}
-		ethClient = ethclient.NewClient(rpcClient)
+		dialOpts = append(dialOpts, rpc.WithHTTPAuth(authFn))
+	}
 
-	default:
-		ethClient, err = ethclient.Dial(cfg.RPCHost)
-		if err != nil {
-			return nil, fmt.Errorf("fail to dial ETH rpc host(%s): %w", cfg.RPCHost, err)
-		}
+	dialed, err := rpc.DialOptions(context.Background(), pool.URL(), dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("fail to dial ETH rpc host(%s): %w", cfg.RPCHost, err)
 	}
+	ethClient := ethclient.NewClient(dialed)
+	clog.Info().Int("endpoints", pool.Size()).Int("quorum", pool.Quorum()).Msg("initialized rpc pool")
 
 	rpcClient, err := evm.NewEthRPC(
 		ethClient,
@@ -157,6 +154,7 @@ func NewEVMClient(
 	if err != nil {
 		return nil, fmt.Errorf("fail to create ETH rpc host(%s): %w", cfg.RPCHost, err)
 	}
+	rpcClient.SetPool(pool)
 
 	// get chain id
 	chainID, err := getChainID(ethClient, cfg.BlockScanner.HTTPRequestTimeout)
@@ -183,7 +181,9 @@ func NewEVMClient(
 		logger:       clog,
 		cfg:          cfg,
 		ethClient:    ethClient,
+		rpcPool:      pool,
 		localPubKey:  pk,
+		chainID:      chainID,
 		kw:           keysignWrapper,
 		bridge:       bridge,
 		gatewayAbi:   vaultABI,
@@ -191,6 +191,7 @@ func NewEVMClient(
 		tssKeySigner: tssKm,
 		wg:           &sync.WaitGroup{},
 		stopchan:     make(chan struct{}),
+		submitter:    evm.NewSubmitterElection(bridge, pk, cfg.SubmitterTakeoverBlocks),
 	}
 
 	// initialize storage
@@ -274,6 +275,16 @@ func (c *EVMClient) IsBlockScannerHealthy() bool {
 	return c.blockScanner.IsHealthy()
 }
 
+// Rescan scans the given range of blocks again
+func (c *EVMClient) Rescan(from, to int64) error {
+	return c.blockScanner.Rescan(from, to)
+}
+
+// ScanRange fetches the txs of the given range of blocks without sending them to the observer
+func (c *EVMClient) ScanRange(from, to int64) ([]stypes.TxIn, error) {
+	return c.blockScanner.ScanRange(from, to)
+}
+
 // --------------------------------- config ---------------------------------
 
 // GetConfig returns the chain configuration.
@@ -371,8 +382,16 @@ func (c *EVMClient) buildOutboundTx(txOutItem stypes.TxOutItem, nonce uint64) (*
 		}
 	}
 
+	// dynamic fee transactions need the base fee, until a block is scanned send a legacy one
+	var feeCap, tipCap *big.Int
+	baseFee := c.evmScanner.GetBaseFee()
+	dynamicFee := c.cfg.DynamicFee && c.cfg.BlockScanner.FixedGasRate == 0 && baseFee != nil
+	if dynamicFee {
+		feeCap, tipCap = evm.DynamicFee(baseFee, gasRate, c.cfg.MaxGasTipPercentage)
+	}
+
 	// outbound tx always send to smart contract address
-	createdTx := etypes.NewTransaction(nonce, ecommon.HexToAddress(c.cfg.BlockScanner.Mos), big.NewInt(0), c.cfg.BlockScanner.MaxGasLimit, gasRate, txData)
+	createdTx := c.newOutboundTx(nonce, c.cfg.BlockScanner.MaxGasLimit, gasRate, feeCap, tipCap, txData)
 	estimatedGas, err := c.evmScanner.ethRpc.EstimateGas(fromAddr.String(), createdTx)
 	if err != nil {
 		c.logger.Error().Any("err", err).Str("relayHash", txOutItem.TxHash).Str("input", ecommon.Bytes2Hex(createdTx.Data())).
@@ -396,18 +415,35 @@ func (c *EVMClient) buildOutboundTx(txOutItem stypes.TxOutItem, nonce uint64) (*
 	estimatedFee := big.NewInt(int64(estimatedGas))
 	estimatedFee.Mul(estimatedFee, gasRate)
 
-	createdTx = etypes.NewTransaction(
-		nonce,
-		ecommon.HexToAddress(c.cfg.BlockScanner.Mos),
-		big.NewInt(0),
-		estimatedGas,
-		gasRate,
-		txData,
-	)
+	if dynamicFee {
+		c.logger.Info().Str("relayHash", txOutItem.TxHash).Stringer("rate", gasRate).Stringer("baseFee", baseFee).
+			Stringer("feeCap", feeCap).Stringer("tipCap", tipCap).Uint64("estimatedGasUnits", estimatedGas).
+			Msg("will send tx with dynamic fee")
+	}
+	createdTx = c.newOutboundTx(nonce, estimatedGas, gasRate, feeCap, tipCap, txData)
 
 	return createdTx, nil
 }
 
+// newOutboundTx creates a transaction calling the mos contract, a dynamic fee one when
+// feeCap is set and a legacy one paying gasRate otherwise
+func (c *EVMClient) newOutboundTx(nonce, gas uint64, gasRate, feeCap, tipCap *big.Int, data []byte) *etypes.Transaction {
+	to := ecommon.HexToAddress(c.cfg.BlockScanner.Mos)
+	if feeCap == nil {
+		return etypes.NewTransaction(nonce, to, big.NewInt(0), gas, gasRate, data)
+	}
+	return etypes.NewTx(&etypes.DynamicFeeTx{
+		ChainID:   c.chainID,
+		Nonce:     nonce,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Gas:       gas,
+		GasFeeCap: feeCap, // maxFeePerGas
+		GasTipCap: tipCap, // maxPriorityFeePerGas
+		Data:      data,
+	})
+}
+
 func (c *EVMClient) calGasLimit(estimatedGas, min, max uint64, limitMultiplier int) uint64 {
 	mulResult := big.NewInt(0).Mul(big.NewInt(int64(estimatedGas)), big.NewInt(int64(limitMultiplier)))
 	if mulResult.Uint64() < min {
@@ -423,17 +459,38 @@ func (c *EVMClient) calGasLimit(estimatedGas, min, max uint64, limitMultiplier i
 
 // GetAddress returns the address for the given public key.
 func (c *EVMClient) GetAddress(poolPubKey common.PubKey) string {
-	return ""
+	addr, err := poolPubKey.GetAddress(c.cfg.ChainID)
+	if err != nil {
+		c.logger.Error().Err(err).Str("pubkey", poolPubKey.String()).Msg("fail to get address")
+		return ""
+	}
+	return addr.String()
 }
 
 // GetAccount returns the account for the given public key.
 func (c *EVMClient) GetAccount(pk common.PubKey, height *big.Int) (common.Account, error) {
-	return common.Account{}, nil
+	addr, err := pk.GetAddress(c.cfg.ChainID)
+	if err != nil {
+		return common.Account{}, fmt.Errorf("fail to get address of %s: %w", pk, err)
+	}
+	return c.GetAccountByAddress(addr.String(), height)
 }
 
-// GetAccountByAddress returns the account for the given address.
+// GetAccountByAddress returns the account for the given address, with the balance of the
+// gas asset only.
 func (c *EVMClient) GetAccountByAddress(address string, height *big.Int) (common.Account, error) {
-	return common.Account{}, nil
+	nonce, err := c.evmScanner.GetNonce(address)
+	if err != nil {
+		return common.Account{}, err
+	}
+	balance, err := c.evmScanner.ethRpc.GetBalance(address, height)
+	if err != nil {
+		return common.Account{}, err
+	}
+	// the gas asset has 18 decimals, accounts are reported with 1e8 precision
+	amount := cosmos.NewUintFromBigInt(balance).QuoUint64(common.One * 100)
+	coins := common.Coins{common.NewCoin(c.cfg.ChainID.GetGasAsset(), amount)}
+	return common.NewAccount(int64(nonce), 0, coins, false), nil
 }
 
 // --------------------------------- sign ---------------------------------
@@ -460,6 +517,11 @@ func (c *EVMClient) SignTx(tx stypes.TxOutItem, height int64) ([]byte, []byte, *
 		return nil, nil, nil, constants.ErrorOfOrderExecuted
 	}
 
+	// only the elected node submits, the others take over if it doesn't land in time
+	if !c.submitter.IsMyTurn(tx.OrderId, height) {
+		return nil, nil, nil, constants.ErrorOfNotSubmitter
+	}
+
 	// the nonce is stored as the transaction checkpoint, if it is set deserialize it
 	// so we only retry with the same nonce to avoid double spend
 	var (
@@ -535,16 +597,19 @@ func (c *EVMClient) OrderExecuted(orderId ecommon.Hash) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	var isExecuted bool
-	err = c.callContract(&isExecuted, c.cfg.BlockScanner.Mos, method, input, c.gatewayAbi)
-	if err != nil {
-		return false, err
-	}
-	return isExecuted, nil
+	// an executed order suppresses signing, so require the configured quorum to agree
+	return rpcpool.QuorumRead(context.Background(), c.rpcPool, func(ctx context.Context) (bool, error) {
+		var isExecuted bool
+		err := c.callContract(ctx, &isExecuted, c.cfg.BlockScanner.Mos, method, input, c.gatewayAbi)
+		return isExecuted, err
+	}, func(executed bool) string {
+		return strconv.FormatBool(executed)
+	})
 }
-func (c *EVMClient) callContract(ret interface{}, addr, method string, input []byte, abi *abi.ABI) error {
+
+func (c *EVMClient) callContract(ctx context.Context, ret interface{}, addr, method string, input []byte, abi *abi.ABI) error {
 	to := ecommon.HexToAddress(addr)
-	outPut, err := c.ethClient.CallContract(context.Background(), ethereum.CallMsg{
+	outPut, err := c.ethClient.CallContract(ctx, ethereum.CallMsg{
 		From: constants.ZeroAddress,
 		To:   &to,
 		Data: input,
@@ -56,6 +56,7 @@ type EVMScanner struct {
 	eipSigner             etypes.Signer
 	currentBlockHeight    int64
 	gasCache              []*big.Int
+	baseFee               evm.BaseFeeTracker
 	solvencyReporter      SolvencyReporter
 	signerCacheManager    *signercache.CacheManager
 	gatewayABI, erc20ABI  *abi.ABI
@@ -138,6 +139,11 @@ func (e *EVMScanner) GetGasPrice() *big.Int {
 	return e.gasPrice
 }
 
+// GetBaseFee returns the base fee of the latest scanned block, nil if it is not known yet
+func (e *EVMScanner) GetBaseFee() *big.Int {
+	return e.baseFee.BaseFee()
+}
+
 // GetNetworkFee returns current chain network fee according to Bifrost.
 func (e *EVMScanner) GetNetworkFee() (transactionSize, txSwapGasLimit, transactionFeeRate uint64) {
 	return e.cfg.MaxGasLimit, e.cfg.MaxSwapGasLimit, e.lastReportedGasPrice
@@ -260,6 +266,7 @@ func (e *EVMScanner) processBlock(block *evm.Block, logs []etypes.Log, skip bool
 			txsGas = append(txsGas, gas)
 		}
 		e.updateGasPrice(txsGas)
+		e.baseFee.Update(block.Header)
 
 		// process reorg if possible on this chain
 		if e.cfg.MaxReorgRescanBlocks > 0 {
@@ -57,7 +57,7 @@ func Test_Scanner(t *testing.T) {
 	cfg := getConfigForNativeTest()
 	os.Setenv("KEYSTORE_PASSWORD", "123456")
 
-	storage, err := blockscanner.NewBlockScannerStorage("./db", config.LevelDBOptions{})
+	storage, err := blockscanner.NewBlockScannerStorage(t.TempDir(), config.LevelDBOptions{})
 	assert.Nil(t, err)
 
 	ethClient, err := ethclient.Dial("https://bsc-prebsc-dataseed.bnbchain.org")
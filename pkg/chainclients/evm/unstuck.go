@@ -15,6 +15,7 @@ import (
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
 	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
 	evmtypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm/types"
 )
 
@@ -153,14 +154,25 @@ func (c *EVMClient) unstuckTx(clog zerolog.Logger, item evmtypes.SignedTxItem) e
 	}
 
 	// create the cancel transaction
-	canceltx := etypes.NewTransaction(
-		tx.Nonce(),
-		ecommon.HexToAddress(address.String()),
-		big.NewInt(0),
-		c.cfg.BlockScanner.MaxGasLimit,
-		currentGasRate,
-		nil,
-	)
+	to := ecommon.HexToAddress(address.String())
+	canceltx := etypes.NewTransaction(tx.Nonce(), to, big.NewInt(0), c.cfg.BlockScanner.MaxGasLimit, currentGasRate, nil)
+	baseFee := c.evmScanner.GetBaseFee()
+	if c.cfg.DynamicFee && c.cfg.BlockScanner.FixedGasRate == 0 && baseFee != nil {
+		// the replacement must raise both the fee cap and the tip of the stuck transaction
+		feeCap, tipCap := evm.DynamicFee(baseFee, currentGasRate, c.cfg.MaxGasTipPercentage)
+		feeCap, tipCap = evm.BumpDynamicFee(tx, feeCap, tipCap)
+		clog.Info().Stringer("baseFee", baseFee).Stringer("feeCap", feeCap).Stringer("tipCap", tipCap).
+			Msg("cancel tx with dynamic fee")
+		canceltx = etypes.NewTx(&etypes.DynamicFeeTx{
+			ChainID:   c.chainID,
+			Nonce:     tx.Nonce(),
+			To:        &to,
+			Value:     big.NewInt(0),
+			Gas:       c.cfg.BlockScanner.MaxGasLimit,
+			GasFeeCap: feeCap,
+			GasTipCap: tipCap,
+		})
+	}
 	rawBytes, err := c.kw.Sign(canceltx, pubKey)
 	if err != nil {
 		return fmt.Errorf("fail to sign tx for cancelling with nonce: %d, err: %w", tx.Nonce(), err)
@@ -1,6 +1,7 @@
 package chainclients
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/mapprotocol/compass-tss/internal/keys"
@@ -23,6 +24,60 @@ import (
 // ChainClient exports the shared type.
 type ChainClient = types.ChainClient
 
+// clientConstructor creates the chain client of a chain
+type clientConstructor func(relayKeys *keys.Keys,
+	cfg config.BifrostChainConfiguration,
+	server *tss.TssServer,
+	bridge shareTypes.Bridge,
+	m *metrics.Metrics,
+	pubKeyValidator pubkeymanager.PubKeyValidator,
+) (ChainClient, error)
+
+// clientConstructors maps the client kind of the chain registry to its constructor
+var clientConstructors = map[common.ChainClientKind]clientConstructor{
+	common.ChainClientEthereum: func(relayKeys *keys.Keys, cfg config.BifrostChainConfiguration, server *tss.TssServer,
+		bridge shareTypes.Bridge, m *metrics.Metrics, pubKeyValidator pubkeymanager.PubKeyValidator,
+	) (ChainClient, error) {
+		return ethereum.NewClient(relayKeys, cfg, server, bridge, m, pubKeyValidator)
+	},
+	common.ChainClientEVM: func(relayKeys *keys.Keys, cfg config.BifrostChainConfiguration, server *tss.TssServer,
+		bridge shareTypes.Bridge, m *metrics.Metrics, pubKeyValidator pubkeymanager.PubKeyValidator,
+	) (ChainClient, error) {
+		return evm.NewEVMClient(relayKeys, cfg, server, bridge, m, pubKeyValidator)
+	},
+	common.ChainClientUTXO: func(relayKeys *keys.Keys, cfg config.BifrostChainConfiguration, server *tss.TssServer,
+		bridge shareTypes.Bridge, m *metrics.Metrics, _ pubkeymanager.PubKeyValidator,
+	) (ChainClient, error) {
+		return utxo.NewClient(relayKeys, cfg, server, bridge, m)
+	},
+	common.ChainClientXRP: func(relayKeys *keys.Keys, cfg config.BifrostChainConfiguration, server *tss.TssServer,
+		bridge shareTypes.Bridge, m *metrics.Metrics, _ pubkeymanager.PubKeyValidator,
+	) (ChainClient, error) {
+		return xrp.NewClient(relayKeys, cfg, server, bridge, m)
+	},
+	common.ChainClientTron: func(relayKeys *keys.Keys, cfg config.BifrostChainConfiguration, server *tss.TssServer,
+		bridge shareTypes.Bridge, m *metrics.Metrics, _ pubkeymanager.PubKeyValidator,
+	) (ChainClient, error) {
+		return tron.NewTronClient(relayKeys, cfg, server, bridge, m)
+	},
+}
+
+// NewChainClient creates the client of a single chain, for the tools that work on one chain
+func NewChainClient(relayKeys *keys.Keys,
+	cfg config.BifrostChainConfiguration,
+	server *tss.TssServer,
+	bridge shareTypes.Bridge,
+	m *metrics.Metrics,
+	pubKeyValidator pubkeymanager.PubKeyValidator,
+) (ChainClient, error) {
+	info, _ := common.GetChainInfo(cfg.ChainID)
+	newClient, ok := clientConstructors[info.Client]
+	if !ok {
+		return nil, fmt.Errorf("chain %s is not supported", cfg.ChainID)
+	}
+	return newClient(relayKeys, cfg, server, bridge, m, pubKeyValidator)
+}
+
 // LoadChains returns chain clients from chain configuration
 func LoadChains(relayKeys *keys.Keys,
 	cfg map[common.Chain]config.BifrostChainConfiguration,
@@ -38,24 +93,13 @@ func LoadChains(relayKeys *keys.Keys,
 	failedChains := []common.Chain{}
 
 	loadChain := func(chain config.BifrostChainConfiguration) (ChainClient, error) {
-		switch chain.ChainID {
-		case common.ETHChain:
-			return ethereum.NewClient(relayKeys, chain, server, bridge, m, pubKeyValidator)
-		case common.BSCChain, common.BASEChain, common.ARBChain, common.OPTChain,
-			common.UNIChain, common.AVAXChain, common.XLAYERChain, common.POLChain:
-			return evm.NewEVMClient(relayKeys, chain, server, bridge, m, pubKeyValidator)
-		//case common.GAIAChain:
-		//	return gaia.NewCosmosClient(thorKeys, chain, server, thorchainBridge, m)
-		case common.BTCChain, common.DOGEChain:
-			return utxo.NewClient(relayKeys, chain, server, bridge, m)
-		case common.XRPChain:
-			return xrp.NewClient(relayKeys, chain, server, bridge, m)
-		case common.TRONChain:
-			return tron.NewTronClient(relayKeys, chain, server, bridge, m)
-		default:
+		info, _ := common.GetChainInfo(chain.ChainID)
+		newClient, ok := clientConstructors[info.Client]
+		if !ok {
 			log.Fatal().Msgf("chain %s is not supported", chain.ChainID)
 			return nil, nil
 		}
+		return newClient(relayKeys, chain, server, bridge, m, pubKeyValidator)
 	}
 
 	for _, chain := range cfg {
@@ -72,8 +116,7 @@ func LoadChains(relayKeys *keys.Keys,
 		}
 
 		// trunk-ignore-all(golangci-lint/forcetypeassert)
-		switch chain.ChainID {
-		case common.BTCChain, common.BCHChain, common.LTCChain, common.DOGEChain:
+		if chain.ChainID.IsUTXO() {
 			pubKeyValidator.RegisterCallback(client.(*utxo.Client).RegisterPublicKey)
 		}
 		chains[chain.ChainID] = client
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"reflect"
 	"strings"
 	"sync"
@@ -16,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/mapprotocol/compass-tss/common"
 	"github.com/mapprotocol/compass-tss/config"
@@ -82,10 +84,17 @@ func NewBridge(cfg config.BifrostClientConfiguration, m *metrics.Metrics, k *key
 
 	httpClient := retryablehttp.NewClient()
 	httpClient.Logger = nil
-	ethClient, err := ethclient.Dial(cfg.ChainHost)
+	// dial over the configured transport, so the traffic can be recorded and replayed
+	transport := cfg.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	c, err := rpc.DialOptions(context.Background(), cfg.ChainHost,
+		rpc.WithHTTPClient(&http.Client{Transport: transport}))
 	if err != nil {
 		return nil, fmt.Errorf("fail to dial map rpc host(%s): %w", cfg.ChainHost, err)
 	}
+	ethClient := ethclient.NewClient(c)
 
 	chainID, err := getChainID(ethClient, time.Second*5)
 	if err != nil {
@@ -245,6 +245,48 @@ func (b *Bridge) FetchActiveNodes() ([]common.PubKey, error) {
 	return active, nil
 }
 
+// GetMaintainerEpochs retrieves the current epoch and the epoch in election from mapBridge,
+// the election epoch is zero when no election is in progress
+func (b *Bridge) GetMaintainerEpochs() (*big.Int, *big.Int, error) {
+	epochs := make([]*big.Int, 0, 2)
+	for _, method := range []string{constants.CurrentEpoch, constants.ElectionEpoch} {
+		input, err := b.mainAbi.Pack(method)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "fail to pack input")
+		}
+		var epoch *big.Int
+		if err = b.callContract(&epoch, b.cfg.Maintainer, method, input, b.mainAbi); err != nil {
+			return nil, nil, errors.Wrap(err, "fail to call contract")
+		}
+		epochs = append(epochs, epoch)
+	}
+	return epochs[0], epochs[1], nil
+}
+
+// GetEpochPubKeys retrieves the compressed secp256k1 pubkeys of the maintainers of the given epoch
+func (b *Bridge) GetEpochPubKeys(epoch *big.Int) ([]common.PubKey, error) {
+	info, err := b.GetEpochInfo(epoch)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.Maintainers) == 0 {
+		return nil, nil
+	}
+	na, err := b.GetNodeAccounts(info.Maintainers)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get node accounts: %w", err)
+	}
+	pubKeys := make([]common.PubKey, 0, len(na))
+	for _, item := range na {
+		epk, err := ecrypto.UnmarshalPubkey(append([]byte{4}, item.Secp256Pubkey...))
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ECDSA public key of %s: %w", item.Account, err)
+		}
+		pubKeys = append(pubKeys, common.PubKey(ecommon.Bytes2Hex(ecrypto.CompressPubkey(epk))))
+	}
+	return pubKeys, nil
+}
+
 func (b *Bridge) genHash(epoch *big.Int, members []ecommon.Address, electedBlock uint64) (ecommon.Hash, error) {
 	memberStrs := make([]string, 0, len(members))
 	for _, item := range members {
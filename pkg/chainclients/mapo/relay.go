@@ -39,10 +39,29 @@ func (b *Bridge) GetTxByBlockNumber(blockHeight int64) (types.TxOut, error) {
 		b.logger.Error().Err(err).Int64("height", blockHeight).Msg("Failed to search tx in block")
 		return types.TxOut{}, fmt.Errorf("failed to process block: %d, err:%w", blockHeight, err)
 	}
-	// done
+	defer func() {
+		b.blockHeight = blockHeight
+	}()
+	txOuts, err := b.getTxOuts(blockHeight, blockHeight)
+	if err != nil || len(txOuts) == 0 {
+		return types.TxOut{}, err
+	}
+	return txOuts[0], nil
+}
+
+// GetTxOutsInRange retrieves the txouts of the relay blocks from and to inclusive, a
+// txout per block with any. Unlike GetTxByBlockNumber it neither updates the gas price
+// nor the scanned height.
+func (b *Bridge) GetTxOutsInRange(from, to int64) ([]types.TxOut, error) {
+	return b.getTxOuts(from, to)
+}
+
+// getTxOuts returns a txout per block with bridge events from and to inclusive, in
+// block order
+func (b *Bridge) getTxOuts(from, to int64) ([]types.TxOut, error) {
 	logs, err := b.getFilterLogs(ethereum.FilterQuery{
-		FromBlock: big.NewInt(blockHeight),
-		ToBlock:   big.NewInt(blockHeight),
+		FromBlock: big.NewInt(from),
+		ToBlock:   big.NewInt(to),
 		Addresses: []ecommon.Address{ecommon.HexToAddress(b.cfg.Relay)}, // done
 		Topics: [][]ecommon.Hash{{
 			constants.EventOfBridgeRelay.GetTopic(),
@@ -50,21 +69,15 @@ func (b *Bridge) GetTxByBlockNumber(blockHeight int64) (types.TxOut, error) {
 			constants.EventOfBridgeRelaySigned.GetTopic(),
 		}},
 	})
-	defer func() {
-		b.blockHeight = blockHeight
-	}()
 	if err != nil {
-		return types.TxOut{}, err
+		return nil, err
 	}
 	if len(logs) == 0 {
-		return types.TxOut{}, nil
+		return nil, nil
 	}
-	b.logger.Info().Msgf("Find tx blockHeight=%v, logs=%d", blockHeight, len(logs))
+	b.logger.Info().Msgf("Find tx blockHeight=%v-%v, logs=%d", from, to, len(logs))
 
-	ret := types.TxOut{
-		Height:  blockHeight,
-		TxArray: make([]types.TxArrayItem, 0, len(logs)),
-	}
+	var ret []types.TxOut
 	mapCId, _ := common.MAPChain.ChainID()
 
 	// handler parse coins & gas
@@ -84,7 +97,11 @@ func (b *Bridge) GetTxByBlockNumber(blockHeight int64) (types.TxOut, error) {
 			continue
 		}
 
-		ret.TxArray = append(ret.TxArray, *item)
+		height := int64(ele.BlockNumber)
+		if len(ret) == 0 || ret[len(ret)-1].Height != height {
+			ret = append(ret, types.TxOut{Height: height})
+		}
+		ret[len(ret)-1].TxArray = append(ret[len(ret)-1].TxArray, *item)
 	}
 
 	return ret, nil
@@ -17,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/rpc"
 	btypes "github.com/mapprotocol/compass-tss/blockscanner/types"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -24,6 +25,7 @@ import (
 // EthRPC is a struct that interacts with an ETH RPC compatible blockchain
 type EthRPC struct {
 	client  *ethclient.Client
+	pool    *rpcpool.Pool
 	timeout time.Duration
 	logger  zerolog.Logger
 }
@@ -36,6 +38,12 @@ func NewEthRPC(client *ethclient.Client, timeout time.Duration, chain string) (*
 	}, nil
 }
 
+// SetPool sets the rpc endpoint pool backing the client. When the pool has quorum
+// reads enabled, block headers and receipts must be agreed on by the quorum.
+func (e *EthRPC) SetPool(pool *rpcpool.Pool) {
+	e.pool = pool
+}
+
 func (e *EthRPC) getContext() (context.Context, context.CancelFunc) {
 	return context.WithTimeout(context.Background(), e.timeout)
 }
@@ -56,13 +64,21 @@ func (e *EthRPC) EstimateGas(from string, tx *etypes.Transaction) (uint64, error
 func (e *EthRPC) GetReceipt(hash string) (*etypes.Receipt, error) {
 	ctx, cancel := e.getContext()
 	defer cancel()
-	return e.client.TransactionReceipt(ctx, ecommon.HexToHash(hash))
+	return rpcpool.QuorumRead(ctx, e.pool, func(ctx context.Context) (*etypes.Receipt, error) {
+		return e.client.TransactionReceipt(ctx, ecommon.HexToHash(hash))
+	}, func(r *etypes.Receipt) string {
+		return fmt.Sprintf("%s/%d", r.BlockHash.Hex(), r.Status)
+	})
 }
 
 func (e *EthRPC) GetHeader(height int64) (*etypes.Header, error) {
 	ctx, cancel := e.getContext()
 	defer cancel()
-	return e.client.HeaderByNumber(ctx, big.NewInt(height))
+	return rpcpool.QuorumRead(ctx, e.pool, func(ctx context.Context) (*etypes.Header, error) {
+		return e.client.HeaderByNumber(ctx, big.NewInt(height))
+	}, func(h *etypes.Header) string {
+		return h.Hash().Hex()
+	})
 }
 
 func (e *EthRPC) GetBlockHeight() (int64, error) {
@@ -115,6 +131,17 @@ func (e *EthRPC) GetNonce(addr string) (uint64, error) {
 	return nonce, nil
 }
 
+// GetBalance gets the native balance of an address in wei, at the latest block when height is nil.
+func (e *EthRPC) GetBalance(addr string, height *big.Int) (*big.Int, error) {
+	ctx, cancel := e.getContext()
+	defer cancel()
+	balance, err := e.client.BalanceAt(ctx, ecommon.HexToAddress(addr), height)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get account balance: %w", err)
+	}
+	return balance, nil
+}
+
 // GetNonceFinalized gets the nonce excluding pending transactions.
 func (e *EthRPC) GetNonceFinalized(addr string) (uint64, error) {
 	ctx, cancel := e.getContext()
@@ -0,0 +1,93 @@
+package evm
+
+import (
+	"math/big"
+	"sync"
+
+	etypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+// baseFeeHeadroom is how many times the latest base fee the fee cap of a dynamic fee
+// transaction covers, so it stays includable while the base fee rises for a few blocks
+const baseFeeHeadroom = 2
+
+// BaseFeeTracker keeps the base fee of the latest scanned block header
+type BaseFeeTracker struct {
+	lock    sync.RWMutex
+	height  int64
+	baseFee *big.Int
+}
+
+// Update records the base fee of the given header, headers without a base fee (pre
+// london or legacy only chains) and headers older than the latest one are ignored
+func (t *BaseFeeTracker) Update(header *etypes.Header) {
+	if header == nil || header.BaseFee == nil || header.Number == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.baseFee != nil && header.Number.Int64() < t.height {
+		return
+	}
+	t.height = header.Number.Int64()
+	t.baseFee = new(big.Int).Set(header.BaseFee)
+}
+
+// BaseFee returns the latest base fee, nil if no header with a base fee was seen yet
+func (t *BaseFeeTracker) BaseFee() *big.Int {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	if t.baseFee == nil {
+		return nil
+	}
+	return new(big.Int).Set(t.baseFee)
+}
+
+// DynamicFee returns the fee cap and the tip cap of a dynamic fee transaction for the given
+// base fee and gas rate, the rate a legacy transaction would pay. The tip is what the rate
+// pays above the base fee, limited to maxTipPercentage of the fee cap when it is positive.
+func DynamicFee(baseFee, gasRate *big.Int, maxTipPercentage int) (feeCap, tipCap *big.Int) {
+	tipCap = new(big.Int).Sub(gasRate, baseFee)
+	if tipCap.Sign() < 0 {
+		tipCap.SetInt64(0)
+	}
+	feeCap = new(big.Int).Mul(baseFee, big.NewInt(baseFeeHeadroom))
+	feeCap.Add(feeCap, tipCap)
+	if feeCap.Cmp(gasRate) < 0 {
+		feeCap.Set(gasRate)
+	}
+	if maxTipPercentage > 0 {
+		maxTip := new(big.Int).Mul(feeCap, big.NewInt(int64(maxTipPercentage)))
+		maxTip.Div(maxTip, big.NewInt(100))
+		if tipCap.Cmp(maxTip) > 0 {
+			tipCap = maxTip
+		}
+	}
+	return feeCap, tipCap
+}
+
+// BumpDynamicFee returns the fee cap and tip cap to replace the given pending transaction,
+// a replacement must raise both of them by at least 10% to be accepted by the mempool. A
+// legacy transaction is replaced as if both caps were its gas price.
+func BumpDynamicFee(tx *etypes.Transaction, feeCap, tipCap *big.Int) (*big.Int, *big.Int) {
+	feeCap = maxBig(feeCap, inflate(tx.GasFeeCap()))
+	tipCap = maxBig(tipCap, inflate(tx.GasTipCap()))
+	if tipCap.Cmp(feeCap) > 0 {
+		feeCap = new(big.Int).Set(tipCap)
+	}
+	return feeCap, tipCap
+}
+
+// inflate returns 110% of the given value, rounded up
+func inflate(v *big.Int) *big.Int {
+	result := new(big.Int).Mul(v, big.NewInt(11))
+	result.Add(result, big.NewInt(9))
+	return result.Div(result, big.NewInt(10))
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return new(big.Int).Set(a)
+	}
+	return new(big.Int).Set(b)
+}
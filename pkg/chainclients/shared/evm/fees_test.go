@@ -0,0 +1,122 @@
+package evm
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	etypes "github.com/ethereum/go-ethereum/core/types"
+)
+
+func loadHeader(t *testing.T, name string) *etypes.Header {
+	t.Helper()
+	buf, err := os.ReadFile("test/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var header etypes.Header
+	if err = json.Unmarshal(buf, &header); err != nil {
+		t.Fatal(err)
+	}
+	return &header
+}
+
+func gwei(v int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(v), big.NewInt(1e9))
+}
+
+func TestBaseFeeTracker(t *testing.T) {
+	var tracker BaseFeeTracker
+	tracker.Update(loadHeader(t, "header_legacy.json"))
+	if tracker.BaseFee() != nil {
+		t.Fatal("legacy header should not set a base fee")
+	}
+
+	tracker.Update(loadHeader(t, "header_london.json"))
+	if got := tracker.BaseFee(); got == nil || got.Cmp(gwei(30)) != 0 {
+		t.Fatalf("expected base fee of 30 gwei, got %s", got)
+	}
+	next := loadHeader(t, "header_london_next.json")
+	tracker.Update(next)
+	if got := tracker.BaseFee(); got.Cmp(next.BaseFee) != 0 {
+		t.Fatalf("expected base fee %s, got %s", next.BaseFee, got)
+	}
+	// an older header doesn't override the latest base fee
+	tracker.Update(loadHeader(t, "header_london.json"))
+	if got := tracker.BaseFee(); got.Cmp(next.BaseFee) != 0 {
+		t.Fatalf("older header overrode the base fee: %s", got)
+	}
+	// the returned value is a copy
+	tracker.BaseFee().SetInt64(0)
+	if tracker.BaseFee().Sign() == 0 {
+		t.Fatal("base fee was modified through the returned value")
+	}
+}
+
+func TestDynamicFee(t *testing.T) {
+	baseFee := loadHeader(t, "header_london.json").BaseFee
+	testCases := []struct {
+		name       string
+		gasRate    *big.Int
+		percentage int
+		feeCap     *big.Int
+		tipCap     *big.Int
+	}{
+		{name: "rate above base fee", gasRate: gwei(32), feeCap: gwei(62), tipCap: gwei(2)},
+		{name: "rate below base fee", gasRate: gwei(20), feeCap: gwei(60), tipCap: gwei(0)},
+		{name: "rate above the headroom", gasRate: gwei(100), feeCap: gwei(130), tipCap: gwei(70)},
+		{name: "tip limited", gasRate: gwei(100), percentage: 10, feeCap: gwei(130), tipCap: gwei(13)},
+		{name: "tip under the limit", gasRate: gwei(32), percentage: 10, feeCap: gwei(62), tipCap: gwei(2)},
+	}
+	for _, tc := range testCases {
+		feeCap, tipCap := DynamicFee(baseFee, tc.gasRate, tc.percentage)
+		if feeCap.Cmp(tc.feeCap) != 0 || tipCap.Cmp(tc.tipCap) != 0 {
+			t.Errorf("%s: expected fee cap %s and tip %s, got %s and %s", tc.name, tc.feeCap, tc.tipCap, feeCap, tipCap)
+		}
+		if tipCap.Cmp(feeCap) > 0 {
+			t.Errorf("%s: tip %s above fee cap %s", tc.name, tipCap, feeCap)
+		}
+		// the fee cap always covers the base fee of the next block, which can grow by 12.5%
+		next := new(big.Int).Add(baseFee, new(big.Int).Div(baseFee, big.NewInt(8)))
+		if new(big.Int).Sub(feeCap, tipCap).Cmp(next) < 0 {
+			t.Errorf("%s: fee cap %s doesn't cover the next base fee %s", tc.name, feeCap, next)
+		}
+	}
+}
+
+func TestBumpDynamicFee(t *testing.T) {
+	to := ecommon.HexToAddress("0x1")
+	pending := etypes.NewTx(&etypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     1,
+		GasTipCap: gwei(2),
+		GasFeeCap: gwei(62),
+		Gas:       21000,
+		To:        &to,
+	})
+
+	// the fees went down, both caps are raised by 10% of the pending transaction
+	baseFee := loadHeader(t, "header_london.json").BaseFee
+	feeCap, tipCap := DynamicFee(baseFee, gwei(20), 0)
+	feeCap, tipCap = BumpDynamicFee(pending, feeCap, tipCap)
+	if feeCap.Cmp(big.NewInt(68200000000)) != 0 || tipCap.Cmp(big.NewInt(2200000000)) != 0 {
+		t.Fatalf("unexpected bumped fees %s and %s", feeCap, tipCap)
+	}
+
+	// the fees went up, the new ones are used
+	baseFee = loadHeader(t, "header_london_next.json").BaseFee
+	feeCap, tipCap = DynamicFee(baseFee, gwei(40), 0)
+	bumpedCap, bumpedTip := BumpDynamicFee(pending, feeCap, tipCap)
+	if bumpedCap.Cmp(feeCap) != 0 || bumpedTip.Cmp(tipCap) != 0 {
+		t.Fatalf("expected %s and %s, got %s and %s", feeCap, tipCap, bumpedCap, bumpedTip)
+	}
+
+	// a legacy transaction is replaced with both caps above its gas price
+	legacy := etypes.NewTransaction(1, to, nil, 21000, gwei(50), nil)
+	feeCap, tipCap = BumpDynamicFee(legacy, gwei(62), gwei(2))
+	if feeCap.Cmp(gwei(62)) != 0 || tipCap.Cmp(gwei(55)) != 0 {
+		t.Fatalf("unexpected bumped fees %s and %s", feeCap, tipCap)
+	}
+}
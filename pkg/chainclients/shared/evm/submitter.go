@@ -0,0 +1,161 @@
+package evm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/internal/structure"
+)
+
+// MaintainerSource provides the maintainer sets of the epochs and the relay chain height, it
+// is implemented by the relay chain bridge
+type MaintainerSource interface {
+	GetBlockHeight() (int64, error)
+	GetMaintainerEpochs() (*big.Int, *big.Int, error)
+	GetEpochInfo(epoch *big.Int) (*structure.EpochInfo, error)
+	GetEpochPubKeys(epoch *big.Int) ([]common.PubKey, error)
+}
+
+// SubmitterRanking returns the maintainers in the order they take turns to submit the
+// outbound of the given order. The ranking only depends on its inputs so every node
+// computes the same one, like p2p.LeaderNode does for the keysign leader.
+func SubmitterRanking(orderID string, height int64, members []string) []string {
+	hashes := make(map[string]string, len(members))
+	ranking := make([]string, 0, len(members))
+	for _, member := range members {
+		sum := sha256.Sum256([]byte(orderID + strconv.FormatInt(height, 10) + member))
+		hashes[member] = hex.EncodeToString(sum[:])
+		ranking = append(ranking, member)
+	}
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return hashes[ranking[i]] < hashes[ranking[j]]
+	})
+	return ranking
+}
+
+// IsSubmitterTurn returns true when the submitter at the given rank may submit, each rank
+// gets takeoverBlocks relay blocks, counted from the outbound height, before the next one
+// takes over
+func IsSubmitterTurn(rank int, outboundHeight, currentHeight, takeoverBlocks int64) bool {
+	return currentHeight-outboundHeight >= int64(rank)*takeoverBlocks
+}
+
+// epochMembers are the maintainers of an epoch and the relay height it started at
+type epochMembers struct {
+	start   int64
+	members []string
+}
+
+// SubmitterElection decides whether the local node should submit an outbound, so only
+// one node spends gas on it unless the elected submitter goes silent
+type SubmitterElection struct {
+	logger         zerolog.Logger
+	source         MaintainerSource
+	localPubKey    common.PubKey
+	takeoverBlocks int64
+	lock           sync.Mutex
+	// epochs caches the maintainers by epoch, they don't change once the epoch started
+	epochs map[string]*epochMembers
+}
+
+// NewSubmitterElection create a new instance of SubmitterElection, a non-positive
+// takeoverBlocks disables the election and every node submits
+func NewSubmitterElection(source MaintainerSource, localPubKey common.PubKey, takeoverBlocks int64) *SubmitterElection {
+	return &SubmitterElection{
+		logger:         log.With().Str("module", "submitter_election").Logger(),
+		source:         source,
+		localPubKey:    localPubKey,
+		takeoverBlocks: takeoverBlocks,
+		epochs:         make(map[string]*epochMembers),
+	}
+}
+
+// IsMyTurn returns true when the local node should submit the outbound of the given order,
+// which was emitted at outboundHeight on the relay chain. The maintainers of the epoch
+// active at outboundHeight are ranked, so nodes agree on the ranking across an epoch
+// change. When the maintainers or the relay height can't be loaded it is not the turn of
+// the node yet, the outbound is retried and every node would submit otherwise.
+func (e *SubmitterElection) IsMyTurn(orderID ecommon.Hash, outboundHeight int64) bool {
+	if e.takeoverBlocks <= 0 {
+		return true
+	}
+	members, err := e.getMembers(outboundHeight)
+	if err != nil {
+		e.logger.Warn().Err(err).Str("orderId", orderID.Hex()).Msg("fail to get maintainers, retry later")
+		return false
+	}
+	currentHeight, err := e.source.GetBlockHeight()
+	if err != nil {
+		e.logger.Warn().Err(err).Str("orderId", orderID.Hex()).Msg("fail to get relay height, retry later")
+		return false
+	}
+
+	ranking := SubmitterRanking(orderID.Hex(), outboundHeight, members)
+	// a node outside of the maintainer set only submits once every maintainer had its turn
+	rank := len(ranking)
+	for i, member := range ranking {
+		if member == e.localPubKey.String() {
+			rank = i
+			break
+		}
+	}
+	ok := IsSubmitterTurn(rank, outboundHeight, currentHeight, e.takeoverBlocks)
+	e.logger.Debug().Str("orderId", orderID.Hex()).Int("rank", rank).Int64("outboundHeight", outboundHeight).
+		Int64("currentHeight", currentHeight).Bool("submit", ok).Msg("submitter election")
+	return ok
+}
+
+// getMembers returns the maintainers of the epoch active at the relay height, the epochs are
+// walked back from the current one until one started at or before it
+func (e *SubmitterElection) getMembers(height int64) ([]string, error) {
+	current, _, err := e.source.GetMaintainerEpochs()
+	if err != nil {
+		return nil, fmt.Errorf("fail to get maintainer epochs: %w", err)
+	}
+	for epoch := new(big.Int).Set(current); epoch.Sign() > 0; epoch.Sub(epoch, big.NewInt(1)) {
+		em, err := e.getEpoch(epoch)
+		if err != nil {
+			return nil, err
+		}
+		if em.start <= height {
+			return em.members, nil
+		}
+	}
+	return nil, fmt.Errorf("no epoch started at or before relay height %d", height)
+}
+
+// getEpoch returns the maintainers of an epoch, loading them the first time
+func (e *SubmitterElection) getEpoch(epoch *big.Int) (*epochMembers, error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if em, ok := e.epochs[epoch.String()]; ok {
+		return em, nil
+	}
+	info, err := e.source.GetEpochInfo(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get info of epoch %s: %w", epoch, err)
+	}
+	pubKeys, err := e.source.GetEpochPubKeys(epoch)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get maintainers of epoch %s: %w", epoch, err)
+	}
+	if len(pubKeys) == 0 {
+		return nil, fmt.Errorf("no maintainer in epoch %s", epoch)
+	}
+	em := &epochMembers{start: int64(info.StartBlock), members: make([]string, 0, len(pubKeys))}
+	for _, pk := range pubKeys {
+		em.members = append(em.members, pk.String())
+	}
+	e.epochs[epoch.String()] = em
+	return em, nil
+}
@@ -0,0 +1,174 @@
+package evm
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/internal/structure"
+)
+
+// fakeMaintainerSource serves epoch 1 from relay height 0 with members, and epoch 2 from
+// next with nextMembers once epoch is 2
+type fakeMaintainerSource struct {
+	height      int64
+	epoch       int64
+	members     []common.PubKey
+	next        int64
+	nextMembers []common.PubKey
+	err         error
+}
+
+func (f *fakeMaintainerSource) GetBlockHeight() (int64, error) {
+	return f.height, f.err
+}
+
+func (f *fakeMaintainerSource) GetMaintainerEpochs() (*big.Int, *big.Int, error) {
+	return big.NewInt(f.epoch), big.NewInt(0), f.err
+}
+
+func (f *fakeMaintainerSource) GetEpochInfo(epoch *big.Int) (*structure.EpochInfo, error) {
+	if epoch.Int64() == 2 {
+		return &structure.EpochInfo{StartBlock: uint64(f.next)}, f.err
+	}
+	return &structure.EpochInfo{}, f.err
+}
+
+func (f *fakeMaintainerSource) GetEpochPubKeys(epoch *big.Int) ([]common.PubKey, error) {
+	if epoch.Int64() == 2 {
+		return f.nextMembers, f.err
+	}
+	return f.members, f.err
+}
+
+var testMembers = []common.PubKey{
+	"02a1633cafcc01ebfb6d78e39f687a1f0995c62fc95f51ead10a02ee0be551b5dc",
+	"03bbb3ac1c40fbc88e14e8cdb32be3b5c4c5cfa9e1a1da5b4cdf6cbd44a8e7f7bd",
+	"0379be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+	"02c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5",
+}
+
+func TestSubmitterRankingIsDeterministic(t *testing.T) {
+	orderID := ecommon.HexToHash("0x01").Hex()
+	members := make([]string, 0, len(testMembers))
+	for _, pk := range testMembers {
+		members = append(members, pk.String())
+	}
+	reversed := make([]string, 0, len(members))
+	for i := len(members) - 1; i >= 0; i-- {
+		reversed = append(reversed, members[i])
+	}
+
+	ranking := SubmitterRanking(orderID, 100, members)
+	if len(ranking) != len(members) {
+		t.Fatalf("expected %d members, got %d", len(members), len(ranking))
+	}
+	other := SubmitterRanking(orderID, 100, reversed)
+	for i := range ranking {
+		if ranking[i] != other[i] {
+			t.Fatalf("ranking depends on the order of the members: %v vs %v", ranking, other)
+		}
+	}
+
+	// the primary rotates between orders
+	primaries := make(map[string]struct{})
+	for i := 0; i < 32; i++ {
+		r := SubmitterRanking(ecommon.BigToHash(big.NewInt(int64(i))).Hex(), 100, members)
+		primaries[r[0]] = struct{}{}
+	}
+	if len(primaries) < 2 {
+		t.Fatal("the same node is always elected")
+	}
+}
+
+func TestSubmitterElection(t *testing.T) {
+	orderID := ecommon.HexToHash("0xabc")
+	source := &fakeMaintainerSource{height: 100, epoch: 1, members: testMembers}
+	elections := make(map[string]*SubmitterElection, len(testMembers))
+	for _, pk := range testMembers {
+		elections[pk.String()] = NewSubmitterElection(source, pk, 10)
+	}
+	submitters := func() []string {
+		result := make([]string, 0)
+		for pk, e := range elections {
+			if e.IsMyTurn(orderID, 100) {
+				result = append(result, pk)
+			}
+		}
+		return result
+	}
+
+	// every node agrees on a single submitter
+	ranking := SubmitterRanking(orderID.Hex(), 100, []string{
+		testMembers[0].String(), testMembers[1].String(), testMembers[2].String(), testMembers[3].String(),
+	})
+	got := submitters()
+	if len(got) != 1 || got[0] != ranking[0] {
+		t.Fatalf("expected only %s to submit, got %v", ranking[0], got)
+	}
+
+	// the primary went silent, the backup takes over after the takeover window
+	source.height = 109
+	if got = submitters(); len(got) != 1 {
+		t.Fatalf("backup took over too early: %v", got)
+	}
+	source.height = 110
+	got = submitters()
+	if len(got) != 2 {
+		t.Fatalf("expected the backup to take over, got %v", got)
+	}
+	for _, pk := range got {
+		if pk != ranking[0] && pk != ranking[1] {
+			t.Fatalf("unexpected submitter %s", pk)
+		}
+	}
+
+	// a node outside of the maintainer set waits for every maintainer
+	outsider := NewSubmitterElection(source, "03ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 10)
+	if outsider.IsMyTurn(orderID, 100) {
+		t.Fatal("outsider should not submit")
+	}
+	source.height = 140
+	if !outsider.IsMyTurn(orderID, 100) {
+		t.Fatal("outsider should submit once every maintainer had its turn")
+	}
+}
+
+func TestSubmitterElectionEpochChange(t *testing.T) {
+	orderID := ecommon.HexToHash("0xabc")
+	source := &fakeMaintainerSource{height: 100, epoch: 1, members: testMembers[:2]}
+	elections := make(map[string]*SubmitterElection, len(testMembers))
+	for _, pk := range testMembers {
+		elections[pk.String()] = NewSubmitterElection(source, pk, 10)
+	}
+	primary := SubmitterRanking(orderID.Hex(), 100, []string{testMembers[0].String(), testMembers[1].String()})[0]
+	// a node that loads the maintainers after the epoch changed ranks the same members
+	elections[testMembers[2].String()].IsMyTurn(orderID, 100)
+	source.epoch, source.next, source.nextMembers = 2, 105, testMembers[2:]
+	for pk, e := range elections {
+		if got := e.IsMyTurn(orderID, 100); got != (pk == primary) {
+			t.Fatalf("%s submits %v, expected only %s to submit", pk, got, primary)
+		}
+	}
+	// outbounds emitted in the new epoch are ranked over its members
+	primary = SubmitterRanking(orderID.Hex(), 105, []string{testMembers[2].String(), testMembers[3].String()})[0]
+	source.height = 105
+	for pk, e := range elections {
+		if got := e.IsMyTurn(orderID, 105); got != (pk == primary) {
+			t.Fatalf("%s submits %v, expected only %s to submit", pk, got, primary)
+		}
+	}
+}
+
+func TestSubmitterElectionRelayUnavailable(t *testing.T) {
+	source := &fakeMaintainerSource{err: errors.New("relay unavailable")}
+	if NewSubmitterElection(source, testMembers[0], 10).IsMyTurn(ecommon.HexToHash("0x1"), 100) {
+		t.Fatal("should wait while the maintainers are unknown")
+	}
+	if !NewSubmitterElection(nil, testMembers[0], 0).IsMyTurn(ecommon.HexToHash("0x1"), 100) {
+		t.Fatal("should always submit when the election is disabled")
+	}
+}
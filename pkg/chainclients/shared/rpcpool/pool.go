@@ -0,0 +1,402 @@
+package rpcpool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/metrics"
+)
+
+const (
+	// DefaultMaxFailures is the number of consecutive failures after which an endpoint
+	// is taken out of rotation.
+	DefaultMaxFailures = 3
+
+	// DefaultCooldown is how long an unhealthy endpoint stays out of rotation before it
+	// is tried again.
+	DefaultCooldown = 30 * time.Second
+)
+
+var (
+	ErrNoEndpoints      = errors.New("no rpc endpoints configured")
+	ErrQuorumNotReached = errors.New("rpc quorum not reached")
+)
+
+type pinnedKey struct{}
+
+// WithEndpoint returns a context that pins every request made with it to the endpoint
+// at the given index, bypassing failover. It is used for quorum reads.
+func WithEndpoint(ctx context.Context, idx int) context.Context {
+	return context.WithValue(ctx, pinnedKey{}, idx)
+}
+
+func pinnedEndpoint(ctx context.Context) (int, bool) {
+	idx, ok := ctx.Value(pinnedKey{}).(int)
+	return idx, ok
+}
+
+// Options configures the health tracking and quorum behaviour of a Pool.
+type Options struct {
+	// MaxFailures is the number of consecutive failures before an endpoint is marked
+	// unhealthy.
+	MaxFailures int
+
+	// Cooldown is how long an unhealthy endpoint is skipped.
+	Cooldown time.Duration
+
+	// Quorum is the number of endpoints that must agree on a critical read. Values
+	// below 2 disable quorum reads.
+	Quorum int
+
+	// Transport is the transport requests are sent over, nil for http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+// EndpointStatus is a point in time snapshot of an endpoint's health.
+type EndpointStatus struct {
+	Host          string        `json:"host"`
+	Healthy       bool          `json:"healthy"`
+	Failures      int           `json:"consecutive_failures"`
+	Requests      uint64        `json:"requests"`
+	Errors        uint64        `json:"errors"`
+	Latency       time.Duration `json:"latency"`
+	CooldownUntil time.Time     `json:"cooldown_until,omitempty"`
+}
+
+type endpoint struct {
+	url           *url.URL
+	failures      int
+	requests      uint64
+	errors        uint64
+	latency       time.Duration
+	cooldownUntil time.Time
+}
+
+// Pool is an http.RoundTripper that spreads requests for a single chain over a list of
+// RPC endpoints. Requests go to the first healthy endpoint in configured order and fail
+// over to the next one on transport errors or server-side failures. Endpoints that keep
+// failing are skipped for a cooldown period.
+type Pool struct {
+	chain     common.Chain
+	logger    zerolog.Logger
+	opts      Options
+	base      http.RoundTripper
+	metrics   *metrics.Metrics
+	lock      sync.Mutex
+	endpoints []*endpoint
+	now       func() time.Time
+}
+
+// NewPool creates a pool for the given hosts. The first host is the primary endpoint,
+// and is the one dialers should be given via URL.
+func NewPool(chain common.Chain, hosts []string, opts Options, m *metrics.Metrics) (*Pool, error) {
+	if len(hosts) == 0 {
+		return nil, ErrNoEndpoints
+	}
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = DefaultMaxFailures
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = DefaultCooldown
+	}
+	if opts.Transport == nil {
+		opts.Transport = http.DefaultTransport
+	}
+
+	p := &Pool{
+		chain:   chain,
+		logger:  log.With().Str("module", "rpc_pool").Stringer("chain", chain).Logger(),
+		opts:    opts,
+		base:    opts.Transport,
+		metrics: m,
+		now:     time.Now,
+	}
+	for _, host := range hosts {
+		if !strings.Contains(host, "://") {
+			host = "http://" + host
+		}
+		u, err := url.Parse(host)
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse rpc host(%s): %w", host, err)
+		}
+		p.endpoints = append(p.endpoints, &endpoint{url: u})
+	}
+	return p, nil
+}
+
+// URL returns the primary endpoint url.
+func (p *Pool) URL() string {
+	return p.endpoints[0].url.String()
+}
+
+// Size returns the number of configured endpoints.
+func (p *Pool) Size() int {
+	return len(p.endpoints)
+}
+
+// Quorum returns the configured number of endpoints that must agree on a critical read.
+func (p *Pool) Quorum() int {
+	return p.opts.Quorum
+}
+
+// HTTPClient returns an http client that routes all requests through the pool.
+func (p *Pool) HTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: p, Timeout: timeout}
+}
+
+// Status returns a snapshot of every endpoint's health.
+func (p *Pool) Status() []EndpointStatus {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.now()
+	status := make([]EndpointStatus, 0, len(p.endpoints))
+	for _, ep := range p.endpoints {
+		status = append(status, EndpointStatus{
+			Host:          ep.url.Host,
+			Healthy:       !now.Before(ep.cooldownUntil),
+			Failures:      ep.failures,
+			Requests:      ep.requests,
+			Errors:        ep.errors,
+			Latency:       ep.latency,
+			CooldownUntil: ep.cooldownUntil,
+		})
+	}
+	return status
+}
+
+// RoundTrip implements http.RoundTripper.
+func (p *Pool) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("fail to read request body: %w", err)
+		}
+	}
+
+	order := p.candidates()
+	if idx, ok := pinnedEndpoint(req.Context()); ok {
+		if idx < 0 || idx >= len(p.endpoints) {
+			return nil, fmt.Errorf("rpc endpoint %d out of range", idx)
+		}
+		order = []int{idx}
+	}
+
+	var lastErr error
+	for _, idx := range order {
+		resp, err := p.do(req, body, idx)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if req.Context().Err() != nil {
+			break
+		}
+		p.logger.Warn().Err(err).Str("host", p.endpoints[idx].url.Host).Msg("rpc endpoint failed, trying next")
+	}
+	return nil, lastErr
+}
+
+func (p *Pool) do(req *http.Request, body []byte, idx int) (*http.Response, error) {
+	ep := p.endpoints[idx]
+	out := req.Clone(req.Context())
+	out.URL = p.rewrite(req.URL, ep.url)
+	out.Host = ep.url.Host
+	if ep.url.User != nil {
+		password, _ := ep.url.User.Password()
+		out.SetBasicAuth(ep.url.User.Username(), password)
+	}
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.ContentLength = int64(len(body))
+	}
+
+	start := p.now()
+	resp, err := p.base.RoundTrip(out)
+	elapsed := p.now().Sub(start)
+	if err == nil && (resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests) {
+		_ = resp.Body.Close()
+		err = fmt.Errorf("rpc endpoint response status: %d", resp.StatusCode)
+	}
+	p.record(idx, elapsed, err)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// rewrite maps a request built against the primary endpoint onto the target endpoint,
+// keeping any path suffix the client appended (e.g. "/wallet/getnowblock").
+func (p *Pool) rewrite(in *url.URL, target *url.URL) *url.URL {
+	out := *in
+	out.Scheme = target.Scheme
+	out.Host = target.Host
+	out.User = nil
+	suffix := strings.TrimPrefix(in.Path, strings.TrimSuffix(p.endpoints[0].url.Path, "/"))
+	out.Path = strings.TrimSuffix(target.Path, "/") + suffix
+	if out.Path == "" {
+		out.Path = "/"
+	}
+	out.RawPath = ""
+	if in.RawQuery == "" {
+		out.RawQuery = target.RawQuery
+	}
+	return &out
+}
+
+// candidates returns endpoint indexes in the order they should be tried: healthy
+// endpoints in configured order, followed by unhealthy ones by earliest recovery.
+func (p *Pool) candidates() []int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.now()
+	var healthy, unhealthy []int
+	for i, ep := range p.endpoints {
+		if now.Before(ep.cooldownUntil) {
+			unhealthy = append(unhealthy, i)
+			continue
+		}
+		healthy = append(healthy, i)
+	}
+	// insertion sort, the list is tiny
+	for i := 1; i < len(unhealthy); i++ {
+		for j := i; j > 0 && p.endpoints[unhealthy[j]].cooldownUntil.Before(p.endpoints[unhealthy[j-1]].cooldownUntil); j-- {
+			unhealthy[j], unhealthy[j-1] = unhealthy[j-1], unhealthy[j]
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// healthy returns the indexes of endpoints currently in rotation.
+func (p *Pool) healthy() []int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	now := p.now()
+	var out []int
+	for i, ep := range p.endpoints {
+		if !now.Before(ep.cooldownUntil) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func (p *Pool) record(idx int, elapsed time.Duration, err error) {
+	p.lock.Lock()
+	ep := p.endpoints[idx]
+	ep.requests++
+	if ep.latency == 0 {
+		ep.latency = elapsed
+	} else {
+		// exponential moving average, weighting the latest sample at 1/5
+		ep.latency = (ep.latency*4 + elapsed) / 5
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+		ep.errors++
+		ep.failures++
+		if ep.failures >= p.opts.MaxFailures {
+			ep.cooldownUntil = p.now().Add(p.opts.Cooldown)
+			p.logger.Error().Str("host", ep.url.Host).Int("failures", ep.failures).Msg("rpc endpoint marked unhealthy")
+		}
+	} else {
+		ep.failures = 0
+		ep.cooldownUntil = time.Time{}
+	}
+	host := ep.url.Host
+	p.lock.Unlock()
+
+	if p.metrics == nil {
+		return
+	}
+	if c := p.metrics.GetCounterVec(metrics.RPCEndpointRequests); c != nil {
+		c.WithLabelValues(p.chain.String(), host, result).Inc()
+	}
+	if h := p.metrics.GetHistogramVec(metrics.RPCEndpointLatency); h != nil {
+		h.WithLabelValues(p.chain.String(), host).Observe(elapsed.Seconds())
+	}
+}
+
+// QuorumRead calls fn once per healthy endpoint of the pool, with the context pinned to
+// that endpoint, and returns the first value that the configured quorum of endpoints
+// agree on. Values are compared by the string returned from key. If the pool is nil or
+// quorum reads are disabled, fn is called once with normal failover.
+func QuorumRead[T any](ctx context.Context, p *Pool, fn func(ctx context.Context) (T, error), key func(T) string) (T, error) {
+	var empty T
+	if p == nil || p.opts.Quorum < 2 {
+		return fn(ctx)
+	}
+	idxs := p.healthy()
+	if len(idxs) < p.opts.Quorum {
+		return empty, fmt.Errorf("%w: %d healthy endpoints, need %d", ErrQuorumNotReached, len(idxs), p.opts.Quorum)
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	results := make(chan result, len(idxs))
+	for _, idx := range idxs {
+		go func(idx int) {
+			value, err := fn(WithEndpoint(ctx, idx))
+			results <- result{value: value, err: err}
+		}(idx)
+	}
+
+	votes := make(map[string]int)
+	var lastErr error
+	for i := range idxs {
+		r := <-results
+		if r.err != nil {
+			lastErr = r.err
+		} else {
+			k := key(r.value)
+			votes[k]++
+			if votes[k] >= p.opts.Quorum {
+				return r.value, nil
+			}
+		}
+
+		// stop early once the remaining responses can no longer produce a quorum
+		best := 0
+		for _, v := range votes {
+			if v > best {
+				best = v
+			}
+		}
+		if best+len(idxs)-i-1 < p.opts.Quorum {
+			break
+		}
+	}
+	if lastErr != nil {
+		return empty, fmt.Errorf("%w: %w", ErrQuorumNotReached, lastErr)
+	}
+	p.logger.Error().Interface("votes", votes).Msg("rpc endpoints disagree")
+	return empty, fmt.Errorf("%w: endpoints disagree", ErrQuorumNotReached)
+}
+
+// NewPoolFromConfig creates a pool over the configured rpc endpoints of a chain.
+func NewPoolFromConfig(cfg config.BifrostChainConfiguration, m *metrics.Metrics) (*Pool, error) {
+	return NewPool(cfg.ChainID, cfg.GetRPCHosts(), Options{
+		MaxFailures: cfg.RPCPool.MaxFailures,
+		Cooldown:    cfg.RPCPool.Cooldown,
+		Quorum:      cfg.RPCPool.Quorum,
+		Transport:   cfg.Transport,
+	}, m)
+}
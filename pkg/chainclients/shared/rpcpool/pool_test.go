@@ -0,0 +1,187 @@
+package rpcpool
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mapprotocol/compass-tss/common"
+)
+
+func newServer(t *testing.T, status int, body string, hits *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(r.URL.Path + "|" + body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func get(ctx context.Context, t *testing.T, client *http.Client, url string) (string, error) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return string(data), err
+}
+
+func TestPool_Failover(t *testing.T) {
+	var badHits, goodHits int32
+	bad := newServer(t, http.StatusBadGateway, "bad", &badHits)
+	good := newServer(t, http.StatusOK, "good", &goodHits)
+
+	pool, err := NewPool(common.ETHChain, []string{bad.URL + "/rpc", good.URL + "/other"}, Options{
+		MaxFailures: 2,
+		Cooldown:    time.Minute,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := pool.HTTPClient(time.Second)
+
+	for i := 0; i < 3; i++ {
+		got, err := get(context.Background(), t, client, pool.URL()+"/wallet/getnowblock")
+		if err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+		if got != "/other/wallet/getnowblock|good" {
+			t.Fatalf("unexpected response: %s", got)
+		}
+	}
+
+	// the failing endpoint is skipped once it reaches max failures
+	if badHits != 2 {
+		t.Fatalf("expected 2 requests to the failing endpoint, got %d", badHits)
+	}
+	if goodHits != 3 {
+		t.Fatalf("expected 3 requests to the healthy endpoint, got %d", goodHits)
+	}
+	status := pool.Status()
+	if status[0].Healthy || status[0].Errors != 2 {
+		t.Fatalf("expected failing endpoint unhealthy with 2 errors, got %+v", status[0])
+	}
+	if !status[1].Healthy || status[1].Requests != 3 {
+		t.Fatalf("expected healthy endpoint with 3 requests, got %+v", status[1])
+	}
+
+	// after the cooldown the primary endpoint is tried again
+	pool.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err = get(context.Background(), t, client, pool.URL()); err != nil {
+		t.Fatal(err)
+	}
+	if badHits != 3 {
+		t.Fatalf("expected primary endpoint to be retried after cooldown, got %d hits", badHits)
+	}
+}
+
+func TestPool_AllEndpointsFail(t *testing.T) {
+	var hits int32
+	bad := newServer(t, http.StatusTooManyRequests, "", &hits)
+	pool, err := NewPool(common.BTCChain, []string{bad.URL}, Options{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = get(context.Background(), t, pool.HTTPClient(time.Second), pool.URL()); err == nil {
+		t.Fatal("expected error when every endpoint fails")
+	}
+}
+
+func TestPool_PinnedEndpoint(t *testing.T) {
+	var aHits, bHits int32
+	a := newServer(t, http.StatusOK, "a", &aHits)
+	b := newServer(t, http.StatusOK, "b", &bHits)
+	pool, err := NewPool(common.BSCChain, []string{a.URL, b.URL}, Options{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := get(WithEndpoint(context.Background(), 1), t, pool.HTTPClient(time.Second), pool.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "/|b" || aHits != 0 {
+		t.Fatalf("expected request pinned to second endpoint, got %s (primary hits %d)", got, aHits)
+	}
+}
+
+func TestQuorumRead(t *testing.T) {
+	hosts := []string{"http://a", "http://b", "http://c"}
+	tests := []struct {
+		name    string
+		quorum  int
+		answers map[int]string
+		fails   map[int]bool
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "disabled quorum calls once",
+			quorum:  0,
+			answers: map[int]string{-1: "x"},
+			want:    "x",
+		},
+		{
+			name:    "two of three agree",
+			quorum:  2,
+			answers: map[int]string{0: "x", 1: "y", 2: "x"},
+			want:    "x",
+		},
+		{
+			name:    "disagreement",
+			quorum:  2,
+			answers: map[int]string{0: "x", 1: "y", 2: "z"},
+			wantErr: true,
+		},
+		{
+			name:    "errors prevent quorum",
+			quorum:  3,
+			answers: map[int]string{0: "x", 1: "x", 2: "x"},
+			fails:   map[int]bool{2: true},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, err := NewPool(common.ETHChain, hosts, Options{Quorum: tt.quorum}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := QuorumRead(context.Background(), pool, func(ctx context.Context) (string, error) {
+				idx, ok := pinnedEndpoint(ctx)
+				if !ok {
+					idx = -1
+				}
+				if tt.fails[idx] {
+					return "", errors.New("boom")
+				}
+				return tt.answers[idx], nil
+			}, func(s string) string { return s })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("QuorumRead() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrQuorumNotReached) {
+					t.Fatalf("expected ErrQuorumNotReached, got %v", err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Fatalf("QuorumRead() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package rpcpool
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mapprotocol/compass-tss/common"
+)
+
+// ErrNotRecorded is returned by a replay transport for a request the fixture has no
+// response for.
+var ErrNotRecorded = errors.New("rpc request not recorded")
+
+// Exchange is a recorded rpc request and the response the endpoint gave to it. The
+// json-rpc ids of request and response are replaced by their position in the request,
+// so the exchange matches whatever ids the client picks when it is replayed.
+type Exchange struct {
+	Chain    common.Chain `json:"chain"`
+	Method   string       `json:"method"`
+	Path     string       `json:"path"`
+	Request  string       `json:"request"`
+	Status   int          `json:"status"`
+	Response string       `json:"response"`
+}
+
+func (e Exchange) key() string {
+	return strings.Join([]string{e.Chain.String(), e.Method, e.Path, e.Request}, " ")
+}
+
+// Fixture is the rpc traffic recorded while scanning a range of blocks of a chain. The
+// traffic of several chains, such as the one of the configuration checks, is recorded
+// without a chain and range.
+type Fixture struct {
+	Chain     common.Chain `json:"chain,omitempty"`
+	From      int64        `json:"from,omitempty"`
+	To        int64        `json:"to,omitempty"`
+	Exchanges []Exchange   `json:"exchanges"`
+}
+
+// LoadFixture reads a fixture from the given file.
+func LoadFixture(path string) (*Fixture, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read fixture: %w", err)
+	}
+	var f Fixture
+	if err = json.Unmarshal(buf, &f); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal fixture: %w", err)
+	}
+	return &f, nil
+}
+
+// Save writes the fixture to the given file.
+func (f *Fixture) Save(path string) error {
+	buf, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("fail to marshal fixture: %w", err)
+	}
+	if err = os.WriteFile(path, buf, 0o600); err != nil {
+		return fmt.Errorf("fail to write fixture: %w", err)
+	}
+	return nil
+}
+
+// Recorder records the rpc traffic of the chain clients, so a scan can be replayed
+// later without network.
+type Recorder struct {
+	lock      sync.Mutex
+	exchanges []Exchange
+}
+
+// NewRecorder creates an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Transport returns a transport which sends the requests of the chain over base and
+// records them together with their responses.
+func (r *Recorder) Transport(chain common.Chain, base http.RoundTripper) http.RoundTripper {
+	return &recordTransport{recorder: r, chain: chain, base: base}
+}
+
+// Fixture returns the traffic recorded so far as the fixture of the given range.
+func (r *Recorder) Fixture(chain common.Chain, from, to int64) *Fixture {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return &Fixture{
+		Chain:     chain,
+		From:      from,
+		To:        to,
+		Exchanges: append([]Exchange(nil), r.exchanges...),
+	}
+}
+
+// Traffic returns the traffic recorded so far as a fixture of no particular chain or range.
+func (r *Recorder) Traffic() *Fixture {
+	return r.Fixture("", 0, 0)
+}
+
+type recordTransport struct {
+	recorder *Recorder
+	chain    common.Chain
+	base     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("fail to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	request, ids := normalizeRequest(body)
+	t.recorder.lock.Lock()
+	t.recorder.exchanges = append(t.recorder.exchanges, Exchange{
+		Chain:    t.chain,
+		Method:   req.Method,
+		Path:     req.URL.RequestURI(),
+		Request:  request,
+		Status:   resp.StatusCode,
+		Response: normalizeResponse(respBody, ids),
+	})
+	t.recorder.lock.Unlock()
+	return resp, nil
+}
+
+// Replayer answers the rpc requests of the chain clients from a fixture. Identical
+// requests are answered in the order they were recorded, once the recorded responses
+// are used up the last one is repeated.
+type Replayer struct {
+	lock      sync.Mutex
+	exchanges map[string][]Exchange
+	served    map[string]int
+}
+
+// NewReplayer creates a replayer over the exchanges of the fixture.
+func NewReplayer(f *Fixture) *Replayer {
+	r := &Replayer{
+		exchanges: make(map[string][]Exchange),
+		served:    make(map[string]int),
+	}
+	for _, e := range f.Exchanges {
+		r.exchanges[e.key()] = append(r.exchanges[e.key()], e)
+	}
+	return r
+}
+
+// Transport returns the transport replaying the traffic of the chain.
+func (r *Replayer) Transport(chain common.Chain) http.RoundTripper {
+	return &replayTransport{replayer: r, chain: chain}
+}
+
+func (r *Replayer) next(key string) (Exchange, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	recorded := r.exchanges[key]
+	if len(recorded) == 0 {
+		return Exchange{}, false
+	}
+	idx := r.served[key]
+	if idx >= len(recorded) {
+		idx = len(recorded) - 1
+	}
+	r.served[key]++
+	return recorded[idx], true
+}
+
+type replayTransport struct {
+	replayer *Replayer
+	chain    common.Chain
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	request, ids := normalizeRequest(body)
+	e, ok := t.replayer.next(Exchange{Chain: t.chain, Method: req.Method, Path: req.URL.RequestURI(), Request: request}.key())
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s %s", ErrNotRecorded, req.Method, req.URL.RequestURI(), request)
+	}
+	respBody := []byte(restoreResponse(e.Response, ids))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(respBody)),
+		ContentLength: int64(len(respBody)),
+		Request:       req,
+	}, nil
+}
+
+// readRequestBody reads the body of the request and puts it back for the next reader.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("fail to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// normalizeRequest replaces the json-rpc ids of a request, or of every request of a
+// batch, by their position and returns the original ids in that order. Bodies which
+// aren't json objects are returned as they are.
+func normalizeRequest(body []byte) (string, []json.RawMessage) {
+	objs, batch, ok := decodeObjects(body)
+	if !ok {
+		return string(body), nil
+	}
+	ids := make([]json.RawMessage, len(objs))
+	for i, obj := range objs {
+		if id, ok := obj["id"]; ok {
+			ids[i] = id
+			obj["id"] = json.RawMessage(strconv.Itoa(i))
+		}
+	}
+	return encodeObjects(objs, batch, body), ids
+}
+
+// normalizeResponse replaces the json-rpc ids of a response by the position of the
+// request they answer.
+func normalizeResponse(body []byte, ids []json.RawMessage) string {
+	objs, batch, ok := decodeObjects(body)
+	if !ok || len(ids) == 0 {
+		return string(body)
+	}
+	for _, obj := range objs {
+		id, ok := obj["id"]
+		if !ok {
+			continue
+		}
+		for i, reqID := range ids {
+			if bytes.Equal(id, reqID) {
+				obj["id"] = json.RawMessage(strconv.Itoa(i))
+				break
+			}
+		}
+	}
+	return encodeObjects(objs, batch, body)
+}
+
+// restoreResponse puts the ids of the replayed request back into a normalized response.
+func restoreResponse(body string, ids []json.RawMessage) string {
+	objs, batch, ok := decodeObjects([]byte(body))
+	if !ok || len(ids) == 0 {
+		return body
+	}
+	for _, obj := range objs {
+		pos, err := strconv.Atoi(string(obj["id"]))
+		if err != nil || pos < 0 || pos >= len(ids) || ids[pos] == nil {
+			continue
+		}
+		obj["id"] = ids[pos]
+	}
+	return encodeObjects(objs, batch, []byte(body))
+}
+
+func decodeObjects(body []byte) ([]map[string]json.RawMessage, bool, bool) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, false, false
+	}
+	switch trimmed[0] {
+	case '{':
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &obj); err != nil {
+			return nil, false, false
+		}
+		return []map[string]json.RawMessage{obj}, false, true
+	case '[':
+		var objs []map[string]json.RawMessage
+		if err := json.Unmarshal(trimmed, &objs); err != nil {
+			return nil, false, false
+		}
+		return objs, true, true
+	}
+	return nil, false, false
+}
+
+// encodeObjects marshals the objects with sorted keys, so equal requests always encode
+// the same way, falling back to the original body.
+func encodeObjects(objs []map[string]json.RawMessage, batch bool, fallback []byte) string {
+	var (
+		buf []byte
+		err error
+	)
+	if batch {
+		buf, err = json.Marshal(objs)
+	} else {
+		buf, err = json.Marshal(objs[0])
+	}
+	if err != nil {
+		return string(fallback)
+	}
+	return string(buf)
+}
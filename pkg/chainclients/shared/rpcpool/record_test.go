@@ -0,0 +1,163 @@
+package rpcpool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mapprotocol/compass-tss/common"
+)
+
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method,omitempty"`
+	Result string          `json:"result,omitempty"`
+}
+
+// newRPCServer answers every json-rpc call with the method and how often it was called,
+// batches are answered in reverse order
+func newRPCServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var lock sync.Mutex
+	calls := make(map[string]int)
+	answer := func(req rpcMessage) rpcMessage {
+		lock.Lock()
+		defer lock.Unlock()
+		calls[req.Method]++
+		return rpcMessage{ID: req.ID, Result: fmt.Sprintf("%s-%d", req.Method, calls[req.Method])}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var batch []rpcMessage
+		if err := json.Unmarshal(body, &batch); err == nil {
+			var resp []rpcMessage
+			for i := len(batch) - 1; i >= 0; i-- {
+				resp = append(resp, answer(batch[i]))
+			}
+			_ = json.NewEncoder(w).Encode(resp)
+			return
+		}
+		var req rpcMessage
+		if err := json.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(answer(req))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func call(t *testing.T, client *http.Client, url, body string) ([]rpcMessage, error) {
+	t.Helper()
+	resp, err := client.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(string(data), "[") {
+		var result []rpcMessage
+		return result, json.Unmarshal(data, &result)
+	}
+	var result rpcMessage
+	return []rpcMessage{result}, json.Unmarshal(data, &result)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	srv := newRPCServer(t)
+	host := srv.URL + "/rpc"
+	requests := []string{
+		`{"jsonrpc":"2.0","id":%d,"method":"eth_blockNumber"}`,
+		`{"jsonrpc":"2.0","id":%d,"method":"eth_blockNumber"}`,
+		`[{"jsonrpc":"2.0","id":%d,"method":"eth_getBlockByNumber"},{"jsonrpc":"2.0","id":%d,"method":"eth_getLogs"}]`,
+	}
+	scan := func(firstID int, transport http.RoundTripper) ([][]rpcMessage, error) {
+		pool, err := NewPool(common.ETHChain, []string{host}, Options{Transport: transport}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := pool.HTTPClient(time.Second)
+		var result [][]rpcMessage
+		for i, req := range requests {
+			ids := []any{firstID + 2*i, firstID + 2*i + 1}
+			resp, err := call(t, client, pool.URL(), fmt.Sprintf(req, ids[:strings.Count(req, "%d")]...))
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, resp)
+		}
+		return result, nil
+	}
+
+	recorder := NewRecorder()
+	recorded, err := scan(1, recorder.Transport(common.ETHChain, http.DefaultTransport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err = recorder.Fixture(common.ETHChain, 10, 20).Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// the node is gone, everything is answered from the fixture
+	srv.Close()
+	f, err := LoadFixture(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Chain != common.ETHChain || f.From != 10 || f.To != 20 || len(f.Exchanges) != 3 {
+		t.Fatalf("unexpected fixture: %+v", f)
+	}
+	replay := NewReplayer(f).Transport(common.ETHChain)
+	replayed, err := scan(100, replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range recorded {
+		if len(recorded[i]) != len(replayed[i]) {
+			t.Fatalf("request %d: recorded %d responses, replayed %d", i, len(recorded[i]), len(replayed[i]))
+		}
+		for j := range recorded[i] {
+			if recorded[i][j].Result != replayed[i][j].Result {
+				t.Fatalf("request %d: recorded %s, replayed %s", i, recorded[i][j].Result, replayed[i][j].Result)
+			}
+		}
+	}
+	// the replayed responses carry the ids of the replayed requests
+	if got := string(replayed[0][0].ID); got != "100" {
+		t.Fatalf("expected id 100, got %s", got)
+	}
+	// the batch was answered in reverse order, the ids follow the requests they answer
+	batch := replayed[2]
+	if string(batch[0].ID) != "105" || batch[0].Result != "eth_getLogs-1" ||
+		string(batch[1].ID) != "104" || batch[1].Result != "eth_getBlockByNumber-1" {
+		t.Fatalf("unexpected batch response: %+v", batch)
+	}
+
+	// identical requests are answered in recorded order, then the last answer repeats
+	client := &http.Client{Transport: replay}
+	resp, err := call(t, client, host, `{"jsonrpc":"2.0","id":7,"method":"eth_blockNumber"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp[0].Result != "eth_blockNumber-2" {
+		t.Fatalf("expected the last answer to repeat, got %s", resp[0].Result)
+	}
+
+	_, err = call(t, client, host, `{"jsonrpc":"2.0","id":8,"method":"eth_chainId"}`)
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("expected ErrNotRecorded, got %v", err)
+	}
+}
@@ -0,0 +1,208 @@
+package runners
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/metrics"
+)
+
+// defaultBalanceCheckInterval is used when no check interval is configured
+const defaultBalanceCheckInterval = 5 * time.Minute
+
+// BalanceSource methods that a chain client should have to track the balance of the local
+// signing account
+type BalanceSource interface {
+	GetConfig() config.BifrostChainConfiguration
+	GetAddress(pk common.PubKey) string
+	GetAccount(pk common.PubKey, height *big.Int) (common.Account, error)
+}
+
+// BalanceAlerter sends a message to the node operator
+type BalanceAlerter func(text string) error
+
+// RelayerBalance is the gas asset balance of the local signing account on a chain, in 1e8 units
+type RelayerBalance struct {
+	Chain      common.Chain `json:"chain"`
+	Address    string       `json:"address"`
+	Balance    uint64       `json:"balance"`
+	MinBalance uint64       `json:"min_balance"`
+	Low        bool         `json:"low"`
+	Error      string       `json:"error,omitempty"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// BalanceMonitor periodically fetches the balance the local signing account pays outbound
+// gas from, exports it as metrics and alerts the operator when it drops below the minimum
+// configured for its chain
+type BalanceMonitor struct {
+	logger    zerolog.Logger
+	cfg       config.BifrostRelayerBalanceConfiguration
+	pubKey    common.PubKey
+	sources   map[common.Chain]BalanceSource
+	m         *metrics.Metrics
+	alert     BalanceAlerter
+	lock      sync.RWMutex
+	balances  map[common.Chain]RelayerBalance
+	lastAlert map[common.Chain]time.Time
+	wg        *sync.WaitGroup
+	stopChan  chan struct{}
+}
+
+// NewBalanceMonitor create a new instance of BalanceMonitor, alert can be nil to only log low balances
+func NewBalanceMonitor(cfg config.BifrostRelayerBalanceConfiguration,
+	pubKey common.PubKey,
+	sources map[common.Chain]BalanceSource,
+	m *metrics.Metrics,
+	alert BalanceAlerter,
+) *BalanceMonitor {
+	return &BalanceMonitor{
+		logger:    log.With().Str("module", "relayer_balance").Logger(),
+		cfg:       cfg,
+		pubKey:    pubKey,
+		sources:   sources,
+		m:         m,
+		alert:     alert,
+		balances:  make(map[common.Chain]RelayerBalance),
+		lastAlert: make(map[common.Chain]time.Time),
+		wg:        &sync.WaitGroup{},
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start checks the balances in the background until Stop is called
+func (b *BalanceMonitor) Start() {
+	interval := b.cfg.CheckInterval
+	if interval <= 0 {
+		interval = defaultBalanceCheckInterval
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.Check()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-b.stopChan:
+				return
+			case <-ticker.C:
+				b.Check()
+			}
+		}
+	}()
+}
+
+// Stop the background checks
+func (b *BalanceMonitor) Stop() {
+	close(b.stopChan)
+	b.wg.Wait()
+}
+
+// Check fetches the balance on every chain
+func (b *BalanceMonitor) Check() {
+	for chain, source := range b.sources {
+		b.checkChain(chain, source)
+	}
+}
+
+func (b *BalanceMonitor) checkChain(chain common.Chain, source BalanceSource) {
+	balance := RelayerBalance{
+		Chain:      chain,
+		Address:    source.GetAddress(b.pubKey),
+		MinBalance: source.GetConfig().MinRelayerBalance,
+		UpdatedAt:  time.Now(),
+	}
+	account, err := source.GetAccount(b.pubKey, nil)
+	if err != nil {
+		b.logger.Err(err).Stringer("chain", chain).Msg("fail to get relayer balance")
+		balance.Error = err.Error()
+		// keep reporting the last known balance
+		b.lock.RLock()
+		if last, ok := b.balances[chain]; ok {
+			balance.Balance, balance.Low = last.Balance, last.Low
+		}
+		b.lock.RUnlock()
+	} else {
+		balance.Balance = account.Coins.GetCoin(chain.GetGasAsset()).Amount.Uint64()
+		balance.Low = balance.MinBalance > 0 && balance.Balance < balance.MinBalance
+	}
+
+	if b.m != nil && balance.Error == "" {
+		if gauge := b.m.GetGauge(metrics.RelayerBalance(chain)); gauge != nil {
+			gauge.Set(float64(balance.Balance))
+		}
+		if gauge := b.m.GetGauge(metrics.RelayerBalanceLow(chain)); gauge != nil {
+			low := 0.0
+			if balance.Low {
+				low = 1
+			}
+			gauge.Set(low)
+		}
+	}
+
+	b.lock.Lock()
+	b.balances[chain] = balance
+	text := b.alertText(balance)
+	b.lock.Unlock()
+	if text == "" {
+		return
+	}
+	b.logger.Warn().Stringer("chain", chain).Str("address", balance.Address).Uint64("balance", balance.Balance).
+		Uint64("minBalance", balance.MinBalance).Msg(text)
+	if b.alert == nil {
+		return
+	}
+	if err = b.alert(text); err != nil {
+		b.logger.Err(err).Stringer("chain", chain).Msg("fail to send relayer balance alert")
+	}
+}
+
+// alertText returns the message to send for the given balance, empty when nothing should be
+// sent. A low balance is reported again every AlertInterval and its recovery is reported once.
+func (b *BalanceMonitor) alertText(balance RelayerBalance) string {
+	if balance.Error != "" {
+		return ""
+	}
+	last, alerted := b.lastAlert[balance.Chain]
+	if !balance.Low {
+		if !alerted {
+			return ""
+		}
+		delete(b.lastAlert, balance.Chain)
+		return fmt.Sprintf("%s relayer %s balance recovered: %s", balance.Chain, balance.Address,
+			formatAmount(balance.Balance, balance.Chain))
+	}
+	if alerted && (b.cfg.AlertInterval <= 0 || time.Since(last) < b.cfg.AlertInterval) {
+		return ""
+	}
+	b.lastAlert[balance.Chain] = balance.UpdatedAt
+	return fmt.Sprintf("%s relayer %s balance is low: %s, minimum %s", balance.Chain, balance.Address,
+		formatAmount(balance.Balance, balance.Chain), formatAmount(balance.MinBalance, balance.Chain))
+}
+
+// Balances returns the latest balance of every chain, sorted by chain
+func (b *BalanceMonitor) Balances() []RelayerBalance {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	result := make([]RelayerBalance, 0, len(b.balances))
+	for _, balance := range b.balances {
+		result = append(result, balance)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Chain.String() < result[j].Chain.String()
+	})
+	return result
+}
+
+func formatAmount(amount uint64, chain common.Chain) string {
+	return fmt.Sprintf("%d.%08d %s", amount/common.One, amount%common.One, chain.GetGasAsset().Symbol)
+}
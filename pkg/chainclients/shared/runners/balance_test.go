@@ -0,0 +1,103 @@
+package runners
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/common/cosmos"
+	"github.com/mapprotocol/compass-tss/config"
+)
+
+type fakeBalanceSource struct {
+	minBalance uint64
+	balance    uint64
+	err        error
+}
+
+func (f *fakeBalanceSource) GetConfig() config.BifrostChainConfiguration {
+	return config.BifrostChainConfiguration{MinRelayerBalance: f.minBalance}
+}
+
+func (f *fakeBalanceSource) GetAddress(common.PubKey) string {
+	return "0x25fa71d4f689f4b65eb6d020a414090828281d51"
+}
+
+func (f *fakeBalanceSource) GetAccount(common.PubKey, *big.Int) (common.Account, error) {
+	if f.err != nil {
+		return common.Account{}, f.err
+	}
+	coins := common.Coins{common.NewCoin(common.ETHAsset, cosmos.NewUint(f.balance))}
+	return common.NewAccount(1, 0, coins, false), nil
+}
+
+func TestBalanceMonitor(t *testing.T) {
+	source := &fakeBalanceSource{minBalance: 5 * common.One / 100, balance: common.One}
+	var alerts []string
+	monitor := NewBalanceMonitor(config.BifrostRelayerBalanceConfiguration{AlertInterval: time.Hour}, "",
+		map[common.Chain]BalanceSource{common.ETHChain: source}, nil, func(text string) error {
+			alerts = append(alerts, text)
+			return nil
+		})
+
+	monitor.Check()
+	balances := monitor.Balances()
+	if len(balances) != 1 || balances[0].Balance != common.One || balances[0].Low {
+		t.Fatalf("unexpected balances: %+v", balances)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("unexpected alerts: %v", alerts)
+	}
+
+	// the balance drops below the minimum, the operator is alerted once per interval
+	source.balance = common.One / 100
+	monitor.Check()
+	monitor.Check()
+	if !monitor.Balances()[0].Low {
+		t.Fatal("balance should be low")
+	}
+	if len(alerts) != 1 || !strings.Contains(alerts[0], "0.01000000 ETH, minimum 0.05000000 ETH") {
+		t.Fatalf("unexpected alerts: %v", alerts)
+	}
+	monitor.lastAlert[common.ETHChain] = time.Now().Add(-2 * time.Hour)
+	monitor.Check()
+	if len(alerts) != 2 {
+		t.Fatalf("low balance should be reported again, got %v", alerts)
+	}
+
+	// a failed fetch keeps the last known balance and doesn't alert
+	source.err = errors.New("rpc unavailable")
+	monitor.Check()
+	balance := monitor.Balances()[0]
+	if balance.Error == "" || !balance.Low || balance.Balance != common.One/100 {
+		t.Fatalf("unexpected balance: %+v", balance)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("unexpected alerts: %v", alerts)
+	}
+
+	// the recovery is reported once
+	source.err = nil
+	source.balance = common.One
+	monitor.Check()
+	monitor.Check()
+	if len(alerts) != 3 || !strings.Contains(alerts[2], "recovered") {
+		t.Fatalf("unexpected alerts: %v", alerts)
+	}
+}
+
+func TestBalanceMonitorWithoutMinimum(t *testing.T) {
+	var alerts []string
+	monitor := NewBalanceMonitor(config.BifrostRelayerBalanceConfiguration{}, "",
+		map[common.Chain]BalanceSource{common.ETHChain: &fakeBalanceSource{}}, nil, func(text string) error {
+			alerts = append(alerts, text)
+			return nil
+		})
+	monitor.Check()
+	if monitor.Balances()[0].Low || len(alerts) != 0 {
+		t.Fatal("an empty account should not be reported without a minimum")
+	}
+}
@@ -106,12 +106,15 @@ type Bridge interface {
 	GetConfig() config.BifrostClientConfiguration
 	GetContext() ctx.Context
 	GetTxByBlockNumber(blockHeight int64) (types.TxOut, error)
+	GetTxOutsInRange(from, to int64) ([]types.TxOut, error)
 	SendKeyGenStdTx(epoch *big.Int, poolPubKey common.PubKey, signature, keyShares []byte, blame []ecommon.Address,
 		members []ecommon.Address) (string, error)
 	GetKeyShare() ([]byte, []byte, error)
 	GetKeysignParty(vaultPubKey common.PubKey) (common.PubKeys, error)
 	PostKeysignFailure(blame stypes.Blame, height int64, memo string, coins common.Coins, pubkey common.PubKey) (string, error)
 	GetEpochInfo(epoch *big.Int) (*structure.EpochInfo, error)
+	GetMaintainerEpochs() (*big.Int, *big.Int, error)
+	GetEpochPubKeys(epoch *big.Int) ([]common.PubKey, error)
 	GetChainID(name string) (*big.Int, error)
 	GetFusionReceiver() ecommon.Address
 	GetChainName(chain *big.Int) (string, error)
@@ -0,0 +1,233 @@
+package utxo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcutil"
+)
+
+// -------------------------------------------------------------------------------------
+// Coin Selection
+// -------------------------------------------------------------------------------------
+
+const (
+	// CoinSelectionOldestFirst spends the most confirmed UTXOs first, and at least the
+	// maximum number of inputs when available to consolidate the vault.
+	CoinSelectionOldestFirst = "oldest_first"
+
+	// CoinSelectionLargestFirst spends the largest UTXOs first, for the fewest inputs and
+	// so the lowest fee.
+	CoinSelectionLargestFirst = "largest_first"
+
+	// CoinSelectionBranchAndBound looks for a set of UTXOs matching the outbound without
+	// change, falling back to largest first when there is none.
+	CoinSelectionBranchAndBound = "branch_and_bound"
+
+	// CoinSelectionSmallestFirst spends the smallest UTXOs first, and at least the maximum
+	// number of inputs when available, to consolidate dust while fees are paid anyway.
+	CoinSelectionSmallestFirst = "smallest_first"
+)
+
+// bnbMaxTries bounds the branch and bound search, as in Bitcoin Core.
+const bnbMaxTries = 100000
+
+// CoinSelectionRequest describes the outbound UTXOs are selected for, amounts are in sats
+// and sizes in vbytes.
+type CoinSelectionRequest struct {
+	// Target is the amount paid by the outbound.
+	Target int64
+
+	// FeeRate is the fee rate of the outbound in sats per vbyte.
+	FeeRate int64
+
+	// BaseSize is the size of the outbound without inputs.
+	BaseSize int64
+
+	// InputSize is the size added by each input.
+	InputSize int64
+
+	// ChangeSize is the size of the change output back to the vault.
+	ChangeSize int64
+
+	// MaxInputs is the number of UTXOs spent at most, unless more are needed to cover the
+	// outbound. Consolidating strategies spend that many when available.
+	MaxInputs int
+}
+
+// Fee returns the fee of the outbound spending the given number of inputs.
+func (r CoinSelectionRequest) Fee(inputs int) int64 {
+	return (r.BaseSize + int64(inputs)*r.InputSize) * r.FeeRate
+}
+
+// covered returns true when the amount spent by the inputs pays the outbound and its fee.
+func (r CoinSelectionRequest) covered(amount int64, inputs int) bool {
+	return amount >= r.Target+r.Fee(inputs)
+}
+
+// CoinSelector picks the UTXOs spent by an outbound from the spendable UTXOs of the vault.
+// Every member of the vault builds the outbound on its own, so the selection must only
+// depend on the UTXOs and the request.
+type CoinSelector interface {
+	Select(utxos []btcjson.ListUnspentResult, req CoinSelectionRequest) []btcjson.ListUnspentResult
+}
+
+// NewCoinSelector returns the coin selector of the strategy, oldest first when empty.
+func NewCoinSelector(strategy string) (CoinSelector, error) {
+	switch strings.ToLower(strategy) {
+	case "", CoinSelectionOldestFirst:
+		return sortedSelector{less: oldestFirst, consolidate: true}, nil
+	case CoinSelectionLargestFirst:
+		return sortedSelector{less: largestFirst}, nil
+	case CoinSelectionBranchAndBound:
+		return branchAndBoundSelector{}, nil
+	case CoinSelectionSmallestFirst:
+		return sortedSelector{less: smallestFirst, consolidate: true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported coin selection strategy: %s", strategy)
+	}
+}
+
+// utxoSats returns the amount of the UTXO in sats.
+func utxoSats(u btcjson.ListUnspentResult) int64 {
+	amt, err := btcutil.NewAmount(u.Amount)
+	if err != nil {
+		return 0
+	}
+	return int64(amt)
+}
+
+// byOutpoint breaks ties between UTXOs so they are ordered the same on every node.
+func byOutpoint(a, b btcjson.ListUnspentResult) bool {
+	if a.TxID != b.TxID {
+		return a.TxID < b.TxID
+	}
+	return a.Vout < b.Vout
+}
+
+func oldestFirst(a, b btcjson.ListUnspentResult) bool {
+	if a.Confirmations != b.Confirmations {
+		return a.Confirmations > b.Confirmations
+	}
+	return byOutpoint(a, b)
+}
+
+func largestFirst(a, b btcjson.ListUnspentResult) bool {
+	if sa, sb := utxoSats(a), utxoSats(b); sa != sb {
+		return sa > sb
+	}
+	return byOutpoint(a, b)
+}
+
+func smallestFirst(a, b btcjson.ListUnspentResult) bool {
+	if sa, sb := utxoSats(a), utxoSats(b); sa != sb {
+		return sa < sb
+	}
+	return byOutpoint(a, b)
+}
+
+// sortUTXOs returns a sorted copy of the UTXOs.
+func sortUTXOs(utxos []btcjson.ListUnspentResult, less func(a, b btcjson.ListUnspentResult) bool) []btcjson.ListUnspentResult {
+	sorted := make([]btcjson.ListUnspentResult, len(utxos))
+	copy(sorted, utxos)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	return sorted
+}
+
+// sortedSelector spends UTXOs in order until the outbound is covered, consolidating
+// selectors keep going up to the maximum number of inputs.
+type sortedSelector struct {
+	less        func(a, b btcjson.ListUnspentResult) bool
+	consolidate bool
+}
+
+func (s sortedSelector) Select(utxos []btcjson.ListUnspentResult, req CoinSelectionRequest) []btcjson.ListUnspentResult {
+	var result []btcjson.ListUnspentResult
+	var amount int64
+	for _, u := range sortUTXOs(utxos, s.less) {
+		result = append(result, u)
+		amount += utxoSats(u)
+		if !req.covered(amount, len(result)) {
+			continue
+		}
+		if !s.consolidate || len(result) >= req.MaxInputs {
+			break
+		}
+	}
+	return result
+}
+
+// branchAndBoundSelector searches for the UTXOs paying the outbound and its fee without
+// leaving more than the cost of a change output, so no change is created.
+type branchAndBoundSelector struct{}
+
+func (s branchAndBoundSelector) Select(utxos []btcjson.ListUnspentResult, req CoinSelectionRequest) []btcjson.ListUnspentResult {
+	// effective values are net of the fee of spending the input
+	inputFee := req.InputSize * req.FeeRate
+	candidates := make([]btcjson.ListUnspentResult, 0, len(utxos))
+	for _, u := range sortUTXOs(utxos, largestFirst) {
+		if utxoSats(u) > inputFee {
+			candidates = append(candidates, u)
+		}
+	}
+	values := make([]int64, len(candidates))
+	for i, u := range candidates {
+		values[i] = utxoSats(u) - inputFee
+	}
+	if selected := branchAndBound(values, req.Target+req.BaseSize*req.FeeRate, req.ChangeSize*req.FeeRate, req.MaxInputs); selected != nil {
+		result := make([]btcjson.ListUnspentResult, 0, len(selected))
+		for _, i := range selected {
+			result = append(result, candidates[i])
+		}
+		return result
+	}
+	return sortedSelector{less: largestFirst}.Select(utxos, req)
+}
+
+// branchAndBound returns the indexes of the values, sorted from largest to smallest, that
+// sum up to the target without exceeding it by more than the window. The match wasting
+// the least is returned, nil when there is none within the tries.
+func branchAndBound(values []int64, target, window int64, maxInputs int) []int {
+	// remaining[i] is the sum of the values from i on, to prune branches that fall short
+	remaining := make([]int64, len(values)+1)
+	for i := len(values) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + values[i]
+	}
+
+	var (
+		best      []int
+		bestWaste int64
+		selected  []int
+		tries     int
+	)
+	var search func(i int, total int64)
+	search = func(i int, total int64) {
+		if tries >= bnbMaxTries {
+			return
+		}
+		tries++
+		if total > target+window {
+			return
+		}
+		if total >= target {
+			if waste := total - target; best == nil || waste < bestWaste {
+				best = append([]int(nil), selected...)
+				bestWaste = waste
+			}
+			return
+		}
+		if i == len(values) || total+remaining[i] < target {
+			return
+		}
+		if maxInputs <= 0 || len(selected) < maxInputs {
+			selected = append(selected, i)
+			search(i+1, total+values[i])
+			selected = selected[:len(selected)-1]
+		}
+		search(i+1, total)
+	}
+	search(0, 0)
+	return best
+}
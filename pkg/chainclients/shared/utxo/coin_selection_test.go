@@ -0,0 +1,214 @@
+package utxo
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcutil"
+	. "gopkg.in/check.v1"
+)
+
+type CoinSelectionTestSuite struct{}
+
+var _ = Suite(&CoinSelectionTestSuite{})
+
+// btc sizes in vbytes
+var testCoinSelectionRequest = CoinSelectionRequest{
+	FeeRate:    10,
+	BaseSize:   52,
+	InputSize:  68,
+	ChangeSize: 31,
+	MaxInputs:  10,
+}
+
+func testUTXO(id int, sats int64, confirmations int64) btcjson.ListUnspentResult {
+	return btcjson.ListUnspentResult{
+		TxID:          fmt.Sprintf("%064x", id),
+		Amount:        btcutil.Amount(sats).ToBTC(),
+		Confirmations: confirmations,
+	}
+}
+
+func sumSats(utxos []btcjson.ListUnspentResult) int64 {
+	total := int64(0)
+	for _, u := range utxos {
+		total += utxoSats(u)
+	}
+	return total
+}
+
+func (s *CoinSelectionTestSuite) TestNewCoinSelector(c *C) {
+	for _, strategy := range []string{"", CoinSelectionOldestFirst, CoinSelectionLargestFirst, CoinSelectionBranchAndBound, "SMALLEST_FIRST"} {
+		selector, err := NewCoinSelector(strategy)
+		c.Assert(err, IsNil, Commentf(strategy))
+		c.Assert(selector, NotNil)
+	}
+	_, err := NewCoinSelector("random")
+	c.Assert(err, NotNil)
+}
+
+func (s *CoinSelectionTestSuite) TestSortedSelectors(c *C) {
+	utxos := []btcjson.ListUnspentResult{
+		testUTXO(1, 50000, 1),
+		testUTXO(2, 10000, 30),
+		testUTXO(3, 200000, 10),
+		testUTXO(4, 20000, 20),
+	}
+	req := testCoinSelectionRequest
+	req.Target = 40000
+	req.MaxInputs = 2
+
+	// the oldest are spent first, at least up to the maximum inputs
+	oldest, _ := NewCoinSelector(CoinSelectionOldestFirst)
+	selected := oldest.Select(utxos, req)
+	c.Assert(selected, HasLen, 3)
+	c.Assert(selected[0].TxID, Equals, utxos[1].TxID)
+	c.Assert(selected[2].TxID, Equals, utxos[2].TxID)
+
+	largest, _ := NewCoinSelector(CoinSelectionLargestFirst)
+	selected = largest.Select(utxos, req)
+	c.Assert(selected, HasLen, 1)
+	c.Assert(selected[0].TxID, Equals, utxos[2].TxID)
+
+	smallest, _ := NewCoinSelector(CoinSelectionSmallestFirst)
+	selected = smallest.Select(utxos, req)
+	c.Assert(selected, HasLen, 3)
+	c.Assert(selected[0].TxID, Equals, utxos[1].TxID)
+	c.Assert(selected[2].TxID, Equals, utxos[0].TxID)
+	c.Assert(req.covered(sumSats(selected), len(selected)), Equals, true)
+
+	// the input order does not change the selection
+	reversed := []btcjson.ListUnspentResult{utxos[3], utxos[2], utxos[1], utxos[0]}
+	c.Assert(smallest.Select(reversed, req), DeepEquals, selected)
+}
+
+func (s *CoinSelectionTestSuite) TestBranchAndBound(c *C) {
+	req := testCoinSelectionRequest
+	req.Target = 30000
+	inputFee := req.InputSize * req.FeeRate
+	utxos := []btcjson.ListUnspentResult{
+		testUTXO(1, 100000, 1),
+		testUTXO(2, 20000+inputFee, 1),
+		testUTXO(3, 10000+inputFee+req.BaseSize*req.FeeRate, 1),
+		testUTXO(4, 15000, 1),
+	}
+
+	// the two inputs paying the outbound and its fee exactly are found
+	bnb, _ := NewCoinSelector(CoinSelectionBranchAndBound)
+	selected := bnb.Select(utxos, req)
+	c.Assert(selected, HasLen, 2)
+	c.Assert(sumSats(selected), Equals, req.Target+req.Fee(2))
+
+	// falls back to largest first without a match
+	req.Target = 60000
+	selected = bnb.Select(utxos, req)
+	c.Assert(selected, HasLen, 1)
+	c.Assert(selected[0].TxID, Equals, utxos[0].TxID)
+
+	c.Assert(branchAndBound([]int64{5, 4, 3}, 7, 0, 0), DeepEquals, []int{1, 2})
+	c.Assert(branchAndBound([]int64{5, 4, 3}, 7, 0, 1), IsNil)
+	c.Assert(branchAndBound([]int64{5, 4, 3}, 13, 0, 0), IsNil)
+}
+
+// coinSelectionSimulation is the outcome of replaying an outbound stream with a strategy.
+type coinSelectionSimulation struct {
+	fees     int64
+	inputs   int
+	changes  int
+	failures int
+	utxos    int
+}
+
+// simulateCoinSelection replays the outbound stream against the UTXO set, every outbound
+// sends its change back to the vault and the vault receives an inbound in between.
+func simulateCoinSelection(selector CoinSelector, utxos []btcjson.ListUnspentResult, outbounds, inbounds []int64) coinSelectionSimulation {
+	var sim coinSelectionSimulation
+	set := append([]btcjson.ListUnspentResult(nil), utxos...)
+	nextID := 1 << 20
+	for i, amount := range outbounds {
+		for j := range set {
+			set[j].Confirmations++
+		}
+		set = append(set, testUTXO(nextID, inbounds[i], 0))
+		nextID++
+
+		req := testCoinSelectionRequest
+		req.Target = amount
+		selected := selector.Select(set, req)
+		spent := sumSats(selected)
+		if !req.covered(spent, len(selected)) {
+			sim.failures++
+			continue
+		}
+
+		fee := req.Fee(len(selected))
+		change := spent - amount - fee
+		// change worth less than creating it is left to the miners
+		if change > req.ChangeSize*req.FeeRate {
+			change -= req.ChangeSize * req.FeeRate
+			fee += req.ChangeSize * req.FeeRate
+			set = append(set, testUTXO(nextID, change, 0))
+			nextID++
+			sim.changes++
+		} else {
+			fee += change
+		}
+		sim.fees += fee
+		sim.inputs += len(selected)
+
+		isSpent := make(map[string]bool, len(selected))
+		for _, u := range selected {
+			isSpent[u.TxID] = true
+		}
+		remaining := set[:0]
+		for _, u := range set {
+			if !isSpent[u.TxID] {
+				remaining = append(remaining, u)
+			}
+		}
+		set = remaining
+	}
+	sim.utxos = len(set)
+	return sim
+}
+
+func (s *CoinSelectionTestSuite) TestCoinSelectionSimulation(c *C) {
+	rnd := rand.New(rand.NewSource(1))
+	utxos := make([]btcjson.ListUnspentResult, 0, 200)
+	for i := 0; i < 200; i++ {
+		// a vault holding many small deposits and a few large ones
+		sats := 10000 + rnd.Int63n(90000)
+		if i%20 == 0 {
+			sats = 5000000 + rnd.Int63n(5000000)
+		}
+		utxos = append(utxos, testUTXO(i, sats, 1+rnd.Int63n(100)))
+	}
+	outbounds := make([]int64, 300)
+	inbounds := make([]int64, 300)
+	for i := range outbounds {
+		outbounds[i] = 20000 + rnd.Int63n(380000)
+		inbounds[i] = 10000 + rnd.Int63n(390000)
+	}
+
+	results := make(map[string]coinSelectionSimulation)
+	for _, strategy := range []string{CoinSelectionOldestFirst, CoinSelectionLargestFirst, CoinSelectionBranchAndBound, CoinSelectionSmallestFirst} {
+		selector, err := NewCoinSelector(strategy)
+		c.Assert(err, IsNil)
+		sim := simulateCoinSelection(selector, utxos, outbounds, inbounds)
+		c.Logf("%-16s fees: %8d inputs: %5d changes: %4d utxos left: %4d failures: %d",
+			strategy, sim.fees, sim.inputs, sim.changes, sim.utxos, sim.failures)
+		c.Assert(sim.failures, Equals, 0, Commentf(strategy))
+		results[strategy] = sim
+	}
+
+	// spending the largest first pays the least in fees, consolidating leaves the vault
+	// with the fewest UTXOs, and exact matches avoid change outputs
+	largest := results[CoinSelectionLargestFirst]
+	for strategy, sim := range results {
+		c.Check(largest.fees <= sim.fees, Equals, true, Commentf(strategy))
+	}
+	c.Check(results[CoinSelectionSmallestFirst].utxos < largest.utxos, Equals, true)
+	c.Check(results[CoinSelectionOldestFirst].utxos < largest.utxos, Equals, true)
+	c.Check(results[CoinSelectionBranchAndBound].changes <= largest.changes, Equals, true)
+}
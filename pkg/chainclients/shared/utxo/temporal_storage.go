@@ -2,12 +2,15 @@ package utxo
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	lru "github.com/hashicorp/golang-lru"
 	"github.com/rs/zerolog/log"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/util"
+
+	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
 )
 
 // -------------------------------------------------------------------------------------
@@ -31,6 +34,10 @@ const (
 	// PrefixObservedTx is the LevelDB key prefix used for storing observed transactions.
 	// The hash of the transaction is appended for the final key.
 	PrefixObservedTx = "observed-"
+
+	// PrefixPendingOutbound is the LevelDB key prefix used for storing outbounds awaiting
+	// confirmation. The order id of the outbound is appended for the final key.
+	PrefixPendingOutbound = "pendingoutbound-"
 )
 
 // -------------------------------------------------------------------------------------
@@ -51,6 +58,52 @@ type TransactionFee struct {
 	VSize int32 `json:"v_size"`
 }
 
+// PendingOutbound is an outbound broadcast by the vault that is not confirmed yet, it is
+// tracked to bump its fee when it stays in the mempool.
+type PendingOutbound struct {
+	// TxOut is the outbound item the transaction was signed for, the first order of a
+	// batched outbound.
+	TxOut stypes.TxOutItem `json:"tx_out"`
+
+	// Batch are the other outbound items paid by a batched outbound.
+	Batch []stypes.TxOutItem `json:"batch,omitempty"`
+
+	// TxID is the hash of the transaction paying the outbound, after a replace-by-fee it
+	// is the hash of the replacement.
+	TxID string `json:"tx_id"`
+
+	// SignedTx is the signed transaction of TxID.
+	SignedTx []byte `json:"signed_tx"`
+
+	// Height is the chain height the outbound was first broadcast at.
+	Height int64 `json:"height"`
+
+	// Fee and VSize are the fee in sats and the vbytes of the transaction package paying
+	// for the outbound, the transaction of TxID and its fee bumping children.
+	Fee   int64 `json:"fee"`
+	VSize int64 `json:"v_size"`
+
+	// Children are the hashes of the child transactions bumping the fee, the output to
+	// the vault of the last one is spent by the next child.
+	Children []string `json:"children,omitempty"`
+
+	// Bumps is the number of times the fee was bumped.
+	Bumps int `json:"bumps"`
+}
+
+// FeeRate returns the fee rate of the transaction package in sats per vbyte.
+func (p *PendingOutbound) FeeRate() int64 {
+	if p.VSize <= 0 {
+		return 0
+	}
+	return p.Fee / p.VSize
+}
+
+// Orders returns the outbound items paid by the transaction.
+func (p *PendingOutbound) Orders() []stypes.TxOutItem {
+	return append([]stypes.TxOutItem{p.TxOut}, p.Batch...)
+}
+
 // -------------------------------------------------------------------------------------
 // TemporalStorage
 // -------------------------------------------------------------------------------------
@@ -256,6 +309,53 @@ func (t *TemporalStorage) UntrackObservedTx(txid string) error {
 	return t.db.Delete([]byte(key), nil)
 }
 
+// SavePendingOutbound stores the provided pending outbound, overwriting the previous
+// state of the same order.
+func (t *TemporalStorage) SavePendingOutbound(p *PendingOutbound) error {
+	buf, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("fail to marshal pending outbound to json: %w", err)
+	}
+	return t.db.Put([]byte(t.getPendingOutboundKey(p.TxOut.OrderId.Hex())), buf, nil)
+}
+
+// GetPendingOutbound returns the pending outbound of the provided order. Note that if it
+// is not found, we will return nil with nil error.
+func (t *TemporalStorage) GetPendingOutbound(orderID string) (*PendingOutbound, error) {
+	buf, err := t.db.Get([]byte(t.getPendingOutboundKey(orderID)), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fail to get pending outbound(%s) from storage: %w", orderID, err)
+	}
+	var p PendingOutbound
+	if err = json.Unmarshal(buf, &p); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal pending outbound: %w", err)
+	}
+	return &p, nil
+}
+
+// GetPendingOutbounds returns all the pending outbounds in storage.
+func (t *TemporalStorage) GetPendingOutbounds() ([]*PendingOutbound, error) {
+	pending := make([]*PendingOutbound, 0)
+	iterator := t.db.NewIterator(util.BytesPrefix([]byte(PrefixPendingOutbound)), nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		var p PendingOutbound
+		if err := json.Unmarshal(iterator.Value(), &p); err != nil {
+			return nil, fmt.Errorf("fail to unmarshal pending outbound: %w", err)
+		}
+		pending = append(pending, &p)
+	}
+	return pending, iterator.Error()
+}
+
+// RemovePendingOutbound stops tracking the pending outbound of the provided order.
+func (t *TemporalStorage) RemovePendingOutbound(orderID string) error {
+	return t.db.Delete([]byte(t.getPendingOutboundKey(orderID)), nil)
+}
+
 // ------------------------------ internal ------------------------------
 
 func (t *TemporalStorage) getBlockMetaKey(height int64) string {
@@ -269,3 +369,7 @@ func (t *TemporalStorage) getMemPoolKey(txid string) string {
 func (t *TemporalStorage) getObservedTxKey(txid string) string {
 	return PrefixObservedTx + txid
 }
+
+func (t *TemporalStorage) getPendingOutboundKey(orderID string) string {
+	return PrefixPendingOutbound + orderID
+}
@@ -1,9 +1,12 @@
 package utxo
 
 import (
+	ecommon "github.com/ethereum/go-ethereum/common"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/storage"
 	. "gopkg.in/check.v1"
+
+	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
 )
 
 type BitcoinTemporalStorageTestSuite struct{}
@@ -21,3 +24,44 @@ func (s *BitcoinTemporalStorageTestSuite) TestNewTemporalStorage(c *C) {
 	c.Assert(dbTemporalStorage, NotNil)
 	c.Assert(db.Close(), IsNil)
 }
+
+func (s *BitcoinTemporalStorageTestSuite) TestPendingOutbound(c *C) {
+	memStorage := storage.NewMemStorage()
+	db, err := leveldb.Open(memStorage, nil)
+	c.Assert(err, IsNil)
+	store, err := NewTemporalStorage(db, 0)
+	c.Assert(err, IsNil)
+
+	orderID := ecommon.HexToHash("0x01")
+	p, err := store.GetPendingOutbound(orderID.Hex())
+	c.Assert(err, IsNil)
+	c.Assert(p, IsNil)
+
+	c.Assert(store.SavePendingOutbound(&PendingOutbound{
+		TxOut: stypes.TxOutItem{OrderId: orderID},
+		TxID:  "tx1",
+		Fee:   2250,
+		VSize: 150,
+	}), IsNil)
+	p, err = store.GetPendingOutbound(orderID.Hex())
+	c.Assert(err, IsNil)
+	c.Assert(p, NotNil)
+	c.Assert(p.TxID, Equals, "tx1")
+	c.Assert(p.FeeRate(), Equals, int64(15))
+
+	// a bump overwrites the pending outbound of the order
+	p.TxID = "tx2"
+	p.Bumps++
+	c.Assert(store.SavePendingOutbound(p), IsNil)
+	pending, err := store.GetPendingOutbounds()
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 1)
+	c.Assert(pending[0].TxID, Equals, "tx2")
+	c.Assert(pending[0].Bumps, Equals, 1)
+
+	c.Assert(store.RemovePendingOutbound(orderID.Hex()), IsNil)
+	pending, err = store.GetPendingOutbounds()
+	c.Assert(err, IsNil)
+	c.Assert(pending, HasLen, 0)
+	c.Assert(db.Close(), IsNil)
+}
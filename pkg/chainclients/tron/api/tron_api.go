@@ -34,6 +34,12 @@ func NewTronApi(url string, timeout time.Duration) *TronApi {
 	}
 }
 
+// SetHTTPClient replaces the http client used for requests, e.g. with one routing
+// through an rpc endpoint pool.
+func (api *TronApi) SetHTTPClient(client *http.Client) {
+	api.http = client
+}
+
 // public
 // ----------------------------------------------------------------------------
 
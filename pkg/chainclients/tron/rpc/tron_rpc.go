@@ -30,6 +30,12 @@ func NewTronRpc(url string, timeout time.Duration) *TronRpc {
 	}
 }
 
+// SetHTTPClient replaces the http client used for requests, e.g. with one routing
+// through an rpc endpoint pool.
+func (rpc *TronRpc) SetHTTPClient(client *http.Client) {
+	rpc.http = client
+}
+
 // public
 // ----------------------------------------------------------------------------
 
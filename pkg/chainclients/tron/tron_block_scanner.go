@@ -14,12 +14,14 @@ import (
 	ecommon "github.com/ethereum/go-ethereum/common"
 	etypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/mapprotocol/compass-tss/common"
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
 	"github.com/mapprotocol/compass-tss/mapclient/types"
 	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
 	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/tron/api"
 	"github.com/mr-tron/base58"
@@ -52,13 +54,15 @@ type TronBlockScanner struct {
 func NewTronBlockScanner(
 	cfg config.BifrostChainConfiguration,
 	bridge shareTypes.Bridge,
+	pool *rpcpool.Pool,
 ) (*TronBlockScanner, error) {
 	logger := log.Logger.With().
 		Str("module", "blockscanner").
 		Str("chain", cfg.ChainID.String()).
 		Logger()
 
-	ethClient, err := ethclient.Dial(cfg.RPCHost)
+	httpClient := pool.HTTPClient(cfg.BlockScanner.HTTPRequestTimeout)
+	rpcClient, err := rpc.DialOptions(context.Background(), pool.URL(), rpc.WithHTTPClient(httpClient))
 	if err != nil {
 		return nil, fmt.Errorf("fail to dial ETH rpc host(%s): %w", cfg.RPCHost, err)
 	}
@@ -66,10 +70,11 @@ func NewTronBlockScanner(
 	scanner := TronBlockScanner{
 		cfg:       cfg.BlockScanner,
 		logger:    logger,
-		api:       api.NewTronApi(cfg.RPCHost, cfg.BlockScanner.HTTPRequestTimeout),
+		api:       api.NewTronApi(pool.URL(), cfg.BlockScanner.HTTPRequestTimeout),
 		bridge:    bridge,
-		ethClient: ethClient,
+		ethClient: ethclient.NewClient(rpcClient),
 	}
+	scanner.api.SetHTTPClient(httpClient)
 	scanner.gatewayAbi, err = abi.JSON(bytes.NewReader(gatewayABI))
 	if err != nil {
 		logger.Err(err).Msg("failed to parse ABI")
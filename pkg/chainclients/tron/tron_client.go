@@ -17,6 +17,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/mapprotocol/compass-tss/blockscanner"
 	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/common/cosmos"
 	tcconfig "github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
 	"github.com/mapprotocol/compass-tss/internal/keys"
@@ -24,6 +25,7 @@ import (
 	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
 	tcmetrics "github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/runners"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/signercache"
 	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
@@ -85,6 +87,13 @@ func NewTronClient(
 		logger.Err(err).Msg("failed to parse ABI")
 		return nil, err
 	}
+	pool, err := rpcpool.NewPoolFromConfig(config, metrics)
+	if err != nil {
+		logger.Err(err).Msg("failed to create rpc pool")
+		return nil, err
+	}
+	httpClient := pool.HTTPClient(config.BlockScanner.HTTPRequestTimeout)
+
 	client := TronClient{
 		logger:     logger,
 		chainId:    config.ChainID.String(),
@@ -93,9 +102,11 @@ func NewTronClient(
 		wg:         &sync.WaitGroup{},
 		stopchan:   make(chan struct{}),
 		gatewayAbi: &gatewayAbi,
-		api:        api.NewTronApi(config.RPCHost, config.BlockScanner.HTTPRequestTimeout),
-		rpc:        rpc.NewTronRpc(config.RPCHost, config.BlockScanner.HTTPRequestTimeout),
+		api:        api.NewTronApi(pool.URL(), config.BlockScanner.HTTPRequestTimeout),
+		rpc:        rpc.NewTronRpc(pool.URL(), config.BlockScanner.HTTPRequestTimeout),
 	}
+	client.api.SetHTTPClient(httpClient)
+	client.rpc.SetHTTPClient(httpClient)
 
 	client.tssKeyManager, err = tss.NewKeySign(server, bridge)
 	if err != nil {
@@ -128,6 +139,7 @@ func NewTronClient(
 	client.tronScanner, err = NewTronBlockScanner(
 		config,
 		client.bridge,
+		pool,
 	)
 	if err != nil {
 		logger.Err(err).Msg("failed to create tron block scanner")
@@ -201,6 +213,16 @@ func (c *TronClient) IsBlockScannerHealthy() bool {
 	return c.blockScanner.IsHealthy()
 }
 
+// Rescan scans the given range of blocks again
+func (c *TronClient) Rescan(from, to int64) error {
+	return c.blockScanner.Rescan(from, to)
+}
+
+// ScanRange fetches the txs of the given range of blocks without sending them to the observer
+func (c *TronClient) ScanRange(from, to int64) ([]stypes.TxIn, error) {
+	return c.blockScanner.ScanRange(from, to)
+}
+
 // GetChain returns the chain.
 func (c *TronClient) GetChain() common.Chain {
 	return c.cfg.ChainID
@@ -247,8 +269,14 @@ func (c *TronClient) GetAccountByAddress(
 	address string,
 	_ *big.Int,
 ) (common.Account, error) {
-	account := common.Account{}
-	return account, nil
+	balance, err := c.api.GetBalance(address)
+	if err != nil {
+		return common.Account{}, fmt.Errorf("fail to get balance of %s: %w", address, err)
+	}
+	// TRX has 6 decimals, accounts are reported with 1e8 precision
+	amount := cosmos.NewUint(balance).MulUint64(100)
+	coins := common.Coins{common.NewCoin(common.TRXAsset, amount)}
+	return common.NewAccount(0, 0, coins, false), nil
 }
 
 // GetBlockScannerHeight returns block scanner height for chain
@@ -0,0 +1,313 @@
+package utxo
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcutil"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/constants"
+	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
+	mem "github.com/mapprotocol/compass-tss/x/memo"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+// Outbound Batching
+////////////////////////////////////////////////////////////////////////////////////////
+
+// errUnresolvedBatch is returned when the orders paid by a batched outbound can not be
+// rebuilt, the block is scanned again rather than the outbound being dropped.
+var errUnresolvedBatch = errors.New("fail to resolve batch outbound")
+
+// maxBatchOutputs is the most orders a batched outbound pays, so it stays within the
+// outputs observed by ignoreTx along with the change and memo outputs.
+const maxBatchOutputs = 9
+
+// BatchMaxOutputs returns the maximum number of orders paid by one outbound transaction,
+// zero when outbounds of the chain are not batched.
+func (c *Client) BatchMaxOutputs() int {
+	switch c.cfg.ChainID {
+	case common.BTCChain, common.DOGEChain:
+		if c.cfg.UTXO.Batch.MaxOutputs > 1 {
+			return c.cfg.UTXO.Batch.MaxOutputs
+		}
+	}
+	return 0
+}
+
+// BatchWindowBlocks returns the number of relay chain blocks outbounds are grouped over.
+func (c *Client) BatchWindowBlocks() int64 {
+	return c.cfg.UTXO.Batch.WindowBlocks
+}
+
+// validateBatch verifies the batch configuration of the chain.
+func (c *Client) validateBatch() error {
+	if c.BatchMaxOutputs() == 0 {
+		return nil
+	}
+	if c.cfg.UTXO.Batch.MaxOutputs > maxBatchOutputs {
+		return fmt.Errorf("batch max outputs must be at most %d", maxBatchOutputs)
+	}
+	if c.cfg.UTXO.Batch.WindowBlocks <= 0 {
+		return errors.New("batch window blocks must be greater than zero")
+	}
+	return nil
+}
+
+// BatchWindow returns the first relay chain height of the window the height is in, and
+// the batches the outbounds of the vault emitted by the relay within the window are paid
+// in. The batches are built from the relay chain alone, in the order the outbounds were
+// emitted, so every node builds the same ones.
+func (c *Client) BatchWindow(vaultAddress string, height int64) (int64, [][]stypes.TxOutItem, error) {
+	window := c.BatchWindowBlocks()
+	maxOutputs := c.BatchMaxOutputs()
+	if maxOutputs == 0 || window <= 0 {
+		return 0, nil, errors.New("outbounds of the chain are not batched")
+	}
+	start := height / window * window
+	chainID, err := c.cfg.ChainID.ChainID()
+	if err != nil {
+		return start, nil, fmt.Errorf("fail to get chain id: %w", err)
+	}
+	txOuts, err := c.bridge.GetTxOutsInRange(start, start+window-1)
+	if err != nil {
+		return start, nil, fmt.Errorf("fail to get relay outbounds of window %d: %w", start, err)
+	}
+
+	var outbounds []stypes.TxOutItem
+	for _, txOut := range txOuts {
+		for _, item := range txOut.TxArray {
+			// the same outbounds the signer stores, see Signer.processTxnOut
+			if item.Method == constants.Completed || item.ToChain == nil || item.ToChain.Cmp(chainID) != 0 ||
+				item.TxType == uint8(constants.MIGRATE) {
+				continue
+			}
+			addr, err := c.VaultAddress(item.Vault)
+			if err != nil || !strings.EqualFold(addr, vaultAddress) {
+				continue
+			}
+			outbounds = append(outbounds, item.TxOutItem(txOut.Height))
+		}
+	}
+	sort.SliceStable(outbounds, func(i, j int) bool {
+		if outbounds[i].Height != outbounds[j].Height {
+			return outbounds[i].Height < outbounds[j].Height
+		}
+		return outbounds[i].LogIndex < outbounds[j].LogIndex
+	})
+
+	var batches [][]stypes.TxOutItem
+	for i := 0; i < len(outbounds); i += maxOutputs {
+		end := i + maxOutputs
+		if end > len(outbounds) {
+			end = len(outbounds)
+		}
+		batches = append(batches, outbounds[i:end])
+	}
+	return start, batches, nil
+}
+
+// VaultAddress returns the address on the chain of the vault an outbound is emitted from
+func (c *Client) VaultAddress(vault []byte) (string, error) {
+	pubKey, err := common.CompressPubKey(vault)
+	if err != nil {
+		return "", fmt.Errorf("fail to compress vault public key: %w", err)
+	}
+	addr, err := common.PubKey(pubKey).GetAddress(c.cfg.ChainID)
+	if err != nil {
+		return "", fmt.Errorf("fail to get vault address: %w", err)
+	}
+	return addr.String(), nil
+}
+
+// SameOutbound returns true when both items are the same outbound event of the relay
+func SameOutbound(a, b stypes.TxOutItem) bool {
+	return a.Height == b.Height && a.LogIndex == b.LogIndex && strings.EqualFold(a.TxHash, b.TxHash)
+}
+
+// SignBatchTx builds and signs one transaction paying the outbounds of a batch of their
+// window, an output per outbound in batch order. The memo names the batch and the
+// outbounds paid, so every node maps the outputs back to the orders from the relay chain.
+// Returns the signed transaction, a serialized checkpoint on error, the outbounds paid
+// and an error.
+func (c *Client) SignBatchTx(txs []stypes.TxOutItem, thorchainHeight int64) ([]byte, []byte, []stypes.TxOutItem, error) {
+	if len(txs) == 0 {
+		return nil, nil, nil, nil
+	}
+	vaultAddress, err := txs[0].VaultPubKey.GetAddress(c.cfg.ChainID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("fail to get vault address: %w", err)
+	}
+	window, batches, err := c.BatchWindow(vaultAddress.String(), txs[0].Height)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	index := -1
+	for i, batch := range batches {
+		for _, out := range batch {
+			if SameOutbound(out, txs[0]) {
+				index = i
+			}
+		}
+	}
+	if index < 0 {
+		return nil, nil, nil, fmt.Errorf("outbound(%s) is not in a batch of window %d", txs[0].OrderId.Hex(), window)
+	}
+
+	included := make([]stypes.TxOutItem, 0, len(txs))
+	var paid uint64
+	found := 0
+	for i, out := range batches[index] {
+		for _, tx := range txs {
+			if !SameOutbound(out, tx) {
+				continue
+			}
+			found++
+			prepared, ok, err := c.prepareOutbound(tx)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("fail to prepare outbound(%s): %w", tx.OrderId.Hex(), err)
+			}
+			if ok {
+				included = append(included, prepared)
+				paid |= 1 << uint(i)
+			}
+		}
+	}
+	if found != len(txs) {
+		return nil, nil, nil, fmt.Errorf("outbounds are not batch %d of window %d", index, window)
+	}
+	if len(included) == 0 {
+		return nil, nil, nil, nil
+	}
+	// a checkpoint is only kept for the batch as a whole
+	included[0].Checkpoint = txs[0].Checkpoint
+	if len(included) == 1 {
+		signed, checkpoint, _, err := c.signOutbound(included, "", thorchainHeight)
+		return signed, checkpoint, included, err
+	}
+
+	memo := mem.NewBatchMemo(window, index, paid).String()
+	signed, checkpoint, _, err := c.signOutbound(included, memo, thorchainHeight)
+	return signed, checkpoint, included, err
+}
+
+// BroadcastBatchTx broadcasts the signed transaction paying the outbounds.
+func (c *Client) BroadcastBatchTx(txs []stypes.TxOutItem, payload []byte) (string, error) {
+	if len(txs) == 0 {
+		return "", errors.New("no outbound to broadcast")
+	}
+	return c.broadcastOutbound(txs, payload)
+}
+
+// getTxIns returns the tx in items of the transaction, an item per order paid when it
+// is a batched outbound.
+func (c *Client) getTxIns(tx *btcjson.TxRawResult, height int64, isMemPool bool, vinZeroTxs map[string]*btcjson.TxRawResult) ([]stypes.TxInItem, error) {
+	if memo, err := c.getMemo(tx); err == nil {
+		if parsedMemo, err := mem.ParseMemo(memo); err == nil && parsedMemo.IsType(mem.TxBatch) {
+			txIns, err := c.getBatchTxIns(tx, height, isMemPool, vinZeroTxs, parsedMemo.(mem.BatchMemo))
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", errUnresolvedBatch, err)
+			}
+			return txIns, nil
+		}
+	}
+	txIn, err := c.getTxIn(tx, height, isMemPool, vinZeroTxs)
+	if err != nil || txIn.IsEmpty() {
+		return nil, err
+	}
+	return []stypes.TxInItem{txIn}, nil
+}
+
+// getBatchTxIns returns a tx in item per order paid by a batched outbound, observed with
+// the memo the order would be paid with on its own. The orders and their outputs are
+// rebuilt from the relay chain with the batch named by the memo. The fee is split evenly
+// across the orders.
+func (c *Client) getBatchTxIns(tx *btcjson.TxRawResult, height int64, isMemPool bool, vinZeroTxs map[string]*btcjson.TxRawResult,
+	batchMemo mem.BatchMemo) ([]stypes.TxInItem, error) {
+	if c.ignoreTx(tx, height) {
+		c.log.Debug().Int64("height", height).Str("txid", tx.Txid).Msg("ignore tx not matching format")
+		return nil, nil
+	}
+	// RBF enabled transaction will not be observed until committed to block
+	if c.isRBFEnabled(tx) && isMemPool {
+		c.log.Debug().Int64("height", height).Str("txid", tx.Txid).Msg("ignore RBF enabled tx in mempool")
+		return nil, nil
+	}
+	sender, err := c.getSender(tx, vinZeroTxs)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get sender from tx: %w", err)
+	}
+	if !c.isAsgardAddress(sender) {
+		c.log.Debug().Int64("height", height).Str("txid", tx.Txid).Msg("ignore batch memo not sent by a vault")
+		return nil, nil
+	}
+	window, batches, err := c.BatchWindow(sender, batchMemo.Window)
+	if err != nil {
+		return nil, err
+	}
+	if window != batchMemo.Window || batchMemo.Batch >= len(batches) {
+		return nil, fmt.Errorf("batch %d of window %d not found", batchMemo.Batch, batchMemo.Window)
+	}
+	batch := batches[batchMemo.Batch]
+	if batchMemo.Paid>>uint(len(batch)) != 0 {
+		return nil, fmt.Errorf("batch %d of window %d has %d outbounds, paid %b", batchMemo.Batch, window, len(batch), batchMemo.Paid)
+	}
+	var orders []stypes.TxOutItem
+	for i, out := range batch {
+		if batchMemo.Paid&(1<<uint(i)) != 0 {
+			orders = append(orders, out)
+		}
+	}
+	if len(orders) > len(tx.Vout) {
+		return nil, fmt.Errorf("batch %d of window %d pays %d orders with %d outputs", batchMemo.Batch, window, len(orders), len(tx.Vout))
+	}
+
+	txResult, err := c.rpc.GetRawTransactionVerboseWithFee(tx.Txid)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get tx result: %w", err)
+	}
+	fee, err := btcutil.NewAmount(txResult.Fee)
+	if err != nil {
+		return nil, fmt.Errorf("fail to parse amount(%f): %w", txResult.Fee, err)
+	}
+	share := fee / btcutil.Amount(len(orders))
+
+	txIns := make([]stypes.TxInItem, 0, len(orders))
+	for i, order := range orders {
+		vout := tx.Vout[i]
+		addresses := c.getAddressesFromScriptPubKey(vout.ScriptPubKey)
+		if len(addresses) == 0 {
+			return nil, fmt.Errorf("batch outbound output %d has no address", i)
+		}
+		toAddr := addresses[0]
+		if c.cfg.ChainID.Equals(common.BCHChain) {
+			toAddr = c.stripBCHAddress(toAddr)
+		}
+		amount, err := btcutil.NewAmount(vout.Value)
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse amount(%f): %w", vout.Value, err)
+		}
+		memo, err := c.getOutboundMemo(order)
+		if err != nil {
+			return nil, err
+		}
+		parsedMemo, err := mem.ParseMemo(memo)
+		if err != nil {
+			return nil, fmt.Errorf("fail to parse memo(%s) of batch outbound: %w", memo, err)
+		}
+		gas := share
+		if i == 0 { // the remainder of the split is charged to the first order
+			gas += fee - share*btcutil.Amount(len(orders))
+		}
+		txIn, err := c.getVaultOutTxIn(tx, height, sender, toAddr, memo, parsedMemo, amount, gas, uint(i))
+		if err != nil {
+			return nil, err
+		}
+		txIns = append(txIns, txIn)
+	}
+	return txIns, nil
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcutil"
 	"github.com/ethereum/go-ethereum/crypto"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/mapprotocol/compass-tss/blockscanner"
 	btypes "github.com/mapprotocol/compass-tss/blockscanner/types"
@@ -29,6 +30,7 @@ import (
 	"github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/runners"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/signercache"
 	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
@@ -63,12 +65,14 @@ type Client struct {
 	nodePrivKey        *btcec.PrivateKey
 	tssKeySigner       tss.RelayKeyManager
 	signerCacheManager *signercache.CacheManager
+	coinSelector       utxo.CoinSelector
 
 	// ---------- sync ----------
 	wg                    *sync.WaitGroup
 	signerLock            *sync.Mutex
 	vaultLocks            map[string]*sync.Mutex
 	consolidateInProgress *atomic.Bool
+	feeBumpInProgress     *atomic.Bool
 
 	// ---------- scanner ----------
 	blockScanner    *blockscanner.BlockScanner
@@ -95,6 +99,8 @@ type Client struct {
 	lastFeeRate             uint64
 	feeRateCache            []uint64
 	lastSolvencyCheckHeight int64
+	feeBumpRecorder         FeeBumpRecorder
+	blameRecorder           BlameRecorder
 
 	// ---------- testing ----------
 	disableVinZeroBatch bool
@@ -121,8 +127,19 @@ func NewClient(
 
 	logger := log.Logger.With().Stringer("chain", cfg.ChainID).Logger()
 
-	// create rpc client
-	rpcClient, err := rpc.NewClient(cfg.RPCHost, cfg.UserName, cfg.Password, cfg.MaxRPCRetries, logger)
+	// create rpc client, failing over between the configured rpc hosts
+	pool, err := rpcpool.NewPoolFromConfig(cfg, m)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create rpc pool: %w", err)
+	}
+	rpcClient, err := rpc.NewClient(
+		pool.URL(),
+		cfg.UserName,
+		cfg.Password,
+		cfg.MaxRPCRetries,
+		logger,
+		ethrpc.WithHTTPClient(pool.HTTPClient(cfg.BlockScanner.HTTPRequestTimeout)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("fail to create rpc client: %w", err)
 	}
@@ -157,11 +174,25 @@ func NewClient(
 		signerLock:            &sync.Mutex{},
 		vaultLocks:            make(map[string]*sync.Mutex),
 		consolidateInProgress: atomic.NewBool(false),
+		feeBumpInProgress:     atomic.NewBool(false),
 		stopchan:              make(chan struct{}),
 		currentBlockHeight:    atomic.NewInt64(0),
 		bridge:                bridge,
 	}
 
+	if err = c.validateFeeBump(); err != nil {
+		return nil, fmt.Errorf("invalid fee bump config: %w", err)
+	}
+
+	if err = c.validateBatch(); err != nil {
+		return nil, fmt.Errorf("invalid batch config: %w", err)
+	}
+
+	c.coinSelector, err = utxo.NewCoinSelector(c.cfg.UTXO.CoinSelection)
+	if err != nil {
+		return nil, fmt.Errorf("invalid coin selection config: %w", err)
+	}
+
 	// import the node local address in the daemon wallet
 	if err = c.RegisterPublicKey(c.nodePubKey); err != nil {
 		return nil, fmt.Errorf("fail to register (%s): %w", c.nodePubKey, err)
@@ -211,6 +242,16 @@ func (c *Client) IsBlockScannerHealthy() bool {
 	return c.blockScanner.IsHealthy()
 }
 
+// Rescan scans the given range of blocks again
+func (c *Client) Rescan(from, to int64) error {
+	return c.blockScanner.Rescan(from, to)
+}
+
+// ScanRange fetches the txs of the given range of blocks without sending them to the observer
+func (c *Client) ScanRange(from, to int64) ([]types.TxIn, error) {
+	return c.blockScanner.ScanRange(from, to)
+}
+
 // GetHeight returns current chain (not scanner) height.
 func (c *Client) GetHeight() (int64, error) {
 	return c.rpc.GetBlockCount()
@@ -511,6 +552,14 @@ func (c *Client) FetchTxs(height, chainHeight int64) (types.TxIn, error) {
 		go c.consolidateUTXOs()
 	}
 
+	// bump the fee of stuck outbounds if there is not a bump in progress
+	if chainHeight-height <= c.cfg.BlockScanner.ObservationFlexibilityBlocks &&
+		c.feeBumpMethod() != "" && !c.feeBumpInProgress.Load() {
+		c.wg.Add(1)
+		c.feeBumpInProgress.Store(true)
+		go c.bumpStuckOutbounds()
+	}
+
 	return txIn, nil
 }
 
@@ -595,23 +644,22 @@ func (c *Client) FetchMemPool(height int64) (types.TxIn, error) {
 			}
 
 			// filter transactions
-			var txInItem types.TxInItem
-			txInItem, err = c.getTxIn(result, height, true, nil)
+			var txInItems []types.TxInItem
+			txInItems, err = c.getTxIns(result, height, true, nil)
 			if err != nil {
 				c.log.Error().Str("txid", result.Txid).Err(err).Msg("fail to get TxInItem")
 				continue
 			}
-			if txInItem.IsEmpty() {
+			if len(txInItems) == 0 {
 				continue
 			}
-			if txInItem.IsEmpty() {
-				c.log.Debug().Str("txid", result.Txid).Err(err).Msg("not found tx in")
-			}
 			//if txInItem.Coins.IsEmpty() {
 			//	continue
 			//}
 
-			txIn.TxArray = append(txIn.TxArray, &txInItem)
+			for idx := range txInItems {
+				txIn.TxArray = append(txIn.TxArray, &txInItems[idx])
+			}
 		}
 	}
 
@@ -535,17 +535,6 @@ func (c *Client) getTxIn(tx *btcjson.TxRawResult, height int64, isMemPool bool,
 		return types.TxInItem{}, fmt.Errorf("fail to encode payload: %w", err)
 	}
 	if c.isAsgardAddress(sender) {
-		token := constants.BTCToken
-		tokenAddress, err := c.bridge.GetTokenAddress(chainID, token)
-		if err != nil {
-			return types.TxInItem{}, fmt.Errorf("fail to get token address: %w, chainID: %s, token: %s", err, chainID, token)
-		}
-
-		vaultPbuKey, err := utxo.GetAsgardPubKeyByAddress(c.cfg.ChainID, c.bridge, common.Address(sender))
-		if err != nil {
-			return types.TxInItem{}, fmt.Errorf("fail to get vault pub key by address: %w", err)
-		}
-
 		amount, err := btcutil.NewAmount(tx.Vout[0].Value)
 		if err != nil {
 			return types.TxInItem{}, fmt.Errorf("fail to parse amount(%f): %w", tx.Vout[0].Value, err)
@@ -558,77 +547,7 @@ func (c *Client) getTxIn(tx *btcjson.TxRawResult, height int64, isMemPool bool,
 		if err != nil {
 			return types.TxInItem{}, fmt.Errorf("fail to parse amount(%f): %w", tx.Vout[0].Value, err)
 		}
-
-		var (
-			toBytes   []byte
-			txOutType constants.TxInType
-		)
-
-		switch parsedMemo.GetType() {
-		case mem.TxInbound:
-			address, err := btcutil.DecodeAddress(toAddr, c.getChainCfgBTC())
-			if err != nil {
-				return types.TxInItem{}, fmt.Errorf("fail to decode btc address(%s): %w", address, err)
-			}
-			to, err := EncodeBitcoinAddress(address)
-			if err != nil {
-				return types.TxInItem{}, fmt.Errorf("fail to encode btc address(%s): %w", address.String(), err)
-			}
-			toBytes, err = hex.DecodeString(strings.TrimPrefix(to, "0x"))
-			if err != nil {
-				return types.TxInItem{}, fmt.Errorf("fail to decode hex address(%s): %w", to, err)
-			}
-			txOutType = constants.TRANSFER
-		case mem.TxMigrate:
-			toBytes = []byte{}
-			payload, err = utxo.GetAsgardPubKeyByAddress(c.cfg.ChainID, c.bridge, common.Address(toAddr))
-			if err != nil {
-				return types.TxInItem{}, fmt.Errorf("fail to get vault pub key by address: %w", err)
-			}
-			txOutType = constants.MIGRATE
-		case mem.TxRefund:
-			address, err := btcutil.DecodeAddress(toAddr, c.getChainCfgBTC())
-			if err != nil {
-				return types.TxInItem{}, fmt.Errorf("fail to decode btc address(%s): %w", address, err)
-			}
-			to, err := EncodeBitcoinAddress(address)
-			if err != nil {
-				return types.TxInItem{}, fmt.Errorf("fail to encode btc address(%s): %w", address.String(), err)
-			}
-			toBytes, err = hex.DecodeString(strings.TrimPrefix(to, "0x"))
-			if err != nil {
-				return types.TxInItem{}, fmt.Errorf("fail to decode hex address(%s): %w", to, err)
-			}
-			txOutType = constants.REFUND
-		default:
-			return types.TxInItem{}, fmt.Errorf("unsupported tx type: %s", parsedMemo.GetType())
-		}
-
-		chainAndGasLimit := make([]byte, 32)
-		toChain := ethcommon.LeftPadBytes(chainID.Bytes(), 8)
-		copy(chainAndGasLimit[8:16], toChain)
-		txIn := types.TxInItem{
-			Tx:               tx.Txid,
-			Memo:             memo,
-			Height:           new(big.Int).SetInt64(height),
-			Amount:           big.NewInt(int64(amount)),
-			OrderId:          ethcommon.HexToHash(parsedMemo.GetOrderID()),
-			GasUsed:          big.NewInt(int64(fee)),
-			Token:            tokenAddress,
-			Vault:            vaultPbuKey,
-			From:             nil,
-			To:               toBytes,
-			Payload:          payload,
-			Method:           constants.VoteTxOut,
-			LogIndex:         0,
-			ChainAndGasLimit: new(big.Int).SetBytes(chainAndGasLimit),
-			TxOutType:        uint8(txOutType),
-			Sequence:         big.NewInt(0),
-			Topic:            constants.EventOfBridgeIn.GetTopic().String(),
-			Timestamp:        tx.Blocktime,
-		}
-		c.log.Info().Int64("height", height).Str("txid", tx.Txid).Interface("txIn", txIn).Msg("got tx in")
-		return txIn, nil
+		return c.getVaultOutTxIn(tx, height, sender, toAddr, memo, parsedMemo, amount, fee, 0)
 	}
 
 	if c.isAsgardAddress(toAddr) {
@@ -757,6 +676,101 @@ func (c *Client) getTxIn(tx *btcjson.TxRawResult, height int64, isMemPool bool,
 	return types.TxInItem{}, nil
 }
 
+// getVaultOutTxIn returns the tx in item of an outbound sent by a vault, paying amount to
+// toAddr for the order of the memo.
+func (c *Client) getVaultOutTxIn(tx *btcjson.TxRawResult, height int64, sender, toAddr, memo string, parsedMemo mem.Memo,
+	amount, fee btcutil.Amount, logIndex uint) (types.TxInItem, error) {
+	chainID, err := c.GetChain().ChainID()
+	if err != nil {
+		return types.TxInItem{}, fmt.Errorf("fail to get chain id: %w, chain: %s", err, c.GetChain())
+	}
+	payload, err := utxo.EncodePayload(nil, nil, nil) // todo utxo
+	if err != nil {
+		return types.TxInItem{}, fmt.Errorf("fail to encode payload: %w", err)
+	}
+	token := constants.BTCToken
+	tokenAddress, err := c.bridge.GetTokenAddress(chainID, token)
+	if err != nil {
+		return types.TxInItem{}, fmt.Errorf("fail to get token address: %w, chainID: %s, token: %s", err, chainID, token)
+	}
+
+	vaultPbuKey, err := utxo.GetAsgardPubKeyByAddress(c.cfg.ChainID, c.bridge, common.Address(sender))
+	if err != nil {
+		return types.TxInItem{}, fmt.Errorf("fail to get vault pub key by address: %w", err)
+	}
+
+	var (
+		toBytes   []byte
+		txOutType constants.TxInType
+	)
+
+	switch parsedMemo.GetType() {
+	case mem.TxInbound:
+		address, err := btcutil.DecodeAddress(toAddr, c.getChainCfgBTC())
+		if err != nil {
+			return types.TxInItem{}, fmt.Errorf("fail to decode btc address(%s): %w", address, err)
+		}
+		to, err := EncodeBitcoinAddress(address)
+		if err != nil {
+			return types.TxInItem{}, fmt.Errorf("fail to encode btc address(%s): %w", address.String(), err)
+		}
+		toBytes, err = hex.DecodeString(strings.TrimPrefix(to, "0x"))
+		if err != nil {
+			return types.TxInItem{}, fmt.Errorf("fail to decode hex address(%s): %w", to, err)
+		}
+		txOutType = constants.TRANSFER
+	case mem.TxMigrate:
+		toBytes = []byte{}
+		payload, err = utxo.GetAsgardPubKeyByAddress(c.cfg.ChainID, c.bridge, common.Address(toAddr))
+		if err != nil {
+			return types.TxInItem{}, fmt.Errorf("fail to get vault pub key by address: %w", err)
+		}
+		txOutType = constants.MIGRATE
+	case mem.TxRefund:
+		address, err := btcutil.DecodeAddress(toAddr, c.getChainCfgBTC())
+		if err != nil {
+			return types.TxInItem{}, fmt.Errorf("fail to decode btc address(%s): %w", address, err)
+		}
+		to, err := EncodeBitcoinAddress(address)
+		if err != nil {
+			return types.TxInItem{}, fmt.Errorf("fail to encode btc address(%s): %w", address.String(), err)
+		}
+		toBytes, err = hex.DecodeString(strings.TrimPrefix(to, "0x"))
+		if err != nil {
+			return types.TxInItem{}, fmt.Errorf("fail to decode hex address(%s): %w", to, err)
+		}
+		txOutType = constants.REFUND
+	default:
+		return types.TxInItem{}, fmt.Errorf("unsupported tx type: %s", parsedMemo.GetType())
+	}
+
+	chainAndGasLimit := make([]byte, 32)
+	toChain := ethcommon.LeftPadBytes(chainID.Bytes(), 8)
+	copy(chainAndGasLimit[8:16], toChain)
+	txIn := types.TxInItem{
+		Tx:               tx.Txid,
+		Memo:             memo,
+		Height:           new(big.Int).SetInt64(height),
+		Amount:           big.NewInt(int64(amount)),
+		OrderId:          ethcommon.HexToHash(parsedMemo.GetOrderID()),
+		GasUsed:          big.NewInt(int64(fee)),
+		Token:            tokenAddress,
+		Vault:            vaultPbuKey,
+		From:             nil,
+		To:               toBytes,
+		Payload:          payload,
+		Method:           constants.VoteTxOut,
+		LogIndex:         logIndex,
+		ChainAndGasLimit: new(big.Int).SetBytes(chainAndGasLimit),
+		TxOutType:        uint8(txOutType),
+		Sequence:         big.NewInt(0),
+		Topic:            constants.EventOfBridgeIn.GetTopic().String(),
+		Timestamp:        tx.Blocktime,
+	}
+	c.log.Info().Int64("height", height).Str("txid", tx.Txid).Interface("txIn", txIn).Msg("got tx in")
+	return txIn, nil
+}
+
 func (c *Client) encodePayload(nativeToken, destToken string, mapChainID, destChainID *big.Int, to []byte, parsedMemo mem.Memo) (payload []byte, err error) {
 	var relayData []byte
 	// if the dest token is not native token, we need to build the relay data
@@ -927,14 +941,17 @@ func (c *Client) extractTxs(block *btcjson.GetBlockVerboseTxResult) (types.TxIn,
 	for idx, tx := range block.Tx {
 		// mempool transaction get committed to block , thus remove it from mempool cache
 		c.removeFromMemPoolCache(tx.Hash)
-		var txInItem types.TxInItem
-		txInItem, err = c.getTxIn(&block.Tx[idx], block.Height, false, vinZeroTxs)
+		var txInItems []types.TxInItem
+		txInItems, err = c.getTxIns(&block.Tx[idx], block.Height, false, vinZeroTxs)
+		if errors.Is(err, errUnresolvedBatch) {
+			return txIn, fmt.Errorf("fail to observe tx(%s): %w", tx.Txid, err)
+		}
 		if err != nil {
 			// expected since vouts below dust threshold are skipped for vinZeroTxs
 			c.log.Error().Str("txid", tx.Txid).Err(err).Msg("fail to get TxInItem")
 			continue
 		}
-		if txInItem.IsEmpty() {
+		if len(txInItems) == 0 {
 			c.log.Debug().Str("txid", tx.Txid).Err(err).Msg("not found tx in")
 			continue
 		}
@@ -944,26 +961,35 @@ func (c *Client) extractTxs(block *btcjson.GetBlockVerboseTxResult) (types.TxIn,
 		//if txInItem.Coins[0].Amount.LT(c.cfg.ChainID.DustThreshold()) {
 		//	continue
 		//}
-		if txInItem.Amount == nil {
-			continue
+		var found []*types.TxInItem
+		for i := range txInItems {
+			txInItem := &txInItems[i]
+			if txInItem.Amount == nil {
+				continue
+			}
+			if cosmos.NewUint(txInItem.Amount.Uint64()).LT(c.cfg.ChainID.DustThreshold()) {
+				c.log.Error().Str("txid", tx.Txid).Err(err).Msg(fmt.Sprintf("amount lesser than dust threshold(%d)", c.cfg.ChainID.DustThreshold().Uint64()))
+				continue
+			}
+			found = append(found, txInItem)
 		}
-		if cosmos.NewUint(txInItem.Amount.Uint64()).LT(c.cfg.ChainID.DustThreshold()) {
-			c.log.Error().Str("txid", tx.Txid).Err(err).Msg(fmt.Sprintf("amount lesser than dust threshold(%d)", c.cfg.ChainID.DustThreshold().Uint64()))
+		if len(found) == 0 {
 			continue
 		}
+		// a batched outbound is tracked once for all the orders it pays
 		var added bool
-		added, err = c.temporalStorage.TrackObservedTx(txInItem.Tx)
+		added, err = c.temporalStorage.TrackObservedTx(tx.Txid)
 		if err != nil {
-			c.log.Err(err).Msgf("fail to determine whether hash(%s) had been observed before", txInItem.Tx)
+			c.log.Err(err).Msgf("fail to determine whether hash(%s) had been observed before", tx.Txid)
 		}
 		if !added {
-			c.log.Info().Msgf("tx: %s had been report before, ignore", txInItem.Tx)
-			if err = c.temporalStorage.UntrackObservedTx(txInItem.Tx); err != nil {
-				c.log.Err(err).Msgf("fail to remove observed tx from cache: %s", txInItem.Tx)
+			c.log.Info().Msgf("tx: %s had been report before, ignore", tx.Txid)
+			if err = c.temporalStorage.UntrackObservedTx(tx.Txid); err != nil {
+				c.log.Err(err).Msgf("fail to remove observed tx from cache: %s", tx.Txid)
 			}
 			continue
 		}
-		txItems = append(txItems, &txInItem)
+		txItems = append(txItems, found...)
 	}
 	txIn.TxArray = txItems
 	return txIn, nil
@@ -0,0 +1,490 @@
+package utxo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcjson"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	btcwire "github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/internal/cross"
+	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/metrics"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/utxo"
+	"github.com/mapprotocol/compass-tss/tss"
+)
+
+////////////////////////////////////////////////////////////////////////////////////////
+// Fee Bumping
+////////////////////////////////////////////////////////////////////////////////////////
+
+const (
+	feeBumpRBF  = "rbf"
+	feeBumpCPFP = "cpfp"
+)
+
+// FeeBumpRecorder records the replacements and children broadcast for an outbound
+// against its order.
+type FeeBumpRecorder interface {
+	AddOrUpdateTx(insertData *cross.CrossData, _type string)
+}
+
+// BlameRecorder records the failed keysigns of the fee bumps with the peers blamed for them.
+type BlameRecorder interface {
+	Record(record tss.BlameRecord) error
+}
+
+// SetFeeBumpRecorder sets the recorder the fee bumps of stuck outbounds are reported to.
+func (c *Client) SetFeeBumpRecorder(recorder FeeBumpRecorder) {
+	c.feeBumpRecorder = recorder
+}
+
+// SetBlameRecorder sets the recorder the failed keysigns of fee bumps are reported to.
+func (c *Client) SetBlameRecorder(recorder BlameRecorder) {
+	c.blameRecorder = recorder
+}
+
+// feeBumpMethod returns the configured fee bump method, empty when outbounds of the
+// chain are not bumped.
+func (c *Client) feeBumpMethod() string {
+	switch c.cfg.ChainID {
+	case common.BTCChain, common.DOGEChain:
+		return strings.ToLower(c.cfg.UTXO.FeeBump.Method)
+	default:
+		return ""
+	}
+}
+
+// validateFeeBump verifies the fee bump configuration of the chain.
+func (c *Client) validateFeeBump() error {
+	switch c.feeBumpMethod() {
+	case "":
+		return nil
+	case feeBumpRBF, feeBumpCPFP:
+	default:
+		return fmt.Errorf("unsupported fee bump method: %s", c.cfg.UTXO.FeeBump.Method)
+	}
+	if c.cfg.UTXO.FeeBump.StuckBlocks <= 0 {
+		return errors.New("fee bump stuck blocks must be greater than zero")
+	}
+	if c.cfg.UTXO.FeeBump.FeeRateIncreasePercent <= 0 {
+		return errors.New("fee bump fee rate increase percent must be greater than zero")
+	}
+	return nil
+}
+
+// bumpFeeRate returns the fee rate to bump a transaction paying the given rate to, it is
+// raised by the given percent and at least by the incremental relay fee rate. Returns
+// zero when the rate can not be raised below the maximum.
+func bumpFeeRate(rate, increasePercent, incremental, maxRate int64) int64 {
+	bumped := rate * (100 + increasePercent) / 100
+	if bumped < rate+incremental {
+		bumped = rate + incremental
+	}
+	if maxRate > 0 && bumped > maxRate {
+		bumped = maxRate
+	}
+	if bumped <= rate {
+		return 0
+	}
+	return bumped
+}
+
+// bumpDue returns true when the next bump of an outbound emitted at the given relay height is
+// due at the relay chain height, the bumps are spaced by the stuck blocks.
+func bumpDue(relayHeight, outboundHeight, stuckBlocks int64, bumps int) bool {
+	return outboundHeight > 0 && relayHeight-outboundHeight >= stuckBlocks*int64(bumps+1)
+}
+
+// buildReplacementTx returns an unsigned copy of the signed transaction spending the same
+// inputs, paying the extra fee out of the change output to the source script. The change
+// follows the outputs paying the outbounds.
+func buildReplacementTx(signed *btcwire.MsgTx, payouts int, sourceScript []byte, extraFee, dust int64) (*btcwire.MsgTx, error) {
+	replacement := signed.Copy()
+	for _, txIn := range replacement.TxIn {
+		txIn.SignatureScript = nil
+		txIn.Witness = nil
+	}
+
+	for i := payouts; i < len(replacement.TxOut); i++ {
+		txOut := replacement.TxOut[i]
+		if !bytes.Equal(txOut.PkScript, sourceScript) {
+			continue
+		}
+		if txOut.Value-extraFee < dust {
+			return nil, fmt.Errorf("change output %d can not pay extra fee %d", txOut.Value, extraFee)
+		}
+		txOut.Value -= extraFee
+		return replacement, nil
+	}
+	return nil, errors.New("transaction has no change output")
+}
+
+// buildChildTx returns an unsigned transaction spending the given output back to the
+// source script, paying the given fee.
+func buildChildTx(parent chainhash.Hash, vout uint32, value int64, sourceScript []byte, fee, dust int64) (*btcwire.MsgTx, error) {
+	if value-fee < dust {
+		return nil, fmt.Errorf("output %d can not pay child fee %d", value, fee)
+	}
+	child := btcwire.NewMsgTx(btcwire.TxVersion)
+	child.AddTxIn(btcwire.NewTxIn(btcwire.NewOutPoint(&parent, vout), nil, nil))
+	child.AddTxOut(btcwire.NewTxOut(value-fee, sourceScript))
+	return child, nil
+}
+
+// getWireTx returns the transaction with the given id from the chain daemon.
+func (c *Client) getWireTx(txid string) (*btcwire.MsgTx, error) {
+	raw, err := c.rpc.GetRawTransaction(txid)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get raw transaction(%s): %w", txid, err)
+	}
+	buf, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("fail to decode raw transaction(%s): %w", txid, err)
+	}
+	tx := btcwire.NewMsgTx(btcwire.TxVersion)
+	if err = tx.Deserialize(bytes.NewReader(buf)); err != nil {
+		return nil, fmt.Errorf("fail to deserialize transaction(%s): %w", txid, err)
+	}
+	return tx, nil
+}
+
+// getInputAmounts returns the amounts of the outputs spent by the transaction, keyed by
+// outpoint as in the signing checkpoint.
+func (c *Client) getInputAmounts(tx *btcwire.MsgTx) (map[string]int64, int64, error) {
+	amounts := make(map[string]int64, len(tx.TxIn))
+	total := int64(0)
+	for _, txIn := range tx.TxIn {
+		prev, err := c.getWireTx(txIn.PreviousOutPoint.Hash.String())
+		if err != nil {
+			return nil, 0, err
+		}
+		if int(txIn.PreviousOutPoint.Index) >= len(prev.TxOut) {
+			return nil, 0, fmt.Errorf("invalid outpoint: %s", txIn.PreviousOutPoint)
+		}
+		value := prev.TxOut[txIn.PreviousOutPoint.Index].Value
+		amounts[fmt.Sprintf("%s-%d", txIn.PreviousOutPoint.Hash, txIn.PreviousOutPoint.Index)] = value
+		total += value
+	}
+	return amounts, total, nil
+}
+
+// incrementalFeeRate returns the minimum fee rate increase accepted for a replacement.
+func (c *Client) incrementalFeeRate() int64 {
+	rate := c.cfg.UTXO.DefaultMinRelayFeeSats / 1000
+	if rate < 1 {
+		return 1
+	}
+	return int64(rate)
+}
+
+// trackOutbound stores the broadcast outbound to bump its fee if it gets stuck.
+func (c *Client) trackOutbound(txs []stypes.TxOutItem, tx *btcwire.MsgTx, payload []byte, txid string, height int64) {
+	txOut := txs[0]
+	if c.feeBumpMethod() == "" || txOut.OrderId == (ethcommon.Hash{}) {
+		return
+	}
+	existing, err := c.temporalStorage.GetPendingOutbound(txOut.OrderId.Hex())
+	if err != nil {
+		c.log.Err(err).Str("txid", txid).Msg("fail to get pending outbound")
+		return
+	}
+	if existing != nil && existing.TxID == txid {
+		return
+	}
+
+	_, total, err := c.getInputAmounts(tx)
+	if err != nil {
+		c.log.Err(err).Str("txid", txid).Msg("fail to get input amounts of outbound")
+		return
+	}
+	fee := total
+	for _, out := range tx.TxOut {
+		fee -= out.Value
+	}
+	pending := &utxo.PendingOutbound{
+		TxOut:    txOut,
+		Batch:    txs[1:],
+		TxID:     txid,
+		SignedTx: payload,
+		Height:   height,
+		Fee:      fee,
+		VSize:    mempool.GetTxVirtualSize(btcutil.NewTx(tx)),
+	}
+	if err = c.temporalStorage.SavePendingOutbound(pending); err != nil {
+		c.log.Err(err).Str("txid", txid).Msg("fail to save pending outbound")
+	}
+}
+
+// bumpStuckOutbounds bumps the fee of the outbounds that stayed unconfirmed for the
+// configured number of relay chain blocks, and stops tracking the confirmed ones.
+func (c *Client) bumpStuckOutbounds() {
+	defer func() {
+		c.wg.Done()
+		c.feeBumpInProgress.Store(false)
+	}()
+
+	pending, err := c.temporalStorage.GetPendingOutbounds()
+	if err != nil {
+		c.log.Err(err).Msg("fail to get pending outbounds")
+		return
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].TxOut.OrderId.Hex() < pending[j].TxOut.OrderId.Hex()
+	})
+	// every node of the vault signs the bump, so it is due at a relay height they all agree on
+	relayHeight, err := c.bridge.GetBlockHeight()
+	if err != nil {
+		c.log.Err(err).Msg("fail to get relay chain height")
+		return
+	}
+
+	for _, p := range pending {
+		select {
+		case <-c.stopchan:
+			return
+		default:
+		}
+		if err = c.bumpStuckOutbound(p, relayHeight); err != nil {
+			c.reportFeeBumpFailure(p, err)
+		}
+	}
+}
+
+// reportFeeBumpFailure counts the failed bump, and records the peers blamed when its keysign
+// failed, as the signer does for the keysigns of outbounds.
+func (c *Client) reportFeeBumpFailure(p *utxo.PendingOutbound, err error) {
+	c.log.Err(err).Str("txid", p.TxID).Str("orderId", p.TxOut.OrderId.Hex()).Msg("fail to bump outbound fee")
+	c.m.GetCounterVec(metrics.SignerError).WithLabelValues("fail_to_bump_fee", c.cfg.ChainID.String()).Inc()
+	ksErr := tss.KeysignError{}
+	if c.blameRecorder == nil || !errors.As(err, &ksErr) {
+		return
+	}
+	record := tss.NewBlameRecord(tss.BlameKeysign, ksErr.Blame)
+	record.Vault = p.TxOut.VaultPubKey.String()
+	record.Chain = c.cfg.ChainID.String()
+	record.OrderId = p.TxOut.OrderId.Hex()
+	record.Height = p.TxOut.Height
+	if err = c.blameRecorder.Record(record); err != nil {
+		c.log.Err(err).Str("orderId", record.OrderId).Msg("fail to record fee bump blame")
+	}
+}
+
+func (c *Client) bumpStuckOutbound(p *utxo.PendingOutbound, relayHeight int64) error {
+	orderID := p.TxOut.OrderId.Hex()
+	entry, err := c.rpc.GetMempoolEntry(p.TxID)
+	if err != nil {
+		var rpcErr *btcjson.RPCError
+		if !errors.As(err, &rpcErr) || rpcErr.Code != btcjson.ErrRPCInvalidAddressOrKey {
+			return fmt.Errorf("fail to get mempool entry: %w", err)
+		}
+		// not in the mempool, either confirmed or dropped
+		var tx *btcjson.TxRawResult
+		tx, err = c.rpc.GetRawTransactionVerbose(p.TxID)
+		if err == nil && tx.Confirmations > 0 {
+			c.log.Info().Str("txid", p.TxID).Str("orderId", orderID).Msg("outbound confirmed")
+			return c.temporalStorage.RemovePendingOutbound(orderID)
+		}
+		signed := btcwire.NewMsgTx(btcwire.TxVersion)
+		if err = signed.Deserialize(bytes.NewReader(p.SignedTx)); err != nil {
+			return fmt.Errorf("fail to deserialize signed tx: %w", err)
+		}
+		if _, _, err = c.sendTx(signed); err != nil && !errors.Is(err, errTxInChain) {
+			c.log.Warn().Err(err).Str("txid", p.TxID).Str("orderId", orderID).Msg("outbound dropped, stop tracking")
+			return c.temporalStorage.RemovePendingOutbound(orderID)
+		}
+		return nil
+	}
+
+	cfg := c.cfg.UTXO.FeeBump
+	if p.Bumps >= cfg.MaxBumps {
+		return nil
+	}
+	// counted from the relay height the outbound was emitted at rather than the heights the
+	// daemon of each node saw it enter the mempool at
+	if !bumpDue(relayHeight, p.TxOut.Height, cfg.StuckBlocks, p.Bumps) {
+		return nil
+	}
+	// the fee rate is raised from the fee of the outbound computed from its inputs and
+	// outputs on chain and the configuration, the same on every node
+	newRate := bumpFeeRate(p.FeeRate(), cfg.FeeRateIncreasePercent, c.incrementalFeeRate(), c.cfg.UTXO.MaxSatsPerVByte)
+	if newRate == 0 {
+		return nil
+	}
+
+	// the same lock as the signer takes for the vault, so they never spend the same utxos
+	lock := c.GetVaultLock(string(p.TxOut.Vault))
+	lock.Lock()
+	defer lock.Unlock()
+
+	signed := btcwire.NewMsgTx(btcwire.TxVersion)
+	if err = signed.Deserialize(bytes.NewReader(p.SignedTx)); err != nil {
+		return fmt.Errorf("fail to deserialize signed tx: %w", err)
+	}
+	sourceScript, err := c.getSourceScript(p.TxOut)
+	if err != nil {
+		return fmt.Errorf("fail to get source pay to address script: %w", err)
+	}
+
+	method := c.feeBumpMethod()
+	if method == feeBumpRBF && (len(p.Children) > 0 || !signalsRBF(signed)) {
+		method = feeBumpCPFP
+	}
+	switch method {
+	case feeBumpRBF:
+		if entry.DescendantCount > 1 {
+			return fmt.Errorf("outbound has %d descendants, can not replace", entry.DescendantCount-1)
+		}
+		err = c.replaceByFee(p, signed, sourceScript, newRate)
+	case feeBumpCPFP:
+		err = c.childPaysForParent(p, signed, sourceScript, newRate)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.Bumps++
+	return c.temporalStorage.SavePendingOutbound(p)
+}
+
+// replaceByFee re-signs the outbound with the same inputs at the new fee rate.
+func (c *Client) replaceByFee(p *utxo.PendingOutbound, signed *btcwire.MsgTx, sourceScript []byte, newRate int64) error {
+	extraFee := newRate*p.VSize - p.Fee
+	replacement, err := buildReplacementTx(signed, len(p.Orders()), sourceScript, extraFee, c.cfg.ChainID.DustThreshold().BigInt().Int64())
+	if err != nil {
+		return err
+	}
+	amounts, _, err := c.getInputAmounts(replacement)
+	if err != nil {
+		return err
+	}
+	replacement, err = c.signRedeemTx(replacement, p.TxOut, amounts, sourceScript)
+	if err != nil {
+		return err
+	}
+	txid, _, err := c.sendTx(replacement)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err = replacement.Serialize(&buf); err != nil {
+		return fmt.Errorf("fail to serialize tx to bytes: %w", err)
+	}
+	c.log.Info().Str("replaces", p.TxID).Str("txid", txid).Int64("feeRate", newRate).Msg("outbound replaced by fee")
+	for _, txOut := range p.Orders() {
+		if err = c.signerCacheManager.SetSigned(txOut.CacheHash(), txOut.CacheVault(c.GetChain()), txid); err != nil {
+			c.log.Err(err).Msgf("fail to mark tx out item (%+v) as signed", txOut)
+		}
+	}
+	c.recordFeeBump(p, txid, cross.FeeBumpRBF, p.TxID)
+
+	p.TxID = txid
+	p.SignedTx = buf.Bytes()
+	p.Fee += extraFee
+	p.VSize = mempool.GetTxVirtualSize(btcutil.NewTx(replacement))
+	return nil
+}
+
+// childPaysForParent spends the vault change of the outbound, or of its last child, with
+// a child paying the fee of the whole package at the new fee rate.
+func (c *Client) childPaysForParent(p *utxo.PendingOutbound, signed *btcwire.MsgTx, sourceScript []byte, newRate int64) error {
+	tipID := p.TxID
+	tip := signed
+	if len(p.Children) > 0 {
+		tipID = p.Children[len(p.Children)-1]
+		var err error
+		if tip, err = c.getWireTx(tipID); err != nil {
+			return err
+		}
+	}
+	// a child pays back to the vault with its only output
+	vout := 0
+	if len(p.Children) == 0 {
+		vout = -1
+		for i := len(p.Orders()); i < len(tip.TxOut); i++ {
+			if bytes.Equal(tip.TxOut[i].PkScript, sourceScript) {
+				vout = i
+				break
+			}
+		}
+	}
+	if vout < 0 {
+		return errors.New("outbound has no change output")
+	}
+	tipEntry, err := c.rpc.GetMempoolEntry(tipID)
+	if err != nil {
+		return fmt.Errorf("fail to get mempool entry of %s: %w", tipID, err)
+	}
+	if tipEntry.DescendantCount > 1 {
+		return fmt.Errorf("change of %s is already spent", tipID)
+	}
+
+	childVSize := c.estimateTxSize("", make([]btcjson.ListUnspentResult, 1))
+	childFee := newRate*(p.VSize+childVSize) - p.Fee
+	// the configured minimum rather than the one of the local daemon, every node signs the
+	// same child
+	if minFee := int64(c.cfg.UTXO.DefaultMinRelayFeeSats); childFee < minFee {
+		childFee = minFee
+	}
+	child, err := buildChildTx(tip.TxHash(), uint32(vout), tip.TxOut[vout].Value, sourceScript, childFee, c.cfg.ChainID.DustThreshold().BigInt().Int64())
+	if err != nil {
+		return err
+	}
+	amounts := map[string]int64{
+		fmt.Sprintf("%s-%d", tip.TxHash(), vout): tip.TxOut[vout].Value,
+	}
+	child, err = c.signRedeemTx(child, p.TxOut, amounts, sourceScript)
+	if err != nil {
+		return err
+	}
+	txid, _, err := c.sendTx(child)
+	if err != nil {
+		return err
+	}
+
+	c.log.Info().Str("parent", tipID).Str("txid", txid).Int64("feeRate", newRate).Msg("outbound bumped by child")
+	c.recordFeeBump(p, txid, cross.FeeBumpCPFP, p.TxID)
+
+	p.Children = append(p.Children, txid)
+	p.Fee += childFee
+	p.VSize += mempool.GetTxVirtualSize(btcutil.NewTx(child))
+	return nil
+}
+
+// recordFeeBump records the bump against the orders of the outbound.
+func (c *Client) recordFeeBump(p *utxo.PendingOutbound, txid, method, replaces string) {
+	if c.feeBumpRecorder == nil {
+		return
+	}
+	for _, txOut := range p.Orders() {
+		c.feeBumpRecorder.AddOrUpdateTx(&cross.CrossData{
+			TxHash:    txid,
+			Chain:     txOut.ToChain.String(),
+			OrderId:   txOut.OrderId.Hex(),
+			Timestamp: time.Now().Unix(),
+			FeeBump:   method,
+			Replaces:  replaces,
+		}, cross.TypeOfBumpDst)
+	}
+}
+
+// signalsRBF returns true if any input of the transaction signals replaceability.
+func signalsRBF(tx *btcwire.MsgTx) bool {
+	for _, txIn := range tx.TxIn {
+		if txIn.Sequence <= mempool.MaxRBFSequence {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package utxo
+
+import (
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
+	btcwire "github.com/btcsuite/btcd/wire"
+	. "gopkg.in/check.v1"
+)
+
+type FeeBumpTestSuite struct{}
+
+var _ = Suite(&FeeBumpTestSuite{})
+
+func (s *FeeBumpTestSuite) TestBumpFeeRate(c *C) {
+	c.Assert(bumpFeeRate(10, 50, 1, 1000), Equals, int64(15))
+	// raised by at least the incremental relay fee rate
+	c.Assert(bumpFeeRate(1, 50, 1, 1000), Equals, int64(2))
+	// capped at the maximum rate
+	c.Assert(bumpFeeRate(800, 50, 1, 1000), Equals, int64(1000))
+	c.Assert(bumpFeeRate(1000, 50, 1, 1000), Equals, int64(0))
+}
+
+func (s *FeeBumpTestSuite) TestBumpDue(c *C) {
+	c.Assert(bumpDue(105, 100, 5, 0), Equals, true)
+	c.Assert(bumpDue(104, 100, 5, 0), Equals, false)
+	// the next bump is due after as many blocks again
+	c.Assert(bumpDue(109, 100, 5, 1), Equals, false)
+	c.Assert(bumpDue(110, 100, 5, 1), Equals, true)
+	// no relay height
+	c.Assert(bumpDue(105, 0, 5, 0), Equals, false)
+}
+
+func (s *FeeBumpTestSuite) TestBuildReplacementTx(c *C) {
+	vault := []byte{0x00, 0x14, 0x01}
+	signed := btcwire.NewMsgTx(btcwire.TxVersion)
+	txIn := btcwire.NewTxIn(btcwire.NewOutPoint(&chainhash.Hash{1}, 1), nil, [][]byte{{0x01}})
+	txIn.Sequence = mempool.MaxRBFSequence
+	signed.AddTxIn(txIn)
+	signed.AddTxOut(btcwire.NewTxOut(5000, []byte{0x00, 0x14, 0x02}))
+	signed.AddTxOut(btcwire.NewTxOut(20000, vault))
+	c.Assert(signalsRBF(signed), Equals, true)
+
+	replacement, err := buildReplacementTx(signed, 1, vault, 1500, 1000)
+	c.Assert(err, IsNil)
+	c.Assert(replacement.TxIn, HasLen, 1)
+	c.Assert(replacement.TxIn[0].PreviousOutPoint, Equals, signed.TxIn[0].PreviousOutPoint)
+	c.Assert(replacement.TxIn[0].Witness, IsNil)
+	c.Assert(replacement.TxOut[0].Value, Equals, int64(5000))
+	c.Assert(replacement.TxOut[1].Value, Equals, int64(18500))
+	// the signed tx is left untouched
+	c.Assert(signed.TxOut[1].Value, Equals, int64(20000))
+
+	_, err = buildReplacementTx(signed, 1, vault, 19500, 1000)
+	c.Assert(err, NotNil)
+	_, err = buildReplacementTx(signed, 1, []byte{0x00, 0x14, 0x03}, 1500, 1000)
+	c.Assert(err, NotNil)
+}
+
+func (s *FeeBumpTestSuite) TestBuildChildTx(c *C) {
+	vault := []byte{0x00, 0x14, 0x01}
+	child, err := buildChildTx(chainhash.Hash{2}, 1, 20000, vault, 3000, 1000)
+	c.Assert(err, IsNil)
+	c.Assert(child.TxIn, HasLen, 1)
+	c.Assert(child.TxIn[0].PreviousOutPoint.Hash, Equals, chainhash.Hash{2})
+	c.Assert(child.TxIn[0].PreviousOutPoint.Index, Equals, uint32(1))
+	c.Assert(child.TxOut, HasLen, 1)
+	c.Assert(child.TxOut[0].Value, Equals, int64(17000))
+	c.Assert(signalsRBF(child), Equals, false)
+
+	_, err = buildChildTx(chainhash.Hash{2}, 1, 3500, vault, 3000, 1000)
+	c.Assert(err, NotNil)
+}
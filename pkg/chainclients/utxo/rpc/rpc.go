@@ -56,8 +56,9 @@ type Client struct {
 	maxRetries int
 }
 
-// NewClient returns a client connection to a UTXO daemon.
-func NewClient(host, user, password string, maxRetries int, log zerolog.Logger) ( // todo replace ?
+// NewClient returns a client connection to a UTXO daemon. Additional dial options, such
+// as an http client routing through an endpoint pool, may be provided.
+func NewClient(host, user, password string, maxRetries int, log zerolog.Logger, opts ...rpc.ClientOption) ( // todo replace ?
 	*Client, error,
 ) {
 	authFn := func(h http.Header) error {
@@ -71,7 +72,8 @@ func NewClient(host, user, password string, maxRetries int, log zerolog.Logger)
 		host = "http://" + host
 	}
 
-	c, err := rpc.DialOptions(context.Background(), host, rpc.WithHTTPAuth(authFn))
+	opts = append(opts, rpc.WithHTTPAuth(authFn))
+	c, err := rpc.DialOptions(context.Background(), host, opts...)
 	if err != nil {
 		return nil, err
 	}
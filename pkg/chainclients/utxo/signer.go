@@ -36,18 +36,28 @@ import (
 // SignTx builds and signs the outbound transaction. Returns the signed transaction, a
 // serialized checkpoint on error, and an error.
 func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []byte, *stypes.TxInItem, error) {
+	tx, ok, err := c.prepareOutbound(tx)
+	if err != nil || !ok {
+		return nil, nil, nil, err
+	}
+	return c.signOutbound([]stypes.TxOutItem{tx}, "", thorchainHeight)
+}
+
+// prepareOutbound verifies the outbound can be paid and sets its fee rate and size.
+// Returns false when the outbound is skipped.
+func (c *Client) prepareOutbound(tx stypes.TxOutItem) (stypes.TxOutItem, bool, error) {
 	chain, ok := common.GetChainName(tx.Chain)
 	if !ok {
-		return nil, nil, nil, fmt.Errorf("unsupported chain: %s", tx.Chain)
+		return tx, false, fmt.Errorf("unsupported chain: %s", tx.Chain)
 	}
 	if !chain.Equals(c.cfg.ChainID) {
-		return nil, nil, nil, errors.New("wrong chain")
+		return tx, false, errors.New("wrong chain")
 	}
 
 	cgl, err := parseChainAndGasLimit(tx.ChainAndGasLimit)
 	if err != nil {
 		c.log.Err(err).Str("relayHash", tx.TxHash).Msg("fail to parse chain and gas limit")
-		return nil, nil, nil, err
+		return tx, false, err
 	}
 	tx.TransactionRate = cgl.TxRate
 	tx.TransactionSize = cgl.TxSize
@@ -61,19 +71,14 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 	if c.cfg.ChainID.Equals(common.BCHChain) { // todo decode address
 		if !common.Address(toAddress).IsValidBCHAddress() {
 			c.log.Error().Str("relayHash", tx.TxHash).Msgf("to address: %s is legacy not allowed ", toAddress)
-			return nil, nil, nil, nil
+			return tx, false, nil
 		}
 	}
 
 	// skip outbounds that have been signed
 	if c.signerCacheManager.HasSigned(tx.CacheHash()) {
 		c.log.Info().Str("relayHash", tx.TxHash).Msgf("ignoring already signed transaction: (%+v)", tx)
-		return nil, nil, nil, nil
-	}
-
-	sourceScript, err := c.getSourceScript(tx)
-	if err != nil {
-		return nil, nil, nil, fmt.Errorf("fail to get source pay to address script: %w", err)
+		return tx, false, nil
 	}
 
 	// get chain specific address type
@@ -83,19 +88,19 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 	case common.DOGEChain:
 		outputAddr, err = dogutil.DecodeAddress(toAddress, c.getChainCfgDOGE())
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("fail to decode next address: %w", err)
+			return tx, false, fmt.Errorf("fail to decode next address: %w", err)
 		}
 		outputAddrStr = outputAddr.(dogutil.Address).String() // trunk-ignore(golangci-lint/forcetypeassert)
 	case common.BCHChain:
 		outputAddr, err = bchutil.DecodeAddress(toAddress, c.getChainCfgBCH())
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("fail to decode next address: %w", err)
+			return tx, false, fmt.Errorf("fail to decode next address: %w", err)
 		}
 		outputAddrStr = outputAddr.(bchutil.Address).String() // trunk-ignore(golangci-lint/forcetypeassert)
 	case common.LTCChain:
 		outputAddr, err = ltcutil.DecodeAddress(toAddress, c.getChainCfgLTC())
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("fail to decode next address: %w", err)
+			return tx, false, fmt.Errorf("fail to decode next address: %w", err)
 		}
 		outputAddrStr = outputAddr.(ltcutil.Address).String() // trunk-ignore(golangci-lint/forcetypeassert)
 	case common.BTCChain:
@@ -105,23 +110,23 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 			pubKey, err := common.CompressPubKey(tx.Data)
 			if err != nil {
 				c.log.Error().Err(err).Str("pubkey", hex.EncodeToString(tx.Data)).Msg("fail to compress pub key")
-				return nil, nil, nil, fmt.Errorf("fail to compress pub key: %w", err)
+				return tx, false, fmt.Errorf("fail to compress pub key: %w", err)
 			}
 			addr, err := common.PubKey(pubKey).GetAddress(c.cfg.ChainID)
 			if err != nil {
 				c.log.Error().Err(err).Str("pubkey", pubKey).Msg("fail to get vault address")
-				return nil, nil, nil, fmt.Errorf("fail to get vault address: %w", err)
+				return tx, false, fmt.Errorf("fail to get vault address: %w", err)
 			}
 			outputAddr, err = btcutil.DecodeAddress(addr.String(), c.getChainCfgBTC())
 			if err != nil {
 				c.log.Error().Err(err).Str("relayHash", tx.TxHash).Str("toAddress", addr.String()).Msg("fail to decode next address")
-				return nil, nil, nil, fmt.Errorf("fail to decode next addres: %w", err)
+				return tx, false, fmt.Errorf("fail to decode next addres: %w", err)
 			}
 		} else {
 			outputAddr, err = DecodeBitcoinAddress(toAddress, c.getChainCfgBTC())
 			if err != nil {
 				c.log.Error().Err(err).Str("relayHash", tx.TxHash).Str("toAddress", toAddress).Msg("DecodeBitcoinAddress failed, will ignore")
-				return nil, nil, nil, nil
+				return tx, false, nil
 			}
 		}
 		outputAddrStr = outputAddr.(btcutil.Address).String()
@@ -138,9 +143,21 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 	switch outputAddr.(type) {
 	case *dogutil.AddressPubKey, *bchutil.AddressPubKey, *ltcutil.AddressPubKey, *btcutil.AddressPubKey:
 		c.log.Warn().Str("relayHash", tx.TxHash).Msgf("address: %s is address pubkey type, should not be used", outputAddrStr)
-		return nil, nil, nil, nil
+		return tx, false, nil
 	default: // keep lint happy
 	}
+	return tx, true, nil
+}
+
+// signOutbound builds and signs the transaction paying the outbounds, with the memo of
+// the first outbound when memo is empty. Returns the signed transaction, a serialized
+// checkpoint on error, and an error.
+func (c *Client) signOutbound(txs []stypes.TxOutItem, memo string, thorchainHeight int64) ([]byte, []byte, *stypes.TxInItem, error) {
+	tx := txs[0]
+	sourceScript, err := c.getSourceScript(tx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("fail to get source pay to address script: %w", err)
+	}
 
 	// load from checkpoint if it exists
 	checkpoint := utxo.SignCheckpoint{}
@@ -166,7 +183,11 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 		}
 
 	} else {
-		redeemTx, checkpoint.IndividualAmounts, err = c.buildTx(tx, sourceScript)
+		if memo == "" {
+			redeemTx, checkpoint.IndividualAmounts, err = c.buildTx(tx, sourceScript)
+		} else {
+			redeemTx, checkpoint.IndividualAmounts, err = c.buildOutboundTx(txs, memo, sourceScript)
+		}
 		if err != nil {
 			return nil, nil, nil, err
 		}
@@ -185,15 +206,75 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 		return nil, nil, nil, fmt.Errorf("fail to marshal checkpoint: %w", err)
 	}
 
+	totalAmount := int64(0)
+	for _, txIn := range redeemTx.TxIn {
+		key := fmt.Sprintf("%s-%d", txIn.PreviousOutPoint.Hash, txIn.PreviousOutPoint.Index)
+		totalAmount += checkpoint.IndividualAmounts[key]
+	}
+
+	// sign the tx
+	var signed *btcwire.MsgTx
+	signed, err = c.signRedeemTx(redeemTx, tx, checkpoint.IndividualAmounts, sourceScript)
+	if err != nil {
+		// todo utxo
+		_ = utxo.PostKeysignFailure(c.bridge, tx, c.log, thorchainHeight, err)
+		return nil, checkpointBytes, nil, err
+	}
+	redeemTx = signed
+
+	// calculate the final transaction size
+	finalSize := redeemTx.SerializeSize()
+	finalVBytes := mempool.GetTxVirtualSize(btcutil.NewTx(redeemTx))
+	c.log.Info().Msgf("final size: %d, final vbyte: %d", finalSize, finalVBytes)
+	var signedTx bytes.Buffer
+	if err = redeemTx.Serialize(&signedTx); err != nil {
+		return nil, nil, nil, fmt.Errorf("fail to serialize tx to bytes: %w", err)
+	}
+
+	// create the observation to be sent by the signer before broadcast
+	//chainHeight, err := c.rpc.GetBlockCount()
+	//if err != nil { // fall back to the scanner height, thornode voter does not use height
+	//	chainHeight = c.currentBlockHeight.Load()
+	//}
+	//amt := redeemTx.TxOut[0].Value // the first output is the outbound amount
+	gas := totalAmount
+	for _, txOut := range redeemTx.TxOut { // subtract all vouts to from vins to get the gas
+		gas -= txOut.Value
+	}
+	var txIn *stypes.TxInItem
+	//sender, err := tx.VaultPubKey.GetAddress(tx.Chain)
+	//if err == nil {
+	//	txIn = stypes.NewTxInItem(
+	//		chainHeight,
+	//		redeemTx.TxHash().String(),
+	//		tx.Memo,
+	//		sender.String(),
+	//		tx.ToAddress.String(),
+	//		common.NewCoins(
+	//			common.NewCoin(c.cfg.ChainID.GetGasAsset(), cosmos.NewUint(uint64(amt))),
+	//		),
+	//		common.Gas(common.NewCoins(
+	//			common.NewCoin(c.cfg.ChainID.GetGasAsset(), cosmos.NewUint(uint64(gas))),
+	//		)),
+	//		tx.VaultPubKey,
+	//		"",
+	//		"",
+	//		nil,
+	//	)
+	//}
+
+	return signedTx.Bytes(), nil, txIn, nil
+}
+
+// signRedeemTx signs every input of the transaction with the vault of the outbound, the
+// amounts of the spent outputs are keyed by outpoint.
+func (c *Client) signRedeemTx(redeemTx *btcwire.MsgTx, tx stypes.TxOutItem, amounts map[string]int64, sourceScript []byte) (*btcwire.MsgTx, error) {
 	// create the list of signing requests
 	c.log.Info().Msgf("UTXOs to sign: %d", len(redeemTx.TxIn))
 	signings := []struct{ idx, amount int64 }{}
-	totalAmount := int64(0)
 	for idx, txIn := range redeemTx.TxIn {
 		key := fmt.Sprintf("%s-%d", txIn.PreviousOutPoint.Hash, txIn.PreviousOutPoint.Index)
-		outputAmount := checkpoint.IndividualAmounts[key]
-		totalAmount += outputAmount
-		signings = append(signings, struct{ idx, amount int64 }{int64(idx), outputAmount})
+		signings = append(signings, struct{ idx, amount int64 }{int64(idx), amounts[key]})
 	}
 
 	// convert the wire tx to the chain specific tx for signing
@@ -220,7 +301,6 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 		go func(i int, amount int64) {
 			defer wg.Done()
 
-			// trunk-ignore(golangci-lint/govet): shadow
 			var err error
 
 			// chain specific signing
@@ -246,9 +326,7 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 	}
 	wg.Wait()
 	if utxoErr != nil {
-		// todo utxo
-		err = utxo.PostKeysignFailure(c.bridge, tx, c.log, thorchainHeight, utxoErr)
-		return nil, checkpointBytes, nil, fmt.Errorf("fail to sign the message: %w", utxoErr)
+		return nil, fmt.Errorf("fail to sign the message: %w", utxoErr)
 	}
 
 	// convert back to wire tx
@@ -264,49 +342,7 @@ func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) ([]byte, []b
 	default:
 		c.log.Fatal().Msg("unsupported chain")
 	}
-
-	// calculate the final transaction size
-	finalSize := redeemTx.SerializeSize()
-	finalVBytes := mempool.GetTxVirtualSize(btcutil.NewTx(redeemTx))
-	c.log.Info().Msgf("final size: %d, final vbyte: %d", finalSize, finalVBytes)
-	var signedTx bytes.Buffer
-	if err = redeemTx.Serialize(&signedTx); err != nil {
-		return nil, nil, nil, fmt.Errorf("fail to serialize tx to bytes: %w", err)
-	}
-
-	// create the observation to be sent by the signer before broadcast
-	//chainHeight, err := c.rpc.GetBlockCount()
-	//if err != nil { // fall back to the scanner height, thornode voter does not use height
-	//	chainHeight = c.currentBlockHeight.Load()
-	//}
-	//amt := redeemTx.TxOut[0].Value // the first output is the outbound amount
-	gas := totalAmount
-	for _, txOut := range redeemTx.TxOut { // subtract all vouts to from vins to get the gas
-		gas -= txOut.Value
-	}
-	var txIn *stypes.TxInItem
-	//sender, err := tx.VaultPubKey.GetAddress(tx.Chain)
-	//if err == nil {
-	//	txIn = stypes.NewTxInItem(
-	//		chainHeight,
-	//		redeemTx.TxHash().String(),
-	//		tx.Memo,
-	//		sender.String(),
-	//		tx.ToAddress.String(),
-	//		common.NewCoins(
-	//			common.NewCoin(c.cfg.ChainID.GetGasAsset(), cosmos.NewUint(uint64(amt))),
-	//		),
-	//		common.Gas(common.NewCoins(
-	//			common.NewCoin(c.cfg.ChainID.GetGasAsset(), cosmos.NewUint(uint64(gas))),
-	//		)),
-	//		tx.VaultPubKey,
-	//		"",
-	//		"",
-	//		nil,
-	//	)
-	//}
-
-	return signedTx.Bytes(), nil, txIn, nil
+	return redeemTx, nil
 }
 
 // GetVaultLock returns a mutex for the given vault pubkey. This is primarily used to
@@ -329,15 +365,48 @@ func (c *Client) GetVaultLock(vaultPubKey string) *sync.Mutex {
 
 // BroadcastTx will broadcast the given payload.
 func (c *Client) BroadcastTx(txOut stypes.TxOutItem, payload []byte) (string, error) {
+	return c.broadcastOutbound([]stypes.TxOutItem{txOut}, payload)
+}
+
+// broadcastOutbound broadcasts the signed transaction paying the given outbounds.
+func (c *Client) broadcastOutbound(txs []stypes.TxOutItem, payload []byte) (string, error) {
 	redeemTx := btcwire.NewMsgTx(btcwire.TxVersion)
 	buf := bytes.NewBuffer(payload)
 	if err := redeemTx.Deserialize(buf); err != nil {
 		return "", fmt.Errorf("fail to deserialize payload: %w", err)
 	}
 
+	txid, height, err := c.sendTx(redeemTx)
+	if errors.Is(err, errTxInChain) {
+		c.log.Info().Str("hash", txid).Msg("broadcasted by another node")
+		return txid, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// save tx id to block meta in case we need to errata later
+	for _, txOut := range txs {
+		if err = c.signerCacheManager.SetSigned(txOut.CacheHash(), txOut.CacheVault(c.GetChain()), txid); err != nil {
+			c.log.Err(err).Msgf("fail to mark tx out item (%+v) as signed", txOut)
+		}
+	}
+
+	// track the outbound until it confirms to bump its fee when it gets stuck
+	c.trackOutbound(txs, redeemTx, payload, txid, height)
+
+	return txid, nil
+}
+
+// errTxInChain is returned by sendTx when the transaction is already in a block
+var errTxInChain = errors.New("transaction already in block chain")
+
+// sendTx broadcasts the signed transaction and records it as a self transaction. It
+// returns the transaction id and the chain height it was broadcast at.
+func (c *Client) sendTx(redeemTx *btcwire.MsgTx) (string, int64, error) {
 	height, err := c.rpc.GetBlockCount()
 	if err != nil {
-		return "", fmt.Errorf("fail to get block height: %w", err)
+		return "", 0, fmt.Errorf("fail to get block height: %w", err)
 	}
 	bm, err := c.temporalStorage.GetBlockMeta(height)
 	if err != nil {
@@ -364,23 +433,25 @@ func (c *Client) BroadcastTx(txOut stypes.TxOutItem, payload []byte) (string, er
 	// broadcast tx
 	var txid string
 	txid, err = c.rpc.SendRawTransaction(redeemTx, maxFee)
+	if err != nil && isTxInMempoolError(err) {
+		// another node of the vault broadcast it first
+		txid, err = redeemTx.TxHash().String(), nil
+	}
 	if txid != "" {
 		bm.AddSelfTransaction(txid)
 	}
 	if err != nil {
 		if strings.Contains(err.Error(), "already in block chain") {
-			c.log.Info().Str("hash", redeemTx.TxHash().String()).Msg("broadcasted by another node")
-			return redeemTx.TxHash().String(), nil
+			return redeemTx.TxHash().String(), height, errTxInChain
 		}
-		return "", fmt.Errorf("fail to broadcast transaction to chain: %w", err)
-	}
-
-	// save tx id to block meta in case we need to errata later
-	if err = c.signerCacheManager.SetSigned(txOut.CacheHash(), txOut.CacheVault(c.GetChain()), txid); err != nil {
-		c.log.Err(err).Msgf("fail to mark tx out item (%+v) as signed", txOut)
+		return "", height, fmt.Errorf("fail to broadcast transaction to chain: %w", err)
 	}
+	return txid, height, nil
+}
 
-	return txid, nil
+func isTxInMempoolError(err error) bool {
+	return strings.Contains(err.Error(), "txn-already-in-mempool") ||
+		strings.Contains(err.Error(), "txn-already-known")
 }
 
 type ChainAndGasLimit struct {
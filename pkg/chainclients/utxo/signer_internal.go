@@ -4,13 +4,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"math/big"
-	"sort"
 	"strings"
 
 	"github.com/mapprotocol/compass-tss/constants"
 
 	"github.com/btcsuite/btcd/btcjson"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/mempool"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/eager7/dogutil"
 	dogetxscript "github.com/mapprotocol/compass-tss/txscript/dogd-txscript"
@@ -27,6 +27,7 @@ import (
 	"github.com/mapprotocol/compass-tss/common"
 	"github.com/mapprotocol/compass-tss/common/cosmos"
 	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/utxo"
 	mem "github.com/mapprotocol/compass-tss/x/memo"
 )
 
@@ -46,9 +47,9 @@ func (c *Client) getMaximumUtxosToSpend() int64 {
 	return utxosToSpend
 }
 
-// getAllUtxos will iterate unspend utxos for the given address and return the oldest
-// set of utxos that can cover the amount.
-func (c *Client) getUtxoToSpend(pubkey common.PubKey, total float64) ([]btcjson.ListUnspentResult, error) {
+// getUtxoToSpend returns the spendable utxos of the given vault picked by the configured
+// coin selection strategy to pay the outbound.
+func (c *Client) getUtxoToSpend(pubkey common.PubKey, req utxo.CoinSelectionRequest) ([]btcjson.ListUnspentResult, error) {
 	// get all unspent utxos
 	addr, err := pubkey.GetAddress(c.cfg.ChainID)
 	if err != nil {
@@ -59,21 +60,8 @@ func (c *Client) getUtxoToSpend(pubkey common.PubKey, total float64) ([]btcjson.
 		return nil, fmt.Errorf("fail to get UTXOs: %w", err)
 	}
 
-	// spend UTXO older to younger
-	sort.SliceStable(utxos, func(i, j int) bool {
-		if utxos[i].Confirmations > utxos[j].Confirmations {
-			return true
-		} else if utxos[i].Confirmations < utxos[j].Confirmations {
-			return false
-		}
-		return utxos[i].TxID < utxos[j].TxID
-	})
-
-	var result []btcjson.ListUnspentResult
-	var toSpend float64
+	var spendable []btcjson.ListUnspentResult
 	minUTXOAmt := btcutil.Amount(c.cfg.ChainID.DustThreshold().Uint64()).ToBTC()
-	utxosToSpend := c.getMaximumUtxosToSpend() // can be set by mimir
-
 	for _, item := range utxos {
 		if !c.isValidUTXO(item.ScriptPubKey) {
 			c.log.Warn().Str("script", item.ScriptPubKey).Msgf("invalid utxo, unable to spend")
@@ -90,19 +78,12 @@ func (c *Client) getUtxoToSpend(pubkey common.PubKey, total float64) ([]btcjson.
 				continue
 			}
 		}
-
-		result = append(result, item)
-		toSpend += item.Amount
-
-		// in the scenario that there are too many unspent utxos available, make sure it
-		// doesn't spend too much as too much UTXO will cause huge pressure on TSS, also
-		// make sure it will spend at least maxUTXOsToSpend so the UTXOs will be
-		// consolidated
-		if int64(len(result)) >= utxosToSpend && toSpend >= total {
-			break
-		}
+		spendable = append(spendable, item)
 	}
-	return result, nil
+
+	// too many UTXOs would cause huge pressure on TSS, the maximum can be set by mimir
+	req.MaxInputs = int(c.getMaximumUtxosToSpend())
+	return c.coinSelector.Select(spendable, req), nil
 }
 
 // vinsUnspent will return true if all the vins are unspent.
@@ -251,6 +232,34 @@ func (c *Client) estimateTxSize(memo string, txes []btcjson.ListUnspentResult) i
 	}
 }
 
+// estimateInputSize returns the size of an input, see estimateTxSize for the per input
+// sizes.
+func (c *Client) estimateInputSize() int64 {
+	switch c.cfg.ChainID {
+	case common.DOGEChain, common.BCHChain:
+		return 148
+	case common.LTCChain, common.BTCChain:
+		return 68
+	default:
+		c.log.Fatal().Msg("unsupported chain")
+		return 0
+	}
+}
+
+// estimateOutputSize returns the size of an additional output of a batched outbound, see
+// estimateTxSize for the per output sizes.
+func (c *Client) estimateOutputSize() int64 {
+	switch c.cfg.ChainID {
+	case common.DOGEChain, common.BCHChain:
+		return 34
+	case common.LTCChain, common.BTCChain:
+		return 31
+	default:
+		c.log.Fatal().Msg("unsupported chain")
+		return 0
+	}
+}
+
 func (c *Client) getGasCoin(tx stypes.TxOutItem, vSize int64) common.Coin {
 	gasRate := tx.TransactionRate.Int64()
 
@@ -281,77 +290,64 @@ func (c *Client) getGasCoin(tx stypes.TxOutItem, vSize int64) common.Coin {
 }
 
 func (c *Client) buildTx(tx stypes.TxOutItem, sourceScript []byte) (*wire.MsgTx, map[string]int64, error) {
-	// build memo
-	chainName, err := c.bridge.GetChainName(tx.FromChain)
+	memo, err := c.getOutboundMemo(tx)
 	if err != nil {
-		return nil, nil, fmt.Errorf("fail to get chain name by chain id(%s)", tx.FromChain.String())
-	}
-	if tx.TxType == uint8(constants.MIGRATE) {
-		tx.Memo = mem.NewMigrateMemo(chainName, tx.OrderId.String()).String()
-	} else if tx.TxType == uint8(constants.REFUND) {
-		tx.Memo = mem.NewRefundMemo(chainName, tx.OrderId.String()).String()
-	} else {
-		tx.Memo = mem.NewInboundMemo(chainName, tx.OrderId.String()).String()
+		return nil, nil, err
 	}
+	return c.buildOutboundTx([]stypes.TxOutItem{tx}, memo, sourceScript)
+}
 
-	txes, err := c.getUtxoToSpend(tx.VaultPubKey, c.getPaymentAmount(tx))
+// getOutboundMemo returns the memo the outbound is paid with.
+func (c *Client) getOutboundMemo(tx stypes.TxOutItem) (string, error) {
+	chainName, err := c.bridge.GetChainName(tx.FromChain)
 	if err != nil {
-		return nil, nil, fmt.Errorf("fail to get unspent UTXO")
+		return "", fmt.Errorf("fail to get chain name by chain id(%s)", tx.FromChain.String())
 	}
-	redeemTx := wire.NewMsgTx(wire.TxVersion)
-	totalAmt := int64(0)
-	individualAmounts := make(map[string]int64, len(txes))
-	for _, item := range txes {
-		var txID *chainhash.Hash
-		txID, err = chainhash.NewHashFromStr(item.TxID)
-		if err != nil {
-			return nil, nil, fmt.Errorf("fail to parse txID(%s): %w", item.TxID, err)
-		}
-		// double check that the utxo is still valid
-		outputPoint := wire.NewOutPoint(txID, item.Vout)
-		sourceTxIn := wire.NewTxIn(outputPoint, nil, nil)
-		redeemTx.AddTxIn(sourceTxIn)
-		var amt btcutil.Amount
-		amt, err = btcutil.NewAmount(item.Amount)
-		if err != nil {
-			return nil, nil, fmt.Errorf("fail to parse amount(%f): %w", item.Amount, err)
-		}
-		individualAmounts[fmt.Sprintf("%s-%d", txID, item.Vout)] = int64(amt)
-		totalAmt += int64(amt)
+	if tx.TxType == uint8(constants.MIGRATE) {
+		return mem.NewMigrateMemo(chainName, tx.OrderId.String()).String(), nil
+	} else if tx.TxType == uint8(constants.REFUND) {
+		return mem.NewRefundMemo(chainName, tx.OrderId.String()).String(), nil
 	}
+	return mem.NewInboundMemo(chainName, tx.OrderId.String()).String(), nil
+}
 
-	var buf []byte
+// getPayToAddrScript returns the script paying the receiver of the outbound.
+func (c *Client) getPayToAddrScript(tx stypes.TxOutItem) ([]byte, error) {
+	var (
+		buf []byte
+		err error
+	)
 	toAddress := hex.EncodeToString(tx.To)
 	switch c.cfg.ChainID {
 	case common.DOGEChain:
 		var outputAddr dogutil.Address
 		outputAddr, err = dogutil.DecodeAddress(toAddress, c.getChainCfgDOGE())
 		if err != nil {
-			return nil, nil, fmt.Errorf("fail to decode next address: %w", err)
+			return nil, fmt.Errorf("fail to decode next address: %w", err)
 		}
 		buf, err = dogetxscript.PayToAddrScript(outputAddr)
 		if err != nil {
-			return nil, nil, fmt.Errorf("fail to get pay to address script: %w", err)
+			return nil, fmt.Errorf("fail to get pay to address script: %w", err)
 		}
 	case common.BCHChain:
 		var outputAddr bchutil.Address
 		outputAddr, err = bchutil.DecodeAddress(toAddress, c.getChainCfgBCH())
 		if err != nil {
-			return nil, nil, fmt.Errorf("fail to decode next address: %w", err)
+			return nil, fmt.Errorf("fail to decode next address: %w", err)
 		}
 		buf, err = bchtxscript.PayToAddrScript(outputAddr)
 		if err != nil {
-			return nil, nil, fmt.Errorf("fail to get pay to address script: %w", err)
+			return nil, fmt.Errorf("fail to get pay to address script: %w", err)
 		}
 	case common.LTCChain:
 		var outputAddr ltcutil.Address
 		outputAddr, err = ltcutil.DecodeAddress(toAddress, c.getChainCfgLTC())
 		if err != nil {
-			return nil, nil, fmt.Errorf("fail to decode next address: %w", err)
+			return nil, fmt.Errorf("fail to decode next address: %w", err)
 		}
 		buf, err = ltctxscript.PayToAddrScript(outputAddr)
 		if err != nil {
-			return nil, nil, fmt.Errorf("fail to get pay to address script: %w", err)
+			return nil, fmt.Errorf("fail to get pay to address script: %w", err)
 		}
 	case common.BTCChain:
 		var outputAddr btcutil.Address
@@ -360,35 +356,97 @@ func (c *Client) buildTx(tx stypes.TxOutItem, sourceScript []byte) (*wire.MsgTx,
 			pubKey, err := common.CompressPubKey(tx.Data)
 			if err != nil {
 				c.log.Error().Err(err).Str("pubkey", hex.EncodeToString(tx.Data)).Msg("fail to compress pub key")
-				return nil, nil, fmt.Errorf("fail to compress pub key: %w", err)
+				return nil, fmt.Errorf("fail to compress pub key: %w", err)
 			}
 			addr, err := common.PubKey(pubKey).GetAddress(c.cfg.ChainID)
 			if err != nil {
 				c.log.Error().Err(err).Str("pubkey", pubKey).Msg("fail to get vault address")
-				return nil, nil, fmt.Errorf("fail to get vault address: %w", err)
+				return nil, fmt.Errorf("fail to get vault address: %w", err)
 			}
 			outputAddr, err = btcutil.DecodeAddress(addr.String(), c.getChainCfgBTC())
 			if err != nil {
 				c.log.Error().Err(err).Str("relayHash", tx.TxHash).Str("toAddress", addr.String()).Msg("fail to decode next address")
-				return nil, nil, fmt.Errorf("fail to decode next addres: %w", err)
+				return nil, fmt.Errorf("fail to decode next addres: %w", err)
 			}
 		} else {
 			outputAddr, err = DecodeBitcoinAddress(toAddress, c.getChainCfgBTC())
 			if err != nil {
 				c.log.Error().Err(err).Str("relayHash", tx.TxHash).Str("toAddress", toAddress).Msg("fail to decode bitcoind address")
-				return nil, nil, fmt.Errorf("fail to decode next address: %w", err)
+				return nil, fmt.Errorf("fail to decode next address: %w", err)
 			}
 		}
 		buf, err = btctxscript.PayToAddrScript(outputAddr)
 		if err != nil {
-			return nil, nil, fmt.Errorf("fail to get pay to address script: %w", err)
+			return nil, fmt.Errorf("fail to get pay to address script: %w", err)
 		}
 	default:
 		c.log.Fatal().Msg("unsupported chain")
 	}
+	return buf, nil
+}
+
+// buildOutboundTx builds the transaction paying the outbounds in order, one output each,
+// followed by the change back to the vault and the memo.
+func (c *Client) buildOutboundTx(txs []stypes.TxOutItem, memo string, sourceScript []byte) (*wire.MsgTx, map[string]int64, error) {
+	tx := txs[0]
+	tx.Memo = memo
+	for _, item := range txs[1:] {
+		// the fee is paid at the highest rate of the outbounds
+		if item.TransactionRate != nil && (tx.TransactionRate == nil || item.TransactionRate.Cmp(tx.TransactionRate) > 0) {
+			tx.TransactionRate = item.TransactionRate
+		}
+	}
+
+	total := 0.0
+	for _, item := range txs {
+		total += c.getPaymentAmount(item)
+	}
+	target, err := btcutil.NewAmount(total)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to parse amount(%f): %w", total, err)
+	}
+	feeRate := int64(c.getGasCoin(tx, 1).Amount.Uint64())
+	if feeRate > c.cfg.UTXO.MaxSatsPerVByte {
+		feeRate = c.cfg.UTXO.MaxSatsPerVByte
+	}
+	txes, err := c.getUtxoToSpend(tx.VaultPubKey, utxo.CoinSelectionRequest{
+		Target:     int64(target),
+		FeeRate:    feeRate,
+		BaseSize:   c.estimateTxSize(tx.Memo, nil) + int64(len(txs)-1)*c.estimateOutputSize(),
+		InputSize:  c.estimateInputSize(),
+		ChangeSize: c.estimateOutputSize(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("fail to get unspent UTXO")
+	}
+	redeemTx := wire.NewMsgTx(wire.TxVersion)
+	totalAmt := int64(0)
+	individualAmounts := make(map[string]int64, len(txes))
+	for _, item := range txes {
+		var txID *chainhash.Hash
+		txID, err = chainhash.NewHashFromStr(item.TxID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fail to parse txID(%s): %w", item.TxID, err)
+		}
+		// double check that the utxo is still valid
+		outputPoint := wire.NewOutPoint(txID, item.Vout)
+		sourceTxIn := wire.NewTxIn(outputPoint, nil, nil)
+		if c.feeBumpMethod() == feeBumpRBF {
+			// signal replaceability so the outbound can be replaced by fee when it gets stuck
+			sourceTxIn.Sequence = mempool.MaxRBFSequence
+		}
+		redeemTx.AddTxIn(sourceTxIn)
+		var amt btcutil.Amount
+		amt, err = btcutil.NewAmount(item.Amount)
+		if err != nil {
+			return nil, nil, fmt.Errorf("fail to parse amount(%f): %w", item.Amount, err)
+		}
+		individualAmounts[fmt.Sprintf("%s-%d", txID, item.Vout)] = int64(amt)
+		totalAmt += int64(amt)
+	}
 
 	//coinToCustomer := tx.Coins.GetCoin(c.cfg.ChainID.GetGasAsset())
-	totalSize := c.estimateTxSize(tx.Memo, txes)
+	totalSize := c.estimateTxSize(tx.Memo, txes) + int64(len(txs)-1)*c.estimateOutputSize()
 
 	// maxFee in sats
 	maxFeeSats := totalSize * c.cfg.UTXO.MaxSatsPerVByte
@@ -406,7 +464,10 @@ func (c *Client) buildTx(tx stypes.TxOutItem, sourceScript []byte) (*wire.MsgTx,
 		gasAmtSats = c.minRelayFeeSats
 	}
 
-	maxGas := tx.TransactionRate.Uint64() * tx.TransactionSize.Uint64()
+	maxGas := uint64(0)
+	for _, item := range txs {
+		maxGas += item.TransactionRate.Uint64() * item.TransactionSize.Uint64()
+	}
 	if gasAmtSats > maxGas {
 		c.log.Info().Msgf("max gas: %s, however estimated gas need %d", maxGas, gasAmtSats)
 		gasAmtSats = maxGas
@@ -419,13 +480,22 @@ func (c *Client) buildTx(tx stypes.TxOutItem, sourceScript []byte) (*wire.MsgTx,
 
 	// pay to customer
 	//redeemTxOut := wire.NewTxOut(int64(coinToCustomer.Amount.Uint64()), buf)
-	redeemTxOut := wire.NewTxOut(int64(tx.Amount.Uint64()), buf)
-	redeemTx.AddTxOut(redeemTxOut)
+	toCustomer := int64(0)
+	for _, item := range txs {
+		var buf []byte
+		buf, err = c.getPayToAddrScript(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		redeemTxOut := wire.NewTxOut(int64(item.Amount.Uint64()), buf)
+		redeemTx.AddTxOut(redeemTxOut)
+		toCustomer += redeemTxOut.Value
+	}
 
 	// balance to ourselves
 	// add output to pay the balance back ourselves
-	balance := totalAmt - redeemTxOut.Value - int64(gasAmt)
-	c.log.Info().Msgf("total: %d, to customer: %d, gas: %d", totalAmt, redeemTxOut.Value, int64(gasAmt))
+	balance := totalAmt - toCustomer - int64(gasAmt)
+	c.log.Info().Msgf("total: %d, to customer: %d, gas: %d", totalAmt, toCustomer, int64(gasAmt))
 	if balance < 0 {
 		return nil, nil, fmt.Errorf("%s not enough balance to pay customer: %d", tx.VaultPubKey, balance)
 	}
@@ -1,9 +1,14 @@
 package xrp
 
 import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/big"
 	"strings"
 
 	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
 
 	txtypes "github.com/Peersyst/xrpl-go/xrpl/transaction/types"
 )
@@ -14,6 +19,9 @@ type XrpAssetMapping struct {
 	XrpIssuer      string
 	XrpDecimals    int64
 	THORChainAsset common.Asset
+	// Token is the address the asset is registered with on MAP, when empty it is looked up
+	// by the asset symbol
+	Token []byte
 }
 
 // XrpAssetMappings maps an xrp denom to a THORChain symbol and provides the asset decimals
@@ -29,7 +37,66 @@ var XrpAssetMappings = []XrpAssetMapping{
 }
 
 func GetAssetByXrpCurrency(coin txtypes.CurrencyAmount) (XrpAssetMapping, bool) {
+	return assetMappings(XrpAssetMappings).byCurrency(coin)
+}
+
+func GetAssetByThorchainAsset(asset common.Asset) (XrpAssetMapping, bool) {
 	for _, assetEntry := range XrpAssetMappings {
+		if asset.Equals(assetEntry.THORChainAsset) {
+			return assetEntry, true
+		}
+	}
+	return XrpAssetMapping{}, false
+}
+
+// assetMappings is the whitelist of assets a client observes and sends, XrpAssetMappings
+// followed by the issued currencies of its configuration
+type assetMappings []XrpAssetMapping
+
+// newAssetMappings returns XrpAssetMappings extended with the configured issued currencies
+func newAssetMappings(ious []config.BifrostXRPIOUConfiguration) (assetMappings, error) {
+	mappings := append(assetMappings{}, XrpAssetMappings...)
+	for _, iou := range ious {
+		if iou.Symbol == "" || iou.Issuer == "" {
+			return nil, fmt.Errorf("issued currency %s requires a symbol and an issuer", iou.Currency)
+		}
+		currency, err := normalizeCurrencyCode(iou.Currency)
+		if err != nil {
+			return nil, err
+		}
+		if iou.Decimals < 0 || iou.Decimals > 18 {
+			return nil, fmt.Errorf("issued currency %s has invalid decimals %d", iou.Symbol, iou.Decimals)
+		}
+		token, err := hex.DecodeString(strings.TrimPrefix(iou.Token, "0x"))
+		if err != nil || len(token) == 0 {
+			return nil, fmt.Errorf("issued currency %s has invalid token address %q", iou.Symbol, iou.Token)
+		}
+		mapping := XrpAssetMapping{
+			XrpKind:     txtypes.ISSUED,
+			XrpCurrency: currency,
+			XrpIssuer:   iou.Issuer,
+			XrpDecimals: iou.Decimals,
+			THORChainAsset: common.Asset{
+				Chain:  common.XRPChain,
+				Symbol: common.Symbol(iou.Symbol),
+				Ticker: common.Ticker(iou.Symbol),
+			},
+			Token: token,
+		}
+		if _, ok := mappings.byCurrency(mapping.amount("0")); ok {
+			return nil, fmt.Errorf("issued currency %s of %s is configured twice", iou.Currency, iou.Issuer)
+		}
+		if _, ok := mappings.byToken(token); ok {
+			return nil, fmt.Errorf("token %s is mapped to more than one currency", iou.Token)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+// byCurrency returns the mapping of the currency of the given amount
+func (m assetMappings) byCurrency(coin txtypes.CurrencyAmount) (XrpAssetMapping, bool) {
+	for _, assetEntry := range m {
 		if assetEntry.XrpKind == coin.Kind() {
 			if assetEntry.XrpKind == txtypes.XRP {
 				return assetEntry, true
@@ -44,11 +111,65 @@ func GetAssetByXrpCurrency(coin txtypes.CurrencyAmount) (XrpAssetMapping, bool)
 	return XrpAssetMapping{}, false
 }
 
-func GetAssetByThorchainAsset(asset common.Asset) (XrpAssetMapping, bool) {
-	for _, assetEntry := range XrpAssetMappings {
-		if asset.Equals(assetEntry.THORChainAsset) {
+// byToken returns the issued currency registered on MAP with the given token address
+func (m assetMappings) byToken(token []byte) (XrpAssetMapping, bool) {
+	if len(token) == 0 {
+		return XrpAssetMapping{}, false
+	}
+	for _, assetEntry := range m {
+		if len(assetEntry.Token) > 0 && bytes.Equal(assetEntry.Token, token) {
 			return assetEntry, true
 		}
 	}
 	return XrpAssetMapping{}, false
 }
+
+// toAmount converts an amount of the mapped currency to its integer amount on MAP
+func (a XrpAssetMapping) toAmount(coin txtypes.CurrencyAmount) (*big.Int, error) {
+	switch amount := coin.(type) {
+	case txtypes.XRPCurrencyAmount:
+		return new(big.Int).SetUint64(amount.Uint64()), nil
+	case txtypes.IssuedCurrencyAmount:
+		return iouValueToAmount(amount.Value, a.XrpDecimals)
+	}
+	return nil, fmt.Errorf("invalid xrp currency type")
+}
+
+// fromAmount converts an integer amount on MAP to an amount of the mapped currency
+func (a XrpAssetMapping) fromAmount(amount *big.Int) (txtypes.CurrencyAmount, error) {
+	if a.XrpKind == txtypes.XRP {
+		return decimalToXrp(amount)
+	}
+	value, err := amountToIOUValue(amount, a.XrpDecimals)
+	if err != nil {
+		return nil, err
+	}
+	return a.amount(value), nil
+}
+
+func (a XrpAssetMapping) amount(value string) txtypes.IssuedCurrencyAmount {
+	return txtypes.IssuedCurrencyAmount{
+		Issuer:   txtypes.Address(a.XrpIssuer),
+		Currency: a.XrpCurrency,
+		Value:    value,
+	}
+}
+
+// normalizeCurrencyCode returns the currency code the ledger reports, codes other than the 3
+// character standard codes are hex encoded and right padded to 20 bytes
+func normalizeCurrencyCode(code string) (string, error) {
+	switch {
+	case len(code) == 3 && !strings.EqualFold(code, "XRP"):
+		return code, nil
+	case len(code) == 40:
+		if _, err := hex.DecodeString(code); err != nil {
+			return "", fmt.Errorf("invalid hex currency code %s: %w", code, err)
+		}
+		return strings.ToUpper(code), nil
+	case len(code) > 3 && len(code) <= 20:
+		padded := make([]byte, 20)
+		copy(padded, code)
+		return strings.ToUpper(hex.EncodeToString(padded)), nil
+	}
+	return "", fmt.Errorf("invalid currency code %q", code)
+}
@@ -0,0 +1,138 @@
+package xrp
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/Peersyst/xrpl-go/xrpl/transaction"
+	txtypes "github.com/Peersyst/xrpl-go/xrpl/transaction/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/compass-tss/config"
+)
+
+const (
+	rlusdIssuer   = "rMxCKbEDwqr76QuheSUMdEGf4B9xJ8m5De"
+	rlusdCurrency = "524C555344000000000000000000000000000000"
+	rlusdToken    = "0x8292bb45bf1ee4d140127049757c2e0ff06317ed"
+)
+
+var rlusdConfig = config.BifrostXRPIOUConfiguration{
+	Symbol:   "RLUSD",
+	Currency: "RLUSD",
+	Issuer:   rlusdIssuer,
+	Decimals: 18,
+	Token:    rlusdToken,
+}
+
+func loadFixtureTx(t *testing.T, name string) transaction.FlatTransaction {
+	buf, err := os.ReadFile("test/" + name)
+	require.NoError(t, err)
+	var tx transaction.FlatTransaction
+	require.NoError(t, json.Unmarshal(buf, &tx))
+	return tx
+}
+
+func TestNewAssetMappings(t *testing.T) {
+	mappings, err := newAssetMappings([]config.BifrostXRPIOUConfiguration{rlusdConfig})
+	require.NoError(t, err)
+	require.Len(t, mappings, 2)
+
+	rlusd := mappings[1]
+	assert.Equal(t, rlusdCurrency, rlusd.XrpCurrency)
+	assert.Equal(t, "Xrp.RLUSD", rlusd.THORChainAsset.String())
+
+	xrpAsset, ok := mappings.byCurrency(txtypes.XRPCurrencyAmount(10))
+	require.True(t, ok)
+	assert.Equal(t, txtypes.XRP, xrpAsset.XrpKind)
+
+	_, ok = mappings.byCurrency(txtypes.IssuedCurrencyAmount{Issuer: rlusdIssuer, Currency: "524c555344000000000000000000000000000000"})
+	assert.True(t, ok)
+	_, ok = mappings.byCurrency(txtypes.IssuedCurrencyAmount{Issuer: "rhub8VRN55s94qWKDv6jmDy1pUykJzF3wq", Currency: rlusdCurrency})
+	assert.False(t, ok, "the same currency of another issuer is not whitelisted")
+
+	byToken, ok := mappings.byToken(rlusd.Token)
+	require.True(t, ok)
+	assert.Equal(t, rlusd.XrpCurrency, byToken.XrpCurrency)
+	_, ok = mappings.byToken(nil)
+	assert.False(t, ok)
+
+	invalid := []config.BifrostXRPIOUConfiguration{
+		{Currency: "USD", Issuer: rlusdIssuer, Token: rlusdToken},
+		{Symbol: "XRP", Currency: "XRP", Issuer: rlusdIssuer, Token: rlusdToken},
+		{Symbol: "LONG", Currency: "ACURRENCYCODETOOLONGTOFIT", Issuer: rlusdIssuer, Token: rlusdToken},
+		{Symbol: "RLUSD", Currency: "RLUSD", Issuer: rlusdIssuer, Token: "not hex"},
+		{Symbol: "RLUSD", Currency: "RLUSD", Issuer: rlusdIssuer, Decimals: 19, Token: rlusdToken},
+	}
+	for _, iou := range invalid {
+		_, err = newAssetMappings([]config.BifrostXRPIOUConfiguration{iou})
+		assert.Error(t, err, "%+v", iou)
+	}
+	_, err = newAssetMappings([]config.BifrostXRPIOUConfiguration{rlusdConfig, rlusdConfig})
+	assert.ErrorContains(t, err, "configured twice")
+}
+
+func TestObserveIssuedCurrencyPayment(t *testing.T) {
+	mappings, err := newAssetMappings([]config.BifrostXRPIOUConfiguration{rlusdConfig})
+	require.NoError(t, err)
+	scanner := &XrpBlockScanner{assetMappings: mappings}
+
+	// a partial payment only delivers part of its amount, the delivered amount is observed
+	rawTx := loadFixtureTx(t, "ledger_iou_partial_payment.json")
+	meta, err := scanner.decodeMetaBlobIfNecessary(rawTx)
+	require.NoError(t, err)
+	flatTx, err := scanner.decodeTxBlobIfNecessary(rawTx)
+	require.NoError(t, err)
+	payment, err := scanner.processPayment(flatTx)
+	require.NoError(t, err)
+	require.NotNil(t, payment)
+	assert.Equal(t, "Hello", payment.Memos[0].Memo.MemoData)
+	assert.Equal(t, tfPartialPayment, getFlags(flatTx))
+
+	delivered, err := scanner.getDeliveredAmount(flatTx, meta)
+	require.NoError(t, err)
+	asset, ok := scanner.assetMappings.byCurrency(delivered)
+	require.True(t, ok)
+	assert.Equal(t, "RLUSD", asset.THORChainAsset.Symbol.String())
+	amount, err := asset.toAmount(delivered)
+	require.NoError(t, err)
+	want, _ := new(big.Int).SetString("12500000000000000000", 10)
+	assert.Equal(t, want, amount)
+
+	// without the delivered amount a partial payment is not trusted
+	delete(meta, "delivered_amount")
+	_, err = scanner.getDeliveredAmount(flatTx, meta)
+	assert.Error(t, err)
+
+	// issued currencies that are not configured are ignored
+	rawTx = loadFixtureTx(t, "ledger_iou_unlisted.json")
+	meta, err = scanner.decodeMetaBlobIfNecessary(rawTx)
+	require.NoError(t, err)
+	flatTx, err = scanner.decodeTxBlobIfNecessary(rawTx)
+	require.NoError(t, err)
+	delivered, err = scanner.getDeliveredAmount(flatTx, meta)
+	require.NoError(t, err)
+	_, ok = scanner.assetMappings.byCurrency(delivered)
+	assert.False(t, ok)
+}
+
+func TestIssuedCurrencyFromAmount(t *testing.T) {
+	mappings, err := newAssetMappings([]config.BifrostXRPIOUConfiguration{rlusdConfig})
+	require.NoError(t, err)
+
+	amount, _ := new(big.Int).SetString("12500000000000000000", 10)
+	coin, err := mappings[1].fromAmount(amount)
+	require.NoError(t, err)
+	assert.Equal(t, txtypes.IssuedCurrencyAmount{
+		Issuer:   rlusdIssuer,
+		Currency: rlusdCurrency,
+		Value:    "12.5",
+	}, coin)
+
+	coin, err = mappings[0].fromAmount(big.NewInt(1000000))
+	require.NoError(t, err)
+	assert.Equal(t, txtypes.XRPCurrencyAmount(1000000), coin)
+}
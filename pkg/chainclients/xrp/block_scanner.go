@@ -22,6 +22,7 @@ import (
 	"github.com/mapprotocol/compass-tss/common"
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/internal/cross"
 	"github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
@@ -72,10 +73,14 @@ type XrpBlockScanner struct {
 	lastAsgard      time.Time // cache vault address
 	asgardAddresses []common.Address
 	payloadEncoder  *payload.Editer
+	// assetMappings are the currencies observed, payments of other currencies are ignored
+	assetMappings assetMappings
+	// destinationTags resolves the memo of deposits that only carry a destination tag
+	destinationTags cross.DestinationTagRegistry
 }
 
 // NewXrpBlockScanner create a new instance of BlockScan
-func NewXrpBlockScanner(rpcHost string,
+func NewXrpBlockScanner(rpcClient *rpc.Client,
 	cfg config.BifrostBlockScannerConfiguration,
 	scanStorage blockscanner.ScannerStorage,
 	bridge shareTypes.Bridge,
@@ -91,11 +96,6 @@ func NewXrpBlockScanner(rpcHost string,
 
 	logger := log.Logger.With().Str("module", "blockscanner").Str("chain", cfg.ChainID.String()).Logger()
 
-	rpcConfig, err := rpc.NewClientConfig(rpcHost)
-	if err != nil {
-		return nil, fmt.Errorf("unable to create rpc config, %w", err)
-	}
-	rpcClient := rpc.NewClient(rpcConfig)
 	return &XrpBlockScanner{
 		cfg:              cfg,
 		logger:           logger,
@@ -106,6 +106,7 @@ func NewXrpBlockScanner(rpcHost string,
 		bridge:           bridge,
 		solvencyReporter: solvencyReporter,
 		payloadEncoder:   payload.New(bridge),
+		assetMappings:    XrpAssetMappings,
 	}, nil
 }
 
@@ -251,21 +252,35 @@ func (c *XrpBlockScanner) processTxs(height int64, rawTxs []transaction.FlatTran
 			memo = payment.Memos[0].Memo.MemoData
 		}
 
-		if len(memo) == 0 {
+		// deposits without a memo are routed by the memo registered for their destination tag
+		// at the ledger they are in, deposits with an unknown tag go to the failed receiver
+		unknownTag := false
+		if len(memo) == 0 && isTo && !isSender && payment.DestinationTag != 0 && c.destinationTags != nil {
+			memo, err = c.resolveDestinationTag(payment.DestinationTag, height)
+			if err != nil {
+				return nil, fmt.Errorf("fail to resolve destination tag %d: %w", payment.DestinationTag, err)
+			}
+			unknownTag = len(memo) == 0
+			ctxLog = ctxLog.Uint32("destinationTag", payment.DestinationTag)
+		}
+
+		if len(memo) == 0 && !unknownTag {
 			ctxLog.Msg("skipping tx with empty memo")
 			continue
 		}
 		// update the signer cache
 		var parseMemo mem.Memo
-		parseMemo, err = mem.ParseMemo(memo)
-		if err != nil {
-			// Debug log only as ParseMemo error is expected for THORName inbounds.
-			ctxLog.Err(err).Msgf("fail to parse memo: %s", memo)
-			continue
-		}
-		if !parseMemo.IsValid() {
-			ctxLog.Str("memo", memo).Str("type", parseMemo.GetType().String()).Msg("invalid memo")
-			continue
+		if !unknownTag {
+			parseMemo, err = mem.ParseMemo(memo)
+			if err != nil {
+				// Debug log only as ParseMemo error is expected for THORName inbounds.
+				ctxLog.Err(err).Msgf("fail to parse memo: %s", memo)
+				continue
+			}
+			if !parseMemo.IsValid() {
+				ctxLog.Str("memo", memo).Str("type", parseMemo.GetType().String()).Msg("invalid memo")
+				continue
+			}
 		}
 		var (
 			topic                     string
@@ -279,7 +294,6 @@ func (c *XrpBlockScanner) processTxs(height int64, rawTxs []transaction.FlatTran
 			txOutType                 constants.TxInType
 			mapChainID, _             = common.MAPChain.ChainID()
 			selfId, _                 = c.cfg.ChainID.ChainID()
-			nativeToken               = common.XRPAsset
 		)
 		hash, ok := rawTx["hash"].(string)
 		if !ok {
@@ -291,6 +305,17 @@ func (c *XrpBlockScanner) processTxs(height int64, rawTxs []transaction.FlatTran
 			ctxLog.Msg("skipping tx, cannot parse delivered amount")
 			continue
 		}
+		asset, ok := c.assetMappings.byCurrency(amount)
+		if !ok {
+			ctxLog.Interface("amount", amount).Msg("skipping tx, currency is not whitelisted")
+			continue
+		}
+		nativeToken := asset.THORChainAsset
+		txInAmount, err := asset.toAmount(amount)
+		if err != nil {
+			ctxLog.Err(err).Msg("skipping tx, cannot convert delivered amount")
+			continue
+		}
 
 		// empty payload
 		payload, err = utxo.EncodePayload(nil, nil, nil) // todo utxo
@@ -325,18 +350,23 @@ func (c *XrpBlockScanner) processTxs(height int64, rawTxs []transaction.FlatTran
 			topic = constants.EventOfBridgeIn.GetTopic().String()
 			gasUsed = big.NewInt(int64(payment.Fee.Uint64()))
 
-			toToken, err = c.bridge.GetTokenAddress(selfId, common.XRPAsset.Symbol.String())
+			toToken, err = c.tokenAddress(selfId, asset)
 			if err != nil {
-				return nil, fmt.Errorf("fail to get token address for XRP: %w", err)
+				return nil, fmt.Errorf("fail to get token address for %s: %w", nativeToken, err)
 			}
 		}
 		// xrp2other
 		if isTo {
-			// toBytes default is tx destination address
-			toBytes, err = parseMemo.GetChain().DecodeAddress(parseMemo.GetDestination())
-			if err != nil {
-				ctxLog.Err(err).Msg("memo dst is invalid, treating as refund")
+			if unknownTag {
+				ctxLog.Msg("destination tag is not registered, treating as refund")
 				invalidMemo = true
+			} else {
+				// toBytes default is tx destination address
+				toBytes, err = parseMemo.GetChain().DecodeAddress(parseMemo.GetDestination())
+				if err != nil {
+					ctxLog.Err(err).Msg("memo dst is invalid, treating as refund")
+					invalidMemo = true
+				}
 			}
 			// refund
 			if invalidMemo {
@@ -369,7 +399,7 @@ func (c *XrpBlockScanner) processTxs(height int64, rawTxs []transaction.FlatTran
 			}
 
 			vaultAddress = payment.Destination.String()
-			toToken, err = c.bridge.GetTokenAddress(selfId, nativeToken.Symbol.String())
+			toToken, err = c.tokenAddress(selfId, asset)
 			if err != nil {
 				ctxLog.Msg(fmt.Sprintf("fail to get token err: %s, token: %s", err, nativeToken))
 				continue
@@ -397,11 +427,6 @@ func (c *XrpBlockScanner) processTxs(height int64, rawTxs []transaction.FlatTran
 			continue
 		}
 
-		txInAmount, ok := big.NewInt(0).SetString(amount.Flatten().(string), 10)
-		if !ok {
-			ctxLog.Msg(fmt.Sprintf("fail to parse amount(%s): %v", amount.Flatten().(string), err))
-			continue
-		}
 		fromBytes := xrp.DecodeBase58(payment.Account.String())
 
 		txIn = append(txIn, &types.TxInItem{
@@ -431,6 +456,20 @@ func (c *XrpBlockScanner) processTxs(height int64, rawTxs []transaction.FlatTran
 	return txIn, nil
 }
 
+// resolveDestinationTag returns the memo registered for a destination tag at a ledger, empty
+// when the tag is unknown
+func (c *XrpBlockScanner) resolveDestinationTag(tag uint32, ledger int64) (string, error) {
+	return cross.ResolveDestinationTag(c.destinationTags, c.cfg.ChainID.String(), tag, ledger)
+}
+
+// tokenAddress returns the address the asset is registered with on MAP
+func (c *XrpBlockScanner) tokenAddress(selfId *big.Int, asset XrpAssetMapping) ([]byte, error) {
+	if len(asset.Token) > 0 {
+		return asset.Token, nil
+	}
+	return c.bridge.GetTokenAddress(selfId, asset.THORChainAsset.Symbol.String())
+}
+
 // The expected response from the ledger method.
 type LedgerResponseWithTxHashes struct {
 	Ledger struct {
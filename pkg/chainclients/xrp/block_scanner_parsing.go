@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	binarycodec "github.com/Peersyst/xrpl-go/binary-codec"
 	"github.com/Peersyst/xrpl-go/xrpl/transaction"
@@ -84,7 +85,8 @@ func (c *XrpBlockScanner) processPayment(flatTx map[string]any) (*transaction.Pa
 				},
 			},
 		},
-		Destination: txtypes.Address(to),
+		Destination:    txtypes.Address(to),
+		DestinationTag: getUint32(flatTx, "DestinationTag"),
 	}, nil
 }
 
@@ -162,11 +164,25 @@ func (c *XrpBlockScanner) getDeliveredAmount(tx map[string]any, meta map[string]
 }
 
 func getFlags(tx map[string]any) uint32 {
-	flags, ok := tx["Flags"].(uint32)
-	if !ok {
-		return 0
+	return getUint32(tx, "Flags")
+}
+
+// getUint32 returns an uint32 field of the tx, 0 when it is not set
+func getUint32(tx map[string]any, field string) uint32 {
+	// decoded blobs hold an uint32, json responses a float64
+	switch value := tx[field].(type) {
+	case uint32:
+		return value
+	case float64:
+		return uint32(value)
+	case json.Number:
+		parsed, err := strconv.ParseUint(value.String(), 10, 32)
+		if err != nil {
+			return 0
+		}
+		return uint32(parsed)
 	}
-	return flags
+	return 0
 }
 
 func parseAmountFromTx(amountAny any) (txtypes.CurrencyAmount, error) {
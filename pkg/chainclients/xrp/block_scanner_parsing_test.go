@@ -50,6 +50,26 @@ func TestProcessPayment(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "payment with destination tag and no memo",
+			tx: map[string]any{
+				"TransactionType": "Payment",
+				"Fee":             "10",
+				"Account":         "rSender123",
+				"Destination":     "rReceiver456",
+				"DestinationTag":  float64(1024),
+			},
+			want: &transaction.Payment{
+				BaseTx: transaction.BaseTx{
+					Account: txtypes.Address("rSender123"),
+					Fee:     txtypes.XRPCurrencyAmount(10),
+					Memos:   []txtypes.MemoWrapper{{Memo: txtypes.Memo{MemoData: ""}}},
+				},
+				Destination:    txtypes.Address("rReceiver456"),
+				DestinationTag: 1024,
+			},
+			wantErr: false,
+		},
 		{
 			name: "non-payment transaction",
 			tx: map[string]any{
@@ -483,6 +503,13 @@ func TestGetFlags(t *testing.T) {
 			},
 			want: 131072,
 		},
+		{
+			name: "flags from json",
+			tx: map[string]any{
+				"Flags": float64(131072),
+			},
+			want: 131072,
+		},
 		{
 			name: "flags absent",
 			tx:   map[string]any{},
@@ -1 +1,61 @@
 package xrp
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/internal/cross"
+	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
+)
+
+// fakeRelay serves the token XRP is registered with
+type fakeRelay struct {
+	shareTypes.Bridge
+	token []byte
+}
+
+func (f fakeRelay) GetTokenAddress(chainID *big.Int, name string) ([]byte, error) {
+	return f.token, nil
+}
+
+// fakeTags registers tag 1024 from ledger 100 on and fails to look up tag 13
+type fakeTags struct{}
+
+func (fakeTags) GetDestinationTag(chain string, tag uint32, ledger int64) (*cross.DestinationTag, error) {
+	switch {
+	case tag == 13:
+		return nil, errors.New("leveldb: closed")
+	case chain == common.XRPChain.String() && tag == 1024 && ledger >= 100:
+		return &cross.DestinationTag{Chain: chain, Tag: tag, Ledger: 100, Memo: "Mx|Tron|USDT|TXcb8NicbbiT1sfSuNRZH19XggX1ph3Aoz|690943|bt0"}, nil
+	}
+	return nil, nil
+}
+
+func TestResolveDestinationTag(t *testing.T) {
+	scanner := &XrpBlockScanner{
+		cfg:             config.BifrostBlockScannerConfiguration{ChainID: common.XRPChain},
+		destinationTags: fakeTags{},
+	}
+
+	memo, err := scanner.resolveDestinationTag(1024, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "Mx|Tron|USDT|TXcb8NicbbiT1sfSuNRZH19XggX1ph3Aoz|690943|bt0", memo)
+
+	// deposits before the registration keep the tag unknown
+	memo, err = scanner.resolveDestinationTag(1024, 99)
+	require.NoError(t, err)
+	assert.Empty(t, memo)
+
+	memo, err = scanner.resolveDestinationTag(2048, 100)
+	require.NoError(t, err)
+	assert.Empty(t, memo)
+
+	_, err = scanner.resolveDestinationTag(13, 100)
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package xrp
 
 import (
+	"bytes"
 	"crypto/sha512"
 	"encoding/asn1"
 	"encoding/hex"
@@ -9,6 +10,7 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 	"sync"
 
 	sdkmath "cosmossdk.io/math"
@@ -27,11 +29,13 @@ import (
 	"github.com/mapprotocol/compass-tss/common"
 	"github.com/mapprotocol/compass-tss/config"
 	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/internal/cross"
 	"github.com/mapprotocol/compass-tss/internal/keys"
 	"github.com/mapprotocol/compass-tss/mapclient/types"
 	stypes "github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/evm"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/rpcpool"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/runners"
 	"github.com/mapprotocol/compass-tss/pkg/chainclients/shared/signercache"
 	shareTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
@@ -61,6 +65,7 @@ type Client struct {
 	stopchan            chan struct{}
 	rpcClient           *rpc.Client
 	networkID           uint32
+	assetMappings       assetMappings
 }
 
 // NewClient creates a new instance of an XRP-based chain client
@@ -99,12 +104,24 @@ func NewClient(
 		}
 	}
 
-	rpcConfig, err := rpc.NewClientConfig(cfg.RPCHost)
+	pool, err := rpcpool.NewPoolFromConfig(cfg, m)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create rpc pool for client, %w", err)
+	}
+	rpcConfig, err := rpc.NewClientConfig(
+		pool.URL(),
+		rpc.WithHTTPClient(pool.HTTPClient(cfg.BlockScanner.HTTPRequestTimeout)),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create rpc config for client, %w", err)
 	}
 	rpcClient := rpc.NewClient(rpcConfig)
 
+	mappings, err := newAssetMappings(cfg.XRP.IOUs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid issued currency configuration: %w", err)
+	}
+
 	c := &Client{
 		logger:          logger,
 		cfg:             cfg,
@@ -116,6 +133,7 @@ func NewClient(
 		stopchan:        make(chan struct{}),
 		rpcClient:       rpcClient,
 		networkID:       uint32(networkID),
+		assetMappings:   mappings,
 	}
 
 	var path string // if not set later, will in memory storage
@@ -128,7 +146,7 @@ func NewClient(
 	}
 
 	c.xrpScanner, err = NewXrpBlockScanner(
-		c.cfg.RPCHost,
+		rpcClient,
 		c.cfg.BlockScanner,
 		c.storage,
 		c.relayBridge,
@@ -138,6 +156,7 @@ func NewClient(
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cosmos scanner: %w", err)
 	}
+	c.xrpScanner.assetMappings = mappings
 
 	c.blockScanner, err = blockscanner.NewBlockScanner(c.cfg.BlockScanner, c.storage, m, c.relayBridge, c.xrpScanner)
 	if err != nil {
@@ -153,6 +172,12 @@ func NewClient(
 	return c, nil
 }
 
+// SetDestinationTagRegistry sets the registry deposits without a memo are routed by, it must be
+// set before the client is started
+func (c *Client) SetDestinationTagRegistry(registry cross.DestinationTagRegistry) {
+	c.xrpScanner.destinationTags = registry
+}
+
 // Start Xrp chain client
 func (c *Client) Start(globalTxsQueue chan stypes.TxIn, globalErrataQueue chan stypes.ErrataBlock, globalSolvencyQueue chan stypes.Solvency, globalNetworkFeeQueue chan types.NetworkFee) {
 	c.globalSolvencyQueue = globalSolvencyQueue
@@ -180,6 +205,16 @@ func (c *Client) IsBlockScannerHealthy() bool {
 	return c.blockScanner.IsHealthy()
 }
 
+// Rescan scans the given range of blocks again
+func (c *Client) Rescan(from, to int64) error {
+	return c.blockScanner.Rescan(from, to)
+}
+
+// ScanRange fetches the txs of the given range of blocks without sending them to the observer
+func (c *Client) ScanRange(from, to int64) ([]stypes.TxIn, error) {
+	return c.blockScanner.ScanRange(from, to)
+}
+
 func (c *Client) GetChain() common.Chain {
 	return c.cfg.ChainID
 }
@@ -248,16 +283,34 @@ func (c *Client) processOutboundTx(tx stypes.TxOutItem) (*transactions.Payment,
 		return nil, false, fmt.Errorf("failed to convert address (%s) to bech32: %w", tx.VaultPubKey.String(), err)
 	}
 
-	coin, err := decimalToXrp(tx.Amount)
+	asset, err := c.outboundAsset(tx.Token)
+	if err != nil {
+		return nil, false, err
+	}
+	coin, err := asset.fromAmount(tx.Amount)
 	if err != nil {
 		return nil, false, err
 	}
 
 	inValidTo := false
-	selfId, _ := c.cfg.ChainID.ChainID()
 	dst := xrp.EncodeBase58(tx.To)
-	_, err = xrp.Base58CheckDecode(dst)
-	if err != nil {
+	if _, err = xrp.Base58CheckDecode(dst); err != nil {
+		inValidTo = true
+	} else if asset.XrpKind == txtypes.ISSUED {
+		// an issued currency can only be sent to an account trusting its issuer for the amount
+		var trusted bool
+		trusted, err = c.canReceive(dst, asset, coin)
+		if err != nil {
+			return nil, false, fmt.Errorf("fail to check trust line of %s: %w", dst, err)
+		}
+		if !trusted {
+			c.logger.Warn().Str("to", dst).Str("asset", asset.THORChainAsset.String()).Str("relayHash", tx.TxHash).
+				Msg("destination has no trust line for the amount, sending to the failed receiver")
+			inValidTo = true
+		}
+	}
+	if inValidTo {
+		selfId, _ := c.cfg.ChainID.ChainID()
 		toBytes, err := c.relayBridge.GetMimirWithBytes(constants.KeyOfTransferFailedReceiver, selfId.String())
 		if err != nil {
 			c.logger.Info().Any("relayHash", tx.TxHash).
@@ -265,7 +318,15 @@ func (c *Client) processOutboundTx(tx stypes.TxOutItem) (*transactions.Payment,
 			return nil, false, err
 		}
 		dst = xrp.EncodeBase58(toBytes)
-		inValidTo = true
+		if asset.XrpKind == txtypes.ISSUED {
+			trusted, err := c.canReceive(dst, asset, coin)
+			if err != nil {
+				return nil, false, fmt.Errorf("fail to check trust line of %s: %w", dst, err)
+			}
+			if !trusted {
+				return nil, false, fmt.Errorf("failed receiver %s has no trust line for %s", dst, asset.THORChainAsset)
+			}
+		}
 	}
 
 	payment := transactions.Payment{
@@ -276,6 +337,18 @@ func (c *Client) processOutboundTx(tx stypes.TxOutItem) (*transactions.Payment,
 		Amount:      coin,
 		Destination: txtypes.Address(dst),
 	}
+	if asset.XrpKind == txtypes.ISSUED {
+		// the issuer charges its transfer fee on top of the amount, the vault pays it
+		var rate uint32
+		rate, err = c.transferRate(asset.XrpIssuer)
+		if err != nil {
+			return nil, false, fmt.Errorf("fail to get transfer rate of %s: %w", asset.XrpIssuer, err)
+		}
+		payment.SendMax, err = asset.fromAmount(withTransferFee(tx.Amount, rate))
+		if err != nil {
+			return nil, false, err
+		}
+	}
 
 	c.logger.Info().Str("from", fromAddr.String()).Str("to", xrp.EncodeBase58(tx.To)).
 		Str("relayHash", tx.TxHash).Msg("processing outbound tx")
@@ -287,6 +360,83 @@ func (c *Client) processOutboundTx(tx stypes.TxOutItem) (*transactions.Payment,
 	return &payment, inValidTo, nil
 }
 
+// outboundAsset returns the asset of an outbound from the token it carries, the address the
+// asset is registered with on MAP. Outbounds of any other token are not sent.
+func (c *Client) outboundAsset(token []byte) (XrpAssetMapping, error) {
+	if asset, ok := c.assetMappings.byToken(token); ok {
+		return asset, nil
+	}
+	asset, _ := GetAssetByThorchainAsset(common.XRPAsset)
+	selfId, err := c.cfg.ChainID.ChainID()
+	if err != nil {
+		return XrpAssetMapping{}, err
+	}
+	native, err := c.relayBridge.GetTokenAddress(selfId, asset.THORChainAsset.Symbol.String())
+	if err != nil {
+		return XrpAssetMapping{}, fmt.Errorf("fail to get token address of %s: %w", asset.THORChainAsset, err)
+	}
+	if len(token) == 0 || !bytes.Equal(token, native) {
+		return XrpAssetMapping{}, fmt.Errorf("unknown outbound token %s", hex.EncodeToString(token))
+	}
+	return asset, nil
+}
+
+// transferRate returns the TransferRate of an issuer, 0 when it charges no transfer fee
+func (c *Client) transferRate(issuer string) (uint32, error) {
+	resp, err := c.rpcClient.GetAccountInfo(&account.InfoRequest{
+		Account:     txtypes.Address(issuer),
+		LedgerIndex: qcommon.Validated,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return resp.AccountData.TransferRate, nil
+}
+
+// canReceive checks the destination has a trust line to the issuer of the currency, that is
+// not frozen and has room for the amount. The issuer itself can always receive its currency.
+func (c *Client) canReceive(destination string, asset XrpAssetMapping, coin txtypes.CurrencyAmount) (bool, error) {
+	if strings.EqualFold(destination, asset.XrpIssuer) {
+		return true, nil
+	}
+	amount, ok := coin.(txtypes.IssuedCurrencyAmount)
+	if !ok {
+		return false, fmt.Errorf("%s is not an issued currency", asset.THORChainAsset)
+	}
+	value, ok := new(big.Rat).SetString(amount.Value)
+	if !ok {
+		return false, fmt.Errorf("invalid issued currency value %q", amount.Value)
+	}
+	resp, err := c.rpcClient.GetAccountLines(&account.LinesRequest{
+		Account:     txtypes.Address(destination),
+		Peer:        txtypes.Address(asset.XrpIssuer),
+		LedgerIndex: qcommon.Validated,
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, line := range resp.Lines {
+		if !strings.EqualFold(line.Currency, asset.XrpCurrency) ||
+			!strings.EqualFold(line.Account.String(), asset.XrpIssuer) {
+			continue
+		}
+		if line.Freeze || line.FreezePeer {
+			return false, nil
+		}
+		// both are seen from the destination, the room left is the limit minus the balance
+		limit, ok := new(big.Rat).SetString(line.Limit)
+		if !ok {
+			return false, fmt.Errorf("invalid trust line limit %q", line.Limit)
+		}
+		balance, ok := new(big.Rat).SetString(line.Balance)
+		if !ok {
+			return false, fmt.Errorf("invalid trust line balance %q", line.Balance)
+		}
+		return limit.Sub(limit, balance).Cmp(value) >= 0, nil
+	}
+	return false, nil
+}
+
 // SignTx sign the the given TxArrayItem
 func (c *Client) SignTx(tx stypes.TxOutItem, thorchainHeight int64) (signedTx, checkpoint []byte, _ *stypes.TxInItem, err error) {
 	defer func() {
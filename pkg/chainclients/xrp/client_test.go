@@ -1 +1,104 @@
 package xrp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/Peersyst/xrpl-go/xrpl/rpc"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+)
+
+// newFixtureClient returns a client whose rpc answers account_lines requests with the fixture
+// of the requested account
+func newFixtureClient(t *testing.T, fixtures map[string]string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+			Params []struct {
+				Account string `json:"account"`
+				Peer    string `json:"peer"`
+			} `json:"params"`
+		}
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) || !assert.Len(t, req.Params, 1) {
+			return
+		}
+		assert.Equal(t, "account_lines", req.Method)
+		assert.Equal(t, rlusdIssuer, req.Params[0].Peer)
+		name, ok := fixtures[req.Params[0].Account]
+		if !assert.True(t, ok, "unexpected account %s", req.Params[0].Account) {
+			return
+		}
+		buf, err := os.ReadFile("test/" + name)
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(buf)
+	}))
+	t.Cleanup(server.Close)
+
+	rpcConfig, err := rpc.NewClientConfig(server.URL)
+	require.NoError(t, err)
+	return &Client{rpcClient: rpc.NewClient(rpcConfig)}
+}
+
+func TestCanReceive(t *testing.T) {
+	client := newFixtureClient(t, map[string]string{
+		"rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh": "account_lines_rlusd.json",
+		"rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe": "account_lines_frozen.json",
+		"rLNaPoKeeBjZe2qs6x52yVPZpZ8td4dc6w": "account_lines_empty.json",
+	})
+	mappings, err := newAssetMappings([]config.BifrostXRPIOUConfiguration{rlusdConfig})
+	require.NoError(t, err)
+	rlusd := mappings[1]
+
+	tests := []struct {
+		name        string
+		destination string
+		value       string
+		want        bool
+	}{
+		{name: "room left on the trust line", destination: "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", value: "900", want: true},
+		{name: "amount above the room left", destination: "rHb9CJAWyB4rj91VRWn96DkukG4bwdtyTh", value: "900.000001", want: false},
+		{name: "trust line frozen by the issuer", destination: "rPT1Sjq2YGrBMTttX4GZHjKu9dyfzbpAYe", value: "1", want: false},
+		{name: "no trust line for the currency", destination: "rLNaPoKeeBjZe2qs6x52yVPZpZ8td4dc6w", value: "1", want: false},
+		{name: "issuer", destination: rlusdIssuer, value: "1000000000", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.canReceive(tt.destination, rlusd, rlusd.amount(tt.value))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOutboundAsset(t *testing.T) {
+	mappings, err := newAssetMappings([]config.BifrostXRPIOUConfiguration{rlusdConfig})
+	require.NoError(t, err)
+	client := &Client{
+		cfg:           config.BifrostChainConfiguration{ChainID: common.XRPChain},
+		assetMappings: mappings,
+		relayBridge:   fakeRelay{token: ethcommon.HexToAddress("0x1").Bytes()},
+	}
+
+	asset, err := client.outboundAsset(ethcommon.HexToAddress("0x1").Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, common.XRPAsset, asset.THORChainAsset)
+
+	asset, err = client.outboundAsset(mappings[1].Token)
+	require.NoError(t, err)
+	assert.Equal(t, mappings[1].THORChainAsset, asset.THORChainAsset)
+
+	// an unknown token is never sent as XRP
+	_, err = client.outboundAsset(ethcommon.HexToAddress("0x2").Bytes())
+	assert.Error(t, err)
+	_, err = client.outboundAsset(nil)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
+	"strings"
 
 	sdkmath "cosmossdk.io/math"
 
@@ -55,3 +56,68 @@ func decimalToXrp(amount *big.Int) (txtypes.CurrencyAmount, error) {
 
 	return txtypes.XRPCurrencyAmount(amount.Uint64()), nil
 }
+
+// maxIOUDigits is the number of significant digits of an issued currency value
+const maxIOUDigits = 16
+
+// transferRateUnit is the TransferRate of an issuer that charges no transfer fee
+const transferRateUnit = 1_000_000_000
+
+// iouValueToAmount converts an issued currency value, a decimal string possibly in scientific
+// notation, to an integer amount with the given decimals. Digits beyond the decimals are dropped.
+func iouValueToAmount(value string, decimals int64) (*big.Int, error) {
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("invalid issued currency value %q", value)
+	}
+	if rat.Sign() < 0 {
+		return nil, fmt.Errorf("negative issued currency value %q", value)
+	}
+	rat.Mul(rat, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(decimals), nil)))
+	return new(big.Int).Quo(rat.Num(), rat.Denom()), nil
+}
+
+// amountToIOUValue converts an integer amount with the given decimals to an issued currency
+// value. The ledger keeps 16 significant digits, the remaining ones are dropped so the value
+// sent never exceeds the amount.
+func amountToIOUValue(amount *big.Int, decimals int64) (string, error) {
+	if amount == nil || amount.Sign() < 0 {
+		return "", fmt.Errorf("invalid issued currency amount %v", amount)
+	}
+	digits := amount.String()
+	if len(digits) > maxIOUDigits {
+		dropped := len(digits) - maxIOUDigits
+		digits = digits[:maxIOUDigits] + strings.Repeat("0", dropped)
+	}
+	if int64(len(digits)) <= decimals {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:int64(len(digits))-decimals], digits[int64(len(digits))-decimals:]
+	fraction = strings.TrimRight(fraction, "0")
+	if fraction == "" {
+		return whole, nil
+	}
+	return whole + "." + fraction, nil
+}
+
+// withTransferFee returns the amount a sender spends for the destination to receive amount of
+// an issued currency whose issuer has the given TransferRate. It is rounded up to the digits
+// the ledger keeps, so the fee is always covered.
+func withTransferFee(amount *big.Int, rate uint32) *big.Int {
+	if rate <= transferRateUnit {
+		return new(big.Int).Set(amount)
+	}
+	ret, rem := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(int64(rate))), big.NewInt(transferRateUnit), new(big.Int))
+	if rem.Sign() > 0 {
+		ret.Add(ret, big.NewInt(1))
+	}
+	if digits := len(ret.String()); digits > maxIOUDigits {
+		unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits-maxIOUDigits)), nil)
+		ret.QuoRem(ret, unit, rem)
+		if rem.Sign() > 0 {
+			ret.Add(ret, big.NewInt(1))
+		}
+		ret.Mul(ret, unit)
+	}
+	return ret
+}
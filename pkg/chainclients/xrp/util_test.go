@@ -1 +1,87 @@
 package xrp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIOUValueToAmount(t *testing.T) {
+	tests := []struct {
+		value    string
+		decimals int64
+		want     string
+		wantErr  bool
+	}{
+		{value: "12.5", decimals: 6, want: "12500000"},
+		{value: "1000", decimals: 18, want: "1000000000000000000000"},
+		{value: "0.0000001", decimals: 6, want: "0"},
+		{value: "1.2345678", decimals: 6, want: "1234567"},
+		{value: "1e-3", decimals: 6, want: "1000"},
+		{value: "9999999999999999e80", decimals: 0, want: "999999999999999900000000000000000000000000000000000000000000000000000000000000000000000000000000"},
+		{value: "-1", decimals: 6, wantErr: true},
+		{value: "abc", decimals: 6, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := iouValueToAmount(tt.value, tt.decimals)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestAmountToIOUValue(t *testing.T) {
+	tests := []struct {
+		amount   string
+		decimals int64
+		want     string
+	}{
+		{amount: "12500000", decimals: 6, want: "12.5"},
+		{amount: "1000000", decimals: 6, want: "1"},
+		{amount: "1", decimals: 6, want: "0.000001"},
+		{amount: "0", decimals: 6, want: "0"},
+		{amount: "42", decimals: 0, want: "42"},
+		// only 16 significant digits are kept, rounded down
+		{amount: "1234567890123456789", decimals: 18, want: "1.234567890123456"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			amount, _ := new(big.Int).SetString(tt.amount, 10)
+			got, err := amountToIOUValue(amount, tt.decimals)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	_, err := amountToIOUValue(big.NewInt(-1), 6)
+	assert.Error(t, err)
+}
+
+func TestWithTransferFee(t *testing.T) {
+	tests := []struct {
+		amount string
+		rate   uint32
+		want   string
+	}{
+		{amount: "1000000", rate: 0, want: "1000000"},
+		{amount: "1000000", rate: transferRateUnit, want: "1000000"},
+		// 0.2% fee
+		{amount: "1000000", rate: 1002000000, want: "1002000"},
+		// fractions of the smallest unit are rounded up
+		{amount: "1", rate: 1002000000, want: "2"},
+		// the digits beyond the 16 the ledger keeps are rounded up
+		{amount: "1234567890123456789", rate: 1002000000, want: "1237037025903704000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			amount, _ := new(big.Int).SetString(tt.amount, 10)
+			assert.Equal(t, tt.want, withTransferFee(amount, tt.rate).String())
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package signer
+
+import (
+	"fmt"
+
+	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/utxo"
+	mem "github.com/mapprotocol/compass-tss/x/memo"
+)
+
+// batchOutbounds merges the outbounds of a vault waiting to be signed into the batches
+// they are paid in, for the chains that pay several outbounds in one transaction. The
+// batches of a window of relay blocks are built by the chain client from the relay chain
+// once the window was scanned in full, so they only depend on the relay height, vault and
+// order of the outbounds and are the same on every node. The first outbound of a batch
+// keeps its key and carries the others, the outbounds left alone are signed on their own.
+func (s *Signer) batchOutbounds() {
+	if s.blockScanner == nil {
+		return
+	}
+	scanned := s.blockScanner.PreviousHeight()
+
+	type group struct {
+		client *utxo.Client
+		vault  string
+		window int64
+		items  []TxOutStoreItem
+	}
+	groups := make(map[string]*group)
+	var keys []string
+	for _, item := range s.storage.List() {
+		client, ok := s.batchClient(item)
+		if !ok {
+			continue
+		}
+		window := client.BatchWindowBlocks()
+		start := item.Height / window * window
+		if scanned < start+window {
+			continue
+		}
+		vault, err := client.VaultAddress(item.TxOutItem.Vault)
+		if err != nil {
+			s.logger.Error().Err(err).Str("relayHash", item.TxOutItem.TxHash).Msg("fail to get vault address of outbound")
+			continue
+		}
+		key := fmt.Sprintf("%s-%s-%d", item.TxOutItem.Chain, vault, start)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{client: client, vault: vault, window: start}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		g.items = append(g.items, item)
+	}
+
+	for _, key := range keys {
+		g := groups[key]
+		_, batches, err := g.client.BatchWindow(g.vault, g.window)
+		if err != nil {
+			s.logger.Error().Err(err).Int64("window", g.window).Str("vault", g.vault).Msg("fail to get batches of window")
+			continue
+		}
+		remaining := g.items
+		for _, batch := range batches {
+			var members []TxOutStoreItem
+			for _, out := range batch {
+				for i, item := range remaining {
+					if utxo.SameOutbound(item.TxOutItem, out) {
+						members = append(members, item)
+						remaining = append(remaining[:i:i], remaining[i+1:]...)
+						break
+					}
+				}
+			}
+			if len(members) == 0 {
+				continue
+			}
+			s.mergeBatch(members)
+		}
+		// outbounds the relay chain did not emit for the vault are signed on their own
+		for _, item := range remaining {
+			s.logger.Warn().Str("relayHash", item.TxOutItem.TxHash).Int64("window", g.window).Msg("outbound not in a batch of its window")
+			s.mergeBatch([]TxOutStoreItem{item})
+		}
+	}
+}
+
+// mergeBatch stores the outbounds of a batch under the first one, a single outbound is
+// marked to be signed on its own.
+func (s *Signer) mergeBatch(members []TxOutStoreItem) {
+	lead := members[0]
+	lead.Batched = true
+	lead.Batch = nil
+	for _, member := range members[1:] {
+		member.Batched = true
+		lead.Batch = append(lead.Batch, member)
+	}
+	if err := s.storage.Merge(lead, lead.Batch); err != nil {
+		s.logger.Error().Err(err).Str("relayHash", lead.TxOutItem.TxHash).Msg("fail to batch outbounds")
+		return
+	}
+	if len(lead.Batch) > 0 {
+		s.logger.Info().Str("relayHash", lead.TxOutItem.TxHash).Int("outbounds", len(members)).
+			Str("chain", lead.TxOutItem.Chain.String()).Msg("batched outbounds")
+	}
+}
+
+// batchClient returns the chain client of the outbound when it waits to be batched.
+func (s *Signer) batchClient(item TxOutStoreItem) (*utxo.Client, bool) {
+	if item.Batched || len(item.Batch) > 0 || len(item.SignedTx) > 0 ||
+		item.TxOutItem.TxType == uint8(constants.MIGRATE) {
+		return nil, false
+	}
+	chain, err := s.getChain(item.TxOutItem.Chain)
+	if err != nil {
+		return nil, false
+	}
+	client, ok := chain.(*utxo.Client)
+	if !ok || client.BatchMaxOutputs() == 0 {
+		return nil, false
+	}
+	return client, true
+}
+
+// dropExecutedOutbounds removes the outbounds of the batch whose order was executed
+// already, the first remaining outbound leads the batch. Returns false when none is left.
+func (s *Signer) dropExecutedOutbounds(item TxOutStoreItem) (TxOutStoreItem, bool, error) {
+	remaining := make([]TxOutStoreItem, 0, len(item.Batch)+1)
+	lead := item
+	lead.Batch = nil
+	for _, member := range append([]TxOutStoreItem{lead}, item.Batch...) {
+		exist, err := s.mapBridge.OrderExecuted(member.TxOutItem.OrderId, false)
+		if err != nil {
+			return item, false, fmt.Errorf("fail to check order executed: %w", err)
+		}
+		if exist {
+			s.logger.Info().Str("txHash", member.TxOutItem.TxHash).
+				Str("orderId", member.TxOutItem.OrderId.Hex()).Msg("drop executed outbound from batch")
+			continue
+		}
+		remaining = append(remaining, member)
+	}
+	if len(remaining) == 0 {
+		return item, false, nil
+	}
+	item.TxOutItem = remaining[0].TxOutItem
+	item.Batch = remaining[1:]
+	return item, true, nil
+}
+
+// batchTxOuts returns the outbounds paid by the batch, in output order.
+func batchTxOuts(item TxOutStoreItem, lead types.TxOutItem) []types.TxOutItem {
+	txs := []types.TxOutItem{lead}
+	for _, member := range item.Batch {
+		tx := member.TxOutItem
+		tx.VaultPubKey = lead.VaultPubKey
+		txs = append(txs, tx)
+	}
+	return txs
+}
+
+// keepSignedOutbounds returns the item with only the outbounds paid by the signed
+// transaction, so a broadcast retry reports the same orders.
+func keepSignedOutbounds(item TxOutStoreItem, signed []types.TxOutItem) TxOutStoreItem {
+	members := append([]TxOutStoreItem{item}, item.Batch...)
+	item.Batch = nil
+	for i, tx := range signed {
+		for _, member := range members {
+			if member.TxOutItem.OrderId != tx.OrderId {
+				continue
+			}
+			if i == 0 {
+				item.TxOutItem = member.TxOutItem
+			} else {
+				member.Batch = nil
+				item.Batch = append(item.Batch, member)
+			}
+			break
+		}
+	}
+	return item
+}
+
+// batchID returns the id the batched transaction paying the outbounds carries in its memo.
+func batchID(txs []types.TxOutItem) string {
+	if len(txs) < 2 {
+		return ""
+	}
+	orderIDs := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		orderIDs = append(orderIDs, tx.OrderId.Hex())
+	}
+	return mem.NewBatchID(orderIDs)
+}
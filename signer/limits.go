@@ -0,0 +1,446 @@
+package signer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+)
+
+const (
+	limitBreakerPrefix  = "limit-breaker-"
+	limitOutflowPrefix  = "limit-outflow-"
+	limitApprovedPrefix = "limit-approved-"
+)
+
+// ErrSigningHeld is returned for an outbound of a chain or vault whose outbound limits
+// were breached, the outbound stays queued until an operator releases the breaker
+var ErrSigningHeld = errors.New("signing held by outbound limits")
+
+// Alerter sends a message to the node operator
+type Alerter func(text string) error
+
+// Breaker is a tripped outbound limit, the signing of its scope is held until it is
+// released
+type Breaker struct {
+	// Scope is the chain, the chain and vault joined by a colon, or the chain and token
+	// joined by ":token:"
+	Scope     string    `json:"scope"`
+	Chain     string    `json:"chain"`
+	Vault     string    `json:"vault,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	OrderId   string    `json:"order_id"`
+	Reason    string    `json:"reason"`
+	TrippedAt time.Time `json:"tripped_at"`
+	// OrderIds are the outbounds held since the breaker tripped, they are signed once it
+	// is released even if they breach the limits again
+	OrderIds []string `json:"order_ids"`
+}
+
+// Outflow is the amount of an outbound signed within the window of the limits
+type Outflow struct {
+	OrderId string    `json:"order_id"`
+	Chain   string    `json:"chain"`
+	Vault   string    `json:"vault"`
+	Token   string    `json:"token"`
+	Amount  *big.Int  `json:"amount"`
+	Time    time.Time `json:"time"`
+}
+
+type tokenLimit struct {
+	maxTx, maxVaultOutflow, maxChainOutflow *big.Int
+}
+
+type chainLimits struct {
+	window time.Duration
+	tokens map[string]tokenLimit
+}
+
+// OutboundLimiter holds the signing of the outbounds breaching the local limits of their
+// chain, independently of the halts set on the relay chain. The breakers, the outflows
+// within the window and the approved outbounds are kept in the signer store.
+type OutboundLimiter struct {
+	logger   zerolog.Logger
+	db       *leveldb.DB
+	chains   map[common.Chain]chainLimits
+	lock     sync.Mutex
+	alert    Alerter
+	breakers map[string]Breaker
+	outflows map[string]Outflow
+	approved map[string]bool
+}
+
+// NewOutboundLimiter create a new instance of OutboundLimiter with the limits of the given
+// chains, the state stored in db is loaded
+func NewOutboundLimiter(db *leveldb.DB, chains map[common.Chain]config.BifrostChainConfiguration) (*OutboundLimiter, error) {
+	l := &OutboundLimiter{
+		logger:   log.With().Str("module", "outbound_limiter").Logger(),
+		db:       db,
+		chains:   make(map[common.Chain]chainLimits),
+		breakers: make(map[string]Breaker),
+		outflows: make(map[string]Outflow),
+		approved: make(map[string]bool),
+	}
+	for chain, cfg := range chains {
+		if len(cfg.OutboundLimits.Tokens) == 0 {
+			continue
+		}
+		limits := chainLimits{window: cfg.OutboundLimits.Window, tokens: make(map[string]tokenLimit)}
+		for _, token := range cfg.OutboundLimits.Tokens {
+			var limit tokenLimit
+			var err error
+			if limit.maxTx, err = parseLimit(token.MaxTx); err != nil {
+				return nil, fmt.Errorf("invalid max_tx of %s on %s: %w", token.Token, chain, err)
+			}
+			if limit.maxVaultOutflow, err = parseLimit(token.MaxVaultOutflow); err != nil {
+				return nil, fmt.Errorf("invalid max_vault_outflow of %s on %s: %w", token.Token, chain, err)
+			}
+			if limit.maxChainOutflow, err = parseLimit(token.MaxChainOutflow); err != nil {
+				return nil, fmt.Errorf("invalid max_chain_outflow of %s on %s: %w", token.Token, chain, err)
+			}
+			limits.tokens[normalizeToken(token.Token)] = limit
+		}
+		l.chains[chain] = limits
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// parseLimit returns the amount of a limit, nil when it is not limited
+func parseLimit(value string) (*big.Int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	amount, ok := new(big.Int).SetString(value, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, fmt.Errorf("%q is not an amount", value)
+	}
+	if amount.Sign() == 0 {
+		return nil, nil
+	}
+	return amount, nil
+}
+
+func normalizeToken(token string) string {
+	return "0x" + strings.TrimPrefix(strings.ToLower(strings.TrimSpace(token)), "0x")
+}
+
+// breakerScope returns the scope of a breaker of the chain, of a vault or of a token on it
+func breakerScope(chain common.Chain, vault, token string) string {
+	switch {
+	case token != "":
+		return chain.String() + ":token:" + token
+	case vault != "":
+		return chain.String() + ":" + vault
+	default:
+		return chain.String()
+	}
+}
+
+func (l *OutboundLimiter) load() error {
+	iterator := l.db.NewIterator(util.BytesPrefix([]byte(limitBreakerPrefix)), nil)
+	for iterator.Next() {
+		var breaker Breaker
+		if err := json.Unmarshal(iterator.Value(), &breaker); err != nil {
+			l.logger.Error().Err(err).Msg("fail to unmarshal breaker")
+			continue
+		}
+		l.breakers[breaker.Scope] = breaker
+	}
+	iterator.Release()
+
+	iterator = l.db.NewIterator(util.BytesPrefix([]byte(limitOutflowPrefix)), nil)
+	for iterator.Next() {
+		var outflow Outflow
+		if err := json.Unmarshal(iterator.Value(), &outflow); err != nil {
+			l.logger.Error().Err(err).Msg("fail to unmarshal outflow")
+			continue
+		}
+		l.outflows[outflow.OrderId] = outflow
+	}
+	iterator.Release()
+
+	iterator = l.db.NewIterator(util.BytesPrefix([]byte(limitApprovedPrefix)), nil)
+	for iterator.Next() {
+		l.approved[strings.TrimPrefix(string(iterator.Key()), limitApprovedPrefix)] = true
+	}
+	iterator.Release()
+	return iterator.Error()
+}
+
+// SetAlerter sets where the breaches are reported to besides the log
+func (l *OutboundLimiter) SetAlerter(alert Alerter) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.alert = alert
+}
+
+// Check returns ErrSigningHeld when the chain, the vault or a token of the outbounds is
+// held, or when one of them breaches the limits, in which case its breaker is tripped. The
+// outbounds are signed together from the given vault, so they are held together.
+//
+// The limits are local to the node. On a TSS chain a held outbound is not signed by this
+// node while the other members of the vault still start its keysign, they time out waiting
+// for it and blame it. The limits of a chain should be the same on every node of the vault.
+func (l *OutboundLimiter) Check(chain common.Chain, vault string, txs []types.TxOutItem) error {
+	if l == nil {
+		return nil
+	}
+	limits, ok := l.chains[chain]
+	if !ok {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	// the outbounds released by an operator are not checked again
+	pending := make([]types.TxOutItem, 0, len(txs))
+	for _, tx := range txs {
+		if !l.approved[tx.OrderId.Hex()] {
+			pending = append(pending, tx)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	scopes := []string{breakerScope(chain, "", ""), breakerScope(chain, vault, "")}
+	for _, tx := range pending {
+		scopes = append(scopes, breakerScope(chain, "", normalizeToken(hex.EncodeToString(tx.Token))))
+	}
+	for _, scope := range scopes {
+		if breaker, ok := l.breakers[scope]; ok {
+			l.hold(breaker, pending)
+			return fmt.Errorf("%w: %s", ErrSigningHeld, breaker.Reason)
+		}
+	}
+
+	l.prune(chain, limits.window)
+	chainOutflow := make(map[string]*big.Int)
+	vaultOutflow := make(map[string]*big.Int)
+	for _, outflow := range l.outflows {
+		if outflow.Chain != chain.String() {
+			continue
+		}
+		addAmount(chainOutflow, outflow.Token, outflow.Amount)
+		if outflow.Vault == vault {
+			addAmount(vaultOutflow, outflow.Token, outflow.Amount)
+		}
+	}
+	for _, tx := range pending {
+		token := normalizeToken(hex.EncodeToString(tx.Token))
+		amount := tx.Amount
+		if amount == nil {
+			amount = new(big.Int)
+		}
+		breaker := Breaker{Chain: chain.String(), Token: token, OrderId: tx.OrderId.Hex()}
+		limit, ok := limits.tokens[token]
+		switch {
+		case !ok:
+			// only the outbounds of the token are held, it may not be known to the limits yet
+			breaker.Scope = breakerScope(chain, "", token)
+			breaker.Reason = fmt.Sprintf("token %s is not allowed", token)
+		case limit.maxTx != nil && amount.Cmp(limit.maxTx) > 0:
+			breaker.Reason = fmt.Sprintf("amount %s of order %s is above the max of %s", amount, tx.OrderId.Hex(), limit.maxTx)
+		case limit.maxChainOutflow != nil && addAmount(chainOutflow, token, amount).Cmp(limit.maxChainOutflow) > 0:
+			breaker.Reason = fmt.Sprintf("outflow of %s is above the max of %s within %s", chainOutflow[token], limit.maxChainOutflow, limits.window)
+		case limit.maxVaultOutflow != nil && addAmount(vaultOutflow, token, amount).Cmp(limit.maxVaultOutflow) > 0:
+			breaker.Vault = vault
+			breaker.Reason = fmt.Sprintf("outflow of vault %s is above the max of %s within %s", vault, limit.maxVaultOutflow, limits.window)
+		default:
+			continue
+		}
+		if breaker.Scope == "" {
+			breaker.Scope = breakerScope(chain, breaker.Vault, "")
+		}
+		breaker.TrippedAt = time.Now()
+		l.trip(breaker)
+		l.hold(breaker, pending)
+		return fmt.Errorf("%w: %s", ErrSigningHeld, breaker.Reason)
+	}
+	return nil
+}
+
+func addAmount(amounts map[string]*big.Int, token string, amount *big.Int) *big.Int {
+	total, ok := amounts[token]
+	if !ok {
+		total = new(big.Int)
+		amounts[token] = total
+	}
+	return total.Add(total, amount)
+}
+
+// trip stores the breaker and reports it
+func (l *OutboundLimiter) trip(breaker Breaker) {
+	l.breakers[breaker.Scope] = breaker
+	l.logger.Error().Str("scope", breaker.Scope).Str("orderId", breaker.OrderId).
+		Msgf("outbound limits breached, signing held: %s", breaker.Reason)
+	if l.alert == nil {
+		return
+	}
+	text := fmt.Sprintf("signing of %s held: %s", breaker.Scope, breaker.Reason)
+	if err := l.alert(text); err != nil {
+		l.logger.Err(err).Str("scope", breaker.Scope).Msg("fail to send outbound limits alert")
+	}
+}
+
+// hold adds the outbounds to the ones held by the breaker and stores it
+func (l *OutboundLimiter) hold(breaker Breaker, txs []types.TxOutItem) {
+	for _, tx := range txs {
+		orderID := tx.OrderId.Hex()
+		held := false
+		for _, id := range breaker.OrderIds {
+			if id == orderID {
+				held = true
+				break
+			}
+		}
+		if !held {
+			breaker.OrderIds = append(breaker.OrderIds, orderID)
+		}
+	}
+	l.breakers[breaker.Scope] = breaker
+	buf, err := json.Marshal(breaker)
+	if err != nil {
+		l.logger.Error().Err(err).Msg("fail to marshal breaker")
+		return
+	}
+	if err = l.db.Put([]byte(limitBreakerPrefix+breaker.Scope), buf, nil); err != nil {
+		l.logger.Error().Err(err).Str("scope", breaker.Scope).Msg("fail to store breaker")
+	}
+}
+
+// Record counts the signed outbounds in the outflows of the window
+func (l *OutboundLimiter) Record(chain common.Chain, vault string, txs []types.TxOutItem) {
+	if l == nil {
+		return
+	}
+	if _, ok := l.chains[chain]; !ok {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, tx := range txs {
+		outflow := Outflow{
+			OrderId: tx.OrderId.Hex(),
+			Chain:   chain.String(),
+			Vault:   vault,
+			Token:   normalizeToken(hex.EncodeToString(tx.Token)),
+			Amount:  tx.Amount,
+			Time:    time.Now(),
+		}
+		if outflow.Amount == nil {
+			outflow.Amount = new(big.Int)
+		}
+		buf, err := json.Marshal(outflow)
+		if err != nil {
+			l.logger.Error().Err(err).Msg("fail to marshal outflow")
+			continue
+		}
+		if err = l.db.Put([]byte(limitOutflowPrefix+outflow.OrderId), buf, nil); err != nil {
+			l.logger.Error().Err(err).Str("orderId", outflow.OrderId).Msg("fail to store outflow")
+			continue
+		}
+		l.outflows[outflow.OrderId] = outflow
+		if l.approved[outflow.OrderId] {
+			delete(l.approved, outflow.OrderId)
+			if err = l.db.Delete([]byte(limitApprovedPrefix+outflow.OrderId), nil); err != nil {
+				l.logger.Error().Err(err).Str("orderId", outflow.OrderId).Msg("fail to delete approval")
+			}
+		}
+	}
+}
+
+// prune drops the outflows of the chain older than the window
+func (l *OutboundLimiter) prune(chain common.Chain, window time.Duration) {
+	before := time.Now().Add(-window)
+	for orderID, outflow := range l.outflows {
+		if outflow.Chain != chain.String() || !outflow.Time.Before(before) {
+			continue
+		}
+		if err := l.db.Delete([]byte(limitOutflowPrefix+orderID), nil); err != nil {
+			l.logger.Error().Err(err).Str("orderId", orderID).Msg("fail to delete outflow")
+			continue
+		}
+		delete(l.outflows, orderID)
+	}
+}
+
+// Held returns whether the signing of the chain or of the vault on the chain is held, the
+// breakers of a token only hold the outbounds of the token
+func (l *OutboundLimiter) Held(chain common.Chain, vault string) bool {
+	if l == nil {
+		return false
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	_, chainHeld := l.breakers[breakerScope(chain, "", "")]
+	_, vaultHeld := l.breakers[breakerScope(chain, vault, "")]
+	return chainHeld || vaultHeld
+}
+
+// ListBreakers returns the tripped breakers, oldest first
+func (l *OutboundLimiter) ListBreakers() []Breaker {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	breakers := make([]Breaker, 0, len(l.breakers))
+	for _, breaker := range l.breakers {
+		breakers = append(breakers, breaker)
+	}
+	sort.Slice(breakers, func(i, j int) bool { return breakers[i].TrippedAt.Before(breakers[j].TrippedAt) })
+	return breakers
+}
+
+// Release resumes the signing of the scope of the breaker, regardless of its case. The
+// outbounds it held are signed even if they breach the limits again.
+func (l *OutboundLimiter) Release(scope string) (Breaker, error) {
+	if l == nil {
+		return Breaker{}, errors.New("outbound limits are not enabled")
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	var breaker Breaker
+	for _, b := range l.breakers {
+		if strings.EqualFold(b.Scope, scope) {
+			breaker = b
+			break
+		}
+	}
+	if breaker.Scope == "" {
+		return breaker, fmt.Errorf("breaker %s not found", scope)
+	}
+	scope = breaker.Scope
+	batch := new(leveldb.Batch)
+	for _, orderID := range breaker.OrderIds {
+		batch.Put([]byte(limitApprovedPrefix+orderID), []byte{1})
+	}
+	batch.Delete([]byte(limitBreakerPrefix + scope))
+	if err := l.db.Write(batch, nil); err != nil {
+		return breaker, fmt.Errorf("fail to release breaker %s: %w", scope, err)
+	}
+	for _, orderID := range breaker.OrderIds {
+		l.approved[orderID] = true
+	}
+	delete(l.breakers, scope)
+	l.logger.Info().Str("scope", scope).Int("orders", len(breaker.OrderIds)).Msg("outbound limits breaker released")
+	return breaker, nil
+}
@@ -0,0 +1,114 @@
+package signer
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+	. "gopkg.in/check.v1"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/config"
+	"github.com/mapprotocol/compass-tss/mapclient/types"
+)
+
+type LimitsSuite struct{}
+
+var _ = Suite(&LimitsSuite{})
+
+func limitTxOut(order int64, token string, amount int64) types.TxOutItem {
+	return types.TxOutItem{
+		OrderId: ecommon.BigToHash(big.NewInt(order)),
+		Token:   ecommon.FromHex(token),
+		Amount:  big.NewInt(amount),
+	}
+}
+
+func (s *LimitsSuite) TestOutboundLimiter(c *C) {
+	store, err := NewSignerStore("", config.LevelDBOptions{})
+	c.Assert(err, IsNil)
+	var bsc config.BifrostChainConfiguration
+	bsc.OutboundLimits.Window = time.Hour
+	bsc.OutboundLimits.Tokens = []config.BifrostOutboundTokenLimit{
+		{Token: "0x00000000000000000000000000000000000000AA", MaxTx: "100", MaxVaultOutflow: "150", MaxChainOutflow: "250"},
+	}
+	limiter, err := NewOutboundLimiter(store.GetInternalDb(), map[common.Chain]config.BifrostChainConfiguration{common.BSCChain: bsc})
+	c.Assert(err, IsNil)
+	var alerts []string
+	limiter.SetAlerter(func(text string) error {
+		alerts = append(alerts, text)
+		return nil
+	})
+	const token = "0x00000000000000000000000000000000000000aa"
+
+	// chains without limits are not checked
+	c.Check(limiter.Check(common.ETHChain, "vault1", []types.TxOutItem{limitTxOut(1, "0x01", 1000)}), IsNil)
+
+	c.Assert(limiter.Check(common.BSCChain, "vault1", []types.TxOutItem{limitTxOut(1, token, 100)}), IsNil)
+	limiter.Record(common.BSCChain, "vault1", []types.TxOutItem{limitTxOut(1, token, 100)})
+
+	// the vault outflow trips the breaker of the vault only
+	err = limiter.Check(common.BSCChain, "vault1", []types.TxOutItem{limitTxOut(2, token, 60)})
+	c.Assert(errors.Is(err, ErrSigningHeld), Equals, true)
+	c.Check(limiter.Held(common.BSCChain, "vault1"), Equals, true)
+	c.Check(limiter.Held(common.BSCChain, "vault2"), Equals, false)
+	c.Check(alerts, HasLen, 1)
+	c.Assert(limiter.Check(common.BSCChain, "vault2", []types.TxOutItem{limitTxOut(3, token, 60)}), IsNil)
+	limiter.Record(common.BSCChain, "vault2", []types.TxOutItem{limitTxOut(3, token, 60)})
+
+	// an item of a held vault is added to the held orders
+	err = limiter.Check(common.BSCChain, "vault1", []types.TxOutItem{limitTxOut(4, token, 1)})
+	c.Assert(errors.Is(err, ErrSigningHeld), Equals, true)
+	breakers := limiter.ListBreakers()
+	c.Assert(breakers, HasLen, 1)
+	c.Check(breakers[0].Scope, Equals, "Bsc:vault1")
+	c.Check(breakers[0].OrderIds, HasLen, 2)
+
+	// above the max of a tx trips the breaker of the chain
+	err = limiter.Check(common.BSCChain, "vault2", []types.TxOutItem{limitTxOut(5, token, 101)})
+	c.Assert(errors.Is(err, ErrSigningHeld), Equals, true)
+	c.Check(limiter.Held(common.BSCChain, "vault3"), Equals, true)
+	_, err = limiter.Release("BSC")
+	c.Assert(err, IsNil)
+
+	// a token not allowed only holds the outbounds of the token, with the ones signed with them
+	err = limiter.Check(common.BSCChain, "vault2", []types.TxOutItem{limitTxOut(6, "0x01", 1)})
+	c.Assert(errors.Is(err, ErrSigningHeld), Equals, true)
+	c.Check(alerts, HasLen, 3)
+	c.Check(limiter.Held(common.BSCChain, "vault2"), Equals, false)
+	c.Check(limiter.Check(common.BSCChain, "vault2", []types.TxOutItem{limitTxOut(8, token, 1)}), IsNil)
+	err = limiter.Check(common.BSCChain, "vault2", []types.TxOutItem{limitTxOut(8, token, 1), limitTxOut(9, "0x01", 1)})
+	c.Assert(errors.Is(err, ErrSigningHeld), Equals, true)
+	c.Check(alerts, HasLen, 3)
+
+	// the state survives a restart, the released order is signed even if above the max
+	limiter, err = NewOutboundLimiter(store.GetInternalDb(), map[common.Chain]config.BifrostChainConfiguration{common.BSCChain: bsc})
+	c.Assert(err, IsNil)
+	breakers = limiter.ListBreakers()
+	c.Assert(breakers, HasLen, 2)
+	c.Check(breakers[1].Scope, Equals, "Bsc:token:0x01")
+	c.Check(breakers[1].OrderIds, HasLen, 3)
+	c.Check(limiter.Check(common.BSCChain, "vault2", []types.TxOutItem{limitTxOut(5, token, 101)}), IsNil)
+	_, err = limiter.Release("BSC:token:0x01")
+	c.Assert(err, IsNil)
+	c.Check(limiter.Check(common.BSCChain, "vault2", []types.TxOutItem{limitTxOut(6, "0x01", 1)}), IsNil)
+	_, err = limiter.Release("BSC:token:0x01")
+	c.Check(err, NotNil)
+
+	// the chain outflow counts every vault
+	_, err = limiter.Release("BSC:vault1")
+	c.Assert(err, IsNil)
+	c.Check(limiter.Check(common.BSCChain, "vault1", []types.TxOutItem{limitTxOut(2, token, 60), limitTxOut(4, token, 1)}), IsNil)
+	limiter.Record(common.BSCChain, "vault1", []types.TxOutItem{limitTxOut(2, token, 60), limitTxOut(4, token, 1)})
+	err = limiter.Check(common.BSCChain, "vault3", []types.TxOutItem{limitTxOut(7, token, 30)})
+	c.Assert(errors.Is(err, ErrSigningHeld), Equals, true)
+	c.Check(limiter.Held(common.BSCChain, "vault2"), Equals, true)
+
+	_, err = NewOutboundLimiter(store.GetInternalDb(), map[common.Chain]config.BifrostChainConfiguration{common.BSCChain: func() config.BifrostChainConfiguration {
+		cfg := bsc
+		cfg.OutboundLimits.Tokens = []config.BifrostOutboundTokenLimit{{Token: token, MaxTx: "1e18"}}
+		return cfg
+	}()})
+	c.Check(err, NotNil)
+}
@@ -0,0 +1,114 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff"
+
+	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/metrics"
+)
+
+// submitOracle sends the oracle transaction of the item to the relay chain, the item is
+// removed once sent or when its order was executed already
+func (s *Signer) submitOracle(item TxOutStoreItem) error {
+	txBytes, err := s.mapBridge.GetOracleStdTx(&item.TxOutItem)
+	if err != nil {
+		if errors.Is(err, constants.ErrorOfOrderExecuted) {
+			s.logger.Error().Str("txHash", item.TxOutItem.TxHash).Err(err).Msg("ignore oracle tx")
+			if err = s.oracleStorage.Remove(item); err != nil {
+				s.logger.Error().Err(err).Msg("fail to remove oracle item")
+			}
+			return nil
+		}
+		return fmt.Errorf("fail to get oracle std tx: %w", err)
+	}
+
+	if len(txBytes) == 0 {
+		return nil
+	}
+
+	bf := backoff.NewExponentialBackOff()
+	bf.MaxElapsedTime = 5 * time.Second
+	err = backoff.Retry(func() error {
+		txID, err := s.mapBridge.Broadcast(txBytes)
+		if err != nil {
+			return fmt.Errorf("fail to send the tx to relay: %w", err)
+		}
+		if err = s.oracleStorage.Remove(item); err != nil {
+			s.logger.Error().Err(err).Msg("fail to remove oracle item")
+		}
+		s.logger.Info().Str("txHash", item.TxOutItem.TxHash).Str("relayHash", txID).Msg("oracle tx sent successfully")
+		return nil
+	}, bf)
+	if err != nil {
+		return fmt.Errorf("fail to broadcast tx: %w", err)
+	}
+	return nil
+}
+
+// oracleFailed records the failed submission of the item, it is retried after a backoff
+// or moved to the dead letters once out of attempts
+func (s *Signer) oracleFailed(item TxOutStoreItem, err error, now time.Time) {
+	item.Attempts++
+	item.LastError = err.Error()
+	cfg := s.cfg.Signer.Oracle
+	if cfg.MaxAttempts > 0 && item.Attempts >= cfg.MaxAttempts {
+		s.errCounter.WithLabelValues("oracle_dead_letter", "").Inc()
+		s.logger.Error().Err(err).Str("txHash", item.TxOutItem.TxHash).Str("orderId", item.TxOutItem.OrderId.Hex()).
+			Int("attempts", item.Attempts).Msg("oracle item out of attempts, moved to dead letters")
+		if dlErr := s.oracleStorage.DeadLetter(item); dlErr != nil {
+			s.logger.Error().Err(dlErr).Msg("fail to move oracle item to dead letters")
+		}
+		return
+	}
+
+	wait := oracleRetryBackoff(cfg.RetryBackoff, cfg.MaxRetryBackoff, item.Attempts)
+	item.NextAttempt = now.Add(wait).Unix()
+	s.logger.Error().Err(err).Str("txHash", item.TxOutItem.TxHash).Int("attempts", item.Attempts).
+		Stringer("retryIn", wait).Msg("fail to submit oracle item")
+	if storeErr := s.oracleStorage.Set(item); storeErr != nil {
+		s.logger.Error().Err(storeErr).Msg("fail to update oracle item")
+	}
+}
+
+// oracleRetryBackoff returns the wait before the next submission after the given number
+// of failed attempts, doubled after each failure up to the maximum
+func oracleRetryBackoff(base, maxBackoff time.Duration, attempts int) time.Duration {
+	if base <= 0 || attempts <= 0 {
+		return 0
+	}
+	wait := base
+	for i := 1; i < attempts; i++ {
+		if maxBackoff > 0 && wait >= maxBackoff {
+			break
+		}
+		wait *= 2
+	}
+	if maxBackoff > 0 && wait > maxBackoff {
+		wait = maxBackoff
+	}
+	return wait
+}
+
+// updateOracleMetrics exports the depth and age of the oracle queue and its dead letters
+func (s *Signer) updateOracleMetrics(items []TxOutStoreItem) {
+	if s.m == nil {
+		return
+	}
+	var oldest int64
+	for _, item := range items {
+		if item.QueuedAt > 0 && (oldest == 0 || item.QueuedAt < oldest) {
+			oldest = item.QueuedAt
+		}
+	}
+	age := float64(0)
+	if oldest > 0 {
+		age = time.Since(time.Unix(oldest, 0)).Seconds()
+	}
+	s.m.GetGauge(metrics.OracleQueueDepth).Set(float64(len(items)))
+	s.m.GetGauge(metrics.OracleQueueAge).Set(age)
+	s.m.GetGauge(metrics.OracleDeadLetters).Set(float64(len(s.oracleStorage.ListDeadLetters())))
+}
@@ -0,0 +1,21 @@
+package signer
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type OracleSuite struct{}
+
+var _ = Suite(&OracleSuite{})
+
+func (s *OracleSuite) TestOracleRetryBackoff(c *C) {
+	c.Check(oracleRetryBackoff(10*time.Second, 10*time.Minute, 0), Equals, time.Duration(0))
+	c.Check(oracleRetryBackoff(10*time.Second, 10*time.Minute, 1), Equals, 10*time.Second)
+	c.Check(oracleRetryBackoff(10*time.Second, 10*time.Minute, 3), Equals, 40*time.Second)
+	c.Check(oracleRetryBackoff(10*time.Second, 10*time.Minute, 7), Equals, 10*time.Minute)
+	c.Check(oracleRetryBackoff(10*time.Second, 10*time.Minute, 1000), Equals, 10*time.Minute)
+	c.Check(oracleRetryBackoff(10*time.Second, 0, 4), Equals, 80*time.Second)
+	c.Check(oracleRetryBackoff(0, 10*time.Minute, 4), Equals, time.Duration(0))
+}
@@ -2,6 +2,7 @@ package signer
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/mapprotocol/compass-tss/constants"
@@ -65,6 +66,8 @@ type pipeline struct {
 	// vaultChainLock maps a vault/chain combination to a lock. The lock is represented as
 	// a channel instead of a mutex so we can check if it is taken without blocking.
 	vaultChainLock map[vaultChain]chan struct{}
+	// lockMu guards vaultChainLock, the locks are also taken outside of the signing routines
+	lockMu sync.Mutex
 }
 
 // NewPipeline creates a new pipeline instance using the provided concurrency for active
@@ -88,6 +91,45 @@ func newPipeline(concurrency int64) (*pipeline, error) {
 	}, nil
 }
 
+// lockedVaultChains returns the vault/chain combinations with a signing in progress.
+func (p *pipeline) lockedVaultChains() map[vaultChain]bool {
+	p.lockMu.Lock()
+	defer p.lockMu.Unlock()
+	locked := make(map[vaultChain]bool)
+	for vc, lock := range p.vaultChainLock {
+		if len(lock) > 0 {
+			locked[vc] = true
+		}
+	}
+	return locked
+}
+
+// tryLockVaultChain takes the lock of a vault/chain combination without blocking, it
+// returns false when the lock is taken.
+func (p *pipeline) tryLockVaultChain(vc vaultChain) bool {
+	p.lockMu.Lock()
+	lock, ok := p.vaultChainLock[vc]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		p.vaultChainLock[vc] = lock
+	}
+	p.lockMu.Unlock()
+	select {
+	case lock <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// unlockVaultChain releases the lock of a vault/chain combination.
+func (p *pipeline) unlockVaultChain(vc vaultChain) {
+	p.lockMu.Lock()
+	lock := p.vaultChainLock[vc]
+	p.lockMu.Unlock()
+	<-lock
+}
+
 // SpawnSiginings will fetch all transactions from the provided Signer's storage, and
 // start signing routines for any transactions that have:
 //  1. Sufficient capacity in the vault status semaphore for the source vault's status.
@@ -153,12 +195,7 @@ func (p *pipeline) SpawnSignings(s pipelineSigner, bridge shareTypes.Bridge) {
 	}()
 
 	// get all locked vault/chains - otherwise races if a vault/chain unlocks mid-iteration
-	lockedVaultChains := make(map[vaultChain]bool)
-	for vc, lock := range p.vaultChainLock {
-		if len(lock) > 0 {
-			lockedVaultChains[vc] = true
-		}
-	}
+	lockedVaultChains := p.lockedVaultChains()
 	if len(itemsToSign) > 0 {
 		log.Debug().Msgf("SpawnSignings will handle %d tx locking", len(itemsToSign))
 	}
@@ -179,20 +216,18 @@ func (p *pipeline) SpawnSignings(s pipelineSigner, bridge shareTypes.Bridge) {
 			continue
 		}
 
-		// if no lock exists, create one
-		if _, ok := p.vaultChainLock[vc]; !ok {
-			p.vaultChainLock[vc] = make(chan struct{}, 1)
-		}
-
 		// check if the vault status semaphore has capacity
 		if availableCapacities[constants.VaultStatus_ActiveVault] == 0 {
 			log.Info().Msgf("availableCapacities skill %s a tx", item.TxOutItem.TxHash)
 			continue
 		}
 
-		// acquire the vault status semaphore and vault/chain lock
+		// acquire the vault/chain lock, an operator may hold it, and the vault status semaphore
+		if !p.tryLockVaultChain(vc) {
+			lockedVaultChains[vc] = true
+			continue
+		}
 		availableCapacities[constants.VaultStatus_ActiveVault]--
-		p.vaultChainLock[vc] <- struct{}{}
 		lockedVaultChains[vc] = true
 		log.Info().Msgf("will handler  %s a tx", item.TxOutItem.TxHash)
 
@@ -201,7 +236,7 @@ func (p *pipeline) SpawnSignings(s pipelineSigner, bridge shareTypes.Bridge) {
 			// release the vault status semaphore and vault/chain lock when complete
 			defer func() {
 				vc2 := vaultChain{item.TxOutItem.VaultPubKey, item.TxOutItem.Chain.String()}
-				<-p.vaultChainLock[vc2]
+				p.unlockVaultChain(vc2)
 				p.vaultStatusConcurrency[vaultStatus].release(1)
 			}()
 
@@ -211,18 +246,22 @@ func (p *pipeline) SpawnSignings(s pipelineSigner, bridge shareTypes.Bridge) {
 	}
 }
 
+// running returns whether any pipeline signing routine, or an operator action holding a
+// vault/chain lock, is in progress.
+func (p *pipeline) running() bool {
+	for _, semaphore := range p.vaultStatusConcurrency {
+		if len(semaphore) > 0 {
+			return true
+		}
+	}
+	return len(p.lockedVaultChains()) > 0
+}
+
 // Wait will block until all pipeline signing routines have completed.
 func (p *pipeline) Wait() {
 	log.Info().Msg("waiting for signer pipeline routines to complete")
 	for {
-		running := false
-		for _, semaphore := range p.vaultStatusConcurrency {
-			if len(semaphore) > 0 {
-				running = true
-				break
-			}
-		}
-		if !running {
+		if !p.running() {
 			log.Info().Msg("signer pipeline routines complete")
 			return
 		}
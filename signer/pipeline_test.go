@@ -1,6 +1,8 @@
 package signer
 
 import (
+	"testing"
+
 	"github.com/rs/zerolog/log"
 
 	. "gopkg.in/check.v1"
@@ -15,6 +17,30 @@ func init() {
 	log.Logger = log.With().Caller().Logger()
 }
 
+func TestPackage(t *testing.T) { TestingT(t) }
+
+type PipelineSuite struct{}
+
+var _ = Suite(&PipelineSuite{})
+
+func (s *PipelineSuite) TestVaultChainLock(c *C) {
+	p, err := newPipeline(1)
+	c.Assert(err, IsNil)
+	vc := vaultChain{Vault: "vault", Chain: "56"}
+	c.Assert(p.running(), Equals, false)
+
+	// an operator holding the lock keeps the pipeline running and off the vault/chain
+	c.Assert(p.tryLockVaultChain(vc), Equals, true)
+	c.Assert(p.tryLockVaultChain(vc), Equals, false)
+	c.Assert(p.lockedVaultChains()[vc], Equals, true)
+	c.Assert(p.running(), Equals, true)
+	c.Assert(p.tryLockVaultChain(vaultChain{Vault: "vault", Chain: "1"}), Equals, true)
+
+	p.unlockVaultChain(vc)
+	c.Assert(p.lockedVaultChains()[vc], Equals, false)
+	c.Assert(p.tryLockVaultChain(vc), Equals, true)
+}
+
 // ////////////////////////////////////////////////////////////////////////////////////////
 // // mockPipelineSigner
 // ////////////////////////////////////////////////////////////////////////////////////////
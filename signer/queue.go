@@ -0,0 +1,253 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/constants"
+	"github.com/mapprotocol/compass-tss/pkg/chainclients/utxo"
+)
+
+// The states of a queued item
+const (
+	QueueStateAvailable         = "available"
+	QueueStateUnavailable       = "unavailable"
+	QueueStateRound7Retry       = "round7_retry"
+	QueueStateAwaitingBroadcast = "awaiting_broadcast"
+	// QueueStateBlocked is an item of a vault and chain with another item in retry, it
+	// waits until that one is signed and broadcast
+	QueueStateBlocked = "blocked"
+	// QueueStateHeld is an item of a chain or vault whose outbound limits were breached, it
+	// waits until an operator releases the breaker
+	QueueStateHeld = "held"
+)
+
+// QueueItem is an item of the signer queue along with its age and attempt state
+type QueueItem struct {
+	Key     string `json:"key"`
+	Chain   string `json:"chain"`
+	Vault   string `json:"vault"`
+	OrderId string `json:"order_id"`
+	TxHash  string `json:"relay_hash"`
+	Height  int64  `json:"height"`
+	// Age is the time since the item was queued, it is estimated from the relay chain
+	// height of the items queued before it was recorded
+	Age        string   `json:"age"`
+	AgeSeconds int64    `json:"age_seconds"`
+	Status     TxStatus `json:"status"`
+	State      string   `json:"state"`
+	// Attempt is the signing period the item is in, an outbound is rescheduled by the
+	// relay chain after a number of them
+	Attempt       int64 `json:"attempt"`
+	Round7Retry   bool  `json:"round7_retry"`
+	HasCheckpoint bool  `json:"has_checkpoint"`
+	HasSignedTx   bool  `json:"has_signed_tx"`
+	Batch         int   `json:"batch,omitempty"`
+}
+
+// ListQueue returns the items waiting in the signer (or oracle) store, oldest first. Only
+// the items of the given chain and vault are returned when they are not empty.
+func (s *Signer) ListQueue(oracle bool, chain, vault string) ([]QueueItem, error) {
+	blockHeight, err := s.mapBridge.GetBlockHeight()
+	if err != nil {
+		return nil, fmt.Errorf("fail to get block height: %w", err)
+	}
+	period, err := s.constantsProvider.GetInt64Value(blockHeight, constants.SigningTransactionPeriod)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get constant value for(%s): %w", constants.SigningTransactionPeriod, err)
+	}
+
+	items := s.getStorage(oracle).List()
+	// the pipeline only signs the item in retry of a vault and chain
+	retry := make(map[vaultChain]bool)
+	for _, item := range items {
+		if !oracle && (item.Round7Retry || len(item.SignedTx) > 0) {
+			retry[vaultChain{item.TxOutItem.VaultPubKey, item.TxOutItem.Chain.String()}] = true
+		}
+	}
+
+	now := time.Now()
+	result := make([]QueueItem, 0, len(items))
+	for _, item := range items {
+		qi := newQueueItem(item)
+		if chain != "" && !strings.EqualFold(qi.Chain, chain) {
+			continue
+		}
+		if vault != "" && !strings.EqualFold(qi.Vault, vault) {
+			continue
+		}
+		var age time.Duration
+		if item.QueuedAt > 0 {
+			age = now.Sub(time.Unix(item.QueuedAt, 0))
+		} else if blockHeight > item.Height {
+			age = time.Duration(blockHeight-item.Height) * constants.MAPRelayChainBlockTime
+		}
+		qi.Age = age.Truncate(time.Second).String()
+		qi.AgeSeconds = int64(age.Seconds())
+		if period > 0 && blockHeight > item.Height {
+			qi.Attempt = (blockHeight - item.Height) / period
+		}
+		if qi.State != QueueStateRound7Retry && qi.State != QueueStateAwaitingBroadcast &&
+			retry[vaultChain{item.TxOutItem.VaultPubKey, item.TxOutItem.Chain.String()}] {
+			qi.State = QueueStateBlocked
+		}
+		if !oracle && qi.State != QueueStateAwaitingBroadcast && s.limiter.Held(common.Chain(qi.Chain), qi.Vault) {
+			qi.State = QueueStateHeld
+		}
+		result = append(result, qi)
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].AgeSeconds > result[j].AgeSeconds })
+	return result, nil
+}
+
+func newQueueItem(item TxOutStoreItem) QueueItem {
+	qi := QueueItem{
+		Key:           item.Key(),
+		OrderId:       item.TxOutItem.OrderId.Hex(),
+		TxHash:        item.TxOutItem.TxHash,
+		Height:        item.Height,
+		Status:        item.Status,
+		Round7Retry:   item.Round7Retry,
+		HasCheckpoint: len(item.Checkpoint) > 0,
+		HasSignedTx:   len(item.SignedTx) > 0,
+		Batch:         len(item.Batch),
+	}
+	if chain, ok := common.GetChainName(item.TxOutItem.Chain); ok {
+		qi.Chain = chain.String()
+	} else if item.TxOutItem.Chain != nil {
+		qi.Chain = item.TxOutItem.Chain.String()
+	}
+	if pubKey, err := common.CompressPubKey(item.TxOutItem.Vault); err == nil {
+		qi.Vault = pubKey
+	}
+	switch {
+	case len(item.SignedTx) > 0:
+		qi.State = QueueStateAwaitingBroadcast
+	case item.Round7Retry:
+		qi.State = QueueStateRound7Retry
+	case item.Status == TxAvailable:
+		qi.State = QueueStateAvailable
+	default:
+		qi.State = QueueStateUnavailable
+	}
+	return qi
+}
+
+// getStoreItem returns the item of the signer store with the given key
+func (s *Signer) getStoreItem(key string) (TxOutStoreItem, error) {
+	if !s.storage.Has(key) {
+		return TxOutStoreItem{}, fmt.Errorf("item %s not found", key)
+	}
+	item, err := s.storage.Get(key)
+	if err != nil {
+		return item, fmt.Errorf("fail to get item %s: %w", key, err)
+	}
+	return item, nil
+}
+
+// lockVaultChain takes the vault/chain lock the signing pipeline holds while it signs and
+// broadcasts an item of the vault and chain, it fails when a signing is in progress.
+func (s *Signer) lockVaultChain(vc vaultChain) (func(), error) {
+	s.pipelineMu.Lock()
+	defer s.pipelineMu.Unlock()
+	if s.pipeline == nil {
+		return nil, errors.New("signer pipeline is not started")
+	}
+	p := s.pipeline
+	if !p.tryLockVaultChain(vc) {
+		return nil, fmt.Errorf("a signing of vault %s on chain %s is in progress", vc.Vault, vc.Chain)
+	}
+	return func() { p.unlockVaultChain(vc) }, nil
+}
+
+// RebroadcastStoreItem broadcasts the signed transaction stored on the item with the given
+// key right away, instead of waiting for the next signing round. The item is removed once
+// the transaction was accepted, it returns the hash of the transaction.
+func (s *Signer) RebroadcastStoreItem(key string) (TxOutStoreItem, string, error) {
+	item, err := s.getStoreItem(key)
+	if err != nil {
+		return item, "", err
+	}
+	// the signing round must not sign or broadcast an item of the vault and chain meanwhile
+	unlock, err := s.lockVaultChain(vaultChain{item.TxOutItem.VaultPubKey, item.TxOutItem.Chain.String()})
+	if err != nil {
+		return item, "", err
+	}
+	defer unlock()
+	// the item may have been broadcast and removed before the lock was taken
+	if item, err = s.getStoreItem(key); err != nil {
+		return item, "", err
+	}
+	if len(item.SignedTx) == 0 {
+		return item, "", fmt.Errorf("item %s has no signed tx", key)
+	}
+	chain, err := s.getChain(item.TxOutItem.Chain)
+	if err != nil {
+		return item, "", fmt.Errorf("fail to get chain client: %w", err)
+	}
+	tx := item.TxOutItem
+	pubKey, err := common.CompressPubKey(tx.Vault)
+	if err != nil {
+		return item, "", fmt.Errorf("fail to compress vault public key: %w", err)
+	}
+	tx.VaultPubKey = common.PubKey(pubKey)
+	if item.Checkpoint != nil {
+		tx.Checkpoint = item.Checkpoint
+	}
+
+	// the signing round may be broadcasting the same transaction of a utxo vault
+	if utxoClient, ok := chain.(*utxo.Client); ok {
+		lock := utxoClient.GetVaultLock(string(tx.Vault))
+		lock.Lock()
+		defer lock.Unlock()
+	}
+	hash, err := s.broadcast(chain, item, tx, item.SignedTx)
+	if err != nil {
+		return item, "", fmt.Errorf("fail to broadcast tx: %w", err)
+	}
+	s.logger.Info().Str("relayHash", item.TxOutItem.TxHash).Str("txId", hash).Msg("rebroadcasted tx to chain")
+	s.recordBroadcast(item, tx, hash)
+	if err = s.storage.Remove(item); err != nil {
+		return item, hash, fmt.Errorf("fail to remove item %s: %w", key, err)
+	}
+	return item, hash, nil
+}
+
+// ClearRound7Retry clears the round 7 retry of the item with the given key, which blocks
+// the other items of its vault and chain. The checkpoint is kept to avoid a double spend.
+func (s *Signer) ClearRound7Retry(key string) (TxOutStoreItem, error) {
+	item, err := s.getStoreItem(key)
+	if err != nil {
+		return item, err
+	}
+	if !item.Round7Retry {
+		return item, fmt.Errorf("item %s is not in round 7 retry", key)
+	}
+	item.Round7Retry = false
+	if err = s.storage.Set(item); err != nil {
+		return item, fmt.Errorf("fail to update item %s: %w", key, err)
+	}
+	return item, nil
+}
+
+// ArchiveStoreItem moves the item with the given key out of the signer queue, unlike a
+// dropped item it is kept and listed with ListArchived
+func (s *Signer) ArchiveStoreItem(key string) (TxOutStoreItem, error) {
+	item, err := s.getStoreItem(key)
+	if err != nil {
+		return item, err
+	}
+	if err = s.storage.Archive(item); err != nil {
+		return item, fmt.Errorf("fail to archive item %s: %w", key, err)
+	}
+	return item, nil
+}
+
+// ListArchived returns the items archived out of the signer queue
+func (s *Signer) ListArchived() []TxOutStoreItem {
+	return s.storage.ListArchived()
+}
@@ -7,12 +7,12 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/btcsuite/btcd/btcec"
-	"github.com/cenkalti/backoff"
 	ecommon "github.com/ethereum/go-ethereum/common"
 	ecrypto "github.com/ethereum/go-ethereum/crypto"
 	"github.com/mapprotocol/compass-tss/blockscanner"
@@ -22,6 +22,7 @@ import (
 	"github.com/mapprotocol/compass-tss/internal/cross"
 	"github.com/mapprotocol/compass-tss/internal/keys"
 	"github.com/mapprotocol/compass-tss/internal/structure"
+	"github.com/mapprotocol/compass-tss/internal/tracing"
 	"github.com/mapprotocol/compass-tss/mapclient/types"
 	"github.com/mapprotocol/compass-tss/metrics"
 	"github.com/mapprotocol/compass-tss/observer"
@@ -44,6 +45,9 @@ type Signer struct {
 	wg                   *sync.WaitGroup
 	mapBridge            shareTypes.Bridge
 	stopChan             chan struct{}
+	drainChan            chan struct{}
+	drainedChan          chan struct{}
+	drainOnce            sync.Once
 	blockScanner         *blockscanner.BlockScanner
 	mapChainBlockScanner *mapo.MapChainBlockScan
 	chains               map[common.Chain]chainclients.ChainClient
@@ -59,7 +63,10 @@ type Signer struct {
 	tssKeysignMetricMgr  *metrics.TssKeysignMetricMgr
 	observer             *observer.Observer
 	pipeline             *pipeline
+	pipelineMu           sync.Mutex // guards pipeline, it is replaced by the signing loop
 	crossStorage         *cross.CrossStorage
+	blameLedger          *tss.BlameLedger
+	limiter              *OutboundLimiter
 }
 
 // NewSigner create a new instance of signer
@@ -139,11 +146,17 @@ func NewSigner(cfg config.Bifrost,
 		return nil, fmt.Errorf("fail to create Tss Key gen,err:%w", err)
 	}
 	constantProvider := NewConstantsProvider(bridge)
+	limiter, err := NewOutboundLimiter(storage.GetInternalDb(), cfg.GetChains())
+	if err != nil {
+		return nil, fmt.Errorf("fail to create outbound limiter: %w", err)
+	}
 	return &Signer{
 		logger:               log.With().Str("module", "signer").Logger(),
 		cfg:                  cfg,
 		wg:                   &sync.WaitGroup{},
 		stopChan:             make(chan struct{}),
+		drainChan:            make(chan struct{}),
+		drainedChan:          make(chan struct{}),
 		blockScanner:         blockScanner,
 		mapChainBlockScanner: mapChainBlockScanner,
 		chains:               chains,
@@ -160,9 +173,41 @@ func NewSigner(cfg config.Bifrost,
 		tssKeysignMetricMgr:  tssKeysignMetricMgr,
 		observer:             obs,
 		crossStorage:         crossStorage,
+		blameLedger:          tss.NewBlameLedger(storage.GetInternalDb(), cfg.Signer.BlameRetention, m.GetCounterVec(metrics.TSSBlame)),
+		limiter:              limiter,
 	}, nil
 }
 
+// SetLimitAlerter sets where the breaches of the outbound limits are reported to
+func (s *Signer) SetLimitAlerter(alert Alerter) {
+	s.limiter.SetAlerter(alert)
+}
+
+// ListBreakers returns the tripped outbound limits holding the signing of a chain or vault
+func (s *Signer) ListBreakers() []Breaker {
+	return s.limiter.ListBreakers()
+}
+
+// ReleaseBreaker resumes the signing held by the breaker of the given scope
+func (s *Signer) ReleaseBreaker(scope string) (Breaker, error) {
+	return s.limiter.Release(scope)
+}
+
+// BlameLedger returns the ledger the blame of failed keysigns and keygens is recorded in
+func (s *Signer) BlameLedger() *tss.BlameLedger {
+	return s.blameLedger
+}
+
+// recordBlame stores the blame of a failed keysign or keygen in the blame ledger
+func (s *Signer) recordBlame(record tss.BlameRecord) {
+	if s.blameLedger == nil {
+		return
+	}
+	if err := s.blameLedger.Record(record); err != nil {
+		s.logger.Error().Err(err).Str("type", record.Type).Msg("fail to record blame")
+	}
+}
+
 func (s *Signer) getChain(chainID *big.Int) (chainclients.ChainClient, error) {
 	chainName, ok := common.GetChainName(chainID)
 	if !ok {
@@ -208,6 +253,9 @@ func (s *Signer) signTransactions() {
 		select {
 		case <-s.stopChan:
 			return
+		case <-s.drainChan:
+			s.drainPipeline()
+			return
 		default:
 			// When map relay chain is catching up , bifrost might get stale data from compass-tss , thus it shall pause signing
 			catchingUp, err := s.mapBridge.IsSyncing()
@@ -224,6 +272,49 @@ func (s *Signer) signTransactions() {
 	}
 }
 
+// drainPipeline waits for the signings in progress to complete, no new signing is spawned
+// once the signer is draining
+func (s *Signer) drainPipeline() {
+	defer close(s.drainedChan)
+	if s.pipeline == nil {
+		return
+	}
+	for s.pipeline.running() {
+		select {
+		case <-s.stopChan:
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Drain stops the signer taking new transactions out of the storage and waits until the
+// signings in progress are complete or the context is done. The transactions left in the
+// storage are signed after the restart, a signed transaction is saved before it is
+// broadcast so it is only broadcast again.
+func (s *Signer) Drain(ctx context.Context) error {
+	s.drainOnce.Do(func() {
+		s.logger.Info().Msg("draining signer")
+		close(s.drainChan)
+	})
+	select {
+	case <-s.drainedChan:
+		s.logger.Info().Msg("signer drained")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("signings still in progress: %w", ctx.Err())
+	}
+}
+
+func (s *Signer) isDraining() bool {
+	select {
+	case <-s.drainChan:
+		return true
+	default:
+		return false
+	}
+}
+
 func runWithContext(ctx context.Context, fn func() ([]byte, *types.TxInItem, error)) ([]byte, *types.TxInItem, error) {
 	ch := make(chan error, 1)
 	var checkpoint []byte
@@ -254,20 +345,29 @@ func (s *Signer) processTransactions() {
 	}
 
 	// if previously set to different concurrency, drain existing signings
+	// the operator actions take the vault/chain locks of the pipeline, it is only replaced
+	// once they are released
 	if s.pipeline != nil && s.pipeline.concurrency != signerConcurrency {
+		s.pipelineMu.Lock()
 		s.pipeline.Wait()
 		s.pipeline = nil
+		s.pipelineMu.Unlock()
 	}
 
 	// if not set, or set to different concurrency, create new pipeline
 	if s.pipeline == nil {
+		s.pipelineMu.Lock()
 		s.pipeline, err = newPipeline(signerConcurrency)
+		s.pipelineMu.Unlock()
 		if err != nil {
 			s.logger.Error().Err(err).Msg("fail to create new pipeline")
 			return
 		}
 	}
 
+	// merge the outbounds of a vault paid in one transaction before they are spawned
+	s.batchOutbounds()
+
 	// process transactions
 	s.pipeline.SpawnSignings(s, s.mapBridge)
 }
@@ -290,6 +390,10 @@ func (s *Signer) processTxnOut(ch <-chan types.TxOut) {
 			items := make([]TxOutStoreItem, 0, len(txOut.TxArray))
 
 			for i, tx := range txOut.TxArray {
+				if tx.OrderId != (ecommon.Hash{}) {
+					tracing.Finish(tx.OrderId.Hex(), tracing.StageMapScan, nil, "method", tx.Method,
+						"map_hash", tx.TxHash, "map_height", strconv.FormatInt(txOut.Height, 10))
+				}
 				_type := cross.TypeOfRelaySignedChain
 				switch tx.Method {
 				case constants.Completed:
@@ -298,7 +402,9 @@ func (s *Signer) processTxnOut(ch <-chan types.TxOut) {
 					_type = cross.TypeOfRelayChain
 					fallthrough
 				default:
-					items = append(items, NewTxOutStoreItem(txOut.Height, tx.TxOutItem(txOut.Height), int64(i)))
+					item := NewTxOutStoreItem(txOut.Height, tx.TxOutItem(txOut.Height), int64(i))
+					item.QueuedAt = time.Now().Unix()
+					items = append(items, item)
 					if strings.EqualFold(tx.Topics, constants.EventOfBridgeRelay.GetTopic().String()) {
 						_type = cross.TypeOfRelayChain
 					}
@@ -356,7 +462,9 @@ func (s *Signer) cacheOracle(ch <-chan types.TxOut) {
 			items := make([]TxOutStoreItem, 0, len(txOut.TxArray))
 
 			for i, tx := range txOut.TxArray {
-				items = append(items, NewTxOutStoreItem(txOut.Height, tx.TxOutItem(txOut.Height), int64(i)))
+				item := NewTxOutStoreItem(txOut.Height, tx.TxOutItem(txOut.Height), int64(i))
+				item.QueuedAt = time.Now().Unix()
+				items = append(items, item)
 			}
 			if err := s.oracleStorage.Batch(items); err != nil {
 				s.logger.Error().Err(err).Msg("fail to save tx out items to storage")
@@ -382,41 +490,19 @@ func (s *Signer) processOracle() {
 			return
 		case <-time.After(time.Second * 5):
 			list := s.oracleStorage.List() // this will trigger the storage to load all items
+			s.updateOracleMetrics(list)
+			now := time.Now()
 			for _, item := range list {
-				tmp := item
-				txBytes, err := s.mapBridge.GetOracleStdTx(&item.TxOutItem)
-				if err != nil {
-					if errors.Is(err, constants.ErrorOfOrderExecuted) {
-						s.oracleStorage.Remove(tmp)
-						s.logger.Error().Str("txHash", item.TxOutItem.TxHash).Err(err).Msg("ignore oracle tx")
-						continue
-					}
-					s.logger.Error().Str("txHash", item.TxOutItem.TxHash).Err(err).Msg("fail to get oracle std tx")
-					continue
-				}
-
-				if len(txBytes) == 0 {
+				// wait out the backoff of the previous failure
+				if item.NextAttempt > now.Unix() {
 					continue
 				}
-
-				bf := backoff.NewExponentialBackOff()
-				bf.MaxElapsedTime = 5 * time.Second
-				err = backoff.Retry(func() error {
-					txID, err := s.mapBridge.Broadcast(txBytes)
-					if err != nil {
-						return fmt.Errorf("fail to send the tx to relay: %w", err)
-					}
-					s.oracleStorage.Remove(tmp)
-					s.logger.Info().Str("txHash", tmp.TxOutItem.TxHash).Str("relayHash", txID).Msg("oracle tx sent successfully")
-					return nil
-				}, bf)
-				if err != nil {
-					s.logger.Error().Err(err).Msg("fail to broadcast tx")
+				if err := s.submitOracle(item); err != nil {
+					s.oracleFailed(item, err, now)
 					continue
 				}
-
-				s.logger.Info().Str("txHash", tmp.TxOutItem.TxHash).
-					Str("orderId", tmp.TxOutItem.OrderId.Hex()).Msg("processing oracle item")
+				s.logger.Info().Str("txHash", item.TxOutItem.TxHash).
+					Str("orderId", item.TxOutItem.OrderId.Hex()).Msg("processing oracle item")
 			}
 		}
 	}
@@ -443,6 +529,9 @@ func (s *Signer) processKeygenBlock(keygenBlock *structure.KeyGen) {
 	if !blame.IsEmpty() {
 		s.logger.Error().Str("reason", blame.FailReason).
 			Interface("nodes", blame.BlameNodes).Msg("keygen blame")
+		record := tss.NewBlameRecord(tss.BlameKeygen, blame)
+		record.Epoch = keygenBlock.Epoch.Int64()
+		s.recordBlame(record)
 	}
 	keygenTime := time.Since(keygenStart).Milliseconds()
 	if err != nil {
@@ -563,6 +652,19 @@ func (s *Signer) sendKeygenToMap(epoch *big.Int, poolPubKey common.PubKey, blame
 // that should be submitted to relay.
 func (s *Signer) signAndBroadcast(item TxOutStoreItem) ([]byte, *types.TxInItem, error) {
 	height := item.Height
+
+	// drop the orders of a batch executed meanwhile before it is signed
+	if len(item.Batch) > 0 && len(item.SignedTx) == 0 {
+		batched, ok, err := s.dropExecutedOutbounds(item)
+		if err != nil {
+			s.logger.Error().Str("relayHash", item.TxOutItem.TxHash).Err(err).Msgf("fail to check batch executed")
+			return nil, nil, err
+		}
+		if !ok {
+			return nil, nil, constants.ErrorOfOrderExecuted
+		}
+		item = batched
+	}
 	tx := item.TxOutItem
 
 	// set the checkpoint on the tx out item if it was stored
@@ -653,6 +755,13 @@ func (s *Signer) signAndBroadcast(item TxOutStoreItem) ([]byte, *types.TxInItem,
 		return nil, nil, nil
 	}
 
+	// hold the outbounds breaching the local limits until an operator releases them
+	if len(item.SignedTx) == 0 {
+		if err = s.limiter.Check(chain.GetChain(), tx.VaultPubKey.String(), batchTxOuts(item, tx)); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if !chain.IsBlockScannerHealthy() {
 		return nil, nil, fmt.Errorf("the block scanner for chain %s is unhealthy, not signing transactions due to it", chain.GetChain())
 	}
@@ -679,9 +788,34 @@ func (s *Signer) signAndBroadcast(item TxOutStoreItem) ([]byte, *types.TxInItem,
 		s.logger.Info().Str("relayHash", item.TxOutItem.TxHash).Str("memo", tx.Memo).Msg("retrying broadcast of already signed tx")
 		signedTx = item.SignedTx
 		observation = item.Observation
+	} else if utxoClient, ok := chain.(*utxo.Client); ok && len(item.Batch) > 0 {
+		startKeySign := time.Now()
+		orderIDs := outboundOrderIDs(item, tx)
+		traceBegin(orderIDs, tracing.StageKeysign, "chain", tx.Chain.String(), "vault", tx.VaultPubKey.String())
+		var signed []types.TxOutItem
+		signedTx, checkpoint, signed, err = utxoClient.SignBatchTx(batchTxOuts(item, tx), height)
+		traceFinishKeysign(orderIDs, err)
+		if errors.Is(err, constants.ErrorOfNotSubmitter) {
+			return checkpoint, nil, err
+		}
+		if err != nil {
+			s.logger.Error().Str("relayHash", item.TxOutItem.TxHash).Err(err).Msg("fail to sign batch tx")
+			return checkpoint, nil, err
+		}
+		item = keepSignedOutbounds(item, signed)
+		vaultPubKey := tx.VaultPubKey
+		tx = item.TxOutItem
+		tx.VaultPubKey = vaultPubKey
+		elapse = time.Since(startKeySign)
 	} else {
 		startKeySign := time.Now()
+		orderIDs := outboundOrderIDs(item, tx)
+		traceBegin(orderIDs, tracing.StageKeysign, "chain", tx.Chain.String(), "vault", tx.VaultPubKey.String())
 		signedTx, checkpoint, observation, err = chain.SignTx(tx, height)
+		traceFinishKeysign(orderIDs, err)
+		if errors.Is(err, constants.ErrorOfNotSubmitter) {
+			return checkpoint, nil, err
+		}
 		if err != nil {
 			s.logger.Error().Str("relayHash", item.TxOutItem.TxHash).Err(err).Msg("fail to sign tx")
 			return checkpoint, nil, err
@@ -694,9 +828,18 @@ func (s *Signer) signAndBroadcast(item TxOutStoreItem) ([]byte, *types.TxInItem,
 		s.logger.Warn().Str("relayHash", item.TxOutItem.TxHash).Msgf("signed transaction is empty")
 		return nil, nil, nil
 	}
+	if len(item.SignedTx) == 0 {
+		s.limiter.Record(chain.GetChain(), tx.VaultPubKey.String(), batchTxOuts(item, tx))
+		// the broadcast may be cut short by a shutdown or a crash, it is retried with the same tx
+		item.SignedTx = signedTx
+		item.Observation = observation
+		if storeErr := s.storage.Set(item); storeErr != nil {
+			s.logger.Error().Str("relayHash", item.TxOutItem.TxHash).Err(storeErr).Msg("fail to checkpoint signed tx")
+		}
+	}
 
 	// broadcast the transaction
-	hash, err := chain.BroadcastTx(tx, signedTx)
+	hash, err := s.broadcast(chain, item, tx, signedTx)
 	if err != nil {
 		s.logger.Error().Str("relayHash", item.TxOutItem.TxHash).Err(err).Str("memo", tx.Memo).Msg("fail to broadcast tx to chain")
 
@@ -713,16 +856,88 @@ func (s *Signer) signAndBroadcast(item TxOutStoreItem) ([]byte, *types.TxInItem,
 		Str("txId", hash).Str("memo", tx.Memo).Msg("broadcasted tx to chain")
 
 	s.tssKeysignMetricMgr.SetTssKeysignMetric(hash, elapse.Milliseconds())
+	s.recordBroadcast(item, tx, hash)
+
+	return nil, observation, nil
+}
+
+// broadcast sends the signed transaction of the item to its chain, the transaction of a
+// batch pays all of its outbounds
+func (s *Signer) broadcast(chain chainclients.ChainClient, item TxOutStoreItem, tx types.TxOutItem, signedTx []byte) (string, error) {
+	orderIDs := outboundOrderIDs(item, tx)
+	traceBegin(orderIDs, tracing.StageBroadcast, "chain", tx.Chain.String())
+	var hash string
+	var err error
+	if utxoClient, ok := chain.(*utxo.Client); ok && len(item.Batch) > 0 {
+		hash, err = utxoClient.BroadcastBatchTx(batchTxOuts(item, tx), signedTx)
+	} else {
+		hash, err = chain.BroadcastTx(tx, signedTx)
+	}
+	traceFinish(orderIDs, tracing.StageBroadcast, err, "hash", hash)
+	return hash, err
+}
 
-	// add in cross-chain storage
+// recordBroadcast adds the outbounds paid by the broadcast transaction to the cross-chain
+// storage
+func (s *Signer) recordBroadcast(item TxOutStoreItem, tx types.TxOutItem, hash string) {
+	if len(item.Batch) > 0 {
+		txs := batchTxOuts(item, tx)
+		id := batchID(txs)
+		for i, txOut := range txs {
+			s.crossStorage.AddOrUpdateTx(&cross.CrossData{
+				Chain:     txOut.ToChain.String(),
+				TxHash:    hash,
+				OrderId:   txOut.OrderId.Hex(),
+				Timestamp: time.Now().Unix(),
+				BatchId:   id,
+				Vout:      uint32(i),
+			}, cross.TypeOfSendDst)
+		}
+		return
+	}
 	s.crossStorage.AddOrUpdateTx(&cross.CrossData{
 		Chain:     item.TxOutItem.ToChain.String(),
 		TxHash:    hash,
 		OrderId:   item.TxOutItem.OrderId.Hex(),
 		Timestamp: time.Now().Unix(),
 	}, cross.TypeOfSendDst)
+}
 
-	return nil, observation, nil
+// outboundOrderIDs returns the ids of the orders paid by the outbound of the item
+func outboundOrderIDs(item TxOutStoreItem, tx types.TxOutItem) []string {
+	txs := []types.TxOutItem{tx}
+	if len(item.Batch) > 0 {
+		txs = batchTxOuts(item, tx)
+	}
+	orderIDs := make([]string, 0, len(txs))
+	for _, txOut := range txs {
+		if txOut.OrderId != (ecommon.Hash{}) {
+			orderIDs = append(orderIDs, txOut.OrderId.Hex())
+		}
+	}
+	return orderIDs
+}
+
+func traceBegin(orderIDs []string, stage string, attrs ...string) {
+	for _, orderID := range orderIDs {
+		tracing.Begin(orderID, stage, attrs...)
+	}
+}
+
+func traceFinish(orderIDs []string, stage string, err error, attrs ...string) {
+	for _, orderID := range orderIDs {
+		tracing.Finish(orderID, stage, err, attrs...)
+	}
+}
+
+// traceFinishKeysign finishes the keysign of the orders, a node which is not the
+// submitter took part in the keysign all the same
+func traceFinishKeysign(orderIDs []string, err error) {
+	if errors.Is(err, constants.ErrorOfNotSubmitter) {
+		traceFinish(orderIDs, tracing.StageKeysign, nil, "submitter", "false")
+		return
+	}
+	traceFinish(orderIDs, tracing.StageKeysign, err)
 }
 
 // Stop the signer process
@@ -747,12 +962,100 @@ func (s *Signer) isStopped() bool {
 	case <-s.stopChan:
 		return true
 	default:
-		return false
+		return s.isDraining()
 	}
 }
 
+// storageList returns the items ready to be signed, the outbounds waiting for their
+// batch are left out so no node signs them before the batch is built.
 func (s *Signer) storageList() []TxOutStoreItem {
-	return s.storage.List()
+	items := s.storage.List()
+	result := items[:0]
+	for _, item := range items {
+		// skip the chains paused locally by the operator
+		if chain, ok := common.GetChainName(item.TxOutItem.Chain); ok && blockscanner.IsChainPausedLocally(chain) {
+			continue
+		}
+		if _, ok := s.batchClient(item); ok {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+////////////////////////////////////////////////////////////////////////////////////////
+// Operator actions
+////////////////////////////////////////////////////////////////////////////////////////
+
+func (s *Signer) getStorage(oracle bool) SignerStorage {
+	if oracle {
+		return s.oracleStorage
+	}
+	return s.storage
+}
+
+// RequeueStoreItem clears the retry state of the item with the given key, so it is picked
+// up again by the next signing round. The checkpoint is kept to avoid a double spend.
+func (s *Signer) RequeueStoreItem(oracle bool, key string) (TxOutStoreItem, error) {
+	storage := s.getStorage(oracle)
+	if !storage.Has(key) {
+		return TxOutStoreItem{}, fmt.Errorf("item %s not found", key)
+	}
+	item, err := storage.Get(key)
+	if err != nil {
+		return item, fmt.Errorf("fail to get item %s: %w", key, err)
+	}
+	item.Status = TxAvailable
+	item.Round7Retry = false
+	item.Attempts = 0
+	item.NextAttempt = 0
+	if err = storage.Set(item); err != nil {
+		return item, fmt.Errorf("fail to update item %s: %w", key, err)
+	}
+	return item, nil
+}
+
+// DropStoreItem removes the item with the given key from the store
+func (s *Signer) DropStoreItem(oracle bool, key string) (TxOutStoreItem, error) {
+	storage := s.getStorage(oracle)
+	if !storage.Has(key) {
+		return TxOutStoreItem{}, fmt.Errorf("item %s not found", key)
+	}
+	item, err := storage.Get(key)
+	if err != nil {
+		return item, fmt.Errorf("fail to get item %s: %w", key, err)
+	}
+	if err = storage.Remove(item); err != nil {
+		return item, fmt.Errorf("fail to remove item %s: %w", key, err)
+	}
+	return item, nil
+}
+
+// ListDeadLetters returns the oracle items that ran out of attempts
+func (s *Signer) ListDeadLetters() []TxOutStoreItem {
+	return s.oracleStorage.ListDeadLetters()
+}
+
+// RequeueDeadLetter moves the oracle item with the given key back into the queue, its
+// attempts are cleared
+func (s *Signer) RequeueDeadLetter(key string) (TxOutStoreItem, error) {
+	return s.oracleStorage.RequeueDeadLetter(key)
+}
+
+// DropDeadLetter removes the oracle item with the given key from the dead letters
+func (s *Signer) DropDeadLetter(key string) (TxOutStoreItem, error) {
+	return s.oracleStorage.DropDeadLetter(key)
+}
+
+// BackupKeyShares encrypts the local key shares with the signer seed phrase into the given
+// folder, it returns the paths of the backups
+func (s *Signer) BackupKeyShares(folder string) ([]string, error) {
+	passphrase := os.Getenv("SIGNER_SEED_PHRASE")
+	if passphrase == "" {
+		return nil, errors.New("SIGNER_SEED_PHRASE is not set, key shares are not backed up")
+	}
+	return tss.BackupKeyShares(constants.DefaultHome, folder, passphrase)
 }
 
 func (s *Signer) processTransaction(item TxOutStoreItem) {
@@ -765,6 +1068,18 @@ func (s *Signer) processTransaction(item TxOutStoreItem) {
 		// will next 400
 		return s.signAndBroadcast(item)
 	})
+	if errors.Is(err, constants.ErrorOfNotSubmitter) {
+		// keep the item, it is retried in case the elected node doesn't submit it
+		s.logger.Debug().Str("relayHash", item.TxOutItem.TxHash).Msg("not the elected submitter, wait for takeover")
+		cancel()
+		return
+	}
+	if errors.Is(err, ErrSigningHeld) {
+		// keep the item until an operator releases the breaker
+		s.logger.Warn().Str("relayHash", item.TxOutItem.TxHash).Err(err).Msg("outbound held by outbound limits")
+		cancel()
+		return
+	}
 	if err != nil {
 		for e := range constants.ToMapIgnoreError {
 			if strings.Contains(err.Error(), e) {
@@ -776,7 +1091,18 @@ func (s *Signer) processTransaction(item TxOutStoreItem) {
 
 		// mark the txout on round 7 failure to block other txs for the chain / pubkey
 		ksErr := tss.KeysignError{}
-		if errors.As(err, &ksErr) && ksErr.IsRound7() {
+		isKeysignErr := errors.As(err, &ksErr)
+		if isKeysignErr {
+			record := tss.NewBlameRecord(tss.BlameKeysign, ksErr.Blame)
+			record.Vault = item.TxOutItem.VaultPubKey.String()
+			if chainName, ok := common.GetChainName(item.TxOutItem.Chain); ok {
+				record.Chain = chainName.String()
+			}
+			record.OrderId = item.TxOutItem.OrderId.Hex()
+			record.Height = item.Height
+			s.recordBlame(record)
+		}
+		if isKeysignErr && ksErr.IsRound7() {
 			s.logger.Error().Err(err).Interface("tx", item.TxOutItem).Msg("round 7 signing error")
 			item.Round7Retry = true
 			item.Checkpoint = checkpoint
@@ -1 +1,44 @@
 package signer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+
+	"github.com/mapprotocol/compass-tss/constants"
+)
+
+type SignSuite struct{}
+
+var _ = Suite(&SignSuite{})
+
+func (s *SignSuite) TestDrain(c *C) {
+	p, err := newPipeline(2)
+	c.Assert(err, IsNil)
+	// a signing in progress
+	p.vaultStatusConcurrency[constants.VaultStatus_ActiveVault] <- struct{}{}
+	sign := &Signer{
+		wg:          &sync.WaitGroup{},
+		stopChan:    make(chan struct{}),
+		drainChan:   make(chan struct{}),
+		drainedChan: make(chan struct{}),
+		pipeline:    p,
+	}
+	c.Check(sign.isStopped(), Equals, false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	sign.wg.Add(1)
+	go sign.signTransactions()
+	c.Check(sign.Drain(ctx), NotNil)
+	c.Check(sign.isStopped(), Equals, true)
+
+	// the signer is drained once the signing completes
+	p.vaultStatusConcurrency[constants.VaultStatus_ActiveVault].release(1)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c.Check(sign.Drain(ctx), IsNil)
+	sign.wg.Wait()
+}
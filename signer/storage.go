@@ -4,8 +4,10 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -21,6 +23,8 @@ import (
 const (
 	DefaultSignerLevelDBFolder = "signer_data"
 	txOutPrefix                = "txout-v4-"
+	deadLetterPrefix           = "deadletter-"
+	archivePrefix              = "archive-"
 )
 
 type TxStatus int
@@ -41,7 +45,13 @@ type TxOutStoreItem struct {
 	Checkpoint   []byte
 	SignedTx     []byte
 	Observation  *types.TxInItem
-	RetrievalKey string `json:"-"`
+	Batch        []TxOutStoreItem // outbounds of the vault paid along with TxOutItem in one transaction
+	Batched      bool             // set once the batch of the outbound was built, also when it is paid on its own
+	Attempts     int              // failed oracle submissions
+	NextAttempt  int64            // unix time the oracle submission is retried from
+	LastError    string           // error of the last failed oracle submission
+	QueuedAt     int64            // unix time the item was queued
+	RetrievalKey string           `json:"-"`
 	// RetrievalKey is to ensure consistent KV overwrite/deletion after iterator retrieval;
 	// the json "-" tag is to not store it in the KVStore.
 }
@@ -78,6 +88,13 @@ func (s *TxOutStoreItem) Key() string {
 type SignerStorage interface {
 	Set(item TxOutStoreItem) error
 	Batch(items []TxOutStoreItem) error
+	Merge(item TxOutStoreItem, merged []TxOutStoreItem) error
+	DeadLetter(item TxOutStoreItem) error
+	ListDeadLetters() []TxOutStoreItem
+	RequeueDeadLetter(key string) (TxOutStoreItem, error)
+	DropDeadLetter(key string) (TxOutStoreItem, error)
+	Archive(item TxOutStoreItem) error
+	ListArchived() []TxOutStoreItem
 	Get(key string) (TxOutStoreItem, error)
 	Has(key string) bool
 	Remove(item TxOutStoreItem) error
@@ -139,6 +156,21 @@ func (s *SignerStore) Batch(items []TxOutStoreItem) error {
 	return s.db.Write(batch, nil)
 }
 
+// Merge stores the item and removes the items merged into it in one write
+func (s *SignerStore) Merge(item TxOutStoreItem, merged []TxOutStoreItem) error {
+	buf, err := json.Marshal(item)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to marshal to txout store item")
+		return err
+	}
+	batch := new(leveldb.Batch)
+	for _, m := range merged {
+		batch.Delete([]byte(m.Key()))
+	}
+	batch.Put([]byte(item.Key()), buf)
+	return s.db.Write(batch, nil)
+}
+
 func (s *SignerStore) Get(keyString string) (item TxOutStoreItem, err error) {
 	key := []byte(keyString)
 
@@ -216,6 +248,118 @@ func (s *SignerStore) OrderedLists() map[string][]TxOutStoreItem {
 	return lists
 }
 
+// DeadLetter moves the item out of the queue into the dead letters in one write, it keeps
+// its key so it can be requeued
+func (s *SignerStore) DeadLetter(item TxOutStoreItem) error {
+	buf, err := json.Marshal(item)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to marshal to txout store item")
+		return err
+	}
+	key := item.Key()
+	batch := new(leveldb.Batch)
+	batch.Delete([]byte(key))
+	batch.Put([]byte(deadLetterPrefix+key), buf)
+	return s.db.Write(batch, nil)
+}
+
+// ListDeadLetters returns the dead letters, the retrieval key of each is the key it is
+// requeued under
+func (s *SignerStore) ListDeadLetters() []TxOutStoreItem {
+	iterator := s.db.NewIterator(util.BytesPrefix([]byte(deadLetterPrefix)), nil)
+	defer iterator.Release()
+	results := make([]TxOutStoreItem, 0)
+	for iterator.Next() {
+		var item TxOutStoreItem
+		if err := json.Unmarshal(iterator.Value(), &item); err != nil {
+			s.logger.Error().Err(err).Msg("fail to unmarshal to txout store item")
+			continue
+		}
+		item.RetrievalKey = strings.TrimPrefix(string(iterator.Key()), deadLetterPrefix)
+		results = append(results, item)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Height < results[j].Height })
+	return results
+}
+
+// getDeadLetter returns the dead letter requeued under the given key
+func (s *SignerStore) getDeadLetter(key string) (TxOutStoreItem, error) {
+	var item TxOutStoreItem
+	buf, err := s.db.Get([]byte(deadLetterPrefix+key), nil)
+	if err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return item, fmt.Errorf("dead letter %s not found", key)
+		}
+		return item, fmt.Errorf("fail to get dead letter %s: %w", key, err)
+	}
+	if err = json.Unmarshal(buf, &item); err != nil {
+		return item, fmt.Errorf("fail to unmarshal dead letter %s: %w", key, err)
+	}
+	item.RetrievalKey = key
+	return item, nil
+}
+
+// RequeueDeadLetter moves the dead letter back into the queue with its attempts cleared
+func (s *SignerStore) RequeueDeadLetter(key string) (TxOutStoreItem, error) {
+	item, err := s.getDeadLetter(key)
+	if err != nil {
+		return item, err
+	}
+	item.Status = TxAvailable
+	item.Attempts = 0
+	item.NextAttempt = 0
+	buf, err := json.Marshal(item)
+	if err != nil {
+		return item, fmt.Errorf("fail to marshal dead letter %s: %w", key, err)
+	}
+	batch := new(leveldb.Batch)
+	batch.Delete([]byte(deadLetterPrefix + key))
+	batch.Put([]byte(key), buf)
+	return item, s.db.Write(batch, nil)
+}
+
+// DropDeadLetter removes the dead letter
+func (s *SignerStore) DropDeadLetter(key string) (TxOutStoreItem, error) {
+	item, err := s.getDeadLetter(key)
+	if err != nil {
+		return item, err
+	}
+	return item, s.db.Delete([]byte(deadLetterPrefix+key), nil)
+}
+
+// Archive moves the item out of the queue in one write, it is kept for inspection only
+func (s *SignerStore) Archive(item TxOutStoreItem) error {
+	buf, err := json.Marshal(item)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("fail to marshal to txout store item")
+		return err
+	}
+	key := item.Key()
+	batch := new(leveldb.Batch)
+	batch.Delete([]byte(key))
+	batch.Put([]byte(archivePrefix+key), buf)
+	return s.db.Write(batch, nil)
+}
+
+// ListArchived returns the archived items, the retrieval key of each is the key it was
+// queued under
+func (s *SignerStore) ListArchived() []TxOutStoreItem {
+	iterator := s.db.NewIterator(util.BytesPrefix([]byte(archivePrefix)), nil)
+	defer iterator.Release()
+	results := make([]TxOutStoreItem, 0)
+	for iterator.Next() {
+		var item TxOutStoreItem
+		if err := json.Unmarshal(iterator.Value(), &item); err != nil {
+			s.logger.Error().Err(err).Msg("fail to unmarshal to txout store item")
+			continue
+		}
+		item.RetrievalKey = strings.TrimPrefix(string(iterator.Key()), archivePrefix)
+		results = append(results, item)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Height < results[j].Height })
+	return results
+}
+
 // Close underlying db
 func (s *SignerStore) Close() error {
 	return s.db.Close()
@@ -54,16 +54,82 @@ func (s *StorageSuite) TestStorage(c *C) {
 	items = store.List()
 	c.Assert(items, HasLen, 4)
 	c.Check(items[0].TxOutItem.Memo, Equals, "boo")
-	c.Check(items[1].TxOutItem.Memo, Equals, "bar", Commentf("%s", items[1].TxOutItem.Memo))
-	c.Check(items[2].TxOutItem.Memo, Equals, "foo", Commentf("%s", items[2].TxOutItem.Memo))
+	// items of the same height are listed in the order of their hash
+	c.Check(items[1].Height, Equals, int64(12))
+	c.Check(items[2].Height, Equals, int64(12))
+	c.Check(items[1].TxOutItem.Hash() < items[2].TxOutItem.Hash(), Equals, true, Commentf("%s", items[1].TxOutItem.Memo))
 	c.Check(items[3].TxOutItem.Memo, Equals, "baz")
 
 	ordered := store.OrderedLists()
 	c.Assert(ordered, HasLen, 2, Commentf("%+v", ordered))
-	c.Check(ordered[fmt.Sprintf("BTC-%s", pk.String())][0].TxOutItem.Memo, Equals, "boo")
-	c.Check(ordered[fmt.Sprintf("BTC-%s", pk.String())][1].TxOutItem.Memo, Equals, "foo", Commentf("%s", items[1].TxOutItem.Memo))
-	c.Check(ordered[fmt.Sprintf("ETH-%s", pk.String())][0].TxOutItem.Memo, Equals, "bar", Commentf("%s", items[2].TxOutItem.Memo))
-	c.Check(ordered[fmt.Sprintf("ETH-%s", pk.String())][1].TxOutItem.Memo, Equals, "baz")
+	c.Check(ordered[fmt.Sprintf("%s-%s", btcId, pk.String())][0].TxOutItem.Memo, Equals, "boo")
+	c.Check(ordered[fmt.Sprintf("%s-%s", btcId, pk.String())][1].TxOutItem.Memo, Equals, "foo")
+	c.Check(ordered[fmt.Sprintf("%s-%s", ethId, pk.String())][0].TxOutItem.Memo, Equals, "bar")
+	c.Check(ordered[fmt.Sprintf("%s-%s", ethId, pk.String())][1].TxOutItem.Memo, Equals, "baz")
+
+	c.Check(store.Close(), IsNil)
+}
+
+func (s *StorageSuite) TestMerge(c *C) {
+	store, err := NewSignerStore("", config.LevelDBOptions{})
+	c.Assert(err, IsNil)
+
+	items := []TxOutStoreItem{
+		NewTxOutStoreItem(10, types.TxOutItem{Memo: "foo"}, 0),
+		NewTxOutStoreItem(11, types.TxOutItem{Memo: "bar"}, 1),
+		NewTxOutStoreItem(12, types.TxOutItem{Memo: "baz"}, 2),
+	}
+	c.Assert(store.Batch(items), IsNil)
+	items = store.List()
+	c.Assert(items, HasLen, 3)
+
+	lead := items[0]
+	lead.Batch = items[1:]
+	c.Assert(store.Merge(lead, lead.Batch), IsNil)
+	items = store.List()
+	c.Assert(items, HasLen, 1)
+	c.Check(items[0].Key(), Equals, lead.Key())
+	c.Assert(items[0].Batch, HasLen, 2)
+	c.Check(items[0].Batch[0].TxOutItem.Memo, Equals, "bar")
+	c.Check(items[0].Batch[1].TxOutItem.Memo, Equals, "baz")
+
+	c.Check(store.Close(), IsNil)
+}
+
+func (s *StorageSuite) TestDeadLetter(c *C) {
+	store, err := NewSignerStore("", config.LevelDBOptions{})
+	c.Assert(err, IsNil)
+
+	items := []TxOutStoreItem{
+		NewTxOutStoreItem(10, types.TxOutItem{Memo: "foo"}, 0),
+		NewTxOutStoreItem(11, types.TxOutItem{Memo: "bar"}, 1),
+	}
+	c.Assert(store.Batch(items), IsNil)
+
+	item := items[0]
+	item.Attempts = 20
+	item.LastError = "fail to broadcast tx"
+	c.Assert(store.DeadLetter(item), IsNil)
+	c.Assert(store.List(), HasLen, 1)
+	deadLetters := store.ListDeadLetters()
+	c.Assert(deadLetters, HasLen, 1)
+	c.Check(deadLetters[0].Key(), Equals, item.Key())
+	c.Check(deadLetters[0].LastError, Equals, "fail to broadcast tx")
+
+	requeued, err := store.RequeueDeadLetter(item.Key())
+	c.Assert(err, IsNil)
+	c.Check(requeued.Attempts, Equals, 0)
+	c.Assert(store.ListDeadLetters(), HasLen, 0)
+	c.Assert(store.List(), HasLen, 2)
+	_, err = store.RequeueDeadLetter(item.Key())
+	c.Assert(err, NotNil)
+
+	c.Assert(store.DeadLetter(items[1]), IsNil)
+	dropped, err := store.DropDeadLetter(items[1].Key())
+	c.Assert(err, IsNil)
+	c.Check(dropped.TxOutItem.Memo, Equals, "bar")
+	c.Assert(store.ListDeadLetters(), HasLen, 0)
+	c.Assert(store.List(), HasLen, 1)
 
 	c.Check(store.Close(), IsNil)
 }
@@ -0,0 +1,235 @@
+package tss
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+
+	"github.com/mapprotocol/compass-tss/internal/keys"
+	"github.com/mapprotocol/compass-tss/x/types"
+)
+
+const (
+	// BlameKeysign is the type of the blame records of failed keysigns
+	BlameKeysign = "keysign"
+	// BlameKeygen is the type of the blame records of failed keygens
+	BlameKeygen = "keygen"
+
+	blameLedgerPrefix = "blame-"
+)
+
+// BlameRecord is a failed keysign or keygen and the peers blamed for it
+type BlameRecord struct {
+	Type     string    `json:"type"`
+	Round    string    `json:"round,omitempty"`
+	Reason   string    `json:"reason,omitempty"`
+	Culprits []string  `json:"culprits"`
+	Vault    string    `json:"vault,omitempty"`
+	Chain    string    `json:"chain,omitempty"`
+	OrderId  string    `json:"order_id,omitempty"`
+	Height   int64     `json:"height,omitempty"`
+	Epoch    int64     `json:"epoch,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// NewBlameRecord create a blame record of the given type from the blame of go-tss
+func NewBlameRecord(blameType string, blame types.Blame) BlameRecord {
+	culprits := make([]string, 0, len(blame.BlameNodes))
+	for _, node := range blame.BlameNodes {
+		culprits = append(culprits, node.Pubkey)
+	}
+	return BlameRecord{
+		Type:     blameType,
+		Round:    blame.Round,
+		Reason:   blame.FailReason,
+		Culprits: culprits,
+	}
+}
+
+// HasCulprit returns true when the peer was blamed in the record
+func (r BlameRecord) HasCulprit(pubkey string) bool {
+	for _, culprit := range r.Culprits {
+		if culprit == pubkey {
+			return true
+		}
+	}
+	return false
+}
+
+// PeerBlame is how often a peer was blamed within each of the queried windows
+type PeerBlame struct {
+	Pubkey     string    `json:"pubkey"`
+	Address    string    `json:"address,omitempty"`
+	Failures   []int     `json:"failures"`
+	LastType   string    `json:"last_type"`
+	LastRound  string    `json:"last_round,omitempty"`
+	LastReason string    `json:"last_reason,omitempty"`
+	LastTime   time.Time `json:"last_time"`
+}
+
+// BlameLedger keeps the blame records of the local node in leveldb, ordered by time, so a
+// maintainer failing over and over can be spotted
+type BlameLedger struct {
+	logger    zerolog.Logger
+	db        *leveldb.DB
+	retention time.Duration
+	culprits  *prometheus.CounterVec
+
+	lock sync.Mutex
+	seq  uint64
+}
+
+// NewBlameLedger create a new instance of BlameLedger, records older than the retention
+// are pruned unless it is zero. The culprits counter is optional.
+func NewBlameLedger(db *leveldb.DB, retention time.Duration, culprits *prometheus.CounterVec) *BlameLedger {
+	return &BlameLedger{
+		logger:    log.With().Str("module", "blame_ledger").Logger(),
+		db:        db,
+		retention: retention,
+		culprits:  culprits,
+	}
+}
+
+// blameKey sorts the records by time, the sequence keeps records of the same instant apart
+func blameKey(t time.Time, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d-%020d", blameLedgerPrefix, t.UnixNano(), seq))
+}
+
+// Record stores the blame record and counts a failure for each culprit
+func (l *BlameLedger) Record(record BlameRecord) error {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	if record.Culprits == nil {
+		record.Culprits = []string{}
+	}
+	buf, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("fail to marshal blame record: %w", err)
+	}
+
+	l.lock.Lock()
+	l.seq++
+	key := blameKey(record.Time, l.seq)
+	l.lock.Unlock()
+	if err = l.db.Put(key, buf, nil); err != nil {
+		return fmt.Errorf("fail to save blame record: %w", err)
+	}
+
+	if l.culprits != nil {
+		for _, culprit := range record.Culprits {
+			l.culprits.WithLabelValues(culprit, record.Type, record.Round).Inc()
+		}
+	}
+	if l.retention > 0 {
+		if err = l.Prune(record.Time.Add(-l.retention)); err != nil {
+			l.logger.Error().Err(err).Msg("fail to prune blame records")
+		}
+	}
+	return nil
+}
+
+// List returns the blame records since the given time, oldest first. Only the records
+// blaming the peer are returned when the pubkey is not empty.
+func (l *BlameLedger) List(since time.Time, pubkey string) ([]BlameRecord, error) {
+	prefix := util.BytesPrefix([]byte(blameLedgerPrefix))
+	iterator := l.db.NewIterator(&util.Range{Start: blameKey(since, 0), Limit: prefix.Limit}, nil)
+	defer iterator.Release()
+	records := make([]BlameRecord, 0)
+	for iterator.Next() {
+		var record BlameRecord
+		if err := json.Unmarshal(iterator.Value(), &record); err != nil {
+			l.logger.Error().Err(err).Str("key", string(iterator.Key())).Msg("fail to unmarshal blame record")
+			continue
+		}
+		if pubkey != "" && !record.HasCulprit(pubkey) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, iterator.Error()
+}
+
+// PeerFailures returns every peer blamed within the largest window up to now, with the
+// number of failures it was blamed for within each window in the given order. Peers
+// blamed the most within the largest window come first.
+func (l *BlameLedger) PeerFailures(windows []time.Duration, now time.Time) ([]PeerBlame, error) {
+	if len(windows) == 0 {
+		return []PeerBlame{}, nil
+	}
+	largest := 0
+	for i, window := range windows {
+		if window > windows[largest] {
+			largest = i
+		}
+	}
+	records, err := l.List(now.Add(-windows[largest]), "")
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make(map[string]*PeerBlame)
+	for _, record := range records {
+		for _, culprit := range record.Culprits {
+			peer, ok := peers[culprit]
+			if !ok {
+				peer = &PeerBlame{
+					Pubkey:   culprit,
+					Failures: make([]int, len(windows)),
+				}
+				if addr, err := keys.GetAddressByCompressPk(culprit); err == nil {
+					peer.Address = addr.String()
+				}
+				peers[culprit] = peer
+			}
+			for i, window := range windows {
+				if !record.Time.Before(now.Add(-window)) {
+					peer.Failures[i]++
+				}
+			}
+			// records are listed oldest first
+			peer.LastType = record.Type
+			peer.LastRound = record.Round
+			peer.LastReason = record.Reason
+			peer.LastTime = record.Time
+		}
+	}
+
+	result := make([]PeerBlame, 0, len(peers))
+	for _, peer := range peers {
+		result = append(result, *peer)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Failures[largest] != result[j].Failures[largest] {
+			return result[i].Failures[largest] > result[j].Failures[largest]
+		}
+		return result[i].Pubkey < result[j].Pubkey
+	})
+	return result, nil
+}
+
+// Prune deletes the blame records older than the given time
+func (l *BlameLedger) Prune(before time.Time) error {
+	prefix := util.BytesPrefix([]byte(blameLedgerPrefix))
+	iterator := l.db.NewIterator(&util.Range{Start: prefix.Start, Limit: blameKey(before, 0)}, nil)
+	defer iterator.Release()
+	batch := new(leveldb.Batch)
+	for iterator.Next() {
+		batch.Delete(iterator.Key())
+	}
+	if err := iterator.Error(); err != nil {
+		return fmt.Errorf("fail to iterate blame records: %w", err)
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+	return l.db.Write(batch, nil)
+}
@@ -0,0 +1,73 @@
+package tss
+
+import (
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	. "gopkg.in/check.v1"
+
+	"github.com/mapprotocol/compass-tss/x/types"
+)
+
+type BlameLedgerTestSuite struct{}
+
+var _ = Suite(&BlameLedgerTestSuite{})
+
+func (s *BlameLedgerTestSuite) TestBlameLedger(c *C) {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	c.Assert(err, IsNil)
+	defer db.Close()
+	ledger := NewBlameLedger(db, 48*time.Hour, nil)
+
+	now := time.Now()
+	record := NewBlameRecord(BlameKeysign, types.Blame{
+		FailReason: "fail to sign",
+		Round:      "SignRound7Message",
+		BlameNodes: []types.Node{{Pubkey: "peer-a"}, {Pubkey: "peer-b"}},
+	})
+	record.Chain = "BTC"
+	record.OrderId = "0x01"
+	record.Time = now.Add(-30 * time.Hour)
+	c.Assert(ledger.Record(record), IsNil)
+
+	record = NewBlameRecord(BlameKeygen, types.Blame{
+		FailReason: "fail to keygen",
+		BlameNodes: []types.Node{{Pubkey: "peer-b"}},
+	})
+	record.Epoch = 3
+	record.Time = now.Add(-time.Minute)
+	c.Assert(ledger.Record(record), IsNil)
+
+	// failures without culprits are recorded too
+	c.Assert(ledger.Record(BlameRecord{Type: BlameKeysign, Reason: "timeout", Time: now}), IsNil)
+
+	records, err := ledger.List(now.Add(-72*time.Hour), "")
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 3)
+	c.Assert(records[0].Chain, Equals, "BTC")
+	c.Assert(records[1].Epoch, Equals, int64(3))
+	c.Assert(records[2].Culprits, HasLen, 0)
+
+	records, err = ledger.List(now.Add(-time.Hour), "peer-b")
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Type, Equals, BlameKeygen)
+
+	peers, err := ledger.PeerFailures([]time.Duration{time.Hour, 24 * time.Hour, 168 * time.Hour}, now)
+	c.Assert(err, IsNil)
+	c.Assert(peers, HasLen, 2)
+	c.Assert(peers[0].Pubkey, Equals, "peer-b")
+	c.Assert(peers[0].Failures, DeepEquals, []int{1, 1, 2})
+	c.Assert(peers[0].LastType, Equals, BlameKeygen)
+	c.Assert(peers[1].Pubkey, Equals, "peer-a")
+	c.Assert(peers[1].Failures, DeepEquals, []int{0, 0, 1})
+	c.Assert(peers[1].LastRound, Equals, "SignRound7Message")
+
+	// records past the retention are pruned on the next record
+	c.Assert(ledger.Record(BlameRecord{Type: BlameKeysign, Time: now.Add(20 * time.Hour)}), IsNil)
+	records, err = ledger.List(time.Time{}, "")
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 3)
+	c.Assert(records[0].Epoch, Equals, int64(3))
+}
@@ -12,7 +12,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"filippo.io/age"
 	"github.com/cosmos/go-bip39"
@@ -209,6 +211,40 @@ func DecryptKeyShares(encrypted []byte, passphrase string) ([]byte, error) {
 	return io.ReadAll(dec)
 }
 
+// BackupKeyShares encrypts every keyshare file found in home with the passphrase and
+// writes the encrypted copies into folder, it returns the paths of the backups.
+func BackupKeyShares(home, folder, passphrase string) ([]string, error) {
+	if passphrase == "" {
+		return nil, errors.New("failed keyshare backup - passphrase is empty")
+	}
+	files, err := filepath.Glob(filepath.Join(home, "localstate-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed keyshare backup - cannot list keyshares: %w", err)
+	}
+	if len(files) == 0 {
+		return nil, errors.New("failed keyshare backup - no keyshares found")
+	}
+	if err = os.MkdirAll(folder, 0o700); err != nil {
+		return nil, fmt.Errorf("failed keyshare backup - cannot create folder: %w", err)
+	}
+
+	now := time.Now().Unix()
+	backups := make([]string, 0, len(files))
+	for _, file := range files {
+		encrypted, err := EncryptKeyShares(file, passphrase)
+		if err != nil {
+			return backups, err
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		path := filepath.Join(folder, fmt.Sprintf("%s-%d.enc", name, now))
+		if err = os.WriteFile(path, encrypted, 0o600); err != nil {
+			return backups, fmt.Errorf("failed keyshare backup - cannot write %s: %w", path, err)
+		}
+		backups = append(backups, path)
+	}
+	return backups, nil
+}
+
 // -------------------------------------------------------------------------------------
 
 // saltAndHash returns a salted SHA256 hash of the provided passphrase.
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"github.com/ethereum/go-ethereum/common"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/itchio/lzma"
@@ -36,6 +37,17 @@ func (s *EncryptKeySharesSuite) TestEncryptKeySharesEmptyPassphrase(c *C) {
 	c.Assert(ks, IsNil)
 }
 
+func (s *EncryptKeySharesSuite) TestBackupKeySharesEmptyPassphrase(c *C) {
+	home := c.MkDir()
+	c.Assert(os.WriteFile(filepath.Join(home, "localstate-1.json"), []byte("{}"), 0o600), IsNil)
+	folder := filepath.Join(c.MkDir(), "backups")
+	backups, err := BackupKeyShares(home, folder, "")
+	c.Assert(err, ErrorMatches, "failed keyshare backup - passphrase is empty")
+	c.Assert(backups, IsNil)
+	_, err = os.Stat(folder)
+	c.Assert(os.IsNotExist(err), Equals, true)
+}
+
 func (s *EncryptKeySharesSuite) TestEncryptKeySharesBadMnemonic(c *C) {
 	ks, err := EncryptKeyShares("", Mnemonic+" dog")
 	c.Assert(err, NotNil)
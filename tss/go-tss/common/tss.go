@@ -862,13 +862,16 @@ func (t *TssCommon) ProcessInboundMessages(finishChan chan struct{}, wg *sync.Wa
 			if !ok {
 				return
 			}
-			var wrappedMsg messages.WrappedMessage
-			if err := json.Unmarshal(m.Payload, &wrappedMsg); nil != err {
-				t.logger.Error().Err(err).Msg("fail to unmarshal wrapped message bytes")
-				continue
+			wrappedMsg := m.Wrapped
+			if wrappedMsg == nil {
+				wrappedMsg = &messages.WrappedMessage{}
+				if err := json.Unmarshal(m.Payload, wrappedMsg); nil != err {
+					t.logger.Error().Err(err).Msg("fail to unmarshal wrapped message bytes")
+					continue
+				}
 			}
 
-			err := t.ProcessOneMessage(&wrappedMsg, m.PeerID.String())
+			err := t.ProcessOneMessage(wrappedMsg, m.PeerID.String())
 			if err != nil {
 				t.logger.Error().Err(err).Msg("fail to process the received message")
 			}
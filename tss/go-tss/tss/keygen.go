@@ -18,6 +18,14 @@ func (t *TssServer) Keygen(req keygen.Request) (keygen.Response, error) {
 	if err != nil {
 		return keygen.Response{}, err
 	}
+	preParams, err := t.keygenPreParams()
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get keygen pre parameters")
+		return keygen.Response{
+			Status: common.Fail,
+			Blame:  blame.NewBlame(blame.InternalError, []blame.Node{}),
+		}, err
+	}
 
 	keygenInstance := keygen.NewTssKeyGen(
 		t.p2pCommunication.GetLocalPeerID(),
@@ -25,7 +33,7 @@ func (t *TssServer) Keygen(req keygen.Request) (keygen.Response, error) {
 		t.localNodePubKey,
 		t.p2pCommunication.BroadcastMsgChan,
 		t.stopChan,
-		t.preParams,
+		preParams,
 		msgID,
 		t.stateManager,
 		t.privateKey,
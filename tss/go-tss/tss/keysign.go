@@ -103,7 +103,7 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 			t.logger.Error().Err(err).Msgf("fail to form keysign party with online:%v", onlinePeers)
 			return keysign.Response{
 				Status: common.Fail,
-				Blame:  blameNodes,
+				Blame:  t.withoutLeavingNodes(blameNodes),
 			}, nil
 		}
 
@@ -121,7 +121,7 @@ func (t *TssServer) generateSignature(msgID string, msgsToSign [][]byte, req key
 		t.logger.Error().Err(errJoinParty).Msgf("messagesID(%s)fail to form keysign party with online:%v", msgID, onlinePeers)
 		return keysign.Response{
 			Status: common.Fail,
-			Blame:  blameLeader,
+			Blame:  t.withoutLeavingNodes(blameLeader),
 		}, nil
 
 	}
@@ -190,6 +190,9 @@ func (t *TssServer) KeySign(req keysign.Request) (keysign.Response, error) {
 		Str("msg", strings.Join(req.Messages, ",")).
 		Msg("received keysign request")
 	emptyResp := keysign.Response{}
+	if t.draining.Load() {
+		return emptyResp, ErrDraining
+	}
 	msgID, err := t.requestToMsgId(req)
 	if err != nil {
 		return emptyResp, err
@@ -1,11 +1,14 @@
 package tss
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
 	tcrypto "github.com/cometbft/cometbft/crypto"
@@ -15,6 +18,7 @@ import (
 	"github.com/mapprotocol/compass-tss/p2p/conversion"
 	"github.com/mapprotocol/compass-tss/p2p/messages"
 	"github.com/mapprotocol/compass-tss/p2p/storage"
+	"github.com/mapprotocol/compass-tss/tss/go-tss/blame"
 	"github.com/mapprotocol/compass-tss/tss/go-tss/common"
 	"github.com/mapprotocol/compass-tss/tss/go-tss/keygen"
 	"github.com/mapprotocol/compass-tss/tss/go-tss/keysign"
@@ -24,6 +28,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// ErrDraining is returned for the keysign requests received once the server is draining
+var ErrDraining = errors.New("tss server is draining, not joining new parties")
+
 // TssServer is the structure that can provide all keysign and key gen features
 type TssServer struct {
 	conf              common.TssConfig
@@ -31,6 +38,7 @@ type TssServer struct {
 	p2pCommunication  *p2p.Communication
 	localNodePubKey   string
 	preParams         *bkeygen.LocalPreParams
+	preParamsPool     PreParamsPool
 	tssKeyGenLocker   *sync.Mutex
 	stopChan          chan struct{}
 	joinPartyChan     chan struct{}
@@ -39,6 +47,7 @@ type TssServer struct {
 	signatureNotifier *keysign.SignatureNotifier
 	privateKey        tcrypto.PrivKey
 	tssMetrics        *monitor.Metric
+	draining          atomic.Bool
 }
 
 type PeerInfo struct {
@@ -46,6 +55,12 @@ type PeerInfo struct {
 	Address string
 }
 
+// PreParamsPool hands out freshly generated pre parameters, each keygen takes its own so
+// no two vaults share the same safe primes
+type PreParamsPool interface {
+	Take(timeout time.Duration) (*bkeygen.LocalPreParams, error)
+}
+
 // NewTss create a new instance of Tss
 func NewTss(
 	comm *p2p.Communication,
@@ -55,8 +70,6 @@ func NewTss(
 	preParams *bkeygen.LocalPreParams,
 ) (*TssServer, error) {
 	var err error
-	pk := priKey.PubKey().Bytes() // use this is compressed
-
 	// When using the keygen party it is recommended that you pre-compute the
 	// "safe primes" and Paillier secret beforehand because this can take some
 	// time.
@@ -71,7 +84,33 @@ func NewTss(
 	if !preParams.Validate() {
 		return nil, errors.New("invalid preparams")
 	}
+	return newTss(comm, stateManager, priKey, conf, preParams, nil), nil
+}
+
+// NewTssWithPreParamsPool create a new instance of Tss taking the pre parameters of
+// every keygen from the pool, nothing is generated at startup
+func NewTssWithPreParamsPool(
+	comm *p2p.Communication,
+	stateManager storage.LocalStateManager,
+	priKey tcrypto.PrivKey,
+	conf common.TssConfig,
+	pool PreParamsPool,
+) (*TssServer, error) {
+	if pool == nil {
+		return nil, errors.New("pre parameters pool is nil")
+	}
+	return newTss(comm, stateManager, priKey, conf, nil, pool), nil
+}
 
+func newTss(
+	comm *p2p.Communication,
+	stateManager storage.LocalStateManager,
+	priKey tcrypto.PrivKey,
+	conf common.TssConfig,
+	preParams *bkeygen.LocalPreParams,
+	pool PreParamsPool,
+) *TssServer {
+	pk := priKey.PubKey().Bytes() // use this is compressed
 	pc := p2p.NewPartyCoordinator(comm.GetHost(), conf.PartyTimeout)
 	sn := keysign.NewSignatureNotifier(comm.GetHost())
 	metrics := monitor.NewMetric()
@@ -84,6 +123,7 @@ func NewTss(
 		p2pCommunication:  comm,
 		localNodePubKey:   ecommon.Bytes2Hex(pk),
 		preParams:         preParams,
+		preParamsPool:     pool,
 		tssKeyGenLocker:   &sync.Mutex{},
 		stopChan:          make(chan struct{}),
 		partyCoordinator:  pc,
@@ -93,7 +133,20 @@ func NewTss(
 		tssMetrics:        metrics,
 	}
 
-	return &tssServer, nil
+	return &tssServer
+}
+
+// keygenPreParams returns the pre parameters of a new keygen, taken from the pool when
+// there is one
+func (t *TssServer) keygenPreParams() (*bkeygen.LocalPreParams, error) {
+	if t.preParamsPool == nil {
+		return t.preParams, nil
+	}
+	preParams, err := t.preParamsPool.Take(t.conf.PreParamTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("fail to take pre parameters from pool: %w", err)
+	}
+	return preParams, nil
 }
 
 // Start Tss server
@@ -114,6 +167,33 @@ func (t *TssServer) Stop() {
 	t.logger.Info().Msg("the tss and p2p server has been stopped successfully")
 }
 
+// Drain stops the server joining new keysign parties, the parties already joined go on.
+// The peers are told the node is leaving so they neither wait for it nor blame it, it
+// returns the number of peers told.
+func (t *TssServer) Drain(ctx context.Context) int {
+	t.draining.Store(true)
+	told := t.partyCoordinator.AnnounceLeaving(ctx)
+	t.logger.Info().Int("peers", told).Msg("draining, told the peers we are leaving")
+	return told
+}
+
+// withoutLeavingNodes drops the nodes which announced they are leaving from the blame.
+// Only the peers an announcement reached drop the node, a peer it did not reach keeps it,
+// so the nodes of a party may post different blame lists for the same keysign
+func (t *TssServer) withoutLeavingNodes(b blame.Blame) blame.Blame {
+	nodes := make([]blame.Node, 0, len(b.BlameNodes))
+	for _, node := range b.BlameNodes {
+		pID, err := conversion.GetPeerIDFromPubKeyByEth(node.Pubkey)
+		if err == nil && t.partyCoordinator.IsLeaving(pID) {
+			t.logger.Info().Str("pubkey", node.Pubkey).Msg("not blaming the leaving node")
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	b.BlameNodes = nodes
+	return b
+}
+
 func (t *TssServer) setJoinPartyChan(jpc chan struct{}) {
 	t.joinPartyChan = jpc
 }
@@ -129,6 +209,16 @@ func (t *TssServer) notifyJoinPartyChan() {
 }
 
 func (t *TssServer) requestToMsgId(request interface{}) (string, error) {
+	msgID, err := RequestMsgID(request)
+	if err != nil {
+		t.logger.Error().Err(err).Msg("fail to get the msg id of the request")
+	}
+	return msgID, err
+}
+
+// RequestMsgID returns the id of the messages a keygen or keysign request is exchanged
+// with, every node taking part in the request computes the same id
+func RequestMsgID(request interface{}) (string, error) {
 	var dat []byte
 	var keys []string
 	switch value := request.(type) {
@@ -139,7 +229,6 @@ func (t *TssServer) requestToMsgId(request interface{}) (string, error) {
 		dat = []byte(strings.Join(value.Messages, ","))
 		keys = value.SignerPubKeys
 	default:
-		t.logger.Error().Msg("unknown request type")
 		return "", errors.New("unknown request type")
 	}
 	keyAccumulation := ""
@@ -193,6 +282,15 @@ func (t *TssServer) GetLocalPeerID() string {
 	return t.p2pCommunication.GetLocalPeerID()
 }
 
+// GetPeerStatus asks the given peer for its version and scanner status over the p2p network
+func (t *TssServer) GetPeerStatus(ctx context.Context, peerID string) (*p2p.NodeStatus, time.Duration, error) {
+	pID, err := peer.Decode(peerID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("fail to decode peer id: %w", err)
+	}
+	return t.p2pCommunication.QueryNodeStatus(ctx, pID)
+}
+
 // GetKnownPeers return the the ID and IP address of all peers.
 func (t *TssServer) GetKnownPeers() []PeerInfo {
 	infos := []PeerInfo{}
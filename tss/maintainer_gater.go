@@ -0,0 +1,114 @@
+package tss
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mapprotocol/compass-tss/metrics"
+	"github.com/mapprotocol/compass-tss/p2p"
+	"github.com/mapprotocol/compass-tss/p2p/conversion"
+	sharedTypes "github.com/mapprotocol/compass-tss/pkg/chainclients/shared/types"
+)
+
+// maintainerGaterInterval is how often the maintainer epochs are checked for a change
+const maintainerGaterInterval = time.Minute
+
+// MaintainerGater keeps the allow list of the TSS p2p host in sync with the maintainers
+// of the current epoch and of the epoch in election
+type MaintainerGater struct {
+	logger   zerolog.Logger
+	bridge   sharedTypes.Bridge
+	comm     *p2p.Communication
+	m        *metrics.Metrics
+	current  *big.Int
+	election *big.Int
+	stopChan chan struct{}
+}
+
+// NewMaintainerGater create a new instance of MaintainerGater
+func NewMaintainerGater(bridge sharedTypes.Bridge, comm *p2p.Communication, m *metrics.Metrics) *MaintainerGater {
+	if m != nil {
+		comm.GetGater().SetRejectedCounter(m.GetCounterVec(metrics.P2PRejectedConnections))
+	}
+	return &MaintainerGater{
+		logger:   log.With().Str("module", "maintainer_gater").Logger(),
+		bridge:   bridge,
+		comm:     comm,
+		m:        m,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start loads the maintainer set and refreshes it whenever the epoch changes
+func (mg *MaintainerGater) Start() {
+	if err := mg.refresh(); err != nil {
+		mg.logger.Error().Err(err).Msg("fail to load maintainer peers")
+	}
+	go mg.run()
+}
+
+// Stop the maintainer gater
+func (mg *MaintainerGater) Stop() {
+	defer mg.logger.Info().Msg("maintainer gater stopped")
+	close(mg.stopChan)
+}
+
+func (mg *MaintainerGater) run() {
+	for {
+		select {
+		case <-mg.stopChan:
+			return
+		case <-time.After(maintainerGaterInterval):
+			if err := mg.refresh(); err != nil {
+				mg.logger.Error().Err(err).Msg("fail to refresh maintainer peers")
+			}
+		}
+	}
+}
+
+func (mg *MaintainerGater) refresh() error {
+	current, election, err := mg.bridge.GetMaintainerEpochs()
+	if err != nil {
+		return fmt.Errorf("fail to get maintainer epochs: %w", err)
+	}
+	if mg.current != nil && mg.current.Cmp(current) == 0 && mg.election.Cmp(election) == 0 {
+		return nil
+	}
+
+	epochs := []*big.Int{current}
+	if election.Sign() > 0 && election.Cmp(current) != 0 {
+		epochs = append(epochs, election)
+	}
+	peers := make([]peer.ID, 0)
+	for _, epoch := range epochs {
+		pubKeys, err := mg.bridge.GetEpochPubKeys(epoch)
+		if err != nil {
+			return fmt.Errorf("fail to get maintainers of epoch %s: %w", epoch, err)
+		}
+		for _, pk := range pubKeys {
+			id, err := conversion.GetPeerIDFromPubKeyByEth(pk.String())
+			if err != nil {
+				return fmt.Errorf("fail to get peer id of %s: %w", pk, err)
+			}
+			peers = append(peers, id)
+		}
+	}
+	if len(peers) == 0 {
+		mg.logger.Warn().Stringer("epoch", current).Msg("no maintainer found, keep the current allow list")
+		return nil
+	}
+
+	mg.comm.UpdateAllowedPeers(peers)
+	mg.current, mg.election = current, election
+	if mg.m != nil {
+		mg.m.GetGauge(metrics.P2PAllowedPeers).Set(float64(len(mg.comm.GetGater().AllowedPeers())))
+	}
+	mg.logger.Info().Stringer("epoch", current).Stringer("election", election).
+		Int("peers", len(peers)).Msg("updated maintainer peers")
+	return nil
+}
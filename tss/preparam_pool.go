@@ -0,0 +1,318 @@
+package tss
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/mapprotocol/compass-tss/metrics"
+)
+
+const (
+	// SaltPreParams is used before hashing the passphrase the pooled pre parameters are
+	// encrypted with.
+	SaltPreParams = 4
+
+	// preParamRetryInterval is how long to wait before generating again after a failure
+	preParamRetryInterval = 30 * time.Second
+
+	preParamFilePrefix = "preparams-"
+	preParamFileSuffix = ".enc"
+
+	// preParamPoolInfo binds the key derived for the pool to its use
+	preParamPoolInfo = "compass-tss pre parameters pool"
+)
+
+var (
+	// ErrPreParamsUnavailable is returned by Take when the pool stays empty for the whole
+	// wait.
+	ErrPreParamsUnavailable = errors.New("no pooled pre parameters available")
+
+	errPreParamPoolStopped = errors.New("pre parameters pool is stopped")
+)
+
+// PreParamPool keeps a number of freshly generated and validated pre parameters encrypted
+// on disk, generated in the background so neither startup nor keygens wait on the safe
+// primes. Every keygen takes a set which is thrown away.
+type PreParamPool struct {
+	logger   zerolog.Logger
+	dir      string
+	key      []byte
+	size     int
+	timeout  time.Duration
+	poolSize prometheus.Gauge
+	backlog  prometheus.Gauge
+	lock     sync.Mutex
+	wake     chan struct{}
+	added    chan struct{} // closed and replaced whenever pre parameters are added
+	stopChan chan struct{}
+
+	// generate is replaced in tests, safe primes take minutes
+	generate func(timeout time.Duration) (*bkeygen.LocalPreParams, error)
+}
+
+// NewPreParamPool create a new instance of PreParamPool storing size pre parameters in
+// dir, encrypted with the passphrase
+func NewPreParamPool(dir string, size int, timeout time.Duration, passphrase string, m *metrics.Metrics) (*PreParamPool, error) {
+	if size <= 0 {
+		return nil, errors.New("pre parameters pool size must be greater than zero")
+	}
+	if passphrase == "" {
+		return nil, errors.New("pre parameters pool passphrase is empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("fail to create pre parameters pool folder: %w", err)
+	}
+	p := &PreParamPool{
+		logger:   log.With().Str("module", "preparam_pool").Logger(),
+		dir:      dir,
+		key:      saltAndHash(passphrase, SaltPreParams),
+		size:     size,
+		timeout:  timeout,
+		wake:     make(chan struct{}, 1),
+		added:    make(chan struct{}),
+		stopChan: make(chan struct{}),
+		generate: func(timeout time.Duration) (*bkeygen.LocalPreParams, error) {
+			return bkeygen.GeneratePreParams(timeout)
+		},
+	}
+	if m != nil {
+		p.poolSize = m.GetGauge(metrics.TSSPreParamsPoolSize)
+		p.backlog = m.GetGauge(metrics.TSSPreParamsBacklog)
+	}
+	return p, nil
+}
+
+// PreParamPoolPassphrase derives the passphrase of the pre parameters pool from the node
+// private key with HKDF, the key itself never encrypts anything
+func PreParamPoolPassphrase(privKey []byte) (string, error) {
+	if len(privKey) == 0 {
+		return "", errors.New("private key is empty")
+	}
+	passphrase := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, privKey, nil, []byte(preParamPoolInfo)), passphrase); err != nil {
+		return "", fmt.Errorf("fail to derive pre parameters pool passphrase: %w", err)
+	}
+	return hex.EncodeToString(passphrase), nil
+}
+
+// Start generates pre parameters in the background whenever the pool is not full
+func (p *PreParamPool) Start() {
+	go p.run()
+}
+
+// Stop the pre parameters pool, a generation in progress is dropped
+func (p *PreParamPool) Stop() {
+	defer p.logger.Info().Msg("pre parameters pool stopped")
+	close(p.stopChan)
+}
+
+func (p *PreParamPool) run() {
+	p.logger.Info().Int("size", p.size).Msg("start pre parameters pool")
+	for {
+		available, err := p.Available()
+		if err != nil {
+			p.logger.Error().Err(err).Msg("fail to count pooled pre parameters")
+		}
+		if err == nil && available >= p.size {
+			select {
+			case <-p.stopChan:
+				return
+			case <-p.wake:
+			}
+			continue
+		}
+
+		start := time.Now()
+		preParams, err := p.newPreParams(p.timeout)
+		if err == nil {
+			err = p.save(preParams)
+		}
+		if err != nil {
+			p.logger.Error().Err(err).Msg("fail to add pre parameters to pool")
+			select {
+			case <-p.stopChan:
+				return
+			case <-time.After(preParamRetryInterval):
+			}
+			continue
+		}
+		p.logger.Info().Stringer("duration", time.Since(start)).Msg("pre parameters added to pool")
+
+		select {
+		case <-p.stopChan:
+			return
+		default:
+		}
+	}
+}
+
+// Take returns pooled pre parameters, they are removed from the pool whether they can be
+// used or not. When the pool is empty it waits up to timeout for the background
+// generation to add some, they are never generated on the caller's behalf.
+func (p *PreParamPool) Take(timeout time.Duration) (*bkeygen.LocalPreParams, error) {
+	defer p.notify()
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		preParams, added, err := p.take()
+		if err != nil || preParams != nil {
+			return preParams, err
+		}
+		p.notify()
+		p.logger.Warn().Stringer("timeout", timeout).Msg("pre parameters pool is empty, waiting for pre parameters")
+		select {
+		case <-added:
+		case <-deadline.C:
+			return nil, ErrPreParamsUnavailable
+		case <-p.stopChan:
+			return nil, errPreParamPoolStopped
+		}
+	}
+}
+
+// take removes the oldest valid pre parameters from the pool. When there are none it
+// returns the channel closed once pre parameters are added.
+func (p *PreParamPool) take() (*bkeygen.LocalPreParams, <-chan struct{}, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	files, err := p.files()
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, file := range files {
+		preParams, err := p.load(file)
+		if removeErr := os.Remove(file); removeErr != nil {
+			p.logger.Error().Err(removeErr).Str("file", file).Msg("fail to remove pooled pre parameters")
+		}
+		if err != nil {
+			p.logger.Error().Err(err).Str("file", file).Msg("drop invalid pooled pre parameters")
+			continue
+		}
+		p.updateMetrics(len(files) - i - 1)
+		return preParams, nil, nil
+	}
+	p.updateMetrics(0)
+	return nil, p.added, nil
+}
+
+// Available returns the number of pre parameters in the pool
+func (p *PreParamPool) Available() (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	files, err := p.files()
+	if err != nil {
+		return 0, err
+	}
+	p.updateMetrics(len(files))
+	return len(files), nil
+}
+
+// newPreParams generates new pre parameters, validated with their proofs
+func (p *PreParamPool) newPreParams(timeout time.Duration) (*bkeygen.LocalPreParams, error) {
+	preParams, err := p.generate(timeout)
+	if err != nil {
+		return nil, fmt.Errorf("fail to generate pre parameters: %w", err)
+	}
+	if preParams == nil || !preParams.ValidateWithProof() {
+		return nil, errors.New("invalid pre parameters")
+	}
+	return preParams, nil
+}
+
+// save encrypts the pre parameters into a new file of the pool, written aside first so a
+// partial file is never taken
+func (p *PreParamPool) save(preParams *bkeygen.LocalPreParams) error {
+	buf, err := json.Marshal(preParams)
+	if err != nil {
+		return fmt.Errorf("fail to marshal pre parameters: %w", err)
+	}
+	encrypted, err := encryptAES(p.key, buf)
+	if err != nil {
+		return fmt.Errorf("fail to encrypt pre parameters: %w", err)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	path := filepath.Join(p.dir, fmt.Sprintf("%s%d%s", preParamFilePrefix, time.Now().UnixNano(), preParamFileSuffix))
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, encrypted, 0o600); err != nil {
+		return fmt.Errorf("fail to write pre parameters: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("fail to write pre parameters: %w", err)
+	}
+	close(p.added)
+	p.added = make(chan struct{})
+	files, err := p.files()
+	if err == nil {
+		p.updateMetrics(len(files))
+	}
+	return nil
+}
+
+// load decrypts and validates the pre parameters of the file
+func (p *PreParamPool) load(file string) (*bkeygen.LocalPreParams, error) {
+	encrypted, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read pre parameters: %w", err)
+	}
+	buf, err := decryptAES(p.key, encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("fail to decrypt pre parameters: %w", err)
+	}
+	var preParams bkeygen.LocalPreParams
+	if err = json.Unmarshal(buf, &preParams); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal pre parameters: %w", err)
+	}
+	if !preParams.ValidateWithProof() {
+		return nil, errors.New("invalid pre parameters")
+	}
+	return &preParams, nil
+}
+
+// files returns the files of the pool, oldest first
+func (p *PreParamPool) files() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(p.dir, preParamFilePrefix+"*"+preParamFileSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("fail to list pooled pre parameters: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// notify wakes up the generation of pre parameters
+func (p *PreParamPool) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *PreParamPool) updateMetrics(available int) {
+	if p.poolSize != nil {
+		p.poolSize.Set(float64(available))
+	}
+	if p.backlog != nil {
+		backlog := p.size - available
+		if backlog < 0 {
+			backlog = 0
+		}
+		p.backlog.Set(float64(backlog))
+	}
+}
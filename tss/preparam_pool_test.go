@@ -0,0 +1,102 @@
+package tss
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	. "gopkg.in/check.v1"
+
+	"github.com/mapprotocol/compass-tss/tss/tsstest"
+)
+
+type PreParamPoolTestSuite struct{}
+
+var _ = Suite(&PreParamPoolTestSuite{})
+
+func (s *PreParamPoolTestSuite) TestPreParamPool(c *C) {
+	_, err := NewPreParamPool(c.MkDir(), 0, time.Minute, "passphrase", nil)
+	c.Assert(err, NotNil)
+	_, err = NewPreParamPool(c.MkDir(), 2, time.Minute, "", nil)
+	c.Assert(err, NotNil)
+
+	dir := c.MkDir()
+	pool, err := NewPreParamPool(dir, 2, time.Minute, "passphrase", nil)
+	c.Assert(err, IsNil)
+	preParams := tsstest.PreParams(c)
+	var generated int32
+	pool.generate = func(time.Duration) (*bkeygen.LocalPreParams, error) {
+		n := atomic.AddInt32(&generated, 1)
+		if int(n) > len(preParams) {
+			return nil, errors.New("no more pre parameters")
+		}
+		return preParams[n-1], nil
+	}
+
+	// pooled pre parameters are encrypted
+	c.Assert(pool.save(preParams[0]), IsNil)
+	c.Assert(pool.save(preParams[1]), IsNil)
+	available, err := pool.Available()
+	c.Assert(err, IsNil)
+	c.Assert(available, Equals, 2)
+	files, err := pool.files()
+	c.Assert(err, IsNil)
+	buf, err := os.ReadFile(files[0])
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(buf), "PaillierSK"), Equals, false)
+
+	// the oldest are taken first and thrown away
+	taken, err := pool.Take(time.Minute)
+	c.Assert(err, IsNil)
+	c.Assert(taken.NTildei.Cmp(preParams[0].NTildei), Equals, 0)
+	available, err = pool.Available()
+	c.Assert(err, IsNil)
+	c.Assert(available, Equals, 1)
+
+	// pre parameters that can't be decrypted are dropped, and an empty pool doesn't
+	// generate pre parameters for the caller
+	other, err := NewPreParamPool(dir, 2, time.Minute, "other passphrase", nil)
+	c.Assert(err, IsNil)
+	other.generate = pool.generate
+	_, err = other.Take(100 * time.Millisecond)
+	c.Assert(errors.Is(err, ErrPreParamsUnavailable), Equals, true)
+	c.Assert(atomic.LoadInt32(&generated), Equals, int32(0))
+	available, err = pool.Available()
+	c.Assert(err, IsNil)
+	c.Assert(available, Equals, 0)
+
+	// a take waits for the background generation, which fills the pool back up
+	pool.Start()
+	defer pool.Stop()
+	taken, err = pool.Take(5 * time.Second)
+	c.Assert(err, IsNil)
+	c.Assert(taken.NTildei.Cmp(preParams[0].NTildei), Equals, 0)
+	for i := 0; i < 50; i++ {
+		if available, _ = pool.Available(); available == 2 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(available, Equals, 2)
+	c.Assert(atomic.LoadInt32(&generated), Equals, int32(3))
+}
+
+func (s *PreParamPoolTestSuite) TestPreParamPoolPassphrase(c *C) {
+	_, err := PreParamPoolPassphrase(nil)
+	c.Assert(err, NotNil)
+	key := []byte("node private key")
+	passphrase, err := PreParamPoolPassphrase(key)
+	c.Assert(err, IsNil)
+	c.Assert(passphrase, HasLen, 64)
+	c.Assert(strings.Contains(passphrase, hex.EncodeToString(key)), Equals, false)
+	again, err := PreParamPoolPassphrase(key)
+	c.Assert(err, IsNil)
+	c.Assert(again, Equals, passphrase)
+	other, err := PreParamPoolPassphrase([]byte("other private key"))
+	c.Assert(err, IsNil)
+	c.Assert(other, Not(Equals), passphrase)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/cosmos/go-bip39"
 	"github.com/ethereum/go-ethereum/common"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -24,7 +25,7 @@ func Test_recoverKeyShares(t *testing.T) {
 		{
 			name: "test",
 			args: args{
-				path:       fmt.Sprintf("./localstate-%d.json", time.Now().UnixMilli()),
+				path:       filepath.Join(t.TempDir(), fmt.Sprintf("localstate-%d.json", time.Now().UnixMilli())),
 				keyShares:  common.Hex2Bytes(keySharesHex),
 				passphrase: Mnemonic,
 			},
@@ -12,7 +12,9 @@ import (
 
 	"github.com/cometbft/cometbft/crypto"
 	sdkTypes "github.com/cosmos/cosmos-sdk/types"
+	"github.com/mapprotocol/compass-tss/internal/tracing"
 	"github.com/mapprotocol/compass-tss/tss/go-tss/keysign"
+	"github.com/mapprotocol/compass-tss/tss/go-tss/tss"
 	"github.com/mapprotocol/compass-tss/x/types"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -280,7 +282,11 @@ func (s *KeySign) toLocalTSSSigner(poolPubKey string, tasks []*tssKeySignTask) {
 
 	s.logger.Info().Msgf("msgToSign to tss Local node PoolPubKey: %s, Messages: %+v, block height: %d", tssMsg.PoolPubKey, tssMsg.Messages, tssMsg.BlockHeight)
 
+	// the keysign spans of the orders carry the msg id the nodes exchange the keysign with
+	msgID, _ := tss.RequestMsgID(tssMsg)
+	start := time.Now()
 	keySignResp, err := s.server.KeySign(tssMsg)
+	tracing.Keysign(msgID, start, time.Now())
 	if err != nil {
 		s.setTssKeySignTasksFail(tasks, fmt.Errorf("fail tss keysign: %w", err))
 		return
@@ -0,0 +1,32 @@
+// Package tsstest provides the tss fixtures shared by the tests of several packages.
+package tsstest
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	bkeygen "github.com/binance-chain/tss-lib/ecdsa/keygen"
+	. "gopkg.in/check.v1"
+)
+
+// PreParams loads the pre parameters generated for the go-tss tests, safe primes take
+// minutes to generate
+func PreParams(c *C) []*bkeygen.LocalPreParams {
+	_, file, _, ok := runtime.Caller(0)
+	c.Assert(ok, Equals, true)
+	buf, err := os.ReadFile(filepath.Join(filepath.Dir(file), "..", "go-tss", "test_data", "preParam_test.data"))
+	c.Assert(err, IsNil)
+	var result []*bkeygen.LocalPreParams
+	for _, line := range strings.Split(strings.TrimSpace(string(buf)), "\n") {
+		val, err := hex.DecodeString(line)
+		c.Assert(err, IsNil)
+		var preParams bkeygen.LocalPreParams
+		c.Assert(json.Unmarshal(val, &preParams), IsNil)
+		result = append(result, &preParams)
+	}
+	return result
+}
@@ -0,0 +1,237 @@
+package memo
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	ecommon "github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/constants"
+)
+
+// The outcomes of a deposit to a vault, as observed with its memo
+const (
+	// OutcomeCross is a deposit sent on to the destination chain of the memo
+	OutcomeCross = "cross"
+	// OutcomeDeposit is a deposit credited to the receiver of the memo on MAP
+	OutcomeDeposit = "deposit"
+	// OutcomeRefund is a deposit refunded to the sender because of its memo
+	OutcomeRefund = "refund"
+	// OutcomeIgnored is a deposit that is not observed
+	OutcomeIgnored = "ignored"
+)
+
+// Resolver looks up on the relay chain the chain and the affiliates a memo refers to
+type Resolver interface {
+	GetChainID(name string) (*big.Int, error)
+	GetAffiliateIDByName(name string) (uint16, error)
+	GetAffiliateIDByAlias(alias string) (uint16, error)
+}
+
+// Explanation is the breakdown of a memo deposited on a source chain, read the way the
+// chain clients observe it
+type Explanation struct {
+	Memo        string `json:"memo"`
+	SourceChain string `json:"source_chain"`
+	Type        string `json:"type,omitempty"`
+	Valid       bool   `json:"valid"`
+	// Outcome is what becomes of a deposit with the memo, one of the Outcome constants
+	Outcome string `json:"outcome"`
+
+	DestinationChain   string `json:"destination_chain,omitempty"`
+	DestinationChainID string `json:"destination_chain_id,omitempty"`
+	// Receiver is the address in the memo, ReceiverBytes the address it decodes to on
+	// the destination chain
+	Receiver      string               `json:"receiver,omitempty"`
+	ReceiverBytes string               `json:"receiver_bytes,omitempty"`
+	Token         string               `json:"token,omitempty"`
+	MinAmount     string               `json:"min_amount,omitempty"`
+	OrderID       string               `json:"order_id,omitempty"`
+	Batch         string               `json:"batch,omitempty"`
+	Affiliates    []ExplainedAffiliate `json:"affiliates,omitempty"`
+
+	// Errors are why the memo is refunded or ignored, Warnings are accepted but likely
+	// not what the sender meant
+	Errors   []string `json:"errors,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ExplainedAffiliate is an affiliate of a memo, ID is its id on the relay chain when it
+// was resolved
+type ExplainedAffiliate struct {
+	Name       string  `json:"name"`
+	Bps        string  `json:"bps"`
+	Compressed bool    `json:"compressed"`
+	ID         *uint16 `json:"id,omitempty"`
+}
+
+func (e *Explanation) addErr(format string, args ...interface{}) {
+	e.Errors = append(e.Errors, fmt.Sprintf(format, args...))
+}
+
+func (e *Explanation) addWarning(format string, args ...interface{}) {
+	e.Warnings = append(e.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Explain parses the memo of a deposit on the source chain with the parser of ParseMemo
+// and reports every failure of it. The destination chain and the affiliates are looked up
+// with the resolver, they are only checked locally when it is nil.
+func Explain(memo string, source common.Chain, resolver Resolver) Explanation {
+	e := Explanation{Memo: memo, SourceChain: source.String(), Outcome: OutcomeIgnored}
+	if info, ok := common.GetChainInfo(source); ok {
+		e.SourceChain = info.Chain.String()
+	}
+	// only the utxo and xrp clients route deposits by their memo
+	utxoSource := source.IsUTXO()
+	if !utxoSource && !common.XRPChain.Equals(common.Chain(e.SourceChain)) {
+		e.addErr("deposits on %s are not routed by memo", source)
+		return e
+	}
+	if len(memo) > constants.MaxMemoSize {
+		e.addErr("memo is %d bytes, longer than the max of %d", len(memo), constants.MaxMemoSize)
+		return e
+	}
+	if max := common.Chain(e.SourceChain).MaxMemoLength(); len(memo) > max {
+		e.addWarning("memo is %d bytes, %s only carries %d", len(memo), e.SourceChain, max)
+	}
+
+	m, errs := explainParse(memo)
+	if m != nil {
+		e.Type = m.GetType().String()
+		e.explainFields(m)
+	}
+	for _, err := range errs {
+		e.addErr("%s", err)
+	}
+	if len(errs) > 0 {
+		// xrp skips the payments it can not parse, utxo chains refund them
+		if utxoSource {
+			e.Outcome = OutcomeRefund
+		}
+		return e
+	}
+
+	switch m.GetType() {
+	case TxOutbound, TxAdd:
+	default:
+		e.addErr("memo type %s is only sent by vaults, a deposit with it is not observed", m.GetType())
+		return e
+	}
+
+	toBytes, err := m.GetChain().DecodeAddress(m.GetDestination())
+	if err != nil {
+		e.addErr("cannot decode receiver '%s' as an address of %s: %s", m.GetDestination(), memoChainName(m), err)
+		e.Outcome = OutcomeRefund
+		return e
+	}
+	e.ReceiverBytes = ecommon.Bytes2Hex(toBytes)
+
+	if m.IsType(TxAdd) {
+		e.Valid = true
+		e.Outcome = OutcomeDeposit
+		return e
+	}
+
+	chainID, err := m.GetChain().ChainID()
+	if resolver != nil {
+		chainID, err = resolver.GetChainID(m.GetChain().String())
+	}
+	if err != nil {
+		e.addErr("fail to get destination chain id of '%s': %s", m.GetChain(), err)
+		return e
+	}
+	e.DestinationChainID = chainID.String()
+	if resolver != nil && !e.resolveAffiliates(resolver) {
+		return e
+	}
+	e.Valid = true
+	e.Outcome = OutcomeCross
+	return e
+}
+
+// explainParse parses the memo and returns each of the failures of the parser once
+func explainParse(memo string) (Memo, []string) {
+	p, err := newParser(memo)
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	m, err := p.parse()
+	var errs []string
+	seen := make(map[string]bool)
+	for _, e := range p.errs {
+		if !seen[e.Error()] {
+			seen[e.Error()] = true
+			errs = append(errs, e.Error())
+		}
+	}
+	if err != nil && len(errs) == 0 {
+		errs = append(errs, err.Error())
+	}
+	return m, errs
+}
+
+func memoChainName(m Memo) string {
+	if m.GetChain().IsEmpty() {
+		return "MAP"
+	}
+	return m.GetChain().String()
+}
+
+func (e *Explanation) explainFields(m Memo) {
+	e.Receiver = m.GetDestination()
+	e.OrderID = m.GetOrderID()
+	switch memo := m.(type) {
+	case OutboundMemo:
+		e.DestinationChain = memo.Chain
+		if info, ok := common.GetChainInfo(memo.GetChain()); ok {
+			e.DestinationChain = info.Chain.String()
+		}
+		e.Token = memo.Token
+		if memo.Amount != nil {
+			e.MinAmount = memo.Amount.String()
+		}
+	case AddLiquidityMemo:
+		e.DestinationChain = common.MAPChain.String()
+	case BatchMemo:
+		e.Batch = fmt.Sprintf("batch %d of the window from relay height %d, paid %b", memo.Batch, memo.Window, memo.Paid)
+	}
+
+	total := new(big.Int)
+	for _, aff := range m.GetAffiliates() {
+		if aff == nil {
+			continue
+		}
+		e.Affiliates = append(e.Affiliates, ExplainedAffiliate{Name: aff.Name, Bps: aff.Bps.String(), Compressed: aff.Compressed})
+		total.Add(total, aff.Bps)
+		if !aff.Bps.IsUint64() || aff.Bps.Uint64() > math.MaxUint16 {
+			e.addWarning("bps %s of affiliate %s does not fit in 16 bits", aff.Bps, aff.Name)
+		}
+	}
+	if total.Cmp(maxBps) > 0 {
+		e.addWarning("affiliates take %s bps, more than %s", total, maxBps)
+	}
+}
+
+// resolveAffiliates looks up the ids of the affiliates, a compressed affiliate name is
+// an alias
+func (e *Explanation) resolveAffiliates(resolver Resolver) bool {
+	ok := true
+	for i, aff := range e.Affiliates {
+		var id uint16
+		var err error
+		if aff.Compressed {
+			id, err = resolver.GetAffiliateIDByAlias(aff.Name)
+		} else {
+			id, err = resolver.GetAffiliateIDByName(aff.Name)
+		}
+		if err != nil {
+			e.addErr("fail to get id of affiliate %s: %s", aff.Name, err)
+			ok = false
+			continue
+		}
+		e.Affiliates[i].ID = &id
+	}
+	return ok
+}
@@ -0,0 +1,160 @@
+package memo_test
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/mapprotocol/compass-tss/common"
+	"github.com/mapprotocol/compass-tss/x/memo"
+)
+
+type fakeResolver struct{}
+
+func (fakeResolver) GetChainID(name string) (*big.Int, error) {
+	if strings.EqualFold(name, "eth") {
+		return big.NewInt(1), nil
+	}
+	return nil, errors.New("chain not registered")
+}
+
+func (fakeResolver) GetAffiliateIDByName(name string) (uint16, error) {
+	if name == "alice" {
+		return 7, nil
+	}
+	return 0, errors.New("affiliate not registered")
+}
+
+func (fakeResolver) GetAffiliateIDByAlias(alias string) (uint16, error) {
+	if alias == "bt" {
+		return 9, nil
+	}
+	return 0, errors.New("alias not registered")
+}
+
+func TestExplain(t *testing.T) {
+	const receiver = "0x4a4f0d7d412f1d47fa45c434cecf05f2f8a434f7"
+	tests := []struct {
+		name     string
+		memo     string
+		source   common.Chain
+		outcome  string
+		errorHas string
+	}{
+		{
+			name:    "cross",
+			memo:    "Mx|Eth|USDT|" + receiver + "|690943|alice:30",
+			source:  common.BTCChain,
+			outcome: memo.OutcomeCross,
+		},
+		{
+			name:    "deposit",
+			memo:    "M+|" + receiver,
+			source:  common.XRPChain,
+			outcome: memo.OutcomeDeposit,
+		},
+		{
+			name:    "source chain in upper case",
+			memo:    "M+|" + receiver,
+			source:  common.Chain("XRP"),
+			outcome: memo.OutcomeDeposit,
+		},
+		{
+			name:     "source without memos",
+			memo:     "M+|" + receiver,
+			source:   common.BSCChain,
+			outcome:  memo.OutcomeIgnored,
+			errorHas: "not routed by memo",
+		},
+		{
+			name:     "unknown type refunded on utxo",
+			memo:     "=:ETH.ETH:" + receiver,
+			source:   common.BTCChain,
+			outcome:  memo.OutcomeRefund,
+			errorHas: "invalid tx type",
+		},
+		{
+			name:     "unknown type ignored on xrp",
+			memo:     "=:ETH.ETH:" + receiver,
+			source:   common.XRPChain,
+			outcome:  memo.OutcomeIgnored,
+			errorHas: "invalid tx type",
+		},
+		{
+			name:     "missing min amount",
+			memo:     "Mx|Eth|USDT|" + receiver,
+			source:   common.BTCChain,
+			outcome:  memo.OutcomeRefund,
+			errorHas: "cannot parse empty string",
+		},
+		{
+			name:     "invalid receiver",
+			memo:     "Mx|Eth|USDT|bc1qxyz|1",
+			source:   common.BTCChain,
+			outcome:  memo.OutcomeRefund,
+			errorHas: "cannot decode receiver 'bc1qxyz'",
+		},
+		{
+			name:     "vault memo",
+			memo:     "M<|Btc|0xe72673a3fc50d39e83cffd4815c82b8192f5fe99027261d7c0d84ec10be06513",
+			source:   common.BTCChain,
+			outcome:  memo.OutcomeIgnored,
+			errorHas: "only sent by vaults",
+		},
+		{
+			name:     "unregistered destination",
+			memo:     "Mx|Bsc|USDT|" + receiver + "|1",
+			source:   common.BTCChain,
+			outcome:  memo.OutcomeIgnored,
+			errorHas: "fail to get destination chain id of 'Bsc'",
+		},
+		{
+			name:     "unregistered affiliate",
+			memo:     "Mx|Eth|USDT|" + receiver + "|1|bob:10",
+			source:   common.BTCChain,
+			outcome:  memo.OutcomeIgnored,
+			errorHas: "fail to get id of affiliate bob",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := memo.Explain(tt.memo, tt.source, fakeResolver{})
+			if e.Outcome != tt.outcome {
+				t.Fatalf("expected outcome %s, got %s: %+v", tt.outcome, e.Outcome, e)
+			}
+			if e.Valid != (tt.errorHas == "") {
+				t.Fatalf("unexpected valid %v: %+v", e.Valid, e)
+			}
+			if tt.errorHas != "" && (len(e.Errors) == 0 || !strings.Contains(strings.Join(e.Errors, "\n"), tt.errorHas)) {
+				t.Fatalf("expected an error with %q, got %v", tt.errorHas, e.Errors)
+			}
+		})
+	}
+}
+
+func TestExplainBreakdown(t *testing.T) {
+	e := memo.Explain("Mx|eth|USDT|0x4A4F0D7D412F1D47FA45C434CECF05F2F8A434F7|1e6|bt6000", common.DOGEChain, fakeResolver{})
+	if !e.Valid || e.SourceChain != common.DOGEChain.String() || e.DestinationChain != common.ETHChain.String() {
+		t.Fatalf("unexpected explanation: %+v", e)
+	}
+	if e.DestinationChainID != "1" || e.Token != "USDT" || e.MinAmount != "1000000" {
+		t.Fatalf("unexpected destination: %+v", e)
+	}
+	if e.ReceiverBytes != "4a4f0d7d412f1d47fa45c434cecf05f2f8a434f7" {
+		t.Fatalf("unexpected receiver: %s", e.ReceiverBytes)
+	}
+	if len(e.Affiliates) != 1 || !e.Affiliates[0].Compressed || e.Affiliates[0].ID == nil || *e.Affiliates[0].ID != 9 {
+		t.Fatalf("unexpected affiliates: %+v", e.Affiliates)
+	}
+	// accepted, but above the max fee
+	if len(e.Warnings) != 1 || !strings.Contains(e.Warnings[0], "6000 bps") {
+		t.Fatalf("unexpected warnings: %v", e.Warnings)
+	}
+
+	// without a resolver only the local registry is used
+	e = memo.Explain("Mx|Eth|USDT|0x4a4f0d7d412f1d47fa45c434cecf05f2f8a434f7|1|carol:10", common.BTCChain, nil)
+	if !e.Valid || e.Affiliates[0].ID != nil {
+		t.Fatalf("unexpected local explanation: %+v", e)
+	}
+}
@@ -20,6 +20,7 @@ const (
 	TxAdd
 	TxMigrate
 	TxException
+	TxBatch
 )
 
 var txToStringMap = map[TxType]string{
@@ -29,6 +30,7 @@ var txToStringMap = map[TxType]string{
 	TxAdd:       "M+",
 	TxMigrate:   "M~",
 	TxException: "M?",
+	TxBatch:     "M*",
 }
 
 var stringToTxTypeMap = map[string]TxType{
@@ -38,6 +40,7 @@ var stringToTxTypeMap = map[string]TxType{
 	"m+": TxAdd,
 	"m~": TxMigrate,
 	"m?": TxException,
+	"m*": TxBatch,
 }
 
 func StringToTxType(s string) (TxType, error) {
@@ -0,0 +1,55 @@
+package memo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// BatchMemo is the memo of an outbound paying several orders of a vault in one
+// transaction. The outbounds of a vault emitted by the relay within a window of blocks
+// are split into batches in the same way on every node, the memo names the window, the
+// batch of the window and which of its outbounds are paid, an output each in order.
+// Each order is still observed with the memo it would be paid with on its own.
+type BatchMemo struct {
+	MemoBase
+	Window int64  // first relay chain height of the window
+	Batch  int    // index of the batch among the batches of the window
+	Paid   uint64 // bit i is set when the i-th outbound of the batch is paid
+}
+
+// String returns a string representation of the memo
+// format: M*|window|batch|paid
+func (m BatchMemo) String() string {
+	return fmt.Sprintf("%s|%d|%d|%x", m.TxType.String(), m.Window, m.Batch, m.Paid)
+}
+
+func NewBatchMemo(window int64, batch int, paid uint64) BatchMemo {
+	return BatchMemo{
+		MemoBase: MemoBase{TxType: TxBatch},
+		Window:   window,
+		Batch:    batch,
+		Paid:     paid,
+	}
+}
+
+// NewBatchID returns the batch id of the given order ids, in output order
+func NewBatchID(orderIDs []string) string {
+	return crypto.Keccak256Hash([]byte(strings.ToLower(strings.Join(orderIDs, "|")))).Hex()
+}
+
+func (p *parser) ParseBatchMemo() (BatchMemo, error) {
+	p.hasMinParams(4)
+	window := p.getInt64(1, true, 0)
+	batch := p.getInt64(2, true, 0)
+	if window < 0 || batch < 0 {
+		p.addErr(fmt.Errorf("invalid batch: %d|%d", window, batch))
+	}
+	paid, err := strconv.ParseUint(p.get(3), 16, 64)
+	if err != nil || paid == 0 {
+		p.addErr(fmt.Errorf("invalid paid outbounds: %s", p.get(3)))
+	}
+	return NewBatchMemo(window, int(batch), paid), p.Error()
+}
@@ -72,6 +72,8 @@ func (p *parser) parse() (mem Memo, err error) {
 		return p.ParseMigrateMemo()
 	case TxException:
 		return p.ParseExceptionMemo()
+	case TxBatch:
+		return p.ParseBatchMemo()
 	default:
 		return EmptyMemo, fmt.Errorf("TxType not supported: %s", p.getType().String())
 	}
@@ -59,3 +59,23 @@ func TestParseMemo(t *testing.T) {
 		})
 	}
 }
+
+func TestBatchMemo(t *testing.T) {
+	m := memo.NewBatchMemo(12345600, 2, 0x1ff)
+	if len(m.String()) > 80 {
+		t.Fatalf("batch memo %s does not fit in OP_RETURN", m)
+	}
+
+	got, err := memo.ParseMemo(m.String())
+	if err != nil {
+		t.Fatalf("ParseMemo() failed: %v", err)
+	}
+	if !got.IsType(memo.TxBatch) || got.(memo.BatchMemo) != m {
+		t.Errorf("ParseMemo() = %v, want %v", got, m)
+	}
+	for _, invalid := range []string{"M*|0x01", "M*|100|0", "M*|100|0|0", "M*|100|-1|3", "M*|100|0|zz"} {
+		if _, err = memo.ParseMemo(invalid); err == nil {
+			t.Errorf("ParseMemo(%s) succeeded with invalid batch", invalid)
+		}
+	}
+}